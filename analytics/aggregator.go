@@ -0,0 +1,580 @@
+// Package analytics aggregates the answers of many responses of a survey into per-question summaries:
+// option counts for choice questions, descriptive statistics for sliders, distributions for dates,
+// lengths for free text, and the skip rate of every question among the respondents who saw it.
+// Responses can be weighted, e.g. with the weights computed by Rake or PostStratify.
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+const (
+	// maxStepBins is the maximum number of slider steps getting one histogram bin each.
+	maxStepBins = 25
+
+	// defaultBins is the number of histogram bins of sliders with more steps.
+	defaultBins = 10
+
+	// maxWords is the maximum number of words of a text summary.
+	maxWords = 50
+)
+
+// Aggregator accumulates the answers of many responses of a survey.
+//
+// Visibility follows Survey.GetVisibleQuestions: a question is seen by a respondent if it is visible,
+// its group is visible and enabled, and both satisfy their dependsOn conditions for the answers of the
+// respondent. Questions inside repeatable groups are evaluated once per group instance, with the
+// instance answers added to the answers of the response.
+//
+// An Aggregator is not safe for concurrent use.
+type Aggregator struct {
+	survey    *surveygo.Survey
+	questions []*questionAcc
+	weights   weightAcc
+}
+
+// tally is a count with its weighted count.
+type tally struct {
+	n int
+	w float64
+}
+
+func (t *tally) add(w float64) {
+	t.n++
+	t.w += w
+}
+
+// weighted is a value with its weight.
+type weighted struct {
+	v, w float64
+}
+
+// questionAcc accumulates the answers of a question.
+type questionAcc struct {
+	ref      questionRef
+	seen     tally
+	answered tally
+	// answeredSq is the sum of the squared weights of the answers, for the effective sample size
+	answeredSq float64
+
+	// choice and toggle
+	options []OptionCount
+	counts  map[string]*tally
+	unknown int
+
+	// slider
+	numbers []weighted
+
+	// date_time
+	dates          tally
+	invalidDates   int
+	byDay, byMonth map[string]*tally
+	byWeekday      [7]tally
+
+	// free text
+	texts                tally
+	lengthSum            int
+	weightedLengthSum    float64
+	minLength, maxLength int
+	words                map[string]*tally
+}
+
+// NewAggregator creates an aggregator of the answers of a survey.
+// Args:
+//   - survey: the survey the answers belong to
+//
+// Returns:
+//   - *Aggregator: the aggregator, without responses
+//   - error: if the survey groups are invalid (e.g. cycles or missing groups)
+func NewAggregator(survey *surveygo.Survey) (*Aggregator, error) {
+	refs, err := walkQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("walking survey groups: %w", err)
+	}
+
+	agg := &Aggregator{survey: survey}
+	for _, ref := range refs {
+		agg.questions = append(agg.questions, newQuestionAcc(ref))
+	}
+	return agg, nil
+}
+
+func newQuestionAcc(ref questionRef) *questionAcc {
+	acc := &questionAcc{ref: ref}
+	q := ref.question
+	switch {
+	case types.IsSimpleChoiceType(q.QTyp):
+		for _, opt := range choiceOptions(q) {
+			acc.options = append(acc.options, OptionCount{NameId: opt.NameId, Label: opt.Label})
+		}
+		acc.counts = make(map[string]*tally)
+	case q.QTyp == types.QTypeToggle:
+		acc.options = toggleOptions(q.Value)
+		acc.counts = make(map[string]*tally)
+	case q.QTyp == types.QTypeDateTime:
+		acc.byDay = make(map[string]*tally)
+		acc.byMonth = make(map[string]*tally)
+	case isFreeText(q.QTyp):
+		acc.words = make(map[string]*tally)
+	}
+	return acc
+}
+
+// toggleOptions returns the "true" and "false" options of a toggle, labelled by the on and off labels
+// of the toggle, or by its first and second options when the toggle is defined with options.
+func toggleOptions(value any) []OptionCount {
+	on, off := "true", "false"
+	switch v := value.(type) {
+	case *choice.Toggle:
+		on, off = v.OnLabel, v.OffLabel
+	case *choice.Choice:
+		if len(v.Options) == 2 {
+			on, off = v.Options[0].Label, v.Options[1].Label
+		}
+	}
+	return []OptionCount{{NameId: "true", Label: on}, {NameId: "false", Label: off}}
+}
+
+func isFreeText(qt types.QuestionType) bool {
+	switch qt {
+	case types.QTypeInputText, types.QTypeTextArea, types.QTypeEmail, types.QTypeTelephone, types.QTypeIdentificationNumber:
+		return true
+	}
+	return false
+}
+
+// Add adds the answers of a response, with weight 1.
+// Args:
+//   - answers: the answers of the response
+func (a *Aggregator) Add(answers surveygo.Answers) {
+	a.add(answers, 1)
+}
+
+// AddWeighted adds the answers of a response with the given weight.
+// Args:
+//   - answers: the answers of the response
+//   - weight: the weight of the response, e.g. from Rake or PostStratify
+//
+// Returns:
+//   - error: if the weight is negative, infinite or NaN
+func (a *Aggregator) AddWeighted(answers surveygo.Answers, weight float64) error {
+	if err := checkWeight(weight); err != nil {
+		return err
+	}
+	a.add(answers, weight)
+	return nil
+}
+
+func (a *Aggregator) add(answers surveygo.Answers, w float64) {
+	a.weights.add(w)
+
+	// scopes and visibility are shared by the questions of the same repeatable groups
+	type scope struct {
+		answers surveygo.Answers
+		visible map[string]string
+	}
+	cache := make(map[string][]scope)
+
+	for _, acc := range a.questions {
+		key := strings.Join(acc.ref.repeats, "/")
+		scs, ok := cache[key]
+		if !ok {
+			for _, ans := range scopes(answers, acc.ref.repeats) {
+				scs = append(scs, scope{answers: ans, visible: a.survey.GetVisibleQuestions(ans)})
+			}
+			cache[key] = scs
+		}
+
+		id := acc.ref.question.NameId
+		for _, sc := range scs {
+			if _, ok := sc.visible[id]; !ok {
+				continue
+			}
+			acc.seen.add(w)
+			if acc.add(sc.answers[id], w) {
+				acc.answered.add(w)
+				acc.answeredSq += w * w
+			}
+		}
+	}
+}
+
+// add accumulates an answer and reports whether it is not empty.
+func (acc *questionAcc) add(ans []any, w float64) bool {
+	if isEmpty(ans) {
+		return false
+	}
+
+	q := acc.ref.question
+	switch {
+	case types.IsSimpleChoiceType(q.QTyp):
+		for _, v := range selectedValues(ans) {
+			acc.count(v, w)
+		}
+	case q.QTyp == types.QTypeToggle:
+		if b, ok := toggleValue(ans); ok {
+			acc.count(strconv.FormatBool(b), w)
+		} else {
+			acc.unknown++
+		}
+	case q.QTyp == types.QTypeSlider:
+		if f, ok := numberValue(ans); ok {
+			acc.numbers = append(acc.numbers, weighted{v: f, w: w})
+		}
+	case q.QTyp == types.QTypeDateTime:
+		acc.addDate(q.Value, textValue(ans), w)
+	case isFreeText(q.QTyp):
+		acc.addText(q.QTyp, textValue(ans), w)
+	}
+	return true
+}
+
+func (acc *questionAcc) count(nameId string, w float64) {
+	for _, opt := range acc.options {
+		if opt.NameId == nameId {
+			addTo(acc.counts, nameId, w)
+			return
+		}
+	}
+	acc.unknown++
+}
+
+func addTo(m map[string]*tally, key string, w float64) {
+	t, ok := m[key]
+	if !ok {
+		t = &tally{}
+		m[key] = t
+	}
+	t.add(w)
+}
+
+func (acc *questionAcc) addDate(value any, v string, w float64) {
+	dt, ok := value.(*text.DateTime)
+	if !ok {
+		return
+	}
+	t, err := time.Parse(dt.Format, v)
+	if err != nil {
+		acc.invalidDates++
+		return
+	}
+	acc.dates.add(w)
+	if dt.Type == text.DateTypeFormatTime {
+		return
+	}
+	addTo(acc.byDay, t.Format("2006-01-02"), w)
+	addTo(acc.byMonth, t.Format("2006-01"), w)
+	// Monday first
+	acc.byWeekday[(int(t.Weekday())+6)%7].add(w)
+}
+
+func (acc *questionAcc) addText(qt types.QuestionType, v string, w float64) {
+	length := utf8.RuneCountInString(v)
+	if acc.texts.n == 0 || length < acc.minLength {
+		acc.minLength = length
+	}
+	if length > acc.maxLength {
+		acc.maxLength = length
+	}
+	acc.texts.add(w)
+	acc.lengthSum += length
+	acc.weightedLengthSum += float64(length) * w
+
+	if qt != types.QTypeInputText && qt != types.QTypeTextArea {
+		return
+	}
+	words := strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		addTo(acc.words, word, w)
+	}
+}
+
+// isEmpty reports whether an answer has no values.
+func isEmpty(ans []any) bool {
+	for _, v := range ans {
+		if v == nil {
+			continue
+		}
+		if s, ok := v.(string); ok && strings.TrimSpace(s) == "" {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// Responses returns the number of responses added.
+func (a *Aggregator) Responses() int {
+	return a.weights.count
+}
+
+// Results returns the summaries of the answers added so far.
+// The aggregator can keep accumulating responses after Results is called.
+func (a *Aggregator) Results() *Results {
+	res := &Results{SurveyNameId: a.survey.NameId, Responses: a.weights.count, Weights: a.weights.summary()}
+	for _, acc := range a.questions {
+		res.Questions = append(res.Questions, acc.summary())
+	}
+	return res
+}
+
+func (acc *questionAcc) summary() *QuestionSummary {
+	q := acc.ref.question
+	s := &QuestionSummary{
+		NameId:           q.NameId,
+		Label:            questionLabel(q),
+		Type:             string(q.QTyp),
+		GroupNameId:      acc.ref.group,
+		Seen:             acc.seen.n,
+		Answered:         acc.answered.n,
+		WeightedSeen:     acc.seen.w,
+		WeightedAnswered: acc.answered.w,
+	}
+	if acc.seen.n > 0 {
+		s.SkipRate = float64(acc.seen.n-acc.answered.n) / float64(acc.seen.n)
+	}
+	if acc.seen.w > 0 {
+		s.WeightedSkipRate = (acc.seen.w - acc.answered.w) / acc.seen.w
+	}
+	if acc.answeredSq > 0 {
+		s.EffectiveAnswered = acc.answered.w * acc.answered.w / acc.answeredSq
+	}
+
+	switch {
+	case acc.counts != nil:
+		s.Choice = acc.choiceSummary()
+	case q.QTyp == types.QTypeSlider:
+		slider, _ := choice.CastToSlider(q.Value)
+		s.Numeric = numericSummary(acc.numbers, slider)
+	case acc.byDay != nil:
+		s.Date = acc.dateSummary()
+	case acc.words != nil:
+		s.Text = acc.textSummary()
+	}
+	return s
+}
+
+func (acc *questionAcc) choiceSummary() *ChoiceSummary {
+	cs := &ChoiceSummary{Options: make([]OptionCount, 0, len(acc.options)), Unknown: acc.unknown}
+	for _, opt := range acc.options {
+		if t, ok := acc.counts[opt.NameId]; ok {
+			opt.Count, opt.WeightedCount = t.n, t.w
+		}
+		if acc.answered.n > 0 {
+			opt.Percent = float64(opt.Count) / float64(acc.answered.n) * 100
+		}
+		if acc.answered.w > 0 {
+			opt.WeightedPercent = opt.WeightedCount / acc.answered.w * 100
+		}
+		cs.Options = append(cs.Options, opt)
+	}
+	return cs
+}
+
+// numericSummary computes the statistics of the given values.
+// Slider histograms have one bin per step when the slider has few steps.
+func numericSummary(values []weighted, slider *choice.Slider) *NumericSummary {
+	ns := &NumericSummary{Count: len(values)}
+	if len(values) == 0 {
+		return ns
+	}
+
+	sorted := append([]weighted(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].v < sorted[j].v })
+
+	var sum, wSum, wSqSum float64
+	for _, x := range sorted {
+		sum += x.v
+		wSum += x.w
+		wSqSum += x.w * x.w
+	}
+	n := float64(len(sorted))
+	ns.Min, ns.Max = sorted[0].v, sorted[len(sorted)-1].v
+	ns.Mean = sum / n
+	ns.Median = weightedMedian(sorted, func(weighted) float64 { return 1 })
+	if wSum > 0 {
+		for _, x := range sorted {
+			ns.WeightedMean += x.v * x.w
+		}
+		ns.WeightedMean /= wSum
+		ns.WeightedMedian = weightedMedian(sorted, func(x weighted) float64 { return x.w })
+	}
+
+	var sq, wSq float64
+	for _, x := range sorted {
+		sq += (x.v - ns.Mean) * (x.v - ns.Mean)
+		wSq += x.w * (x.v - ns.WeightedMean) * (x.v - ns.WeightedMean)
+	}
+	if len(sorted) > 1 {
+		ns.StdDev = math.Sqrt(sq / (n - 1))
+	}
+	// reliability weights: wSum - wSqSum/wSum is n - 1 for unit weights
+	if denom := wSum - wSqSum/wSum; wSum > 0 && denom > 0 {
+		ns.WeightedStdDev = math.Sqrt(wSq / denom)
+	}
+	ns.Histogram = histogram(sorted, slider)
+	if slider != nil && slider.Min >= 0 && slider.Max <= 10 {
+		ns.NPS = npsSummary(sorted)
+	}
+	return ns
+}
+
+// npsSummary classifies the values in promoters (9 or more), passives (7 or more) and detractors.
+func npsSummary(values []weighted) *NPSSummary {
+	nps := &NPSSummary{}
+	var wTotal float64
+	for _, x := range values {
+		wTotal += x.w
+		switch {
+		case x.v >= 9:
+			nps.Promoters++
+			nps.WeightedPromoters += x.w
+		case x.v >= 7:
+			nps.Passives++
+			nps.WeightedPassives += x.w
+		default:
+			nps.Detractors++
+			nps.WeightedDetractors += x.w
+		}
+	}
+	if n := len(values); n > 0 {
+		nps.Score = float64(nps.Promoters-nps.Detractors) / float64(n) * 100
+	}
+	if wTotal > 0 {
+		nps.WeightedScore = (nps.WeightedPromoters - nps.WeightedDetractors) / wTotal * 100
+	}
+	return nps
+}
+
+// weightedMedian returns the value splitting the sorted values in two halves of equal weight,
+// averaging the two middle values when the split falls between them.
+func weightedMedian(sorted []weighted, weight func(weighted) float64) float64 {
+	var total float64
+	for _, x := range sorted {
+		total += weight(x)
+	}
+	half := total / 2
+
+	var cum float64
+	for i, x := range sorted {
+		cum += weight(x)
+		if math.Abs(cum-half) <= 1e-9*total && i+1 < len(sorted) {
+			// the split falls between this value and the next one with weight
+			for _, next := range sorted[i+1:] {
+				if weight(next) > 0 {
+					return (x.v + next.v) / 2
+				}
+			}
+			return x.v
+		}
+		if cum > half {
+			return x.v
+		}
+	}
+	return sorted[len(sorted)-1].v
+}
+
+// histogram bins the sorted values over the range of the slider, widened to the observed values.
+func histogram(sorted []weighted, slider *choice.Slider) []Bin {
+	lo, hi := sorted[0].v, sorted[len(sorted)-1].v
+	var step float64
+	if slider != nil {
+		lo, hi = math.Min(lo, float64(slider.Min)), math.Max(hi, float64(slider.Max))
+		step = float64(slider.Step)
+	}
+
+	var bins []Bin
+	switch {
+	case lo == hi:
+		bins = []Bin{{From: lo, To: hi}}
+	case step > 0 && (hi-lo)/step < maxStepBins:
+		for from := lo; from <= hi; from += step {
+			bins = append(bins, Bin{From: from, To: from + step})
+		}
+	default:
+		width := (hi - lo) / defaultBins
+		for i := 0; i < defaultBins; i++ {
+			bins = append(bins, Bin{From: lo + float64(i)*width, To: lo + float64(i+1)*width})
+		}
+		bins[len(bins)-1].To = hi
+	}
+
+	i := 0
+	for _, x := range sorted {
+		for i < len(bins)-1 && x.v >= bins[i].To {
+			i++
+		}
+		bins[i].Count++
+		bins[i].WeightedCount += x.w
+	}
+	return bins
+}
+
+func (acc *questionAcc) dateSummary() *DateSummary {
+	ds := &DateSummary{Count: acc.dates.n, WeightedCount: acc.dates.w, Invalid: acc.invalidDates}
+	if len(acc.byDay) == 0 {
+		return ds
+	}
+	ds.ByDay = sortedBuckets(acc.byDay)
+	ds.ByMonth = sortedBuckets(acc.byMonth)
+	for i, t := range acc.byWeekday {
+		ds.ByWeekday = append(ds.ByWeekday, Bucket{Key: time.Weekday((i + 1) % 7).String(), Count: t.n, WeightedCount: t.w})
+	}
+	return ds
+}
+
+func buckets(counts map[string]*tally) []Bucket {
+	b := make([]Bucket, 0, len(counts))
+	for k, t := range counts {
+		b = append(b, Bucket{Key: k, Count: t.n, WeightedCount: t.w})
+	}
+	return b
+}
+
+// sortedBuckets returns the buckets sorted by key.
+func sortedBuckets(counts map[string]*tally) []Bucket {
+	b := buckets(counts)
+	sort.Slice(b, func(i, j int) bool { return b[i].Key < b[j].Key })
+	return b
+}
+
+func (acc *questionAcc) textSummary() *TextSummary {
+	ts := &TextSummary{Count: acc.texts.n, WeightedCount: acc.texts.w, MinLength: acc.minLength, MaxLength: acc.maxLength}
+	if acc.texts.n > 0 {
+		ts.MeanLength = float64(acc.lengthSum) / float64(acc.texts.n)
+	}
+	if acc.texts.w > 0 {
+		ts.WeightedMeanLength = acc.weightedLengthSum / acc.texts.w
+	}
+	ts.Words = topWords(acc.words, maxWords)
+	return ts
+}
+
+// topWords returns the n most frequent words, most frequent first, ties sorted alphabetically.
+func topWords(counts map[string]*tally, n int) []Bucket {
+	b := buckets(counts)
+	sort.Slice(b, func(i, j int) bool {
+		if b[i].Count != b[j].Count {
+			return b[i].Count > b[j].Count
+		}
+		return b[i].Key < b[j].Key
+	})
+	if len(b) > n {
+		b = b[:n]
+	}
+	return b
+}
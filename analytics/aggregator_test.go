@@ -0,0 +1,227 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+const feedbackSurvey = `{
+  "nameId": "feedback", "title": "Feedback", "version": "1",
+  "groupsOrder": ["g-main", "g-kids"],
+  "groups": {
+    "g-main": {"nameId": "g-main", "questionsIds": ["q-color", "q-why", "q-pets", "q-agree", "q-score", "q-birth", "q-comment"]},
+    "g-kids": {"nameId": "g-kids", "allowRepeat": true, "questionsIds": ["q-kid-name", "q-kid-age"]}
+  },
+  "questions": {
+    "q-color": {"nameId": "q-color", "visible": true, "type": "radio", "label": "Color",
+      "value": {"options": [{"nameId": "red", "label": "Red"}, {"nameId": "blue", "label": "Blue"}]}},
+    "q-why": {"nameId": "q-why", "visible": true, "type": "input_text", "label": "Why red", "value": {},
+      "dependsOn": [[{"questionNameId": "q-color", "optionNameId": "red"}]]},
+    "q-pets": {"nameId": "q-pets", "visible": true, "type": "checkbox", "label": "Pets",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-agree": {"nameId": "q-agree", "visible": true, "type": "toggle", "label": "Agree",
+      "value": {"options": [{"nameId": "opt-yes", "label": "Yes"}, {"nameId": "opt-no", "label": "No"}]}},
+    "q-score": {"nameId": "q-score", "visible": true, "type": "slider", "label": "Score",
+      "value": {"min": 1, "max": 10, "step": 1}},
+    "q-birth": {"nameId": "q-birth", "visible": true, "type": "date_time", "label": "Birth",
+      "value": {"format": "02/01/2006", "type": "date"}},
+    "q-comment": {"nameId": "q-comment", "visible": true, "type": "text_area", "label": "Comment", "value": {}},
+    "q-kid-name": {"nameId": "q-kid-name", "visible": true, "type": "input_text", "label": "Kid name", "value": {}},
+    "q-kid-age": {"nameId": "q-kid-age", "visible": true, "type": "slider", "label": "Kid age",
+      "value": {"min": 1, "max": 18, "step": 1}}
+  }
+}`
+
+func newFeedbackResults(t *testing.T) *Results {
+	t.Helper()
+	agg, err := NewAggregator(newFeedbackSurvey(t))
+	if err != nil {
+		t.Fatalf("NewAggregator: %v", err)
+	}
+
+	agg.Add(surveygo.Answers{
+		"q-color":   {"red"},
+		"q-pets":    {"dog", []any{"cat", "Cat"}},
+		"q-agree":   {true},
+		"q-score":   {float64(8)},
+		"q-birth":   {"24/12/1990"},
+		"q-comment": {"Great service, great staff"},
+		"g-kids": {
+			map[string]any{"q-kid-name": []any{"Ana"}, "q-kid-age": []any{float64(5)}},
+			map[string]any{"q-kid-name": []any{"Bo"}},
+		},
+	})
+	agg.Add(surveygo.Answers{
+		"q-color":   {"blue"},
+		"q-pets":    {"dog"},
+		"q-agree":   {"false"},
+		"q-score":   {float64(6)},
+		"q-birth":   {"01/01/2000"},
+		"q-comment": {"great"},
+	})
+	agg.Add(surveygo.Answers{
+		"q-color": {"red"},
+		"q-why":   {"cheap"},
+		"q-score": {float64(10)},
+		"q-birth": {"not a date"},
+	})
+
+	if agg.Responses() != 3 {
+		t.Fatalf("Responses = %d, want 3", agg.Responses())
+	}
+	return agg.Results()
+}
+
+func TestAggregator_QuestionOrder(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	want := []string{"q-color", "q-why", "q-pets", "q-agree", "q-score", "q-birth", "q-comment", "q-kid-name", "q-kid-age"}
+	if len(res.Questions) != len(want) {
+		t.Fatalf("got %d questions, want %d", len(res.Questions), len(want))
+	}
+	for i, q := range res.Questions {
+		if q.NameId != want[i] {
+			t.Errorf("question %d = %s, want %s", i, q.NameId, want[i])
+		}
+	}
+	if res.Question("q-kid-age").GroupNameId != "g-kids" {
+		t.Errorf("q-kid-age group = %s", res.Question("q-kid-age").GroupNameId)
+	}
+}
+
+func TestAggregator_Choice(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	color := res.Question("q-color").Choice
+	if color.Options[0].Count != 2 || color.Options[1].Count != 1 {
+		t.Errorf("q-color counts = %+v", color.Options)
+	}
+	if math.Abs(color.Options[0].Percent-200.0/3) > 1e-9 {
+		t.Errorf("red percent = %v", color.Options[0].Percent)
+	}
+
+	pets := res.Question("q-pets")
+	if pets.Choice.Options[0].Percent != 100 || pets.Choice.Options[1].Percent != 50 {
+		t.Errorf("q-pets = %+v", pets.Choice.Options)
+	}
+
+	agree := res.Question("q-agree").Choice
+	want := []OptionCount{
+		{NameId: "true", Label: "Yes", Count: 1, Percent: 50, WeightedCount: 1, WeightedPercent: 50},
+		{NameId: "false", Label: "No", Count: 1, Percent: 50, WeightedCount: 1, WeightedPercent: 50},
+	}
+	for i := range want {
+		if agree.Options[i] != want[i] {
+			t.Errorf("q-agree option %d = %+v, want %+v", i, agree.Options[i], want[i])
+		}
+	}
+}
+
+func TestAggregator_SkipRate(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	tests := []struct {
+		nameId         string
+		seen, answered int
+		skipRate       float64
+	}{
+		{"q-color", 3, 3, 0},
+		{"q-why", 2, 1, 0.5}, // only seen by respondents who chose red
+		{"q-pets", 3, 2, 1.0 / 3},
+		{"q-kid-name", 2, 2, 0}, // counted per instance
+		{"q-kid-age", 2, 1, 0.5},
+	}
+	for _, tt := range tests {
+		q := res.Question(tt.nameId)
+		if q.Seen != tt.seen || q.Answered != tt.answered || math.Abs(q.SkipRate-tt.skipRate) > 1e-9 {
+			t.Errorf("%s seen/answered/skip = %d/%d/%v, want %d/%d/%v",
+				tt.nameId, q.Seen, q.Answered, q.SkipRate, tt.seen, tt.answered, tt.skipRate)
+		}
+	}
+}
+
+func TestAggregator_Numeric(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	n := res.Question("q-score").Numeric
+	if n.Count != 3 || n.Min != 6 || n.Max != 10 || n.Mean != 8 || n.Median != 8 || n.StdDev != 2 {
+		t.Errorf("q-score = %+v", n)
+	}
+	if len(n.Histogram) != 10 {
+		t.Fatalf("got %d bins, want one per step (10)", len(n.Histogram))
+	}
+	for _, v := range []int{6, 8, 10} {
+		if b := n.Histogram[v-1]; b.From != float64(v) || b.Count != 1 {
+			t.Errorf("bin %d = %+v", v, b)
+		}
+	}
+
+	// one promoter (10), one passive (8) and one detractor (6)
+	want := NPSSummary{Promoters: 1, Passives: 1, Detractors: 1, WeightedPromoters: 1, WeightedPassives: 1, WeightedDetractors: 1}
+	if n.NPS == nil || *n.NPS != want {
+		t.Errorf("q-score NPS = %+v, want %+v", n.NPS, want)
+	}
+	// a 1-18 slider has no NPS
+	if kid := res.Question("q-kid-age").Numeric; kid.NPS != nil {
+		t.Errorf("q-kid-age NPS = %+v, want nil", kid.NPS)
+	}
+}
+
+func TestNPSSummary(t *testing.T) {
+	values := []weighted{{v: 0, w: 1}, {v: 6.5, w: 1}, {v: 7, w: 1}, {v: 8.5, w: 1}, {v: 9, w: 2}, {v: 10, w: 2}}
+	nps := npsSummary(values)
+	if nps.Promoters != 2 || nps.Passives != 2 || nps.Detractors != 2 || nps.Score != 0 {
+		t.Errorf("NPS = %+v", nps)
+	}
+	// weighted promoters 4 of 8, detractors 2 of 8
+	if nps.WeightedScore != 25 {
+		t.Errorf("WeightedScore = %v, want 25", nps.WeightedScore)
+	}
+}
+
+func TestAggregator_Date(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	d := res.Question("q-birth").Date
+	if d.Count != 2 || d.Invalid != 1 {
+		t.Errorf("q-birth count/invalid = %d/%d", d.Count, d.Invalid)
+	}
+	if len(d.ByMonth) != 2 || d.ByMonth[0] != (Bucket{Key: "1990-12", Count: 1, WeightedCount: 1}) {
+		t.Errorf("ByMonth = %+v", d.ByMonth)
+	}
+	if len(d.ByWeekday) != 7 || d.ByWeekday[0] != (Bucket{Key: "Monday", Count: 1, WeightedCount: 1}) || d.ByWeekday[5] != (Bucket{Key: "Saturday", Count: 1, WeightedCount: 1}) {
+		t.Errorf("ByWeekday = %+v", d.ByWeekday)
+	}
+}
+
+func TestAggregator_Text(t *testing.T) {
+	res := newFeedbackResults(t)
+
+	txt := res.Question("q-comment").Text
+	if txt.Count != 2 || txt.MinLength != 5 || txt.MaxLength != 26 || txt.MeanLength != 15.5 {
+		t.Errorf("q-comment = %+v", txt)
+	}
+	if txt.Words[0] != (Bucket{Key: "great", Count: 3, WeightedCount: 3}) {
+		t.Errorf("top word = %+v", txt.Words[0])
+	}
+}
+
+func TestNewAggregator_Cycle(t *testing.T) {
+	s, err := surveygo.ParseFromJsonStr(`{
+  "nameId": "cycle", "title": "Cycle", "version": "1",
+  "groupsOrder": ["g-a"],
+  "groups": {
+    "g-a": {"nameId": "g-a", "groupsOrder": ["g-b"]},
+    "g-b": {"nameId": "g-b", "groupsOrder": ["g-a"]}
+  },
+  "questions": {}
+}`)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+	if _, err := NewAggregator(s); err == nil {
+		t.Error("expected cycle error")
+	}
+}
@@ -0,0 +1,227 @@
+package analytics
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/external"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// questionRef locates a question in the group hierarchy.
+type questionRef struct {
+	question *question.Question
+	group    string
+	// repeats are the repeatable groups holding the question, outermost first.
+	// The answers of the question are nested in the instances of these groups.
+	repeats []string
+}
+
+// walkQuestions returns the questions of a survey in survey order: the questions of each group
+// followed by those of its subgroups and of the groups opened by its options.
+func walkQuestions(s *surveygo.Survey) ([]questionRef, error) {
+	var refs []questionRef
+	seen := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var walk func(groupID string, repeats []string) error
+	walk = func(groupID string, repeats []string) error {
+		if visiting[groupID] {
+			return fmt.Errorf("cycle detected at group '%s'", groupID)
+		}
+		g, ok := s.Groups[groupID]
+		if !ok {
+			return fmt.Errorf("group '%s' not found", groupID)
+		}
+		visiting[groupID] = true
+		defer delete(visiting, groupID)
+
+		if g.AllowRepeat {
+			repeats = append(repeats[:len(repeats):len(repeats)], groupID)
+		}
+
+		var children []string
+		for _, qID := range g.QuestionsIds {
+			q, ok := s.Questions[qID]
+			if !ok {
+				return fmt.Errorf("question '%s' referenced by group '%s' not found", qID, groupID)
+			}
+			if !seen[qID] {
+				seen[qID] = true
+				refs = append(refs, questionRef{question: q, group: groupID, repeats: repeats})
+			}
+			for _, opt := range choiceOptions(q) {
+				children = append(children, opt.GroupsIds...)
+			}
+		}
+
+		for _, childID := range append(append([]string{}, g.GroupsOrder...), children...) {
+			if err := walk(childID, repeats); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, groupID := range s.GroupsOrder {
+		if err := walk(groupID, nil); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// choiceOptions returns the options of a simple choice question.
+func choiceOptions(q *question.Question) []*choice.Option {
+	if !types.IsSimpleChoiceType(q.QTyp) {
+		return nil
+	}
+	c, err := choice.CastToChoice(q.Value)
+	if err != nil {
+		return nil
+	}
+	return c.Options
+}
+
+// questionLabel returns the label of a question, its placeholder if it has no label.
+func questionLabel(q *question.Question) string {
+	if q.Label != "" {
+		return q.Label
+	}
+	var placeholder *string
+	switch v := q.Value.(type) {
+	case *choice.Choice:
+		placeholder = v.Placeholder
+	case *choice.Slider:
+		placeholder = v.Placeholder
+	case *choice.Toggle:
+		placeholder = v.Placeholder
+	case *text.FreeText:
+		placeholder = v.Placeholder
+	case *text.Email:
+		placeholder = v.Placeholder
+	case *text.Telephone:
+		placeholder = v.Placeholder
+	case *text.DateTime:
+		placeholder = v.Placeholder
+	case *text.IdentificationNumber:
+		placeholder = v.Placeholder
+	case *external.ExternalQuestion:
+		placeholder = v.Placeholder
+	}
+	if placeholder != nil {
+		return *placeholder
+	}
+	return ""
+}
+
+// groupInstances returns the answers of the instances of a repeatable group.
+func groupInstances(ans []any) []surveygo.Answers {
+	var instances []surveygo.Answers
+	for _, v := range ans {
+		m, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		inst := make(surveygo.Answers, len(m))
+		for k, val := range m {
+			if arr, ok := val.([]any); ok {
+				inst[k] = arr
+			}
+		}
+		instances = append(instances, inst)
+	}
+	return instances
+}
+
+// scopes returns the answers the question is answered in: the response answers, or the answers of
+// each instance of its innermost repeatable group. Instance answers shadow the answers of their parents.
+func scopes(ans surveygo.Answers, repeats []string) []surveygo.Answers {
+	current := []surveygo.Answers{ans}
+	for _, groupID := range repeats {
+		var next []surveygo.Answers
+		for _, parent := range current {
+			for _, inst := range groupInstances(parent[groupID]) {
+				merged := make(surveygo.Answers, len(parent)+len(inst))
+				for k, v := range parent {
+					merged[k] = v
+				}
+				for k, v := range inst {
+					merged[k] = v
+				}
+				next = append(next, merged)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// selectedValues returns the selected option name ids of a choice answer.
+// Options may be given as name ids or as [nameId, label] pairs.
+func selectedValues(ans []any) []string {
+	var values []string
+	for _, v := range ans {
+		switch val := v.(type) {
+		case string:
+			values = append(values, val)
+		case []any:
+			if len(val) > 0 {
+				if s, ok := val[0].(string); ok {
+					values = append(values, s)
+				}
+			}
+		}
+	}
+	return values
+}
+
+// toggleValue returns the value of a toggle answer.
+func toggleValue(ans []any) (bool, bool) {
+	if len(ans) == 0 {
+		return false, false
+	}
+	switch v := ans[0].(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.ToLower(v))
+		return b, err == nil
+	}
+	return false, false
+}
+
+// numberValue returns the value of a numeric answer.
+func numberValue(ans []any) (float64, bool) {
+	if len(ans) == 0 {
+		return 0, false
+	}
+	switch v := ans[0].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// textValue returns the text of a text answer. Telephone answers are joined.
+func textValue(ans []any) string {
+	var parts []string
+	for _, v := range ans {
+		if s, ok := v.(string); ok && s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
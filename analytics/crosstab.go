@@ -0,0 +1,367 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+)
+
+// CrosstabResult is the contingency table of two choice questions.
+//
+// Each respondent answering both questions is counted in every (row option, column option) pair it
+// selected, so multi-select questions may count a respondent in several cells.
+//
+// Percentages, expected counts and the chi-square test are computed from the weighted counts, which
+// equal the counts for unweighted responses.
+type CrosstabResult struct {
+	// RowQuestion and ColQuestion are the name ids of the questions of the rows and the columns.
+	RowQuestion string `json:"rowQuestion"`
+	ColQuestion string `json:"colQuestion"`
+
+	// Respondents is the number of responses matching the filter that answered both questions.
+	Respondents int `json:"respondents"`
+
+	// EffectiveSampleSize is the effective sample size of the respondents (see WeightSummary).
+	EffectiveSampleSize float64 `json:"effectiveSampleSize"`
+
+	// Rows and Cols are the options of the questions, with the number of respondents selecting them and
+	// their share of Respondents, between 0 and 100, unweighted and weighted.
+	Rows []OptionCount `json:"rows"`
+	Cols []OptionCount `json:"cols"`
+
+	// Counts[i][j] is the number of respondents selecting row option i and column option j.
+	Counts [][]int `json:"counts"`
+
+	// WeightedCounts[i][j] is the weight of the respondents selecting row option i and column option j.
+	WeightedCounts [][]float64 `json:"weightedCounts"`
+
+	// RowPercent[i][j] is the weighted share of the respondents selecting row option i that selected
+	// column option j, between 0 and 100.
+	RowPercent [][]float64 `json:"rowPercent"`
+
+	// ColPercent[i][j] is the weighted share of the respondents selecting column option j that selected
+	// row option i, between 0 and 100.
+	ColPercent [][]float64 `json:"colPercent"`
+
+	// Expected[i][j] is the weighted count expected under independence: the sums of the weighted counts
+	// of row i and column j, multiplied, over the sum of all weighted counts.
+	Expected [][]float64 `json:"expected"`
+
+	// ChiSquare is the chi-square test of independence, nil if the table has fewer than two rows
+	// or two columns with counts.
+	ChiSquare *ChiSquareTest `json:"chiSquare,omitempty"`
+}
+
+// ChiSquareTest is the result of Pearson's chi-square test of independence.
+// Rows and columns without counts are left out of the test.
+//
+// The test assumes each respondent is counted in a single cell: with multi-select questions
+// the p-value is only indicative. With weights, the statistic of the weighted counts is scaled by
+// the effective sample size over the sum of the weights.
+type ChiSquareTest struct {
+	// Statistic is the chi-square statistic.
+	Statistic float64 `json:"statistic"`
+
+	// DF are the degrees of freedom, (rows - 1) * (columns - 1).
+	DF int `json:"df"`
+
+	// PValue is the probability of a statistic at least as large under independence.
+	PValue float64 `json:"pValue"`
+}
+
+// Crosstab cross-tabulates the answers of two choice questions (single/multi select, radio, checkbox or toggle).
+// Args:
+//   - survey: the survey the answers belong to
+//   - responses: the answers of the responses
+//   - rowQuestion: the name id of the question of the rows
+//   - colQuestion: the name id of the question of the columns
+//   - filter: optional AND groups of conditions, in the dependsOn format: only the responses satisfying
+//     at least one group are tabulated
+//
+// Returns:
+//   - *CrosstabResult: the contingency table and its chi-square test
+//   - error: if a question is not a choice question, or is inside a repeatable group
+func Crosstab(survey *surveygo.Survey, responses []surveygo.Answers, rowQuestion, colQuestion string, filter ...[]question.DependsOn) (*CrosstabResult, error) {
+	return CrosstabWeighted(survey, responses, nil, rowQuestion, colQuestion, filter...)
+}
+
+// CrosstabWeighted cross-tabulates the answers of two choice questions as Crosstab, with response weights.
+// Args:
+//   - survey: the survey the answers belong to
+//   - responses: the answers of the responses
+//   - weights: the weights of the responses, e.g. from Rake or PostStratify (1 for all if nil)
+//   - rowQuestion: the name id of the question of the rows
+//   - colQuestion: the name id of the question of the columns
+//   - filter: optional AND groups of conditions, in the dependsOn format
+//
+// Returns:
+//   - *CrosstabResult: the contingency table and its chi-square test
+//   - error: if a question is not a choice question, or is inside a repeatable group, or the weights are invalid
+func CrosstabWeighted(survey *surveygo.Survey, responses []surveygo.Answers, weights []float64, rowQuestion, colQuestion string, filter ...[]question.DependsOn) (*CrosstabResult, error) {
+	weights, err := baseWeights(weights, len(responses))
+	if err != nil {
+		return nil, err
+	}
+	refs, err := walkQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("walking survey groups: %w", err)
+	}
+	rowOpts, err := crosstabOptions(refs, rowQuestion)
+	if err != nil {
+		return nil, err
+	}
+	colOpts, err := crosstabOptions(refs, colQuestion)
+	if err != nil {
+		return nil, err
+	}
+
+	rowIdx, colIdx := optionIndex(rowOpts), optionIndex(colOpts)
+	rowType, colType := survey.Questions[rowQuestion].QTyp, survey.Questions[colQuestion].QTyp
+
+	ct := &CrosstabResult{
+		RowQuestion:    rowQuestion,
+		ColQuestion:    colQuestion,
+		Rows:           rowOpts,
+		Cols:           colOpts,
+		Counts:         newMatrix[int](len(rowOpts), len(colOpts)),
+		WeightedCounts: newMatrix[float64](len(rowOpts), len(colOpts)),
+	}
+
+	var respondents weightAcc
+	for k, ans := range responses {
+		if len(filter) > 0 && !survey.EvaluateDependsOn(filter, ans) {
+			continue
+		}
+		rows := selectedIndexes(rowType, ans[rowQuestion], rowIdx)
+		cols := selectedIndexes(colType, ans[colQuestion], colIdx)
+		if len(rows) == 0 || len(cols) == 0 {
+			continue
+		}
+
+		w := weights[k]
+		respondents.add(w)
+		for _, i := range rows {
+			ct.Rows[i].Count++
+			ct.Rows[i].WeightedCount += w
+		}
+		for _, j := range cols {
+			ct.Cols[j].Count++
+			ct.Cols[j].WeightedCount += w
+		}
+		for _, i := range rows {
+			for _, j := range cols {
+				ct.Counts[i][j]++
+				ct.WeightedCounts[i][j] += w
+			}
+		}
+	}
+
+	summary := respondents.summary()
+	ct.Respondents = summary.Count
+	ct.EffectiveSampleSize = summary.EffectiveSampleSize
+	ct.percentages(summary.Sum)
+	ct.ChiSquare = ct.chiSquare(summary)
+	return ct, nil
+}
+
+// crosstabOptions returns the options of a choice question that can be cross-tabulated.
+func crosstabOptions(refs []questionRef, nameId string) ([]OptionCount, error) {
+	for _, ref := range refs {
+		q := ref.question
+		if q.NameId != nameId {
+			continue
+		}
+		if len(ref.repeats) > 0 {
+			return nil, fmt.Errorf("question '%s' is inside repeatable group '%s'", nameId, ref.repeats[0])
+		}
+		switch {
+		case types.IsSimpleChoiceType(q.QTyp):
+			var opts []OptionCount
+			for _, opt := range choiceOptions(q) {
+				opts = append(opts, OptionCount{NameId: opt.NameId, Label: opt.Label})
+			}
+			return opts, nil
+		case q.QTyp == types.QTypeToggle:
+			return toggleOptions(q.Value), nil
+		default:
+			return nil, fmt.Errorf("question '%s' of type '%s' is not a choice question", nameId, q.QTyp)
+		}
+	}
+	return nil, fmt.Errorf("question '%s' not found in survey groups", nameId)
+}
+
+func optionIndex(opts []OptionCount) map[string]int {
+	idx := make(map[string]int, len(opts))
+	for i, opt := range opts {
+		idx[opt.NameId] = i
+	}
+	return idx
+}
+
+// selectedIndexes returns the indexes of the options selected in an answer, without duplicates.
+func selectedIndexes(qt types.QuestionType, ans []any, idx map[string]int) []int {
+	var values []string
+	if qt == types.QTypeToggle {
+		if b, ok := toggleValue(ans); ok {
+			values = []string{strconv.FormatBool(b)}
+		}
+	} else {
+		values = selectedValues(ans)
+	}
+
+	var indexes []int
+	seen := make(map[int]bool, len(values))
+	for _, v := range values {
+		if i, ok := idx[v]; ok && !seen[i] {
+			seen[i] = true
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func newMatrix[T int | float64](rows, cols int) [][]T {
+	m := make([][]T, rows)
+	for i := range m {
+		m[i] = make([]T, cols)
+	}
+	return m
+}
+
+// percentages computes the percentages, given the weight of the respondents.
+func (ct *CrosstabResult) percentages(weight float64) {
+	ct.RowPercent = newMatrix[float64](len(ct.Rows), len(ct.Cols))
+	ct.ColPercent = newMatrix[float64](len(ct.Rows), len(ct.Cols))
+	for i := range ct.Rows {
+		for j := range ct.Cols {
+			if ct.Rows[i].WeightedCount > 0 {
+				ct.RowPercent[i][j] = ct.WeightedCounts[i][j] / ct.Rows[i].WeightedCount * 100
+			}
+			if ct.Cols[j].WeightedCount > 0 {
+				ct.ColPercent[i][j] = ct.WeightedCounts[i][j] / ct.Cols[j].WeightedCount * 100
+			}
+		}
+	}
+	for _, opts := range [][]OptionCount{ct.Rows, ct.Cols} {
+		for i := range opts {
+			if ct.Respondents > 0 {
+				opts[i].Percent = float64(opts[i].Count) / float64(ct.Respondents) * 100
+			}
+			if weight > 0 {
+				opts[i].WeightedPercent = opts[i].WeightedCount / weight * 100
+			}
+		}
+	}
+}
+
+// chiSquare computes the expected counts and the chi-square test of the weighted counts,
+// given the weights of the respondents.
+func (ct *CrosstabResult) chiSquare(weights WeightSummary) *ChiSquareTest {
+	rowSums := make([]float64, len(ct.Rows))
+	colSums := make([]float64, len(ct.Cols))
+	var total float64
+	for i := range ct.Rows {
+		for j := range ct.Cols {
+			c := ct.WeightedCounts[i][j]
+			rowSums[i] += c
+			colSums[j] += c
+			total += c
+		}
+	}
+
+	ct.Expected = newMatrix[float64](len(ct.Rows), len(ct.Cols))
+	if total == 0 {
+		return nil
+	}
+
+	var stat float64
+	for i := range ct.Rows {
+		for j := range ct.Cols {
+			e := rowSums[i] * colSums[j] / total
+			ct.Expected[i][j] = e
+			if e > 0 {
+				d := ct.WeightedCounts[i][j] - e
+				stat += d * d / e
+			}
+		}
+	}
+
+	df := (nonZero(rowSums) - 1) * (nonZero(colSums) - 1)
+	if df <= 0 {
+		return nil
+	}
+	// the statistic grows with the counts: scale it to the effective sample size
+	stat *= weights.EffectiveSampleSize / weights.Sum
+	return &ChiSquareTest{Statistic: stat, DF: df, PValue: chiSquarePValue(stat, df)}
+}
+
+func nonZero(values []float64) int {
+	n := 0
+	for _, v := range values {
+		if v > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// chiSquarePValue returns the upper tail probability of the chi-square distribution.
+func chiSquarePValue(stat float64, df int) float64 {
+	if stat <= 0 {
+		return 1
+	}
+	return upperIncompleteGamma(float64(df)/2, stat/2)
+}
+
+// upperIncompleteGamma returns the regularized upper incomplete gamma function Q(a, x),
+// by its series for x < a+1 and its continued fraction otherwise.
+func upperIncompleteGamma(a, x float64) float64 {
+	const (
+		maxIter = 500
+		eps     = 1e-15
+		tiny    = 1e-300
+	)
+	lgammaA, _ := math.Lgamma(a)
+	prefix := math.Exp(a*math.Log(x) - x - lgammaA)
+
+	if x < a+1 {
+		sum, term := 1/a, 1/a
+		for n := 1; n < maxIter; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*eps {
+				break
+			}
+		}
+		return math.Max(0, 1-sum*prefix)
+	}
+
+	// modified Lentz's method
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for n := 1; n < maxIter; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return prefix * h
+}
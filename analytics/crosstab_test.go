@@ -0,0 +1,113 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+)
+
+func newFeedbackSurvey(t *testing.T) *surveygo.Survey {
+	t.Helper()
+	s, err := surveygo.ParseFromJsonStr(feedbackSurvey)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+	return s
+}
+
+var crosstabResponses = []surveygo.Answers{
+	{"q-color": {"red"}, "q-pets": {"dog"}, "q-agree": {true}},
+	{"q-color": {"red"}, "q-pets": {"dog"}, "q-agree": {true}},
+	{"q-color": {"blue"}, "q-pets": {"cat"}, "q-agree": {false}},
+	{"q-color": {"blue"}, "q-pets": {"dog", []any{"cat", "Cat"}}, "q-agree": {false}},
+	{"q-color": {"red"}}, // no pets: left out
+}
+
+func TestCrosstab_MultiSelect(t *testing.T) {
+	ct, err := Crosstab(newFeedbackSurvey(t), crosstabResponses, "q-color", "q-pets")
+	if err != nil {
+		t.Fatalf("Crosstab: %v", err)
+	}
+
+	if ct.Respondents != 4 {
+		t.Errorf("Respondents = %d, want 4", ct.Respondents)
+	}
+	wantCounts := [][]int{{2, 0}, {1, 2}}
+	for i := range wantCounts {
+		for j := range wantCounts[i] {
+			if ct.Counts[i][j] != wantCounts[i][j] {
+				t.Errorf("Counts = %v, want %v", ct.Counts, wantCounts)
+			}
+		}
+	}
+	if ct.Cols[0].Count != 3 || ct.Cols[0].Percent != 75 || ct.Rows[1].Count != 2 {
+		t.Errorf("Rows = %+v, Cols = %+v", ct.Rows, ct.Cols)
+	}
+	if ct.RowPercent[0][0] != 100 || ct.RowPercent[1][0] != 50 {
+		t.Errorf("RowPercent = %v", ct.RowPercent)
+	}
+	if math.Abs(ct.ColPercent[0][0]-200.0/3) > 1e-9 || ct.ColPercent[1][1] != 100 {
+		t.Errorf("ColPercent = %v", ct.ColPercent)
+	}
+	// row sums 2 and 3, column sums 3 and 2, total 5
+	if math.Abs(ct.Expected[0][0]-1.2) > 1e-9 || math.Abs(ct.Expected[1][1]-1.2) > 1e-9 {
+		t.Errorf("Expected = %v", ct.Expected)
+	}
+	if ct.ChiSquare == nil || ct.ChiSquare.DF != 1 || ct.ChiSquare.PValue <= 0 || ct.ChiSquare.PValue >= 1 {
+		t.Errorf("ChiSquare = %+v", ct.ChiSquare)
+	}
+}
+
+func TestCrosstab_ToggleAndFilter(t *testing.T) {
+	s := newFeedbackSurvey(t)
+
+	ct, err := Crosstab(s, crosstabResponses, "q-agree", "q-color")
+	if err != nil {
+		t.Fatalf("Crosstab: %v", err)
+	}
+	if ct.Rows[0].NameId != "true" || ct.Counts[0][0] != 2 || ct.Counts[1][1] != 2 {
+		t.Errorf("Rows = %+v, Counts = %v", ct.Rows, ct.Counts)
+	}
+
+	ct, err = Crosstab(s, crosstabResponses, "q-color", "q-pets", []question.DependsOn{{QuestionNameId: "q-pets", OptionNameId: "cat"}})
+	if err != nil {
+		t.Fatalf("Crosstab: %v", err)
+	}
+	if ct.Respondents != 1 || ct.Counts[1][1] != 1 {
+		t.Errorf("filtered Respondents = %d, Counts = %v", ct.Respondents, ct.Counts)
+	}
+	if ct.ChiSquare != nil {
+		t.Errorf("expected no test for a single row, got %+v", ct.ChiSquare)
+	}
+}
+
+func TestCrosstab_InvalidQuestions(t *testing.T) {
+	s := newFeedbackSurvey(t)
+
+	for _, col := range []string{"q-comment", "q-kid-age", "q-missing"} {
+		if _, err := Crosstab(s, crosstabResponses, "q-color", col); err == nil {
+			t.Errorf("expected error for column %s", col)
+		}
+	}
+}
+
+func TestChiSquarePValue(t *testing.T) {
+	tests := []struct {
+		stat float64
+		df   int
+		want float64
+	}{
+		{3.841458820694124, 1, 0.05},
+		{5.991464547107979, 2, 0.05},
+		{18.307038053275146, 10, 0.05},
+		{0.5, 4, math.Exp(-0.25) * 1.25},
+		{0, 3, 1},
+	}
+	for _, tt := range tests {
+		if got := chiSquarePValue(tt.stat, tt.df); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("chiSquarePValue(%v, %d) = %v, want %v", tt.stat, tt.df, got, tt.want)
+		}
+	}
+}
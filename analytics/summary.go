@@ -0,0 +1,204 @@
+package analytics
+
+// Results holds the summaries of the answers aggregated for a survey.
+type Results struct {
+	// SurveyNameId is the name id of the survey.
+	SurveyNameId string `json:"surveyNameId"`
+
+	// Responses is the number of responses aggregated.
+	Responses int `json:"responses"`
+
+	// Weights summarizes the weights of the responses, all 1 for responses added without weight.
+	Weights WeightSummary `json:"weights"`
+
+	// Questions are the question summaries, in survey order.
+	Questions []*QuestionSummary `json:"questions"`
+}
+
+// Question returns the summary of a question, nil if the question is not part of the results.
+func (r *Results) Question(nameId string) *QuestionSummary {
+	for _, q := range r.Questions {
+		if q.NameId == nameId {
+			return q
+		}
+	}
+	return nil
+}
+
+// QuestionSummary summarizes the answers of a question.
+//
+// Questions inside repeatable groups are counted once per group instance: Seen counts the instances
+// in which the question was visible, not the respondents. Instances carry the weight of their response.
+//
+// Weighted fields hold the same statistics computed with the response weights. They equal the
+// unweighted ones when all weights are 1.
+type QuestionSummary struct {
+	// NameId is the name id of the question.
+	NameId string `json:"nameId"`
+
+	// Label is the label of the question, its placeholder if it has no label.
+	Label string `json:"label,omitempty"`
+
+	// Type is the type of the question.
+	Type string `json:"type"`
+
+	// GroupNameId is the name id of the group of the question.
+	GroupNameId string `json:"groupNameId"`
+
+	// Seen is the number of respondents (or repeatable group instances) the question was visible to.
+	Seen int `json:"seen"`
+
+	// Answered is the number of respondents (or repeatable group instances) that answered the question.
+	Answered int `json:"answered"`
+
+	// SkipRate is the share of Seen that did not answer the question, between 0 and 1.
+	SkipRate float64 `json:"skipRate"`
+
+	// WeightedSeen, WeightedAnswered and WeightedSkipRate are Seen, Answered and SkipRate with weights.
+	WeightedSeen     float64 `json:"weightedSeen"`
+	WeightedAnswered float64 `json:"weightedAnswered"`
+	WeightedSkipRate float64 `json:"weightedSkipRate"`
+
+	// EffectiveAnswered is the effective sample size of the answers of the question (see WeightSummary).
+	EffectiveAnswered float64 `json:"effectiveAnswered"`
+
+	// Choice summarizes choice and toggle questions.
+	Choice *ChoiceSummary `json:"choice,omitempty"`
+
+	// Numeric summarizes slider questions.
+	Numeric *NumericSummary `json:"numeric,omitempty"`
+
+	// Date summarizes date_time questions.
+	Date *DateSummary `json:"date,omitempty"`
+
+	// Text summarizes free text questions.
+	Text *TextSummary `json:"text,omitempty"`
+}
+
+// ChoiceSummary holds the counts of the options of a choice question.
+type ChoiceSummary struct {
+	// Options are the option counts, in the order of the question options.
+	Options []OptionCount `json:"options"`
+
+	// Unknown is the number of selected values that are not options of the question.
+	Unknown int `json:"unknown,omitempty"`
+}
+
+// OptionCount is the number of times an option was selected.
+type OptionCount struct {
+	// NameId is the name id of the option. Toggles have the "true" and "false" options.
+	NameId string `json:"nameId"`
+
+	// Label is the label of the option.
+	Label string `json:"label"`
+
+	// Count is the number of answers selecting the option.
+	Count int `json:"count"`
+
+	// Percent is the share of the answers of the question selecting the option, between 0 and 100.
+	// Multi-select percentages may add up to more than 100.
+	Percent float64 `json:"percent"`
+
+	// WeightedCount and WeightedPercent are Count and Percent with weights.
+	WeightedCount   float64 `json:"weightedCount"`
+	WeightedPercent float64 `json:"weightedPercent"`
+}
+
+// NumericSummary holds the descriptive statistics of a numeric question.
+type NumericSummary struct {
+	// Count is the number of numeric answers.
+	Count int `json:"count"`
+
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+
+	// StdDev is the sample standard deviation, 0 for a single answer.
+	StdDev float64 `json:"stdDev"`
+
+	// Histogram are the answer counts per bin, from the lowest bin to the highest.
+	Histogram []Bin `json:"histogram"`
+
+	// WeightedMean, WeightedMedian and WeightedStdDev are Mean, Median and StdDev with weights.
+	// The standard deviation uses reliability weights, matching StdDev for unit weights.
+	WeightedMean   float64 `json:"weightedMean"`
+	WeightedMedian float64 `json:"weightedMedian"`
+	WeightedStdDev float64 `json:"weightedStdDev"`
+
+	// NPS is the Net Promoter Score of the answers, only for sliders ranging within 0-10.
+	NPS *NPSSummary `json:"nps,omitempty"`
+}
+
+// NPSSummary holds the Net Promoter Score of the answers of a 0-10 slider, computed from the answered
+// values: promoters answered 9 or more, passives 7 or more and detractors less than 7.
+type NPSSummary struct {
+	Promoters  int `json:"promoters"`
+	Passives   int `json:"passives"`
+	Detractors int `json:"detractors"`
+
+	// Score is the share of promoters minus the share of detractors, from -100 to 100.
+	Score float64 `json:"score"`
+
+	// WeightedPromoters, WeightedPassives, WeightedDetractors and WeightedScore are the counts and the score
+	// with weights.
+	WeightedPromoters  float64 `json:"weightedPromoters"`
+	WeightedPassives   float64 `json:"weightedPassives"`
+	WeightedDetractors float64 `json:"weightedDetractors"`
+	WeightedScore      float64 `json:"weightedScore"`
+}
+
+// Bin is a histogram bin, holding the answers v such that From <= v < To (v <= To for the last bin).
+type Bin struct {
+	From          float64 `json:"from"`
+	To            float64 `json:"to"`
+	Count         int     `json:"count"`
+	WeightedCount float64 `json:"weightedCount"`
+}
+
+// DateSummary holds the distributions of the answers of a date question.
+// Time-only questions have no distributions.
+type DateSummary struct {
+	// Count is the number of answers matching the format of the question.
+	Count int `json:"count"`
+
+	// WeightedCount is Count with weights.
+	WeightedCount float64 `json:"weightedCount"`
+
+	// Invalid is the number of answers not matching the format of the question.
+	Invalid int `json:"invalid,omitempty"`
+
+	// ByDay is the number of answers per day (2006-01-02), sorted by day.
+	ByDay []Bucket `json:"byDay,omitempty"`
+
+	// ByMonth is the number of answers per month (2006-01), sorted by month.
+	ByMonth []Bucket `json:"byMonth,omitempty"`
+
+	// ByWeekday is the number of answers per weekday, from Monday to Sunday.
+	ByWeekday []Bucket `json:"byWeekday,omitempty"`
+}
+
+// Bucket is the number of answers with the same key.
+type Bucket struct {
+	Key           string  `json:"key"`
+	Count         int     `json:"count"`
+	WeightedCount float64 `json:"weightedCount"`
+}
+
+// TextSummary holds the statistics of the answers of a free text question.
+type TextSummary struct {
+	// Count is the number of non-empty answers.
+	Count int `json:"count"`
+
+	// MinLength, MaxLength and MeanLength are the answer lengths, in characters.
+	MinLength  int     `json:"minLength"`
+	MaxLength  int     `json:"maxLength"`
+	MeanLength float64 `json:"meanLength"`
+
+	// WeightedCount and WeightedMeanLength are Count and MeanLength with weights.
+	WeightedCount      float64 `json:"weightedCount"`
+	WeightedMeanLength float64 `json:"weightedMeanLength"`
+
+	// Words are the most frequent words of the answers, most frequent first.
+	Words []Bucket `json:"words,omitempty"`
+}
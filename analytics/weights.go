@@ -0,0 +1,430 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types"
+)
+
+const (
+	defaultRakeIterations = 100
+	defaultRakeTolerance  = 1e-6
+)
+
+// WeightSummary describes a set of response weights.
+type WeightSummary struct {
+	// Count is the number of weights.
+	Count int `json:"count"`
+
+	Sum  float64 `json:"sum"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+
+	// EffectiveSampleSize is Kish's effective sample size, (sum of weights)² / sum of squared weights:
+	// the number of unweighted responses giving the same precision. Equals Count for equal weights.
+	EffectiveSampleSize float64 `json:"effectiveSampleSize"`
+
+	// DesignEffect is Count / EffectiveSampleSize, the variance inflation due to weighting.
+	DesignEffect float64 `json:"designEffect"`
+}
+
+// SummarizeWeights returns the summary of the given weights.
+func SummarizeWeights(weights []float64) WeightSummary {
+	var acc weightAcc
+	for _, w := range weights {
+		acc.add(w)
+	}
+	return acc.summary()
+}
+
+// weightAcc accumulates weights.
+type weightAcc struct {
+	count                int
+	sum, sumSq           float64
+	minWeight, maxWeight float64
+}
+
+func (a *weightAcc) add(w float64) {
+	if a.count == 0 || w < a.minWeight {
+		a.minWeight = w
+	}
+	if a.count == 0 || w > a.maxWeight {
+		a.maxWeight = w
+	}
+	a.count++
+	a.sum += w
+	a.sumSq += w * w
+}
+
+func (a *weightAcc) summary() WeightSummary {
+	s := WeightSummary{Count: a.count, Sum: a.sum, Min: a.minWeight, Max: a.maxWeight}
+	if a.count > 0 {
+		s.Mean = a.sum / float64(a.count)
+	}
+	if a.sumSq > 0 {
+		s.EffectiveSampleSize = a.sum * a.sum / a.sumSq
+		s.DesignEffect = float64(a.count) / s.EffectiveSampleSize
+	}
+	return s
+}
+
+func checkWeight(w float64) error {
+	if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+		return fmt.Errorf("invalid weight %v: weights must be finite and non-negative", w)
+	}
+	return nil
+}
+
+// Margin is the population distribution of a single answer choice question (single select, radio or toggle).
+type Margin struct {
+	// Question is the name id of the question.
+	Question string `json:"question"`
+
+	// Targets are the population shares of the options, by option name id ("true" and "false" for toggles).
+	// Shares are normalized to add up to 1. Options without a target have a share of 0: their responses
+	// get a weight of 0.
+	Targets map[string]float64 `json:"targets"`
+}
+
+// Cell is the population share of a combination of options of the post-stratification questions.
+type Cell struct {
+	// Options are the option name ids of the cell, one per question, in the order of the questions.
+	Options []string `json:"options"`
+
+	// Share is the population share of the cell. Shares are normalized to add up to 1.
+	Share float64 `json:"share"`
+}
+
+// RakeOptions configures Rake.
+type RakeOptions struct {
+	// MaxIterations is the maximum number of adjustments of all the margins. Defaults to 100.
+	MaxIterations int
+
+	// Tolerance is the largest difference allowed between a weighted share and its target for the
+	// weights to converge. Defaults to 1e-6.
+	Tolerance float64
+
+	// BaseWeights are the initial weights of the responses (e.g. design weights), 1 if nil.
+	BaseWeights []float64
+}
+
+// Weighting holds the weights computed by Rake or PostStratify.
+type Weighting struct {
+	// Weights are the weights of the responses, in the order of the responses, scaled to a mean of 1.
+	Weights []float64 `json:"weights"`
+
+	// Iterations is the number of adjustments of all the margins (1 for post-stratification).
+	Iterations int `json:"iterations"`
+
+	// Converged reports whether all the weighted shares are within the tolerance of their targets.
+	Converged bool `json:"converged"`
+
+	// Summary describes the weights, with their effective sample size.
+	Summary WeightSummary `json:"summary"`
+}
+
+// Rake computes weights matching the weighted distribution of each margin question to its population
+// shares, by iterative proportional fitting (raking). Use it when only the marginal distributions of
+// the questions are known, e.g. gender and region separately.
+//
+// Responses without a valid answer to a margin question keep their weight when adjusting that margin.
+// Args:
+//   - survey: the survey the answers belong to
+//   - responses: the answers of the responses
+//   - margins: the population distributions of the margin questions
+//   - opts: the raking options (optional)
+//
+// Returns:
+//   - *Weighting: the weights, not converged if MaxIterations was reached first
+//   - error: if a margin question is not a single answer choice question, a target is not an option
+//     of its question, or an option with a target share has no responses
+func Rake(survey *surveygo.Survey, responses []surveygo.Answers, margins []Margin, opts *RakeOptions) (*Weighting, error) {
+	o := RakeOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.MaxIterations <= 0 {
+		o.MaxIterations = defaultRakeIterations
+	}
+	if o.Tolerance <= 0 {
+		o.Tolerance = defaultRakeTolerance
+	}
+
+	weights, err := baseWeights(o.BaseWeights, len(responses))
+	if err != nil {
+		return nil, err
+	}
+	refs, err := walkQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("walking survey groups: %w", err)
+	}
+
+	// categories[m][i] is the option of response i for margin m, "" if none
+	categories := make([][]string, len(margins))
+	targets := make([]map[string]float64, len(margins))
+	for m, margin := range margins {
+		qt, err := weightingQuestion(refs, margin.Question)
+		if err != nil {
+			return nil, err
+		}
+		if targets[m], err = normalizeShares(refs, margin.Question, margin.Targets, qt); err != nil {
+			return nil, err
+		}
+		categories[m] = make([]string, len(responses))
+		for i, ans := range responses {
+			categories[m][i] = singleAnswer(qt, ans[margin.Question])
+		}
+	}
+
+	res := &Weighting{}
+	for res.Iterations < o.MaxIterations {
+		res.Iterations++
+		for m := range margins {
+			totals, sum := categoryTotals(categories[m], weights)
+			for c, share := range targets[m] {
+				if share > 0 && totals[c] == 0 {
+					return nil, fmt.Errorf("margin '%s': no responses with option '%s'", margins[m].Question, c)
+				}
+			}
+			for i, c := range categories[m] {
+				if c != "" && totals[c] > 0 {
+					weights[i] *= targets[m][c] * sum / totals[c]
+				}
+			}
+		}
+		if maxShareDiff(categories, targets, weights) <= o.Tolerance {
+			res.Converged = true
+			break
+		}
+	}
+
+	res.Weights = normalizeWeights(weights)
+	res.Summary = SummarizeWeights(res.Weights)
+	return res, nil
+}
+
+// PostStratify computes weights matching the weighted distribution of the cells formed by the options
+// of the given questions (e.g. gender x region) to their population shares. Each response gets the
+// population share of its cell over the sample share of its cell.
+// Args:
+//   - survey: the survey the answers belong to
+//   - responses: the answers of the responses
+//   - questions: the name ids of the single answer choice questions forming the cells
+//   - cells: the population shares of the cells
+//
+// Returns:
+//   - *Weighting: the weights
+//   - error: if a question is not a single answer choice question, a cell has unknown options or no
+//     responses, or a response has no answer or no cell
+func PostStratify(survey *surveygo.Survey, responses []surveygo.Answers, questions []string, cells []Cell) (*Weighting, error) {
+	refs, err := walkQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("walking survey groups: %w", err)
+	}
+
+	qtypes := make([]types.QuestionType, len(questions))
+	for j, nameId := range questions {
+		if qtypes[j], err = weightingQuestion(refs, nameId); err != nil {
+			return nil, err
+		}
+	}
+
+	shares := make(map[string]float64, len(cells))
+	var total float64
+	for _, cell := range cells {
+		if len(cell.Options) != len(questions) {
+			return nil, fmt.Errorf("cell %v: expected %d options, one per question", cell.Options, len(questions))
+		}
+		for j, opt := range cell.Options {
+			if !isOption(refs, questions[j], qtypes[j], opt) {
+				return nil, fmt.Errorf("cell %v: '%s' is not an option of question '%s'", cell.Options, opt, questions[j])
+			}
+		}
+		if err = checkWeight(cell.Share); err != nil {
+			return nil, fmt.Errorf("cell %v: %w", cell.Options, err)
+		}
+		shares[cellKey(cell.Options)] += cell.Share
+		total += cell.Share
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("cell shares add up to 0")
+	}
+
+	keys := make([]string, len(responses))
+	counts := make(map[string]int)
+	for i, ans := range responses {
+		opts := make([]string, len(questions))
+		for j, nameId := range questions {
+			if opts[j] = singleAnswer(qtypes[j], ans[nameId]); opts[j] == "" {
+				return nil, fmt.Errorf("response %d: no answer to question '%s'", i, nameId)
+			}
+		}
+		keys[i] = cellKey(opts)
+		if _, ok := shares[keys[i]]; !ok {
+			return nil, fmt.Errorf("response %d: no cell for options %v", i, opts)
+		}
+		counts[keys[i]]++
+	}
+	for key, share := range shares {
+		if share > 0 && counts[key] == 0 {
+			return nil, fmt.Errorf("cell %v: no responses", strings.Split(key, "\x00"))
+		}
+	}
+
+	weights := make([]float64, len(responses))
+	for i, key := range keys {
+		weights[i] = shares[key] / total * float64(len(responses)) / float64(counts[key])
+	}
+	res := &Weighting{Weights: normalizeWeights(weights), Iterations: 1, Converged: true}
+	res.Summary = SummarizeWeights(res.Weights)
+	return res, nil
+}
+
+func cellKey(options []string) string {
+	return strings.Join(options, "\x00")
+}
+
+func baseWeights(base []float64, n int) ([]float64, error) {
+	weights := make([]float64, n)
+	if base == nil {
+		for i := range weights {
+			weights[i] = 1
+		}
+		return weights, nil
+	}
+	if len(base) != n {
+		return nil, fmt.Errorf("got %d base weights for %d responses", len(base), n)
+	}
+	for i, w := range base {
+		if err := checkWeight(w); err != nil {
+			return nil, fmt.Errorf("response %d: %w", i, err)
+		}
+	}
+	copy(weights, base)
+	return weights, nil
+}
+
+// weightingQuestion returns the type of a single answer choice question outside repeatable groups.
+func weightingQuestion(refs []questionRef, nameId string) (types.QuestionType, error) {
+	for _, ref := range refs {
+		q := ref.question
+		if q.NameId != nameId {
+			continue
+		}
+		if len(ref.repeats) > 0 {
+			return "", fmt.Errorf("question '%s' is inside repeatable group '%s'", nameId, ref.repeats[0])
+		}
+		switch q.QTyp {
+		case types.QTypeSingleSelect, types.QTypeRadio, types.QTypeToggle:
+			return q.QTyp, nil
+		}
+		return "", fmt.Errorf("question '%s' of type '%s' is not a single answer choice question", nameId, q.QTyp)
+	}
+	return "", fmt.Errorf("question '%s' not found in survey groups", nameId)
+}
+
+func isOption(refs []questionRef, nameId string, qt types.QuestionType, option string) bool {
+	if qt == types.QTypeToggle {
+		return option == "true" || option == "false"
+	}
+	for _, ref := range refs {
+		if ref.question.NameId != nameId {
+			continue
+		}
+		for _, opt := range choiceOptions(ref.question) {
+			if opt.NameId == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeShares checks the target options and scales the shares to add up to 1.
+func normalizeShares(refs []questionRef, nameId string, targets map[string]float64, qt types.QuestionType) (map[string]float64, error) {
+	var total float64
+	for opt, share := range targets {
+		if !isOption(refs, nameId, qt, opt) {
+			return nil, fmt.Errorf("margin '%s': '%s' is not an option of the question", nameId, opt)
+		}
+		if err := checkWeight(share); err != nil {
+			return nil, fmt.Errorf("margin '%s' option '%s': %w", nameId, opt, err)
+		}
+		total += share
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("margin '%s': target shares add up to 0", nameId)
+	}
+
+	normalized := make(map[string]float64, len(targets))
+	for opt, share := range targets {
+		normalized[opt] = share / total
+	}
+	return normalized, nil
+}
+
+// singleAnswer returns the option of a single answer choice answer, "" if none.
+func singleAnswer(qt types.QuestionType, ans []any) string {
+	if qt == types.QTypeToggle {
+		if b, ok := toggleValue(ans); ok {
+			return strconv.FormatBool(b)
+		}
+		return ""
+	}
+	if values := selectedValues(ans); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// categoryTotals returns the weight of each category and of all the responses with a category.
+func categoryTotals(categories []string, weights []float64) (map[string]float64, float64) {
+	totals := make(map[string]float64)
+	var sum float64
+	for i, c := range categories {
+		if c != "" {
+			totals[c] += weights[i]
+			sum += weights[i]
+		}
+	}
+	return totals, sum
+}
+
+// maxShareDiff returns the largest difference between a weighted share and its target.
+func maxShareDiff(categories [][]string, targets []map[string]float64, weights []float64) float64 {
+	var diff float64
+	for m := range categories {
+		totals, sum := categoryTotals(categories[m], weights)
+		if sum == 0 {
+			continue
+		}
+		for c, share := range targets[m] {
+			diff = math.Max(diff, math.Abs(totals[c]/sum-share))
+		}
+		for c, total := range totals {
+			diff = math.Max(diff, math.Abs(total/sum-targets[m][c]))
+		}
+	}
+	return diff
+}
+
+// normalizeWeights scales the weights to a mean of 1.
+func normalizeWeights(weights []float64) []float64 {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return weights
+	}
+	scale := float64(len(weights)) / sum
+	for i := range weights {
+		weights[i] *= scale
+	}
+	return weights
+}
@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+const panelSurvey = `{
+  "nameId": "panel", "title": "Panel", "version": "1",
+  "groupsOrder": ["g-main"],
+  "groups": {
+    "g-main": {"nameId": "g-main", "questionsIds": ["q-gender", "q-region", "q-pets", "q-score"]}
+  },
+  "questions": {
+    "q-gender": {"nameId": "q-gender", "visible": true, "type": "radio", "label": "Gender",
+      "value": {"options": [{"nameId": "male", "label": "Male"}, {"nameId": "female", "label": "Female"}]}},
+    "q-region": {"nameId": "q-region", "visible": true, "type": "single_select", "label": "Region",
+      "value": {"options": [{"nameId": "north", "label": "North"}, {"nameId": "south", "label": "South"}]}},
+    "q-pets": {"nameId": "q-pets", "visible": true, "type": "checkbox", "label": "Pets",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-score": {"nameId": "q-score", "visible": true, "type": "slider", "label": "Score",
+      "value": {"min": 1, "max": 10, "step": 1}}
+  }
+}`
+
+// three men and one woman, mostly from the north
+var panelResponses = []surveygo.Answers{
+	{"q-gender": {"male"}, "q-region": {"north"}, "q-score": {float64(4)}},
+	{"q-gender": {"male"}, "q-region": {"north"}, "q-score": {float64(4)}},
+	{"q-gender": {"male"}, "q-region": {"south"}, "q-score": {float64(4)}},
+	{"q-gender": {"female"}, "q-region": {"north"}, "q-score": {float64(8)}},
+}
+
+func newPanelSurvey(t *testing.T) *surveygo.Survey {
+	t.Helper()
+	s, err := surveygo.ParseFromJsonStr(panelSurvey)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+	return s
+}
+
+// weightedShare returns the weighted share of the responses with the given option.
+func weightedShare(responses []surveygo.Answers, weights []float64, nameId, option string) float64 {
+	var sum, total float64
+	for i, ans := range responses {
+		total += weights[i]
+		if ans[nameId][0] == option {
+			sum += weights[i]
+		}
+	}
+	return sum / total
+}
+
+func TestRake(t *testing.T) {
+	s := newPanelSurvey(t)
+
+	res, err := Rake(s, panelResponses, []Margin{
+		{Question: "q-gender", Targets: map[string]float64{"male": 50, "female": 50}},
+		{Question: "q-region", Targets: map[string]float64{"north": 0.6, "south": 0.4}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("Rake: %v", err)
+	}
+	if !res.Converged {
+		t.Fatalf("not converged after %d iterations", res.Iterations)
+	}
+
+	for _, tt := range []struct {
+		question, option string
+		want             float64
+	}{
+		{"q-gender", "male", 0.5},
+		{"q-region", "south", 0.4},
+	} {
+		if got := weightedShare(panelResponses, res.Weights, tt.question, tt.option); math.Abs(got-tt.want) > 1e-5 {
+			t.Errorf("%s share of %s = %v, want %v", tt.option, tt.question, got, tt.want)
+		}
+	}
+	if math.Abs(res.Summary.Mean-1) > 1e-9 || res.Summary.EffectiveSampleSize >= 4 {
+		t.Errorf("Summary = %+v", res.Summary)
+	}
+}
+
+func TestRake_Errors(t *testing.T) {
+	s := newPanelSurvey(t)
+
+	tests := []struct {
+		name   string
+		margin Margin
+	}{
+		{"multi-select question", Margin{Question: "q-pets", Targets: map[string]float64{"dog": 1}}},
+		{"unknown option", Margin{Question: "q-gender", Targets: map[string]float64{"other": 1}}},
+		{"empty option", Margin{Question: "q-region", Targets: map[string]float64{"north": 1, "south": 1}}},
+	}
+	responses := panelResponses[:2] // north only
+	for _, tt := range tests {
+		if _, err := Rake(s, responses, []Margin{tt.margin}, nil); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestPostStratify(t *testing.T) {
+	s := newPanelSurvey(t)
+
+	res, err := PostStratify(s, panelResponses, []string{"q-gender", "q-region"}, []Cell{
+		{Options: []string{"male", "north"}, Share: 0.3},
+		{Options: []string{"male", "south"}, Share: 0.2},
+		{Options: []string{"female", "north"}, Share: 0.3},
+		{Options: []string{"female", "south"}, Share: 0.2},
+	})
+	if err == nil {
+		t.Fatal("expected error for the female/south cell without responses")
+	}
+
+	res, err = PostStratify(s, panelResponses, []string{"q-gender"}, []Cell{
+		{Options: []string{"male"}, Share: 1},
+		{Options: []string{"female"}, Share: 1},
+	})
+	if err != nil {
+		t.Fatalf("PostStratify: %v", err)
+	}
+	want := []float64{2.0 / 3, 2.0 / 3, 2.0 / 3, 2}
+	for i := range want {
+		if math.Abs(res.Weights[i]-want[i]) > 1e-9 {
+			t.Errorf("Weights = %v, want %v", res.Weights, want)
+			break
+		}
+	}
+}
+
+func TestSummarizeWeights(t *testing.T) {
+	got := SummarizeWeights([]float64{2.0 / 3, 2.0 / 3, 2.0 / 3, 2})
+	if got.Count != 4 || math.Abs(got.Sum-4) > 1e-9 || got.Max != 2 ||
+		math.Abs(got.EffectiveSampleSize-3) > 1e-9 || math.Abs(got.DesignEffect-4.0/3) > 1e-9 {
+		t.Errorf("SummarizeWeights = %+v", got)
+	}
+}
+
+func TestAggregator_AddWeighted(t *testing.T) {
+	agg, err := NewAggregator(newPanelSurvey(t))
+	if err != nil {
+		t.Fatalf("NewAggregator: %v", err)
+	}
+	weights := []float64{2.0 / 3, 2.0 / 3, 2.0 / 3, 2}
+	for i, ans := range panelResponses {
+		if err = agg.AddWeighted(ans, weights[i]); err != nil {
+			t.Fatalf("AddWeighted: %v", err)
+		}
+	}
+	if err = agg.AddWeighted(panelResponses[0], -1); err == nil {
+		t.Error("expected error for a negative weight")
+	}
+
+	res := agg.Results()
+	if math.Abs(res.Weights.EffectiveSampleSize-3) > 1e-9 {
+		t.Errorf("Weights = %+v", res.Weights)
+	}
+
+	gender := res.Question("q-gender")
+	if male := gender.Choice.Options[0]; male.Percent != 75 || math.Abs(male.WeightedPercent-50) > 1e-9 {
+		t.Errorf("male = %+v", male)
+	}
+	if math.Abs(gender.EffectiveAnswered-3) > 1e-9 || math.Abs(gender.WeightedAnswered-4) > 1e-9 {
+		t.Errorf("q-gender = %+v", gender)
+	}
+
+	score := res.Question("q-score").Numeric
+	if score.Mean != 5 || math.Abs(score.WeightedMean-6) > 1e-9 || score.Median != 4 || score.WeightedMedian != 6 {
+		t.Errorf("q-score = %+v", score)
+	}
+	if b := score.Histogram[7]; b.Count != 1 || b.WeightedCount != 2 {
+		t.Errorf("bin 8 = %+v", b)
+	}
+}
+
+func TestCrosstabWeighted(t *testing.T) {
+	s := newPanelSurvey(t)
+
+	unweighted, err := Crosstab(s, panelResponses, "q-gender", "q-region")
+	if err != nil {
+		t.Fatalf("Crosstab: %v", err)
+	}
+	if unweighted.EffectiveSampleSize != 4 || unweighted.WeightedCounts[0][0] != 2 {
+		t.Errorf("unweighted = %+v", unweighted)
+	}
+
+	ct, err := CrosstabWeighted(s, panelResponses, []float64{2.0 / 3, 2.0 / 3, 2.0 / 3, 2}, "q-gender", "q-region")
+	if err != nil {
+		t.Fatalf("CrosstabWeighted: %v", err)
+	}
+	if ct.Counts[0][0] != 2 || math.Abs(ct.WeightedCounts[0][0]-4.0/3) > 1e-9 || math.Abs(ct.Rows[0].WeightedPercent-50) > 1e-9 {
+		t.Errorf("weighted = %+v", ct)
+	}
+	if math.Abs(ct.EffectiveSampleSize-3) > 1e-9 {
+		t.Errorf("EffectiveSampleSize = %v", ct.EffectiveSampleSize)
+	}
+
+	if _, err = CrosstabWeighted(s, panelResponses, []float64{1}, "q-gender", "q-region"); err == nil {
+		t.Error("expected error for missing weights")
+	}
+}
@@ -0,0 +1,233 @@
+// Package builder builds surveys programmatically with typed constructors instead of JSON strings or
+// untyped question values:
+//
+//	s, err := builder.New("satisfaction").Title("Satisfaction").Version("1").
+//		Group("general", builder.Radio("satisfied", "Satisfied?",
+//			builder.Opt("satisfied-no", "No").Opens("details"),
+//			builder.Opt("satisfied-yes", "Yes"),
+//		)).
+//		Group("details", builder.TextArea("why", "Why?").Required()).
+//		Build()
+//
+// Build validates the survey like ParseFromBytes does and returns all the errors found together.
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+)
+
+// SurveyBuilder builds a survey.
+type SurveyBuilder struct {
+	survey *surveygo.Survey
+	groups []*GroupBuilder
+}
+
+// New returns a builder of a survey.
+// Args:
+//   - nameId: the name id of the survey
+//
+// Returns:
+//   - *SurveyBuilder: the survey builder
+func New(nameId string) *SurveyBuilder {
+	return &SurveyBuilder{survey: &surveygo.Survey{NameId: nameId}}
+}
+
+// Title sets the title of the survey (required).
+func (b *SurveyBuilder) Title(title string) *SurveyBuilder {
+	b.survey.Title = title
+	return b
+}
+
+// Version sets the version of the survey (required).
+func (b *SurveyBuilder) Version(version string) *SurveyBuilder {
+	b.survey.Version = version
+	return b
+}
+
+// Description sets the description of the survey.
+func (b *SurveyBuilder) Description(description string) *SurveyBuilder {
+	b.survey.Description = &description
+	return b
+}
+
+// Metadata sets a metadata entry of the survey.
+func (b *SurveyBuilder) Metadata(key string, value any) *SurveyBuilder {
+	if b.survey.Metadata == nil {
+		b.survey.Metadata = map[string]any{}
+	}
+	b.survey.Metadata[key] = value
+	return b
+}
+
+// Group adds a group with the given questions, see AddGroup.
+// Args:
+//   - nameId: the name id of the group
+//   - questions: the questions of the group, in order
+//
+// Returns:
+//   - *SurveyBuilder: the survey builder
+func (b *SurveyBuilder) Group(nameId string, questions ...Question) *SurveyBuilder {
+	return b.AddGroup(NewGroup(nameId, questions...))
+}
+
+// AddGroup adds a group to the survey.
+// Groups are added to the survey groups order in the order they are added, except the groups opened by an
+// option or nested in another group, which are shown through them.
+// Args:
+//   - g: the group builder
+//
+// Returns:
+//   - *SurveyBuilder: the survey builder
+func (b *SurveyBuilder) AddGroup(g *GroupBuilder) *SurveyBuilder {
+	b.groups = append(b.groups, g)
+	return b
+}
+
+// Build builds the survey and validates it with SurveyValidator and the survey consistency checks.
+// The survey is a copy: modifying it or the builder afterwards does not affect the other.
+// Returns:
+//   - *surveygo.Survey: the survey, with the positions of its groups and questions set
+//   - error: all the errors found, joined, if the survey is not valid
+func (b *SurveyBuilder) Build() (*surveygo.Survey, error) {
+	var errs []error
+	s := *b.survey
+	s.Questions = map[string]*question.Question{}
+	s.Groups = map[string]*question.Group{}
+	s.GroupsOrder = []string{}
+
+	shown := map[string]bool{}        // groups shown through an option or another group
+	builders := map[string]Question{} // key: question name id
+	for _, g := range b.groups {
+		if _, ok := s.Groups[g.group.NameId]; ok {
+			errs = append(errs, fmt.Errorf("group '%s' is defined more than once", g.group.NameId))
+			continue
+		}
+		group := *g.group
+		group.QuestionsIds = nil
+		s.Groups[group.NameId] = &group
+
+		for _, nameId := range group.GroupsOrder {
+			shown[nameId] = true
+		}
+		for _, qb := range g.questions {
+			q, options := qb.build()
+			group.QuestionsIds = append(group.QuestionsIds, q.NameId)
+			if prev, ok := builders[q.NameId]; ok {
+				// the same question in several groups is reported by the consistency checks
+				if prev != qb {
+					errs = append(errs, fmt.Errorf("question '%s' is defined more than once", q.NameId))
+				}
+				continue
+			}
+			builders[q.NameId] = qb
+			s.Questions[q.NameId] = q
+			for _, o := range options {
+				for _, nameId := range o.GroupsIds {
+					shown[nameId] = true
+				}
+			}
+		}
+	}
+	for _, g := range b.groups {
+		if !shown[g.group.NameId] && !slices.Contains(s.GroupsOrder, g.group.NameId) {
+			s.GroupsOrder = append(s.GroupsOrder, g.group.NameId)
+		}
+	}
+
+	if err := surveygo.SurveyValidator.Struct(&s); err != nil {
+		errs = append(errs, surveygo.TranslateValidationErrors(err)...)
+	}
+	if err := s.ValidateSurvey(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(append([]error{fmt.Errorf("error validating survey")}, errs...)...)
+	}
+
+	// sets the positions
+	if err := s.UpdateGroupsOrder(s.GroupsOrder); err != nil {
+		return nil, err
+	}
+
+	// the survey shares the metadata, groups and question values of the builders
+	return s.Clone(), nil
+}
+
+// GroupBuilder builds a group.
+type GroupBuilder struct {
+	group     *question.Group
+	questions []Question
+}
+
+// NewGroup returns a builder of a group.
+// Args:
+//   - nameId: the name id of the group
+//   - questions: the questions of the group, in order
+//
+// Returns:
+//   - *GroupBuilder: the group builder
+func NewGroup(nameId string, questions ...Question) *GroupBuilder {
+	return &GroupBuilder{group: &question.Group{NameId: nameId}, questions: questions}
+}
+
+// Title sets the title of the group.
+func (g *GroupBuilder) Title(title string) *GroupBuilder {
+	g.group.Title = &title
+	return g
+}
+
+// Description sets the description of the group.
+func (g *GroupBuilder) Description(description string) *GroupBuilder {
+	g.group.Description = &description
+	return g
+}
+
+// Hidden hides the group.
+func (g *GroupBuilder) Hidden() *GroupBuilder {
+	g.group.Hidden = true
+	return g
+}
+
+// Disabled disables the group.
+func (g *GroupBuilder) Disabled() *GroupBuilder {
+	g.group.Disabled = true
+	return g
+}
+
+// AllowRepeat allows the group to be answered more than once.
+func (g *GroupBuilder) AllowRepeat() *GroupBuilder {
+	g.group.AllowRepeat = true
+	return g
+}
+
+// Groups nests groups in the group, in order. The nested groups must be added to the survey too.
+func (g *GroupBuilder) Groups(nameIds ...string) *GroupBuilder {
+	g.group.GroupsOrder = append(g.group.GroupsOrder, nameIds...)
+	return g
+}
+
+// Metadata sets a metadata entry of the group.
+func (g *GroupBuilder) Metadata(key string, value any) *GroupBuilder {
+	if g.group.Metadata == nil {
+		g.group.Metadata = map[string]any{}
+	}
+	g.group.Metadata[key] = value
+	return g
+}
+
+// DependsOn shows the group when all the given options are selected. Each call adds an alternative,
+// so the group is shown when any of them is satisfied.
+func (g *GroupBuilder) DependsOn(conditions ...question.DependsOn) *GroupBuilder {
+	g.group.DependsOn = append(g.group.DependsOn, conditions)
+	return g
+}
+
+// On returns the condition that a question has an option selected, see DependsOn.
+func On(questionNameId, optionNameId string) question.DependsOn {
+	return question.DependsOn{QuestionNameId: questionNameId, OptionNameId: optionNameId}
+}
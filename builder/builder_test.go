@@ -0,0 +1,138 @@
+package builder
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+func TestBuild(t *testing.T) {
+	minLength, maxLength := 1, 200
+	s, err := New("satisfaction").Title("Satisfaction").Version("1").Description("Yearly survey").
+		Group("general",
+			Radio("satisfied", "Are you satisfied?", Opt("satisfied-yes", "Yes").Opens("details"), Opt("satisfied-no", "No")).Required(),
+			Slider("score", "Score", 1, 10, 1),
+		).
+		AddGroup(NewGroup("details",
+			TextArea("why", "Why?").With(func(v *text.FreeText) { v.Min, v.Max = &minLength, &maxLength }),
+		).Title("Details")).
+		Group("contact", Email("mail", "Email").DependsOn(On("satisfied", "satisfied-no"))).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if !reflect.DeepEqual(s.GroupsOrder, []string{"general", "contact"}) {
+		t.Errorf("groups order = %v", s.GroupsOrder)
+	}
+	if q := s.Questions["satisfied"]; !q.Required || !q.Visible || q.Position != 1 {
+		t.Errorf("satisfied = %+v", q.BaseQuestion)
+	}
+	if c := s.Questions["satisfied"].Value.(*choice.Choice); len(c.Options) != 2 || c.Options[0].GroupsIds[0] != "details" {
+		t.Errorf("satisfied options = %v", c.Options)
+	}
+	if v := s.Questions["why"].Value.(*text.FreeText); *v.Max != 200 {
+		t.Errorf("why max = %d", *v.Max)
+	}
+	if g := s.Groups["details"]; *g.Title != "Details" || !reflect.DeepEqual(g.QuestionsIds, []string{"why"}) {
+		t.Errorf("details = %+v", g)
+	}
+	if q := s.Questions["mail"]; q.Position != 3 || len(q.DependsOn) != 1 {
+		t.Errorf("mail = %+v", q.BaseQuestion)
+	}
+}
+
+func TestBuild_Copy(t *testing.T) {
+	minLength, maxLength := 1, 200
+	why := TextArea("why", "Why?").With(func(v *text.FreeText) { v.Min, v.Max = &minLength, &maxLength })
+	details := NewGroup("details", why).Metadata("owner", "ops").DependsOn(On("satisfied", "satisfied-no"))
+	b := New("satisfaction").Title("Satisfaction").Version("1").Metadata("tags", []any{"yearly"}).
+		Group("general", Radio("satisfied", "Are you satisfied?", Opt("satisfied-yes", "Yes"), Opt("satisfied-no", "No").Opens("details"))).
+		AddGroup(details)
+
+	s, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	// modifying the builders after Build does not affect the survey
+	b.Metadata("owner", "ops")
+	details.Metadata("owner", "research").DependsOn(On("satisfied", "satisfied-yes"))
+	why.With(func(v *text.FreeText) { *v.Max = 10 })
+	if _, ok := s.Metadata["owner"]; ok {
+		t.Errorf("survey metadata = %v", s.Metadata)
+	}
+	if g := s.Groups["details"]; g.Metadata["owner"] != "ops" || len(g.DependsOn) != 1 {
+		t.Errorf("details metadata = %v, depends on = %v", g.Metadata, g.DependsOn)
+	}
+	if v := s.Questions["why"].Value.(*text.FreeText); *v.Max != 200 {
+		t.Errorf("why max = %d", *v.Max)
+	}
+
+	// nor does modifying the survey affect the next build
+	s.Metadata["tags"].([]any)[0] = "monthly"
+	next, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if got := next.Metadata["tags"].([]any)[0]; got != "yearly" {
+		t.Errorf("rebuilt survey tags = %v", next.Metadata["tags"])
+	}
+}
+
+func TestBuild_Errors(t *testing.T) {
+	_, err := New("satisfaction").Version("1").
+		Group("general",
+			Radio("satisfied", "Are you satisfied?", Opt("yes", "Yes").Opens("missing"), Opt("no", "No")),
+			InputText("satisfied", "Name"),
+			Slider("score", "Score", 0, 10, 1),
+		).
+		Build()
+	if err == nil {
+		t.Fatal("Build: expected error")
+	}
+
+	for _, want := range []string{
+		"question 'satisfied' is defined more than once",
+		"Title",
+		"Min",
+		"group id 'missing' not found for option id 'yes'",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not contain %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestBuild_RoundTrip(t *testing.T) {
+	s, err := New("newsletter").Title("Newsletter").Version("1").
+		Group("general",
+			Toggle("subscribe", "Subscribe?", Opt("subscribe-on", "Yes").Opens("details"), Opt("subscribe-off", "No")),
+			MultiSelect("topics", "Topics", Opt("topics-news", "News"), Opt("topics-offers", "Offers")).Defaults("topics-news"),
+			DateTime("birthday", "Birthday", text.DateTypeFormatDate, "2006-01-02"),
+			Information("notice", "Notice", "We never share your email"),
+		).
+		Group("details", Email("mail", "Email").Required(), Telephone("phone", "Phone"), Document("resume", "Resume")).
+		Group("score", Slider("rating", "Rating", 1, 5, 1)).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := surveygo.ParseFromBytes(b)
+	if err != nil {
+		t.Fatalf("ParseFromBytes: %v", err)
+	}
+	if !s.Equal(parsed) {
+		t.Errorf("parsed survey differs from the built one:\n%s", b)
+	}
+}
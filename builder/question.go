@@ -0,0 +1,253 @@
+package builder
+
+import (
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/external"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// Question is a question builder, returned by the constructor of each question type.
+type Question interface {
+	// build returns the question and its options, if any.
+	build() (*question.Question, []*choice.Option)
+}
+
+// QuestionBuilder builds a question whose value is of type T.
+type QuestionBuilder[T any] struct {
+	question *question.Question
+	value    *T
+	base     *types.QBase
+}
+
+func newQuestion[T any](typ types.QuestionType, nameId, label string, value *T, base *types.QBase) *QuestionBuilder[T] {
+	q := &question.Question{BaseQuestion: question.BaseQuestion{NameId: nameId, Visible: true, QTyp: typ, Label: label}}
+	return &QuestionBuilder[T]{question: q, value: value, base: base}
+}
+
+func (b *QuestionBuilder[T]) build() (*question.Question, []*choice.Option) {
+	q := *b.question
+	q.Value = b.value
+	if c, ok := any(b.value).(*choice.Choice); ok {
+		return &q, c.Options
+	}
+	return &q, nil
+}
+
+// Required makes the question required.
+func (b *QuestionBuilder[T]) Required() *QuestionBuilder[T] {
+	b.question.Required = true
+	return b
+}
+
+// Hidden makes the question invisible.
+func (b *QuestionBuilder[T]) Hidden() *QuestionBuilder[T] {
+	b.question.Visible = false
+	return b
+}
+
+// Disabled disables the question.
+func (b *QuestionBuilder[T]) Disabled() *QuestionBuilder[T] {
+	b.question.Disabled = true
+	return b
+}
+
+// Placeholder sets the placeholder of the question.
+func (b *QuestionBuilder[T]) Placeholder(placeholder string) *QuestionBuilder[T] {
+	b.base.Placeholder = &placeholder
+	return b
+}
+
+// Defaults sets the default values of the question, option name ids for choice questions.
+func (b *QuestionBuilder[T]) Defaults(defaults ...string) *QuestionBuilder[T] {
+	b.base.Defaults = defaults
+	return b
+}
+
+// Metadata sets a metadata entry of the question.
+func (b *QuestionBuilder[T]) Metadata(key string, value any) *QuestionBuilder[T] {
+	if b.question.Metadata == nil {
+		b.question.Metadata = map[string]any{}
+	}
+	b.question.Metadata[key] = value
+	return b
+}
+
+// DependsOn shows the question when all the given options are selected. Each call adds an alternative,
+// so the question is shown when any of them is satisfied.
+func (b *QuestionBuilder[T]) DependsOn(conditions ...question.DependsOn) *QuestionBuilder[T] {
+	b.question.DependsOn = append(b.question.DependsOn, conditions)
+	return b
+}
+
+// AnswerExpr sets the expression processing the answers of the question.
+func (b *QuestionBuilder[T]) AnswerExpr(expr string) *QuestionBuilder[T] {
+	b.question.AnswerExpr = expr
+	return b
+}
+
+// With sets the fields specific to the question type, e.g.:
+//
+//	builder.TextArea("comment", "Comment").With(func(v *text.FreeText) { v.Max = &maxLength })
+func (b *QuestionBuilder[T]) With(set func(value *T)) *QuestionBuilder[T] {
+	set(b.value)
+	return b
+}
+
+// OptionBuilder builds an option of a choice question.
+type OptionBuilder struct {
+	option *choice.Option
+}
+
+// Opt returns a builder of an option.
+// Args:
+//   - nameId: the name id of the option, unique in the survey
+//   - label: the label of the option
+//
+// Returns:
+//   - *OptionBuilder: the option builder
+func Opt(nameId, label string) *OptionBuilder {
+	return &OptionBuilder{option: &choice.Option{NameId: nameId, Label: label}}
+}
+
+// Value sets the value of the option.
+func (o *OptionBuilder) Value(value any) *OptionBuilder {
+	o.option.Value = value
+	return o
+}
+
+// Opens sets the groups shown when the option is selected. The groups must be added to the survey too.
+func (o *OptionBuilder) Opens(groupNameIds ...string) *OptionBuilder {
+	o.option.GroupsIds = append(o.option.GroupsIds, groupNameIds...)
+	return o
+}
+
+// Metadata sets a metadata entry of the option.
+func (o *OptionBuilder) Metadata(key string, value any) *OptionBuilder {
+	if o.option.Metadata == nil {
+		o.option.Metadata = map[string]any{}
+	}
+	o.option.Metadata[key] = value
+	return o
+}
+
+// ------------ Choice types ------------ //
+
+// SingleSelect returns a builder of a single select question.
+func SingleSelect(nameId, label string, options ...*OptionBuilder) *QuestionBuilder[choice.Choice] {
+	return choiceQuestion(types.QTypeSingleSelect, nameId, label, options)
+}
+
+// MultiSelect returns a builder of a multiple select question.
+func MultiSelect(nameId, label string, options ...*OptionBuilder) *QuestionBuilder[choice.Choice] {
+	return choiceQuestion(types.QTypeMultipleSelect, nameId, label, options)
+}
+
+// Radio returns a builder of a radio question.
+func Radio(nameId, label string, options ...*OptionBuilder) *QuestionBuilder[choice.Choice] {
+	return choiceQuestion(types.QTypeRadio, nameId, label, options)
+}
+
+// Checkbox returns a builder of a checkbox question.
+func Checkbox(nameId, label string, options ...*OptionBuilder) *QuestionBuilder[choice.Choice] {
+	return choiceQuestion(types.QTypeCheckbox, nameId, label, options)
+}
+
+func choiceQuestion(typ types.QuestionType, nameId, label string, options []*OptionBuilder) *QuestionBuilder[choice.Choice] {
+	v := &choice.Choice{}
+	for _, o := range options {
+		v.Options = append(v.Options, o.option)
+	}
+	return newQuestion(typ, nameId, label, v, &v.QBase)
+}
+
+// Toggle returns a builder of a toggle question, a choice between its on and off options.
+func Toggle(nameId, label string, on, off *OptionBuilder) *QuestionBuilder[choice.Choice] {
+	return choiceQuestion(types.QTypeToggle, nameId, label, []*OptionBuilder{on, off})
+}
+
+// Slider returns a builder of a slider question.
+func Slider(nameId, label string, min, max, step int) *QuestionBuilder[choice.Slider] {
+	v := &choice.Slider{Min: min, Max: max, Step: step}
+	return newQuestion(types.QTypeSlider, nameId, label, v, &v.QBase)
+}
+
+// ------------ Text types ------------ //
+
+// TextArea returns a builder of a text area question.
+func TextArea(nameId, label string) *QuestionBuilder[text.FreeText] {
+	v := &text.FreeText{}
+	return newQuestion(types.QTypeTextArea, nameId, label, v, &v.QBase)
+}
+
+// InputText returns a builder of a text input question.
+func InputText(nameId, label string) *QuestionBuilder[text.FreeText] {
+	v := &text.FreeText{}
+	return newQuestion(types.QTypeInputText, nameId, label, v, &v.QBase)
+}
+
+// Email returns a builder of an email question.
+func Email(nameId, label string) *QuestionBuilder[text.Email] {
+	v := &text.Email{}
+	return newQuestion(types.QTypeEmail, nameId, label, v, &v.QBase)
+}
+
+// Telephone returns a builder of a telephone question.
+func Telephone(nameId, label string) *QuestionBuilder[text.Telephone] {
+	v := &text.Telephone{}
+	return newQuestion(types.QTypeTelephone, nameId, label, v, &v.QBase)
+}
+
+// IdentificationNumber returns a builder of an identification number question.
+func IdentificationNumber(nameId, label string) *QuestionBuilder[text.IdentificationNumber] {
+	v := &text.IdentificationNumber{}
+	return newQuestion(types.QTypeIdentificationNumber, nameId, label, v, &v.QBase)
+}
+
+// DateTime returns a builder of a date time question.
+func DateTime(nameId, label string, typ text.DateTypeFormat, format string) *QuestionBuilder[text.DateTime] {
+	v := &text.DateTime{Type: typ, Format: format}
+	return newQuestion(types.QTypeDateTime, nameId, label, v, &v.QBase)
+}
+
+// Information returns a builder of an information text.
+func Information(nameId, label, content string) *QuestionBuilder[text.InformationText] {
+	v := &text.InformationText{Text: content}
+	return newQuestion(types.QTypeInformation, nameId, label, v, &v.QBase)
+}
+
+// ------------ Asset types ------------ //
+
+// Image returns a builder of an image upload question.
+func Image(nameId, label string) *QuestionBuilder[asset.ImageAsset] {
+	v := &asset.ImageAsset{}
+	return newQuestion(types.QTypeImage, nameId, label, v, &v.QBase)
+}
+
+// Video returns a builder of a video upload question.
+func Video(nameId, label string) *QuestionBuilder[asset.VideoAsset] {
+	v := &asset.VideoAsset{}
+	return newQuestion(types.QTypeVideo, nameId, label, v, &v.QBase)
+}
+
+// Audio returns a builder of an audio upload question.
+func Audio(nameId, label string) *QuestionBuilder[asset.AudioAsset] {
+	v := &asset.AudioAsset{}
+	return newQuestion(types.QTypeAudio, nameId, label, v, &v.QBase)
+}
+
+// Document returns a builder of a document upload question.
+func Document(nameId, label string) *QuestionBuilder[asset.DocumentAsset] {
+	v := &asset.DocumentAsset{}
+	return newQuestion(types.QTypeDocument, nameId, label, v, &v.QBase)
+}
+
+// ------------ External types ------------ //
+
+// External returns a builder of an external question.
+func External(nameId, label, externalType string) *QuestionBuilder[external.ExternalQuestion] {
+	v := &external.ExternalQuestion{ExternalType: externalType}
+	return newQuestion(types.QTypeExternalQuestion, nameId, label, v, &v.QBase)
+}
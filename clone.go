@@ -0,0 +1,94 @@
+package surveygo
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Clone returns a deep copy of the survey. Questions, groups, options and the typed question values are
+// copied, so the copy can be modified without affecting the survey.
+// Returns:
+//   - *Survey: the copy of the survey, nil if the survey is nil
+func (s *Survey) Clone() *Survey {
+	return deepCopy(reflect.ValueOf(s)).Interface().(*Survey)
+}
+
+// Equal reports whether two surveys have the same definition, i.e. the same CanonicalJSON: the order of
+// map entries and the computed positions are ignored.
+// Args:
+//   - other: the survey to compare with
+//
+// Returns:
+//   - bool: true if both surveys are equal or nil
+func (s *Survey) Equal(other *Survey) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	a, err := s.CanonicalJSON()
+	if err != nil {
+		return false
+	}
+	b, err := other.CanonicalJSON()
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// deepCopy returns a deep copy of v, following pointers, interfaces, maps, slices and exported struct fields.
+// Unexported struct fields are copied by value.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Struct:
+		// copy by value first, keeping the unexported fields (e.g. of time.Time)
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
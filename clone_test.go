@@ -0,0 +1,77 @@
+package surveygo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+func TestSurvey_Clone(t *testing.T) {
+	s := parseDiffSurvey(t, nil)
+	s.Metadata = map[string]any{"owner": "ops", "tags": []any{"a", 1}}
+
+	c := s.Clone()
+	if !s.Equal(c) {
+		t.Fatal("clone is not equal to the survey")
+	}
+
+	// modifying the clone does not affect the survey
+	c.Questions["q-pets"].Value.(*choice.Choice).Options[0].Label = "Puppy"
+	*c.Questions["q-city"].Value.(*text.FreeText).Max = 10
+	c.Groups["grp-main"].QuestionsIds[0] = "q-name"
+	c.Metadata["tags"].([]any)[0] = "b"
+	if got := s.Questions["q-pets"].Value.(*choice.Choice).Options[0].Label; got != "Dog" {
+		t.Errorf("survey option label = %s", got)
+	}
+	if got := *s.Questions["q-city"].Value.(*text.FreeText).Max; got != 50 {
+		t.Errorf("survey max = %d", got)
+	}
+	if got := s.Groups["grp-main"].QuestionsIds[0]; got != "q-city" {
+		t.Errorf("survey questions ids = %v", s.Groups["grp-main"].QuestionsIds)
+	}
+	if got := s.Metadata["tags"].([]any)[0]; got != "a" {
+		t.Errorf("survey metadata = %v", s.Metadata)
+	}
+	if s.Equal(c) {
+		t.Error("modified clone is equal to the survey")
+	}
+
+	// structs with unexported fields are copied by value
+	created := time.Date(2024, 5, 17, 10, 30, 0, 0, time.UTC)
+	s.Metadata["created"] = created
+	if got, _ := s.Clone().Metadata["created"].(time.Time); !got.Equal(created) {
+		t.Errorf("cloned metadata time = %v, want %v", got, created)
+	}
+
+	var nilSurvey *Survey
+	if nilSurvey.Clone() != nil || !nilSurvey.Equal(nil) || nilSurvey.Equal(s) {
+		t.Error("nil survey clone and equality")
+	}
+}
+
+func TestSurvey_CanonicalJSON(t *testing.T) {
+	s := parseDiffSurvey(t, nil)
+	b, err := s.CanonicalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	if strings.Contains(got, `"position"`) {
+		t.Errorf("canonical JSON contains positions:\n%s", got)
+	}
+	// keys are sorted at every level
+	if !strings.HasPrefix(got, "{\n  \"groups\": {\n    \"grp-main\": {\n      \"nameId\": \"grp-main\",\n      \"questionsIds\"") {
+		t.Errorf("canonical JSON:\n%s", got)
+	}
+
+	// positions do not affect the output
+	c := s.Clone()
+	c.Questions["q-city"].Position = 42
+	c.Groups["grp-main"].Position = 0
+	if b2, _ := c.CanonicalJSON(); string(b2) != got || !s.Equal(c) {
+		t.Errorf("canonical JSON depends on positions:\n%s", b2)
+	}
+}
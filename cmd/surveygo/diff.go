@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// runDiff reports the changes between two versions of a survey.
+// Fails when a change is breaking, unless -fail-on-breaking=false.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("diff", "old.json new.json", stderr)
+	failOnBreaking := flags.Bool("fail-on-breaking", true, "fail when a change is breaking")
+	if code := parseFlags(flags, args, 2, 2); code >= 0 {
+		return code
+	}
+
+	from, err := readSurvey(flags.Arg(0))
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "diff", err)
+	}
+	to, err := readSurvey(flags.Arg(1))
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "diff", err)
+	}
+
+	d, err := surveygo.Diff(from, to)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "diff", err)
+	}
+
+	if *asJSON {
+		if err = printJSON(stdout, d); err != nil {
+			return fail(stdout, stderr, *asJSON, "diff", err)
+		}
+	} else {
+		_, _ = fmt.Fprint(stdout, d.Changelog())
+	}
+
+	if *failOnBreaking && d.HasBreakingChanges() {
+		return exitFailed
+	}
+	return exitOK
+}
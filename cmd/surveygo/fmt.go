@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// fmtResult is the --json result of the fmt command for a single file.
+type fmtResult struct {
+	File      string   `json:"file"`
+	Formatted bool     `json:"formatted"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// runFmt formats survey definitions with canonical ordering and indentation.
+// Without -w or -check, the formatted definitions are printed on stdout.
+func runFmt(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("fmt", "survey.json...", stderr)
+	write := flags.Bool("w", false, "write the formatted definitions back to their files")
+	check := flags.Bool("check", false, "fail if a definition is not formatted, without writing anything")
+	if code := parseFlags(flags, args, 1, -1); code >= 0 {
+		return code
+	}
+
+	code := exitOK
+	results := make([]*fmtResult, 0, flags.NArg())
+	for _, path := range flags.Args() {
+		res := &fmtResult{File: path}
+		results = append(results, res)
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fail(stdout, stderr, *asJSON, "fmt", err)
+		}
+
+		formatted, err := formatSurvey(b)
+		if err != nil {
+			res.Errors = errorStrings(err)
+			code = exitFailed
+			if !*asJSON {
+				printFileResult(stdout, &fileResult{File: path, Errors: res.Errors})
+			}
+			continue
+		}
+		res.Formatted = bytes.Equal(b, formatted)
+
+		switch {
+		case *check:
+			if !res.Formatted {
+				code = exitFailed
+				if !*asJSON {
+					_, _ = fmt.Fprintf(stdout, "%s: not formatted\n", path)
+				}
+			}
+		case *write:
+			if !res.Formatted {
+				if err = os.WriteFile(path, formatted, 0o644); err != nil {
+					return fail(stdout, stderr, *asJSON, "fmt", err)
+				}
+				if !*asJSON {
+					_, _ = fmt.Fprintln(stdout, path)
+				}
+			}
+		case !*asJSON:
+			_, _ = stdout.Write(formatted)
+		}
+	}
+
+	if *asJSON {
+		if err := printJSON(stdout, results); err != nil {
+			return fail(stdout, stderr, *asJSON, "fmt", err)
+		}
+	}
+
+	return code
+}
+
+// formatSurvey returns the formatted form of a survey definition: object keys are sorted, the computed
+// Position fields of questions and groups are removed and the output is indented with two spaces and ends
+// with a new line, like surveygo.Survey.CanonicalJSON. The definition must be a valid survey; it is formatted
+// as written, so fields unknown to surveygo are kept.
+func formatSurvey(b []byte) ([]byte, error) {
+	if _, err := surveygo.ParseFromBytes(b); err != nil {
+		return nil, err
+	}
+
+	// decode into maps, which are encoded with sorted keys, keeping numbers as written
+	var m map[string]any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"questions", "groups"} {
+		elements, _ := m[key].(map[string]any)
+		for _, e := range elements {
+			if e, ok := e.(map[string]any); ok {
+				delete(e, "position")
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
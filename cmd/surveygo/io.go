@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// newFlagSet returns a flag set for a command with the common --json flag.
+func newFlagSet(name, argsUsage string, stderr io.Writer) (*flag.FlagSet, *bool) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print a machine-readable JSON result on stdout")
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(stderr, "Usage: surveygo %s [flags] %s\n\nFlags:\n", name, argsUsage)
+		flags.PrintDefaults()
+	}
+	return flags, asJSON
+}
+
+// parseFlags parses the flags of a command and checks the number of positional arguments.
+// Flags may follow the positional arguments (surveygo validate survey.json --json), every argument
+// after "--" is positional.
+// Returns the exit code to use when parsing fails, or -1 when the command can go on.
+func parseFlags(flags *flag.FlagSet, args []string, minArgs, maxArgs int) int {
+	var positionals []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return exitOK
+			}
+			return exitError
+		}
+
+		rest := flags.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positionals = append(positionals, rest...)
+			break
+		}
+		positionals = append(positionals, rest[0])
+		args = rest[1:]
+	}
+
+	// leaves the positional arguments in flags.Args()
+	_ = flags.Parse(append([]string{"--"}, positionals...))
+
+	n := flags.NArg()
+	if n < minArgs || (maxArgs >= 0 && n > maxArgs) {
+		flags.Usage()
+		return exitError
+	}
+
+	return -1
+}
+
+// readSurvey reads and parses a survey definition file.
+func readSurvey(path string) (*surveygo.Survey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return surveygo.ParseFromBytes(b)
+}
+
+// readAnswers reads an answers file, see surveygo.ParseAnswers.
+func readAnswers(path string) (surveygo.Answers, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	answers, err := surveygo.ParseAnswers(f)
+	if err != nil {
+		return nil, fmt.Errorf("'%s': %w", path, err)
+	}
+	return answers, nil
+}
+
+// printJSON prints an indented JSON value.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// errorStrings splits joined errors into a list of messages.
+func errorStrings(err error) []string {
+	if err == nil {
+		return nil
+	}
+
+	var joined interface{ Unwrap() []error }
+	if errors.As(err, &joined) {
+		var res []string
+		for _, e := range joined.Unwrap() {
+			res = append(res, errorStrings(e)...)
+		}
+		return res
+	}
+
+	return []string{err.Error()}
+}
+
+// isIOError returns true if the error comes from reading or writing a file.
+func isIOError(err error) bool {
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr)
+}
+
+// errorResult is the --json result of a command failing with an I/O or usage error.
+type errorResult struct {
+	Command string   `json:"command"`
+	Error   string   `json:"error"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// fail prints an I/O or usage error and returns the error exit code.
+// With --json the error is printed on stdout as a JSON object, like the results of the command.
+func fail(stdout, stderr io.Writer, asJSON bool, cmd string, err error) int {
+	if asJSON {
+		res := &errorResult{Command: cmd, Error: err.Error()}
+		if errs := errorStrings(err); len(errs) > 1 {
+			res.Errors = errs
+		}
+		if printJSON(stdout, res) == nil {
+			return exitError
+		}
+	}
+	_, _ = fmt.Fprintf(stderr, "surveygo %s: %v\n", cmd, err)
+	return exitError
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
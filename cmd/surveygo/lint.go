@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rendis/surveygo/v2/lint"
+)
+
+// runLint reports design smells in survey definitions.
+// Fails when a finding has the -fail-on severity or a higher one.
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("lint", "survey.json...", stderr)
+	failOn := flags.String("fail-on", string(lint.SeverityError), "fail on findings of this severity or higher (error, warning, info)")
+	if code := parseFlags(flags, args, 1, -1); code >= 0 {
+		return code
+	}
+
+	severity := lint.Severity(*failOn)
+	switch severity {
+	case lint.SeverityError, lint.SeverityWarning, lint.SeverityInfo:
+	default:
+		return fail(stdout, stderr, *asJSON, "lint", fmt.Errorf("invalid -fail-on severity '%s'", *failOn))
+	}
+
+	code := exitOK
+	results := make([]*fileResult, 0, flags.NArg())
+	for _, path := range flags.Args() {
+		res := &fileResult{File: path}
+		results = append(results, res)
+
+		s, err := readSurvey(path)
+		if isIOError(err) {
+			return fail(stdout, stderr, *asJSON, "lint", err)
+		}
+		if err != nil {
+			res.Errors = errorStrings(err)
+			code = exitFailed
+		} else {
+			res.Valid = true
+			res.Findings = lint.Lint(s)
+			if lint.HasSeverity(res.Findings, severity) {
+				code = exitFailed
+			}
+		}
+
+		if !*asJSON {
+			printFileResult(stdout, res)
+		}
+	}
+
+	if *asJSON {
+		if err := printJSON(stdout, results); err != nil {
+			return fail(stdout, stderr, *asJSON, "lint", err)
+		}
+	}
+
+	return code
+}
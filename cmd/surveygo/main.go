@@ -0,0 +1,100 @@
+// Command surveygo validates, lints, reviews, renders, formats and diffs surveygo surveys.
+//
+// Usage:
+//
+//	surveygo <command> [flags] [args]
+//
+// Commands:
+//
+//	validate  parse and validate survey definitions
+//	lint      report design smells in survey definitions
+//	review    review an answers file against a survey
+//	render    render an answers file to CSV, HTML, TipTap, JSON and PDF files
+//	tree      render the group tree of a survey as HTML or JSON
+//	fmt       format survey definitions with canonical ordering and indentation
+//	diff      report the changes between two versions of a survey
+//
+// Every command accepts --json to print a machine-readable result, or error, on stdout.
+// Flags may follow the arguments.
+//
+// Exit codes:
+//
+//	0  success
+//	1  the check failed (invalid survey or answers, lint findings, breaking changes, unformatted files)
+//	2  usage or I/O error
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// command is a surveygo subcommand.
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"validate": {"parse and validate survey definitions", runValidate},
+	"lint":     {"report design smells in survey definitions", runLint},
+	"review":   {"review an answers file against a survey", runReview},
+	"render":   {"render an answers file to CSV, HTML, TipTap, JSON and PDF files", runRender},
+	"tree":     {"render the group tree of a survey as HTML or JSON", runTree},
+	"fmt":      {"format survey definitions with canonical ordering and indentation", runFmt},
+	"diff":     {"report the changes between two versions of a survey", runDiff},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "surveygo: unknown command '%s'\n\n", name)
+		usage(stderr)
+		return exitError
+	}
+
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Usage: surveygo <command> [flags] [args]")
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].summary)
+	}
+
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintln(w, "Run 'surveygo <command> -h' for the flags of a command.")
+	_, _ = fmt.Fprintln(w, "Exit codes: 0 success, 1 check failed, 2 usage or I/O error.")
+}
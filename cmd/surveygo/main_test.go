@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runCmd(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestExitCodes(t *testing.T) {
+	invalidSurvey := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalidSurvey, []byte(`{"nameId": "broken"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitError},
+		{"unknown command", []string{"nope"}, exitError},
+		{"help", []string{"help"}, exitOK},
+		{"command help", []string{"validate", "-h"}, exitOK},
+		{"missing args", []string{"validate"}, exitError},
+		{"missing file", []string{"validate", "testdata/missing.json"}, exitError},
+		{"valid survey", []string{"validate", "testdata/survey.json", "testdata/survey_v2.json"}, exitOK},
+		{"invalid survey", []string{"validate", "testdata/survey.json", invalidSurvey}, exitFailed},
+		{"lint clean", []string{"lint", "-fail-on", "info", "testdata/survey.json"}, exitOK},
+		{"lint invalid severity", []string{"lint", "-fail-on", "fatal", "testdata/survey.json"}, exitError},
+		{"review valid", []string{"review", "-survey", "testdata/survey.json", "testdata/answers.json"}, exitOK},
+		{"review invalid", []string{"review", "-survey", "testdata/survey.json", "testdata/answers_invalid.json"}, exitFailed},
+		{"review without survey", []string{"review", "testdata/answers.json"}, exitError},
+		{"diff breaking", []string{"diff", "testdata/survey.json", "testdata/survey_v2.json"}, exitFailed},
+		{"diff breaking allowed", []string{"diff", "-fail-on-breaking=false", "testdata/survey.json", "testdata/survey_v2.json"}, exitOK},
+		{"diff compatible", []string{"diff", "testdata/survey_v2.json", "testdata/survey.json"}, exitOK},
+		{"fmt check", []string{"fmt", "-check", "testdata/survey.json"}, exitFailed},
+		{"tree unknown format", []string{"tree", "-format", "svg", "testdata/survey.json"}, exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, stdout, stderr := runCmd(t, tt.args...); code != tt.want {
+				t.Errorf("exit code = %d, want %d\nstdout: %s\nstderr: %s", code, tt.want, stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	code, stdout, _ := runCmd(t, "validate", "--json", "testdata/survey.json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+
+	var results []*fileResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if len(results) != 1 || !results[0].Valid || results[0].File != "testdata/survey.json" {
+		t.Errorf("unexpected results: %s", stdout)
+	}
+}
+
+func TestFlagsAfterArgs(t *testing.T) {
+	code, stdout, _ := runCmd(t, "validate", "testdata/survey.json", "--json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+	var results []*fileResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+
+	code, stdout, _ = runCmd(t, "review", "testdata/answers_invalid.json", "-survey", "testdata/survey.json", "--json")
+	if code != exitFailed {
+		t.Fatalf("exit code = %d, want %d", code, exitFailed)
+	}
+	var res reviewResult
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+
+	// arguments after -- are positional
+	if code, _, stderr := runCmd(t, "validate", "--", "--json"); code != exitError || !strings.Contains(stderr, "--json") {
+		t.Errorf("exit code = %d, want %d\nstderr: %s", code, exitError, stderr)
+	}
+}
+
+func TestFailJSON(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "validate", "--json", "testdata/missing.json")
+	if code != exitError {
+		t.Fatalf("exit code = %d, want %d", code, exitError)
+	}
+	if stderr != "" {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+
+	var res errorResult
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if res.Command != "validate" || !strings.Contains(res.Error, "testdata/missing.json") {
+		t.Errorf("unexpected error result: %s", stdout)
+	}
+}
+
+func TestReviewJSON(t *testing.T) {
+	code, stdout, _ := runCmd(t, "review", "--json", "-survey", "testdata/survey.json", "testdata/answers_invalid.json")
+	if code != exitFailed {
+		t.Fatalf("exit code = %d, want %d", code, exitFailed)
+	}
+
+	var res reviewResult
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if res.Valid || len(res.Resume.InvalidAnswers) != 2 {
+		t.Errorf("expected 2 invalid answers, got: %s", stdout)
+	}
+}
+
+func TestReviewRequireComplete(t *testing.T) {
+	answers := filepath.Join(t.TempDir(), "partial.json")
+	if err := os.WriteFile(answers, []byte(`{"q-rooms": 2}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, _ := runCmd(t, "review", "-survey", "testdata/survey.json", answers); code != exitOK {
+		t.Errorf("exit code = %d, want %d", code, exitOK)
+	}
+
+	code, stdout, _ := runCmd(t, "review", "-require-complete", "-survey", "testdata/survey.json", answers)
+	if code != exitFailed {
+		t.Errorf("exit code = %d, want %d", code, exitFailed)
+	}
+	if !strings.Contains(stdout, "required question 'q-city' is unanswered") {
+		t.Errorf("expected unanswered required question, got: %s", stdout)
+	}
+}
+
+func TestRender(t *testing.T) {
+	out := t.TempDir()
+	code, _, stderr := runCmd(t, "render", "-survey", "testdata/survey.json", "-out", out, "-check-mark", "x,", "testdata/answers.json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d: %s", code, exitOK, stderr)
+	}
+
+	for _, name := range []string{"answers.csv", "answers.html", "answers.css", "answers.tiptap.json", "answers.card.json", "answers.pdf", "answers.xlsx"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Errorf("expected file %s: %v", name, err)
+		}
+	}
+
+	html, _ := os.ReadFile(filepath.Join(out, "answers.html"))
+	if !bytes.Contains(html, []byte(`href="answers.css"`)) {
+		t.Error("expected HTML to link answers.css")
+	}
+
+	csv, _ := os.ReadFile(filepath.Join(out, "answers.csv"))
+	if !bytes.Contains(csv, []byte("Lisbon")) {
+		t.Errorf("expected CSV to contain the answers, got: %s", csv)
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	out := t.TempDir()
+	code, stdout, _ := runCmd(t, "render", "--json", "-survey", "testdata/survey.json", "-format", "csv", "-out", out, "testdata/answers.json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+
+	var res struct {
+		Written []string `json:"written"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if len(res.Written) != 1 || filepath.Base(res.Written[0]) != "answers.csv" {
+		t.Errorf("expected only answers.csv, got %v", res.Written)
+	}
+
+	if code, _, _ = runCmd(t, "render", "-survey", "testdata/survey.json", "-format", "docx", "testdata/answers.json"); code != exitError {
+		t.Errorf("exit code = %d, want %d", code, exitError)
+	}
+}
+
+func TestTree(t *testing.T) {
+	code, stdout, _ := runCmd(t, "tree", "-format", "json", "testdata/survey.json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+	if !strings.Contains(stdout, `"nameId": "grp-jobs"`) {
+		t.Errorf("expected nested group in tree, got: %s", stdout)
+	}
+
+	output := filepath.Join(t.TempDir(), "tree.html")
+	if code, _, _ = runCmd(t, "tree", "-o", output, "testdata/survey.json"); code != exitOK {
+		t.Fatalf("exit code = %d, want %d", code, exitOK)
+	}
+	if b, err := os.ReadFile(output); err != nil || !bytes.Contains(b, []byte("grp-members")) {
+		t.Errorf("expected HTML tree in %s: %v", output, err)
+	}
+}
+
+func TestFmt(t *testing.T) {
+	src, err := os.ReadFile("testdata/survey.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// positions are computed when parsing, formatting drops them
+	src = bytes.Replace(src, []byte(`"nameId": "q-city",`), []byte(`"nameId": "q-city", "position": 1,`), 1)
+
+	path := filepath.Join(t.TempDir(), "survey.json")
+	if err = os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := runCmd(t, "fmt", "-w", path); code != exitOK {
+		t.Fatalf("exit code = %d, want %d: %s", code, exitOK, stderr)
+	}
+	if code, stdout, _ := runCmd(t, "fmt", "-check", path); code != exitOK {
+		t.Errorf("formatted file should pass -check, got %d: %s", code, stdout)
+	}
+
+	formatted, _ := os.ReadFile(path)
+	if bytes.Contains(formatted, []byte(`"position"`)) {
+		t.Error("expected positions to be removed")
+	}
+	if !bytes.HasPrefix(formatted, []byte("{\n  \"groups\": {\n")) {
+		t.Errorf("expected sorted keys with two spaces indentation, got:\n%s", formatted)
+	}
+
+	// formatting keeps the survey valid
+	if code, _, _ := runCmd(t, "validate", path); code != exitOK {
+		t.Errorf("formatted survey is not valid")
+	}
+}
+
+func TestFmt_UnknownFields(t *testing.T) {
+	src, err := os.ReadFile("testdata/survey.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// fields unknown to surveygo, at the top level and in a question
+	src = bytes.Replace(src, []byte(`"nameId": "q-city",`), []byte(`"nameId": "q-city", "x-hint": "capital",`), 1)
+	src = bytes.Replace(src, []byte("{"), []byte(`{"x-owner": {"team": "research", "weight": 1.50},`), 1)
+
+	path := filepath.Join(t.TempDir(), "survey.json")
+	if err = os.WriteFile(path, src, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := runCmd(t, "fmt", "-w", path); code != exitOK {
+		t.Fatalf("exit code = %d, want %d: %s", code, exitOK, stderr)
+	}
+
+	formatted, _ := os.ReadFile(path)
+	for _, want := range []string{`"x-hint": "capital"`, `"x-owner": {`, `"team": "research"`, `"weight": 1.50`} {
+		if !bytes.Contains(formatted, []byte(want)) {
+			t.Errorf("expected %s to be kept, got:\n%s", want, formatted)
+		}
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	code, stdout, _ := runCmd(t, "diff", "--json", "testdata/survey.json", "testdata/survey_v2.json")
+	if code != exitFailed {
+		t.Fatalf("exit code = %d, want %d", code, exitFailed)
+	}
+
+	var res struct {
+		OldVersion string `json:"oldVersion"`
+		NewVersion string `json:"newVersion"`
+		Changes    []struct {
+			Breaking bool `json:"breaking"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &res); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if res.OldVersion != "1" || res.NewVersion != "2" || len(res.Changes) == 0 {
+		t.Errorf("unexpected diff: %s", stdout)
+	}
+}
+
+func TestReadAnswersNumbers(t *testing.T) {
+	answers, err := readAnswers("testdata/answers.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := answers["q-rooms"][0].(int); !ok || v != 4 {
+		t.Errorf("expected int slider answer, got %T %v", answers["q-rooms"][0], answers["q-rooms"][0])
+	}
+	if v, ok := answers["q-city"][0].(string); !ok || v != "Lisbon" {
+		t.Errorf("expected wrapped scalar answer, got %v", answers["q-city"])
+	}
+	instance := answers["grp-members"][0].(map[string]any)
+	if v, ok := instance["q-age"].([]any)[0].(int); !ok || v != 34 {
+		t.Errorf("expected int slider answer in repeat group, got %v", instance["q-age"])
+	}
+}
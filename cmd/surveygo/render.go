@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rendis/surveygo/v2/render"
+)
+
+// renderFormats are the formats supported by the render command.
+var renderFormats = []string{"csv", "html", "tiptap", "json", "pdf", "xlsx"}
+
+// runRender renders an answers file to CSV, HTML (with its stylesheet), TipTap, JSON, PDF and XLSX files.
+// Files are named after the answers file, e.g. answers.csv, answers.html, answers.tiptap.json, answers.card.json,
+// answers.pdf and answers.xlsx. The name of the answers file is the response id of the XLSX rows.
+func runRender(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("render", "answers.json", stderr)
+	surveyPath := flags.String("survey", "", "survey definition file (required)")
+	formats := flags.String("format", strings.Join(renderFormats, ","), "comma-separated output formats (csv, html, tiptap, json, pdf, xlsx)")
+	outDir := flags.String("out", ".", "output directory")
+	checkMark := flags.String("check-mark", "", "selected and not selected marks of CSV and XLSX boolean columns, separated by a comma (e.g. 'x,')")
+	if code := parseFlags(flags, args, 1, 1); code >= 0 {
+		return code
+	}
+	if *surveyPath == "" {
+		return fail(stdout, stderr, *asJSON, "render", errors.New("flag -survey is required"))
+	}
+
+	opts, xlsx, err := renderOptions(*formats, *checkMark)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "render", err)
+	}
+
+	s, err := readSurvey(*surveyPath)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "render", err)
+	}
+
+	answersPath := flags.Arg(0)
+	answers, err := readAnswers(answersPath)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "render", err)
+	}
+
+	res, err := render.AnswersTo(s, answers, opts)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "render", err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(answersPath), filepath.Ext(answersPath))
+	files := map[string][]byte{}
+
+	if xlsx {
+		if files[base+".xlsx"], err = render.AnswersToXLSX(s, base, answers, opts.CheckMark); err != nil {
+			return fail(stdout, stderr, *asJSON, "render", err)
+		}
+	}
+
+	if err = os.MkdirAll(*outDir, 0o755); err != nil {
+		return fail(stdout, stderr, *asJSON, "render", err)
+	}
+
+	if res.CSV != nil {
+		files[base+".csv"] = res.CSV
+	}
+	if res.HTML != nil {
+		cssName := base + ".css"
+		files[base+".html"] = res.HTML.WithCSSPath(cssName).HTML
+		files[cssName] = res.HTML.CSS
+	}
+	if res.TipTap != nil {
+		if files[base+".tiptap.json"], err = json.MarshalIndent(res.TipTap, "", "  "); err != nil {
+			return fail(stdout, stderr, *asJSON, "render", err)
+		}
+	}
+	if res.PDF != nil {
+		files[base+".pdf"] = res.PDF
+	}
+	if res.JSON != nil {
+		if files[base+".card.json"], err = json.MarshalIndent(res.JSON, "", "  "); err != nil {
+			return fail(stdout, stderr, *asJSON, "render", err)
+		}
+	}
+
+	written := make([]string, 0, len(files))
+	for _, name := range sortedKeys(files) {
+		path := filepath.Join(*outDir, name)
+		if err = os.WriteFile(path, files[name], 0o644); err != nil {
+			return fail(stdout, stderr, *asJSON, "render", err)
+		}
+		written = append(written, path)
+	}
+
+	if *asJSON {
+		if err = printJSON(stdout, map[string]any{"file": answersPath, "written": written}); err != nil {
+			return fail(stdout, stderr, *asJSON, "render", err)
+		}
+		return exitOK
+	}
+
+	for _, path := range written {
+		_, _ = fmt.Fprintln(stdout, path)
+	}
+	return exitOK
+}
+
+// renderOptions builds the render output options from the -format and -check-mark flags.
+// XLSX, written by its own writer, is reported apart from the output options.
+func renderOptions(formats, checkMark string) (opts render.OutputOptions, xlsx bool, err error) {
+	for _, f := range strings.Split(formats, ",") {
+		switch strings.TrimSpace(f) {
+		case "csv":
+			opts.CSV = true
+		case "html":
+			opts.HTML = true
+		case "tiptap":
+			opts.TipTap = true
+		case "json":
+			opts.JSON = true
+		case "pdf":
+			opts.PDF = true
+		case "xlsx":
+			xlsx = true
+		case "":
+		default:
+			return opts, false, fmt.Errorf("unknown format '%s', expected one of %s", f, strings.Join(renderFormats, ", "))
+		}
+	}
+
+	if !opts.CSV && !opts.HTML && !opts.TipTap && !opts.JSON && !opts.PDF && !xlsx {
+		return opts, false, errors.New("no output format requested")
+	}
+
+	if checkMark != "" {
+		selected, notSelected, _ := strings.Cut(checkMark, ",")
+		opts.CheckMark = &render.CheckMark{Selected: selected, NotSelected: notSelected}
+	}
+
+	return opts, xlsx, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// reviewResult is the --json result of the review command.
+type reviewResult struct {
+	File     string                 `json:"file"`
+	Valid    bool                   `json:"valid"`
+	Complete bool                   `json:"complete"`
+	Resume   *surveygo.SurveyResume `json:"resume"`
+}
+
+// runReview reviews an answers file against a survey.
+// Fails when an answer is invalid, or when a required question is unanswered and -require-complete is set.
+func runReview(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("review", "answers.json", stderr)
+	surveyPath := flags.String("survey", "", "survey definition file (required)")
+	requireComplete := flags.Bool("require-complete", false, "fail when a required question is unanswered")
+	if code := parseFlags(flags, args, 1, 1); code >= 0 {
+		return code
+	}
+	if *surveyPath == "" {
+		return fail(stdout, stderr, *asJSON, "review", errors.New("flag -survey is required"))
+	}
+
+	s, err := readSurvey(*surveyPath)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "review", err)
+	}
+
+	answers, err := readAnswers(flags.Arg(0))
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "review", err)
+	}
+
+	resume, err := s.ReviewAnswers(answers)
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "review", err)
+	}
+
+	res := &reviewResult{
+		File:     flags.Arg(0),
+		Valid:    len(resume.InvalidAnswers) == 0,
+		Complete: len(resume.InvalidAnswers) == 0 && resume.TotalRequiredQuestionsAnswered >= resume.TotalRequiredQuestions,
+		Resume:   resume,
+	}
+
+	if *asJSON {
+		if err = printJSON(stdout, res); err != nil {
+			return fail(stdout, stderr, *asJSON, "review", err)
+		}
+	} else {
+		printReview(stdout, res)
+	}
+
+	if !res.Valid || (*requireComplete && !res.Complete) {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func printReview(w io.Writer, res *reviewResult) {
+	if !res.Valid {
+		for _, invalid := range res.Resume.InvalidAnswers {
+			_, _ = fmt.Fprintf(w, "%s: invalid answer for '%s': %s\n", res.File, invalid.QuestionNameId, invalid.Error)
+		}
+		return
+	}
+
+	r := res.Resume
+	_, _ = fmt.Fprintf(w, "%s: ok, %d/%d questions answered, %d/%d required questions answered\n",
+		res.File, r.TotalQuestionsAnswered, r.TotalQuestions, r.TotalRequiredQuestionsAnswered, r.TotalRequiredQuestions)
+
+	unanswered := make([]string, 0, len(r.UnansweredQuestions))
+	for nameId, required := range r.UnansweredQuestions {
+		if required {
+			unanswered = append(unanswered, nameId)
+		}
+	}
+	sort.Strings(unanswered)
+	for _, nameId := range unanswered {
+		_, _ = fmt.Fprintf(w, "%s: required question '%s' is unanswered\n", res.File, nameId)
+	}
+}
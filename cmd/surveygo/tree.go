@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rendis/surveygo/v2/render"
+)
+
+// runTree renders the group tree of a survey as HTML or JSON, on stdout or to the -o file.
+func runTree(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("tree", "survey.json", stderr)
+	format := flags.String("format", "html", "output format (html, json)")
+	output := flags.String("o", "", "output file (default stdout)")
+	if code := parseFlags(flags, args, 1, 1); code >= 0 {
+		return code
+	}
+	if *asJSON {
+		*format = "json"
+	}
+
+	s, err := readSurvey(flags.Arg(0))
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "tree", err)
+	}
+
+	var b []byte
+	switch *format {
+	case "html":
+		b, err = render.DefinitionTreeHTML(s)
+	case "json":
+		var tree *render.GroupTree
+		if tree, err = render.DefinitionTreeJSON(s); err == nil {
+			b, err = json.MarshalIndent(tree, "", "  ")
+			b = append(b, '\n')
+		}
+	default:
+		err = fmt.Errorf("unknown format '%s', expected html or json", *format)
+	}
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "tree", err)
+	}
+
+	if *output == "" {
+		_, err = stdout.Write(b)
+	} else {
+		err = os.WriteFile(*output, b, 0o644)
+	}
+	if err != nil {
+		return fail(stdout, stderr, *asJSON, "tree", err)
+	}
+
+	return exitOK
+}
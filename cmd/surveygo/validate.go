@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/lint"
+)
+
+// fileResult is the --json result of a command for a single file.
+type fileResult struct {
+	File     string          `json:"file"`
+	Valid    bool            `json:"valid"`
+	Errors   []string        `json:"errors,omitempty"`
+	Findings []*lint.Finding `json:"findings,omitempty"`
+}
+
+// runValidate parses and validates survey definitions.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	flags, asJSON := newFlagSet("validate", "survey.json...", stderr)
+	if code := parseFlags(flags, args, 1, -1); code >= 0 {
+		return code
+	}
+
+	code := exitOK
+	results := make([]*fileResult, 0, flags.NArg())
+	for _, path := range flags.Args() {
+		res := &fileResult{File: path}
+		results = append(results, res)
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fail(stdout, stderr, *asJSON, "validate", err)
+		}
+
+		s, err := surveygo.ParseFromBytes(b)
+		if err == nil {
+			err = s.ValidateSurvey()
+		}
+
+		res.Valid = err == nil
+		res.Errors = errorStrings(err)
+		if !res.Valid {
+			code = exitFailed
+		}
+
+		if !*asJSON {
+			printFileResult(stdout, res)
+		}
+	}
+
+	if *asJSON {
+		if err := printJSON(stdout, results); err != nil {
+			return fail(stdout, stderr, *asJSON, "validate", err)
+		}
+	}
+
+	return code
+}
+
+// printFileResult prints the human-readable result of a file.
+func printFileResult(w io.Writer, res *fileResult) {
+	if res.Valid && len(res.Findings) == 0 {
+		_, _ = fmt.Fprintf(w, "%s: ok\n", res.File)
+		return
+	}
+	for _, e := range res.Errors {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", res.File, e)
+	}
+	for _, f := range res.Findings {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", res.File, f)
+	}
+}
@@ -0,0 +1,384 @@
+package convert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxNameIdLength = 64
+
+	// descriptionKey is the metadata key of the help text of a question (SurveyJS description, XLSForm hint).
+	descriptionKey = "description"
+)
+
+var nameIdInvalidChars = regexp.MustCompile(`[^a-zA-Z\d_-]+`)
+
+// builder builds a survey from a source definition. It keeps name ids valid and unique, keeps the
+// source order of questions and groups, and resolves the conditions of the source into DependsOn
+// once all the questions are known.
+type builder struct {
+	survey *surveygo.Survey
+	report *Report
+
+	// ids are the name ids in use: survey, groups, questions and options.
+	ids map[string]bool
+
+	// choices are the choice questions that conditions can reference.
+	// Key: source name of the question.
+	choices map[string]*choiceRef
+
+	// continuations are the groups holding the questions that follow a subgroup.
+	// Key: parent group name id, value: continuation group.
+	continuations map[string]*question.Group
+
+	conditions []*pendingCondition
+}
+
+// choiceRef is a choice question that conditions can reference.
+type choiceRef struct {
+	nameId string
+
+	// values are the source values of the options, in source order.
+	values []string
+
+	// options maps the source values of the options to their name ids.
+	options map[string]string
+}
+
+// pendingCondition is a condition of the source, resolved once all the questions are known.
+type pendingCondition struct {
+	source string
+	nameId string
+	expr   string
+	target *[][]question.DependsOn
+}
+
+func newBuilder(nameId, title, version string) *builder {
+	b := &builder{
+		report:        &Report{},
+		ids:           map[string]bool{},
+		choices:       map[string]*choiceRef{},
+		continuations: map[string]*question.Group{},
+	}
+	if title == "" {
+		title = nameId
+	}
+	b.survey = &surveygo.Survey{
+		NameId:      b.nameId(nameId, "survey"),
+		Title:       title,
+		Version:     version,
+		Questions:   map[string]*question.Question{},
+		Groups:      map[string]*question.Group{},
+		GroupsOrder: []string{},
+	}
+	if b.survey.Title == "" {
+		b.survey.Title = b.survey.NameId
+	}
+	if b.survey.Version == "" {
+		b.survey.Version = "1"
+	}
+	return b
+}
+
+// nameId returns a valid and unused name id derived from raw, or from fallback if raw has no usable characters.
+// Accents are removed and other invalid characters are replaced by '-'.
+func (b *builder) nameId(raw, fallback string) string {
+	id := strings.Trim(nameIdInvalidChars.ReplaceAllString(removeAccents(raw), "-"), "-_")
+	switch {
+	case id == "":
+		id = fallback
+	case !isASCIILetter(id[0]) || len(id) < 3:
+		id = fallback + "-" + id
+	}
+	id = trimNameId(id, maxNameIdLength)
+
+	unique := id
+	for n := 2; b.ids[unique]; n++ {
+		suffix := fmt.Sprintf("-%d", n)
+		unique = trimNameId(id, maxNameIdLength-len(suffix)) + suffix
+	}
+	b.ids[unique] = true
+	return unique
+}
+
+// group adds a new group to the groups order of parent, or to the survey groups order if parent is nil.
+func (b *builder) group(parent *question.Group, raw, fallback, title string) *question.Group {
+	g := &question.Group{NameId: b.nameId(raw, fallback)}
+	if title != "" {
+		g.Title = &title
+	}
+	b.survey.Groups[g.NameId] = g
+
+	if parent == nil {
+		b.survey.GroupsOrder = append(b.survey.GroupsOrder, g.NameId)
+		return g
+	}
+	parent.GroupsOrder = append(parent.GroupsOrder, g.NameId)
+	delete(b.continuations, parent.NameId)
+	return g
+}
+
+// question returns a new visible question. It is added to the survey with addQuestion.
+func (b *builder) question(raw string, qt types.QuestionType, label string, value any) *question.Question {
+	return &question.Question{
+		BaseQuestion: question.BaseQuestion{
+			NameId:  b.nameId(raw, "question"),
+			Visible: true,
+			QTyp:    qt,
+			Label:   strings.TrimSpace(label),
+		},
+		Value: value,
+	}
+}
+
+// addQuestion adds a question to a group. Groups list their questions before their subgroups, so the
+// questions that follow a subgroup go to a continuation group, appended to the groups order of the group.
+func (b *builder) addQuestion(g *question.Group, q *question.Question) {
+	b.survey.Questions[q.NameId] = q
+	if len(g.GroupsOrder) == 0 {
+		g.QuestionsIds = append(g.QuestionsIds, q.NameId)
+		return
+	}
+
+	cont, ok := b.continuations[g.NameId]
+	if !ok {
+		cont = &question.Group{NameId: b.nameId(g.NameId+"-cont", "group")}
+		b.survey.Groups[cont.NameId] = cont
+		g.GroupsOrder = append(g.GroupsOrder, cont.NameId)
+		b.continuations[g.NameId] = cont
+	}
+	cont.QuestionsIds = append(cont.QuestionsIds, q.NameId)
+}
+
+// scale returns a slider from low to high, or a radio question with one option per value, registered
+// under the source name of the question, if the scale starts or ends at 0 (sliders require a non-zero
+// minimum and maximum).
+func (b *builder) scale(source, name, raw, label string, low, high int) *question.Question {
+	if low != 0 && high != 0 {
+		return b.question(raw, types.QTypeSlider, label, &choice.Slider{Min: low, Max: high, Step: 1})
+	}
+	q := b.question(raw, types.QTypeRadio, label, nil)
+	var options []option
+	for v := low; v <= high; v++ {
+		options = append(options, option{value: strconv.Itoa(v)})
+	}
+	q.Value = b.choice(name, q, options)
+	b.report.add(IssueApproximated, source, q.NameId, "scale from %d to %d converted to a radio question", low, high)
+	return q
+}
+
+// option is a choice option of the source.
+type option struct {
+	value string
+	label string
+
+	// nameId is the name id of the option, derived from the question name id and the value if empty.
+	nameId string
+}
+
+// choice returns the choice value of a question, registering its options for the conditions referencing
+// the question by its source name. Option name ids are derived from the question name id and the option value,
+// unless the source names them.
+func (b *builder) choice(source string, q *question.Question, options []option) *choice.Choice {
+	ref := &choiceRef{nameId: q.NameId, options: map[string]string{}}
+	c := &choice.Choice{}
+	for _, o := range options {
+		label := strings.TrimSpace(o.label)
+		if label == "" {
+			label = o.value
+		}
+		raw := o.nameId
+		if raw == "" {
+			raw = q.NameId + "-" + o.value
+		}
+		opt := &choice.Option{NameId: b.nameId(raw, q.NameId+"-opt"), Label: label}
+		if o.value != "" {
+			opt.Value = o.value
+		}
+		c.Options = append(c.Options, opt)
+
+		if _, ok := ref.options[o.value]; !ok {
+			ref.values = append(ref.values, o.value)
+			ref.options[o.value] = opt.NameId
+		}
+	}
+	if source != "" {
+		b.choices[source] = ref
+	}
+	return c
+}
+
+// optionNameIds returns the name ids of the options of a question with the given source values,
+// reporting the values without option.
+func (b *builder) optionNameIds(source string, ref *choiceRef, values []string) []string {
+	var ids []string
+	for _, v := range values {
+		if id, ok := ref.options[v]; ok {
+			ids = append(ids, id)
+			continue
+		}
+		b.report.add(IssueDropped, source, ref.nameId, "default value '%s' is not an option", v)
+	}
+	return ids
+}
+
+// onCondition registers a condition of the source, resolved into target by resolveConditions.
+func (b *builder) onCondition(source, nameId, expr string, target *[][]question.DependsOn) {
+	if strings.TrimSpace(expr) == "" {
+		return
+	}
+	b.conditions = append(b.conditions, &pendingCondition{source: source, nameId: nameId, expr: expr, target: target})
+}
+
+// resolveConditions parses the registered conditions into DependsOn. Conditions that cannot be
+// expressed as DependsOn are dropped and reported: the question or group is then always shown.
+func (b *builder) resolveConditions(parse func(expr string) (condition, error)) {
+	for _, pc := range b.conditions {
+		c, err := parse(pc.expr)
+		if err == nil {
+			*pc.target, err = b.dependsOn(c)
+		}
+		if err != nil {
+			b.report.add(IssueDropped, pc.source, pc.nameId, "condition '%s' cannot be expressed as dependsOn (%s), the element is always shown", pc.expr, err)
+		}
+	}
+}
+
+// step is an element of a source navigated in sequence, such as a Google Forms section or a Typeform
+// field, that jumps can lead to or skip.
+type step struct {
+	source string
+	nameId string
+	target *[][]question.DependsOn
+
+	// question is the source name of the single choice question of the step jumping depending on its
+	// answer, if any.
+	question string
+
+	// jumps maps the source values of the options of the question to the index of the step they jump
+	// to, the number of steps for the options ending the survey. Options not listed continue with next.
+	jumps map[string]int
+
+	// next is the index of the step that follows the step when no jump applies.
+	next int
+}
+
+// resolveJumps converts the jumps between steps into DependsOn: a step is shown if the answers given
+// along one of the paths leading to it are selected. Jumps only lead to later steps. Unlike the source,
+// the steps following a question with jumps are hidden when the question is not answered.
+func (b *builder) resolveJumps(steps []*step) {
+	values := func(q string) []string {
+		if ref, ok := b.choices[q]; ok {
+			return ref.values
+		}
+		return nil
+	}
+	// reach[i] is the condition leading to step i, nil if no path leads to it
+	reach := make([]condition, len(steps)+1)
+	reach[0] = condition{{}}
+	for i, s := range steps {
+		if reach[i] == nil {
+			continue
+		}
+		ref, ok := b.choices[s.question]
+		if !ok {
+			reach[s.next] = reach[s.next].or(reach[i]).simplify(values)
+			continue
+		}
+		for _, v := range ref.values {
+			to, ok := s.jumps[v]
+			if !ok {
+				to = s.next
+			}
+			reach[to] = reach[to].or(reach[i].and(selected(s.question, v))).simplify(values)
+		}
+		b.report.add(IssueApproximated, s.source, ref.nameId, "jumps converted to dependsOn of the elements they lead to or skip, which are hidden when the question is not answered")
+	}
+
+	for i, s := range steps {
+		switch {
+		case reach[i] == nil:
+			b.report.add(IssueApproximated, s.source, s.nameId, "no answer leads to the element, it is always shown")
+		case !reach[i].always():
+			deps, err := b.dependsOn(reach[i])
+			if err != nil {
+				b.report.add(IssueDropped, s.source, s.nameId, "jumps cannot be expressed as dependsOn (%s), the element is always shown", err)
+				continue
+			}
+			*s.target = deps
+		}
+	}
+}
+
+// dependsOn resolves a condition against the registered choice questions.
+func (b *builder) dependsOn(c condition) ([][]question.DependsOn, error) {
+	var res [][]question.DependsOn
+	for _, and := range c {
+		var deps []question.DependsOn
+		for _, a := range and {
+			ref, ok := b.choices[a.question]
+			if !ok {
+				return nil, fmt.Errorf("'%s' is not a choice question", a.question)
+			}
+			optionNameId, ok := ref.options[a.value]
+			if !ok {
+				return nil, fmt.Errorf("'%s' is not an option of '%s'", a.value, a.question)
+			}
+			deps = append(deps, question.DependsOn{QuestionNameId: ref.nameId, OptionNameId: optionNameId})
+		}
+		res = append(res, deps)
+	}
+	return res, nil
+}
+
+// build validates the survey and returns it with the conversion report.
+func (b *builder) build() (*surveygo.Survey, *Report, error) {
+	raw, err := json.Marshal(b.survey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshalling survey: %w", err)
+	}
+	s, err := surveygo.ParseFromBytes(raw)
+	if err != nil {
+		return nil, nil, errors.Join(fmt.Errorf("converted survey is not valid"), err)
+	}
+	return s, b.report, nil
+}
+
+// questionBase returns the QBase embedded in a question value.
+func questionBase(v any) *types.QBase {
+	return reflect.ValueOf(v).Elem().FieldByName("QBase").Addr().Interface().(*types.QBase)
+}
+
+func removeAccents(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func trimNameId(id string, n int) string {
+	if len(id) > n {
+		id = id[:n]
+	}
+	return strings.TrimRight(id, "-_")
+}
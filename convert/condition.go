@@ -0,0 +1,263 @@
+package convert
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// answerRef is the condition that a question of the source has an option selected.
+type answerRef struct {
+	// question is the source name of the question.
+	question string
+
+	// value is the source value of the option.
+	value string
+}
+
+// condition is a condition of the source in disjunctive normal form, like DependsOn:
+// the outer list is evaluated as logical OR and the inner lists as logical AND.
+type condition [][]answerRef
+
+// selected returns the condition that a question has an option selected.
+func selected(question, value string) condition {
+	return condition{{{question: question, value: value}}}
+}
+
+// or returns the condition satisfied by either a or b.
+func (a condition) or(b condition) condition {
+	return append(append(condition{}, a...), b...)
+}
+
+// and returns the condition satisfied by both a and b, distributing the conjunction over the disjunctions.
+func (a condition) and(b condition) condition {
+	var res condition
+	for _, x := range a {
+		for _, y := range b {
+			res = append(res, append(append([]answerRef{}, x...), y...))
+		}
+	}
+	return res
+}
+
+// always returns true if the condition is satisfied whatever the answers, i.e. it has an empty conjunction.
+func (a condition) always() bool {
+	for _, and := range a {
+		if len(and) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// simplify returns an equivalent condition without duplicates, where the conjunctions differing only by
+// an answer of a single choice question covering all its values are merged, and the conjunctions
+// implying another one are removed. values returns the values of the options of a question.
+func (a condition) simplify(values func(question string) []string) condition {
+	key := func(and []answerRef) string {
+		var sb strings.Builder
+		for _, r := range and {
+			sb.WriteString(r.question + "\x00" + r.value + "\x00")
+		}
+		return sb.String()
+	}
+	compare := func(x, y answerRef) int {
+		return strings.Compare(x.question+"\x00"+x.value, y.question+"\x00"+y.value)
+	}
+
+	var res condition
+	seen := map[string]bool{}
+	add := func(and []answerRef) bool {
+		and = slices.Compact(slices.SortedFunc(slices.Values(and), compare))
+		if seen[key(and)] {
+			return false
+		}
+		seen[key(and)] = true
+		res = append(res, and)
+		return true
+	}
+	for _, and := range a {
+		add(and)
+	}
+
+	for merged := true; merged; {
+		merged = false
+		// key: conjunction without the answer to the question, then question; value: answered values
+		covered := map[string]map[string]bool{}
+		rests := map[string][]answerRef{}
+		for _, and := range res {
+			for i, r := range and {
+				rest := slices.Delete(slices.Clone(and), i, i+1)
+				k := key(rest) + "\x01" + r.question
+				if covered[k] == nil {
+					covered[k] = map[string]bool{}
+				}
+				covered[k][r.value] = true
+				rests[k] = rest
+			}
+		}
+		for _, k := range slices.Sorted(maps.Keys(covered)) {
+			got := covered[k]
+			question := k[strings.LastIndexByte(k, '\x01')+1:]
+			all := values(question)
+			if len(all) > 0 && !slices.ContainsFunc(all, func(v string) bool { return !got[v] }) && add(rests[k]) {
+				merged = true
+			}
+		}
+	}
+
+	// absorption: a conjunction implying another one is redundant
+	var simplified condition
+	for i, x := range res {
+		redundant := false
+		for j, y := range res {
+			if i != j && len(y) < len(x) && subset(y, x) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			simplified = append(simplified, x)
+		}
+	}
+	return simplified
+}
+
+// subset returns true if all the answers of a are in b.
+func subset(a, b []answerRef) bool {
+	for _, r := range a {
+		if !slices.Contains(b, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenKind is the kind of a condition token.
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	// tokenVar is a question reference: {name} (SurveyJS) or ${name} (XLSForm).
+	tokenVar
+	tokenString
+	tokenNumber
+	// tokenWord is a keyword or a function name.
+	tokenWord
+	// tokenSymbol is an operator or a punctuation symbol.
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+var conditionSymbols = []string{"==", "!=", "<>", "<=", ">=", "&&", "||", "=", "<", ">", "!", "(", ")", "[", "]", ","}
+
+// tokenize splits a condition expression into tokens. Keywords are lower-cased and numbers are normalized,
+// so that 1 and 1.0 reference the same option value.
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '{' || c == '$' && i+1 < len(expr) && expr[i+1] == '{':
+			start := strings.IndexByte(expr[i:], '{') + i + 1
+			end := strings.IndexByte(expr[start:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated reference at %d", i)
+			}
+			tokens = append(tokens, token{tokenVar, strings.TrimSpace(expr[start : start+end])})
+			i = start + end + 1
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, token{tokenString, expr[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '-' && i+1 < len(expr) && expr[i+1] >= '0' && expr[i+1] <= '9':
+			j := i + 1
+			for j < len(expr) && (expr[j] >= '0' && expr[j] <= '9' || expr[j] == '.') {
+				j++
+			}
+			f, err := strconv.ParseFloat(expr[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number '%s'", expr[i:j])
+			}
+			tokens = append(tokens, token{tokenNumber, strconv.FormatFloat(f, 'f', -1, 64)})
+			i = j
+		case isASCIILetter(c) || c == '_':
+			j := i + 1
+			for j < len(expr) && (isASCIILetter(expr[j]) || expr[j] >= '0' && expr[j] <= '9' || expr[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, strings.ToLower(expr[i:j])})
+			i = j
+		default:
+			sym := ""
+			for _, s := range conditionSymbols {
+				if strings.HasPrefix(expr[i:], s) {
+					sym = s
+					break
+				}
+			}
+			if sym == "" {
+				return nil, fmt.Errorf("unexpected character '%c' at %d", c, i)
+			}
+			tokens = append(tokens, token{tokenSymbol, sym})
+			i += len(sym)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF}), nil
+}
+
+// tokenStream is a cursor over the tokens of a condition.
+type tokenStream struct {
+	tokens []token
+	pos    int
+}
+
+func (s *tokenStream) peek() token {
+	return s.tokens[s.pos]
+}
+
+func (s *tokenStream) next() token {
+	t := s.tokens[s.pos]
+	if t.kind != tokenEOF {
+		s.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of the given symbols or keywords.
+func (s *tokenStream) accept(texts ...string) bool {
+	t := s.peek()
+	if (t.kind == tokenSymbol || t.kind == tokenWord) && slices.Contains(texts, t.text) {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+func (s *tokenStream) expect(text string) error {
+	if !s.accept(text) {
+		return fmt.Errorf("expected '%s', got '%s'", text, s.peek().text)
+	}
+	return nil
+}
+
+// literal consumes a string, number or boolean literal and returns its value.
+func (s *tokenStream) literal() (string, bool) {
+	t := s.peek()
+	if t.kind == tokenString || t.kind == tokenNumber || t.kind == tokenWord && (t.text == "true" || t.text == "false") {
+		s.pos++
+		return t.text, true
+	}
+	return "", false
+}
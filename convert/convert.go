@@ -0,0 +1,109 @@
+// Package convert converts survey definitions authored with other tools into surveygo surveys, and
+// surveygo surveys back into those formats where an exporter exists.
+// Every conversion returns a Report listing the constructs of the source that have no surveygo
+// equivalent (dropped) or that were converted with a loss of behaviour or detail (approximated),
+// so nothing is silently lost.
+package convert
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IssueKind classifies a conversion issue.
+type IssueKind string
+
+const (
+	// IssueDropped the construct has no surveygo equivalent and was not converted.
+	IssueDropped IssueKind = "dropped"
+	// IssueApproximated the construct was converted with a loss of behaviour or detail.
+	IssueApproximated IssueKind = "approximated"
+)
+
+// Issue is a construct of the source that could not be converted as is.
+type Issue struct {
+	// Kind classifies the issue.
+	Kind IssueKind `json:"kind"`
+
+	// Source locates the construct in the source definition (e.g. "pages[0].elements[2]").
+	Source string `json:"source"`
+
+	// NameId is the name id of the resulting question or group, if any.
+	NameId string `json:"nameId,omitempty"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// String returns a one-line human-readable representation of the issue.
+func (i *Issue) String() string {
+	if i.NameId != "" && i.NameId != i.Source {
+		return fmt.Sprintf("%s %s (%s): %s", i.Kind, i.Source, i.NameId, i.Message)
+	}
+	return fmt.Sprintf("%s %s: %s", i.Kind, i.Source, i.Message)
+}
+
+// Report is the report of a conversion.
+type Report struct {
+	// Issues are the issues found, in conversion order.
+	Issues []*Issue `json:"issues,omitempty"`
+}
+
+// Lossless returns true if the conversion has no issues.
+func (r *Report) Lossless() bool {
+	return len(r.Issues) == 0
+}
+
+// Dropped returns the issues of the constructs that were not converted.
+func (r *Report) Dropped() []*Issue {
+	return r.byKind(IssueDropped)
+}
+
+// Approximated returns the issues of the constructs converted with a loss of behaviour or detail.
+func (r *Report) Approximated() []*Issue {
+	return r.byKind(IssueApproximated)
+}
+
+// String returns the issues, one per line.
+func (r *Report) String() string {
+	var sb strings.Builder
+	for _, i := range r.Issues {
+		sb.WriteString(i.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func (r *Report) byKind(kind IssueKind) []*Issue {
+	var res []*Issue
+	for _, i := range r.Issues {
+		if i.Kind == kind {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func (r *Report) add(kind IssueKind, source, nameId, format string, args ...any) {
+	r.Issues = append(r.Issues, &Issue{Kind: kind, Source: source, NameId: nameId, Message: fmt.Sprintf(format, args...)})
+}
+
+// ImportOptions configures the importers.
+type ImportOptions struct {
+	// NameId is the name id of the survey. Defaults to the form id of XLSForm sources, to the title otherwise.
+	NameId string
+
+	// Version is the version of the survey. Defaults to the version of the source, if any, or to "1".
+	Version string
+
+	// Locale is the locale of the texts taken from sources with translations.
+	// Defaults to the default language of the source.
+	Locale string
+}
+
+func importOptions(options []*ImportOptions) *ImportOptions {
+	if len(options) > 0 && options[0] != nil {
+		return options[0]
+	}
+	return &ImportOptions{}
+}
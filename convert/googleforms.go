@@ -0,0 +1,355 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// googleFormsChoiceTypes maps the Google Forms choice question types to question types.
+var googleFormsChoiceTypes = map[string]types.QuestionType{
+	"RADIO":     types.QTypeRadio,
+	"CHECKBOX":  types.QTypeCheckbox,
+	"DROP_DOWN": types.QTypeSingleSelect,
+}
+
+// googleFormsFileTypes maps the Google Forms file types to content types.
+var googleFormsFileTypes = map[string]string{
+	"PDF":   "application/pdf",
+	"IMAGE": "image/*",
+	"VIDEO": "video/*",
+	"AUDIO": "audio/*",
+}
+
+// googleFormsConverter converts a Google Forms API form.
+type googleFormsConverter struct {
+	*builder
+
+	// sections are the groups of the sections, in form order.
+	sections []*question.Group
+
+	// steps are the steps of the sections, in form order.
+	steps []*step
+
+	// sectionIndexes are the indexes of the sections. Key: item id of the page break starting the section.
+	sectionIndexes map[string]int
+
+	// gotos are the go to action or section id of the options of the branching question of each section,
+	// resolved once all sections are known. Key: section index, then option value.
+	gotos map[int]map[string]string
+}
+
+// FromGoogleForms converts a form of the Google Forms API (the JSON returned by forms.get, read from a
+// local file) into a survey.
+// Sections become groups, multiple choice, checkboxes and drop-down questions radio, checkbox and single
+// select questions, scales and ratings sliders, dates and times date_time questions, file uploads document
+// questions, grids a group with one radio or checkbox question per row and text, image and video items
+// information texts. The go to section navigation of the options becomes the DependsOn of the sections
+// the options skip. Quiz grading, shuffling and media are reported.
+// Args:
+//   - b: the form JSON
+//   - options: optional import options
+//
+// Returns:
+//   - *surveygo.Survey: the converted survey, validated
+//   - *Report: the constructs of the form that could not be converted as is
+//   - error: if the JSON is invalid or the converted survey is not valid
+func FromGoogleForms(b []byte, options ...*ImportOptions) (*surveygo.Survey, *Report, error) {
+	var form map[string]any
+	if err := json.Unmarshal(b, &form); err != nil {
+		return nil, nil, fmt.Errorf("unmarshalling Google Forms form: %w", err)
+	}
+
+	o := importOptions(options)
+	info := asMap(form["info"])
+	title := textOr(str(info, "title"), str(info, "documentTitle"))
+	nameId := o.NameId
+	if nameId == "" {
+		nameId = title
+	}
+	c := &googleFormsConverter{
+		builder:        newBuilder(nameId, title, o.Version),
+		sectionIndexes: map[string]int{},
+		gotos:          map[int]map[string]string{},
+	}
+	if d := str(info, "description"); d != "" {
+		c.survey.Description = &d
+	}
+	if quiz := asMap(asMap(form["settings"])["quizSettings"]); boolean(quiz, "isQuiz") {
+		c.report.add(IssueDropped, "settings", "", "quiz settings are not supported")
+	}
+
+	for i, item := range list(form, "items") {
+		c.item(fmt.Sprintf("items[%d]", i), asMap(item))
+	}
+
+	c.resolveGotos()
+	c.resolveJumps(c.steps)
+	return c.build()
+}
+
+// section returns the current section, creating an untitled one for the items before the first page break.
+func (c *googleFormsConverter) section() *question.Group {
+	if len(c.sections) == 0 {
+		c.addSection("items", c.group(nil, "", "section", ""))
+	}
+	return c.sections[len(c.sections)-1]
+}
+
+func (c *googleFormsConverter) addSection(src string, g *question.Group) {
+	c.sections = append(c.sections, g)
+	c.steps = append(c.steps, &step{source: src, nameId: g.NameId, target: &g.DependsOn, next: len(c.steps) + 1})
+}
+
+func (c *googleFormsConverter) item(src string, m map[string]any) {
+	title := str(m, "title")
+	description := str(m, "description")
+
+	switch {
+	case m["pageBreakItem"] != nil:
+		g := c.group(nil, title, "section", title)
+		if description != "" {
+			g.Description = &description
+		}
+		c.sectionIndexes[str(m, "itemId")] = len(c.sections)
+		c.addSection(src, g)
+	case m["questionItem"] != nil:
+		qi := asMap(m["questionItem"])
+		if q := c.convertQuestion(src, title, asMap(qi["question"])); q != nil {
+			if description != "" {
+				q.Metadata = map[string]any{descriptionKey: description}
+			}
+			c.addQuestion(c.section(), q)
+			if qi["image"] != nil {
+				c.report.add(IssueDropped, src, q.NameId, "the image of the question is not supported")
+			}
+		}
+	case m["questionGroupItem"] != nil:
+		c.grid(src, title, description, asMap(m["questionGroupItem"]))
+	case m["textItem"] != nil:
+		c.addQuestion(c.section(), c.question(title, types.QTypeInformation, title, &text.InformationText{Text: textOr(description, title)}))
+	case m["imageItem"] != nil, m["videoItem"] != nil:
+		kind, media := "image", asMap(asMap(m["imageItem"])["image"])
+		link := str(media, "sourceUri")
+		if m["videoItem"] != nil {
+			kind, media = "video", asMap(asMap(m["videoItem"])["video"])
+			link = str(media, "youtubeUri")
+		}
+		q := c.question(title, types.QTypeInformation, title, &text.InformationText{Text: textOr(link, textOr(title, kind))})
+		c.addQuestion(c.section(), q)
+		c.report.add(IssueApproximated, src, q.NameId, "%s item converted to an information text with the %s link", kind, kind)
+	default:
+		c.report.add(IssueDropped, src, "", "unknown item '%s'", title)
+	}
+}
+
+// convertQuestion converts a question, nil if its kind is not supported.
+func (c *googleFormsConverter) convertQuestion(src, title string, m map[string]any) *question.Question {
+	var q *question.Question
+	switch {
+	case m["choiceQuestion"] != nil:
+		q = c.choiceQuestion(src, title, m)
+	case m["textQuestion"] != nil:
+		qt := types.QuestionType(types.QTypeInputText)
+		if boolean(asMap(m["textQuestion"]), "paragraph") {
+			qt = types.QTypeTextArea
+		}
+		q = c.question(title, qt, title, &text.FreeText{})
+	case m["scaleQuestion"] != nil:
+		q = c.scale(src, str(m, "questionId"), title, asMap(m["scaleQuestion"]))
+	case m["ratingQuestion"] != nil:
+		level, ok := number(asMap(m["ratingQuestion"]), "ratingScaleLevel")
+		if !ok || level < 1 {
+			level = 5
+		}
+		q = c.question(title, types.QTypeSlider, title, &choice.Slider{Min: 1, Max: int(level), Step: 1})
+	case m["dateQuestion"] != nil:
+		dq := asMap(m["dateQuestion"])
+		dt := &text.DateTime{Format: "2006-01-02", Type: text.DateTypeFormatDate}
+		if boolean(dq, "includeTime") {
+			dt = &text.DateTime{Format: "2006-01-02T15:04", Type: text.DateTypeFormatDateTime}
+		}
+		q = c.question(title, types.QTypeDateTime, title, dt)
+		if v, ok := dq["includeYear"].(bool); ok && !v {
+			c.report.add(IssueApproximated, src, q.NameId, "date without year converted to a full date")
+		}
+	case m["timeQuestion"] != nil:
+		if boolean(asMap(m["timeQuestion"]), "duration") {
+			q = c.question(title, types.QTypeInputText, title, &text.FreeText{})
+			c.report.add(IssueApproximated, src, q.NameId, "duration question converted to a free text question")
+		} else {
+			q = c.question(title, types.QTypeDateTime, title, &text.DateTime{Format: "15:04", Type: text.DateTypeFormatTime})
+		}
+	case m["fileUploadQuestion"] != nil:
+		q = c.fileUpload(src, title, asMap(m["fileUploadQuestion"]))
+	default:
+		c.report.add(IssueDropped, src, "", "question '%s' of unknown kind is not supported", title)
+		return nil
+	}
+
+	q.Required = boolean(m, "required")
+	if m["grading"] != nil {
+		c.report.add(IssueDropped, src, q.NameId, "quiz grading is not supported")
+	}
+	return q
+}
+
+func (c *googleFormsConverter) choiceQuestion(src, title string, m map[string]any) *question.Question {
+	cq := asMap(m["choiceQuestion"])
+	typ := str(cq, "type")
+	qt, ok := googleFormsChoiceTypes[typ]
+	if !ok {
+		qt = types.QTypeRadio
+	}
+	q := c.question(title, qt, title, nil)
+
+	options, gotos := c.options(src, q.NameId, cq)
+	if len(options) == 0 {
+		c.report.add(IssueApproximated, src, q.NameId, "choice question without options converted to a free text question")
+		q.QTyp, q.Value = types.QTypeInputText, &text.FreeText{}
+		return q
+	}
+	questionId := str(m, "questionId")
+	q.Value = c.choice(questionId, q, options)
+	if boolean(cq, "shuffle") {
+		c.report.add(IssueDropped, src, q.NameId, "shuffling the options is not supported")
+	}
+	if len(gotos) > 0 && typ != "CHECKBOX" {
+		g := c.section()
+		section := len(c.sections) - 1
+		if c.steps[section].question != "" {
+			c.report.add(IssueDropped, src, q.NameId, "section '%s' already goes to the section of the answer of another question", g.NameId)
+			return q
+		}
+		c.steps[section].source, c.steps[section].question = src, questionId
+		c.gotos[section] = gotos
+	}
+	return q
+}
+
+// options returns the options of a choice question or grid, and the go to action or section id of the
+// options that have one.
+func (c *googleFormsConverter) options(src, nameId string, m map[string]any) ([]option, map[string]string) {
+	var options []option
+	gotos := map[string]string{}
+	for _, item := range list(m, "options") {
+		om := asMap(item)
+		o := option{value: str(om, "value")}
+		if boolean(om, "isOther") {
+			o = option{value: "other", label: "Other"}
+			c.report.add(IssueApproximated, src, nameId, "the text of the other option is not converted")
+		}
+		if om["image"] != nil {
+			c.report.add(IssueDropped, src, nameId, "the image of option '%s' is not supported", o.value)
+		}
+		if to := textOr(str(om, "goToSectionId"), str(om, "goToAction")); to != "" {
+			gotos[o.value] = to
+		}
+		options = append(options, o)
+	}
+	return options, gotos
+}
+
+// scale converts a linear scale into a slider, or into a radio question if the scale starts at 0.
+func (c *googleFormsConverter) scale(src, questionId, title string, m map[string]any) *question.Question {
+	low, _ := number(m, "low")
+	high, _ := number(m, "high")
+	q := c.builder.scale(src, questionId, title, title, int(low), int(high))
+	if str(m, "lowLabel") != "" || str(m, "highLabel") != "" {
+		c.report.add(IssueDropped, src, q.NameId, "the labels of the scale ends are not supported")
+	}
+	return q
+}
+
+func (c *googleFormsConverter) fileUpload(src, title string, m map[string]any) *question.Question {
+	doc := &asset.DocumentAsset{}
+	if n, ok := number(m, "maxFiles"); ok && n > 0 {
+		doc.MaxFiles = int(n)
+	}
+	if size, err := strconv.ParseInt(value(m["maxFileSize"]), 10, 64); err == nil && size > 0 {
+		doc.MaxSize = &size
+	}
+	var unknown []string
+	for _, t := range list(m, "types") {
+		if contentType, ok := googleFormsFileTypes[value(t)]; ok {
+			doc.AllowedContentTypes = append(doc.AllowedContentTypes, contentType)
+		} else if value(t) != "ANY" {
+			unknown = append(unknown, value(t))
+		}
+	}
+	q := c.question(title, types.QTypeDocument, title, doc)
+	if len(unknown) > 0 {
+		doc.AllowedContentTypes = nil
+		c.report.add(IssueApproximated, src, q.NameId, "file types %v have no content type, any file type is allowed", unknown)
+	}
+	return q
+}
+
+// grid converts a grid into a group with one radio or checkbox question per row.
+func (c *googleFormsConverter) grid(src, title, description string, m map[string]any) {
+	g := c.group(c.section(), title, "grid", title)
+	if description != "" {
+		g.Description = &description
+	}
+
+	gm := asMap(m["grid"])
+	columns := asMap(gm["columns"])
+	qt := types.QuestionType(types.QTypeRadio)
+	if str(columns, "type") == "CHECKBOX" {
+		qt = types.QTypeCheckbox
+	}
+	options, _ := c.options(src, g.NameId, columns)
+	for _, row := range list(m, "questions") {
+		rm := asMap(row)
+		rowTitle := str(asMap(rm["rowQuestion"]), "title")
+		q := c.question(title+"-"+rowTitle, qt, rowTitle, nil)
+		q.Value = c.choice(str(rm, "questionId"), q, options)
+		q.Required = boolean(rm, "required")
+		c.addQuestion(g, q)
+	}
+	if boolean(gm, "shuffleQuestions") {
+		c.report.add(IssueDropped, src, g.NameId, "shuffling the rows is not supported")
+	}
+	c.report.add(IssueApproximated, src, g.NameId, "grid converted to a group with one %s question per row", qt)
+}
+
+// resolveGotos resolves the go to action or section id of the options into jumps between sections.
+func (c *googleFormsConverter) resolveGotos() {
+	for section, st := range c.steps {
+		gotos, ok := c.gotos[section]
+		if !ok {
+			continue
+		}
+		st.jumps = map[string]int{}
+		for _, v := range c.choices[st.question].values {
+			to, ok := gotos[v]
+			if !ok {
+				continue
+			}
+			switch to {
+			case "NEXT_SECTION":
+			case "SUBMIT_FORM":
+				st.jumps[v] = len(c.steps)
+			case "RESTART_FORM":
+				c.report.add(IssueApproximated, st.source, "", "restarting the form on '%s' is not supported, it continues with the next section", v)
+			default:
+				s, ok := c.sectionIndexes[to]
+				switch {
+				case !ok:
+					c.report.add(IssueDropped, st.source, "", "option '%s' goes to unknown section '%s'", v, to)
+				case s <= section:
+					c.report.add(IssueApproximated, st.source, "", "going back to a previous section on '%s' is not supported, it continues with the next section", v)
+				default:
+					st.jumps[v] = s
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package convert
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+)
+
+// dependsOnString returns a DependsOn as its option name ids, '&' joining the conjunctions and '|' the disjunctions.
+func dependsOnString(deps [][]question.DependsOn) string {
+	var ors []string
+	for _, and := range deps {
+		var ands []string
+		for _, d := range and {
+			ands = append(ands, d.OptionNameId)
+		}
+		ors = append(ors, strings.Join(ands, " & "))
+	}
+	return strings.Join(ors, " | ")
+}
+
+func TestFromGoogleForms(t *testing.T) {
+	b, err := os.ReadFile("testdata/googleforms.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, report, err := FromGoogleForms(b)
+	if err != nil {
+		t.Fatalf("FromGoogleForms: %v", err)
+	}
+
+	if s.NameId != "Car-survey" || s.Title != "Car survey" || s.Version != "1" || *s.Description != "About the way you travel" {
+		t.Errorf("unexpected survey %s %q %s", s.NameId, s.Title, s.Version)
+	}
+	if want := []string{"section", "Your-car", "Public-transport", "Documents"}; !reflect.DeepEqual(s.GroupsOrder, want) {
+		t.Errorf("groups order = %v", s.GroupsOrder)
+	}
+	transport := s.Groups["Public-transport"]
+	if !reflect.DeepEqual(transport.QuestionsIds, []string{"Lines-used"}) || !reflect.DeepEqual(transport.GroupsOrder, []string{"Rate-the-lines"}) {
+		t.Errorf("transport group = %v %v", transport.QuestionsIds, transport.GroupsOrder)
+	}
+
+	wantTypes := map[string]types.QuestionType{
+		"Do-you-own-a-car": types.QTypeRadio, "Your-name": types.QTypeInputText, "Brand": types.QTypeSingleSelect,
+		"Satisfaction": types.QTypeSlider, "Purchase-date": types.QTypeDateTime, "Lines-used": types.QTypeCheckbox,
+		"Rate-the-lines-Bus": types.QTypeRadio, "Rate-the-lines-Subway": types.QTypeRadio, "Driving-license": types.QTypeDocument,
+		"Thanks": types.QTypeInformation, "Our-road-safety-video": types.QTypeInformation, "Time-commuting": types.QTypeInputText,
+	}
+	for nameId, want := range wantTypes {
+		if q, ok := s.Questions[nameId]; !ok || q.QTyp != want {
+			t.Errorf("question %s: want type %s, got %v", nameId, want, q)
+		}
+	}
+	if !s.Questions["Do-you-own-a-car"].Required || s.Questions["Your-name"].Metadata[descriptionKey] != "As in your ID" {
+		t.Error("required and description should be converted")
+	}
+	if o := s.Questions["Brand"].Value.(*choice.Choice).Options; len(o) != 3 || o[2].NameId != "Brand-other" {
+		t.Errorf("brand options = %v", o)
+	}
+	doc := s.Questions["Driving-license"].Value.(*asset.DocumentAsset)
+	if doc.MaxFiles != 2 || *doc.MaxSize != 10485760 || !reflect.DeepEqual(doc.AllowedContentTypes, []string{"application/pdf", "image/*"}) {
+		t.Errorf("document = %+v", doc)
+	}
+
+	// go to section navigation: "Yes" goes to the car section, "No" skips it and the last option submits
+	wantDeps := map[string]string{
+		"section":          "",
+		"Your-car":         "Do-you-own-a-car-Yes",
+		"Public-transport": "Do-you-own-a-car-No | Do-you-own-a-car-Yes",
+		"Documents":        "Do-you-own-a-car-No | Do-you-own-a-car-Yes",
+	}
+	for nameId, want := range wantDeps {
+		if got := dependsOnString(s.Groups[nameId].DependsOn); got != want {
+			t.Errorf("%s dependsOn = %s, want %s", nameId, got, want)
+		}
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	want := []string{
+		"approximated items[3]",
+		"dropped items[4]",
+		"dropped items[7]",
+		"approximated items[8]",
+		"approximated items[12]",
+		"approximated items[13]",
+		"approximated items[0]",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+}
@@ -0,0 +1,474 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// ScoringMetadataKey is the question metadata key of the scoring of quiz questions, exported to and imported
+// from QTI correct responses. Its value is a map with:
+//   - "correct": the correct answers, option name ids for choice questions and values otherwise
+//   - "points": the points awarded for a correct answer, 1 if not set
+const ScoringMetadataKey = "scoring"
+
+const (
+	qtiManifestFile   = "imsmanifest.xml"
+	qtiTestFile       = "test.xml"
+	qtiItemsDir       = "items"
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiSchemaLocation = "http://www.imsglobal.org/xsd/imsqti_v2p1 http://www.imsglobal.org/xsd/qti/qtiv2p1/imsqti_v2p1.xsd"
+	qtiCPNamespace    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiItemType       = "imsqti_item_xmlv2p1"
+	qtiTestType       = "imsqti_test_xmlv2p1"
+	qtiResponse       = "RESPONSE"
+	qtiScore          = "SCORE"
+	qtiMatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+)
+
+var xmlTags = regexp.MustCompile(`<[^>]*>`)
+
+// xmlNode is a generic XML element.
+type xmlNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+	Nodes   []*xmlNode `xml:",any"`
+}
+
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// child returns the first child element with the given name, nil if none or if n is nil.
+func (n *xmlNode) child(name string) *xmlNode {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (n *xmlNode) children(name string) []*xmlNode {
+	if n == nil {
+		return nil
+	}
+	var res []*xmlNode
+	for _, c := range n.Nodes {
+		if c.XMLName.Local == name {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// text returns the text content of the element, without markup and with collapsed whitespace.
+func (n *xmlNode) text() string {
+	if n == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(html.UnescapeString(xmlTags.ReplaceAllString(n.Inner, " "))), " ")
+}
+
+// qtiConverter converts a QTI 2.1 package.
+type qtiConverter struct {
+	*builder
+	files map[string]*zip.File
+}
+
+// FromQTI converts an IMS QTI 2.1 package (a zip with an imsmanifest.xml) into a survey.
+// The sections of the assessment test become groups, or a single group gathers the items of packages without
+// test (question banks). Choice interactions become radio questions (checkbox questions if more than one
+// choice is allowed), slider interactions sliders, extended text and text entry interactions text questions
+// and items without interaction information texts. Correct responses and the score of the match correct
+// template, or of a response condition setting the SCORE outcome, become the ScoringMetadataKey metadata,
+// and preConditions matching choice responses DependsOn. Other interactions, response processing, branch
+// rules, selection and ordering are reported.
+// Args:
+//   - b: the package zip
+//   - options: optional import options
+//
+// Returns:
+//   - *surveygo.Survey: the converted survey, validated
+//   - *Report: the constructs of the package that could not be converted as is
+//   - error: if the package, its manifest or its test cannot be read, or the converted survey is not valid
+func FromQTI(b []byte, options ...*ImportOptions) (*surveygo.Survey, *Report, error) {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening QTI package: %w", err)
+	}
+	c := &qtiConverter{files: map[string]*zip.File{}}
+	for _, f := range zr.File {
+		c.files[path.Clean(f.Name)] = f
+	}
+
+	manifest, err := c.read(qtiManifestFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	var test, testHref string
+	var items []string
+	if resources := manifest.child("resources"); resources != nil {
+		for _, r := range resources.children("resource") {
+			switch {
+			case strings.HasPrefix(r.attr("type"), qtiTestType) && test == "":
+				test, testHref = r.attr("identifier"), r.attr("href")
+			case strings.HasPrefix(r.attr("type"), qtiItemType):
+				items = append(items, r.attr("href"))
+			}
+		}
+	}
+
+	o := importOptions(options)
+	if testHref == "" {
+		nameId := o.NameId
+		if nameId == "" {
+			nameId = manifest.attr("identifier")
+		}
+		c.builder = newBuilder(nameId, "", o.Version)
+		g := c.group(nil, "", "questions", "")
+		for _, href := range items {
+			if q := c.item(href, "", href); q != nil {
+				c.addQuestion(g, q)
+			}
+		}
+		return c.build()
+	}
+
+	testNode, err := c.read(testHref)
+	if err != nil {
+		return nil, nil, err
+	}
+	nameId := o.NameId
+	if nameId == "" {
+		nameId = textOr(testNode.attr("identifier"), test)
+	}
+	c.builder = newBuilder(nameId, testNode.attr("title"), o.Version)
+	dir := path.Dir(testHref)
+	for i, part := range testNode.children("testPart") {
+		for j, section := range part.children("assessmentSection") {
+			c.section(fmt.Sprintf("testPart[%d].assessmentSection[%d]", i, j), dir, nil, section)
+		}
+		c.reportUnsupported(fmt.Sprintf("testPart[%d]", i), "", part, "preCondition", "branchRule")
+	}
+	return c.build()
+}
+
+// read reads and parses an XML file of the package.
+func (c *qtiConverter) read(name string) (*xmlNode, error) {
+	f, ok := c.files[path.Clean(name)]
+	if !ok {
+		return nil, fmt.Errorf("QTI package has no %s file", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("opening QTI %s: %w", name, err)
+	}
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading QTI %s: %w", name, err)
+	}
+	n := &xmlNode{}
+	if err = xml.Unmarshal(data, n); err != nil {
+		return nil, fmt.Errorf("parsing QTI %s: %w", name, err)
+	}
+	return n, nil
+}
+
+// section converts an assessment section into a group of parent, or of the survey if parent is nil.
+func (c *qtiConverter) section(src, dir string, parent *question.Group, n *xmlNode) {
+	g := c.group(parent, n.attr("identifier"), "section", n.attr("title"))
+	c.preConditions(src, g.NameId, n, &g.DependsOn)
+	c.reportUnsupported(src, g.NameId, n, "branchRule", "selection", "ordering", "rubricBlock", "timeLimits")
+
+	for i, part := range n.Nodes {
+		switch part.XMLName.Local {
+		case "assessmentSection":
+			c.section(fmt.Sprintf("%s.assessmentSection[%d]", src, i), dir, g, part)
+		case "assessmentItemRef":
+			href := path.Join(dir, part.attr("href"))
+			q := c.item(href, part.attr("identifier"), sourceName(part.attr("identifier"), href))
+			if q == nil {
+				continue
+			}
+			c.addQuestion(g, q)
+			c.preConditions(src, q.NameId, part, &q.DependsOn)
+			c.reportUnsupported(src, q.NameId, part, "branchRule", "timeLimits")
+		}
+	}
+}
+
+// item converts the item of a file into a question, nil if the item cannot be converted.
+// key is the identifier of the item in the test, referenced by preConditions.
+func (c *qtiConverter) item(href, key, src string) *question.Question {
+	n, err := c.read(href)
+	if err != nil {
+		c.report.add(IssueDropped, src, "", "%s", err)
+		return nil
+	}
+	id := n.attr("identifier")
+	if key == "" {
+		key = id
+	}
+	title := n.attr("title")
+	body := n.child("itemBody")
+	if body == nil {
+		body = &xmlNode{}
+	}
+
+	var interactions []*xmlNode
+	var content []string
+	for _, child := range body.Nodes {
+		if found := qtiInteractions(child); len(found) > 0 {
+			interactions = append(interactions, found...)
+			continue
+		}
+		if t := child.text(); t != "" {
+			content = append(content, t)
+		}
+	}
+	bodyText := strings.Join(content, " ")
+
+	if len(interactions) == 0 {
+		return c.question(textOr(id, key), types.QTypeInformation, title, &text.InformationText{Text: textOr(bodyText, title)})
+	}
+	for _, extra := range interactions[1:] {
+		c.report.add(IssueDropped, src, "", "only the first interaction of an item is converted, %s is not", extra.XMLName.Local)
+	}
+
+	interaction := interactions[0]
+	label := textOr(interaction.child("prompt").text(), textOr(bodyText, title))
+	var q *question.Question
+	switch interaction.XMLName.Local {
+	case "choiceInteraction":
+		q = c.choiceItem(src, key, textOr(id, key), label, interaction)
+	case "sliderInteraction":
+		low, _ := strconv.ParseFloat(interaction.attr("lowerBound"), 64)
+		high, _ := strconv.ParseFloat(interaction.attr("upperBound"), 64)
+		step, err := strconv.Atoi(interaction.attr("step"))
+		if err != nil || step < 1 {
+			step = 1
+		}
+		if low != 0 && high != 0 {
+			q = c.question(textOr(id, key), types.QTypeSlider, label, &choice.Slider{Min: int(low), Max: int(high), Step: step})
+		} else {
+			q = c.scale(src, key, textOr(id, key), label, int(low), int(high))
+		}
+	case "extendedTextInteraction", "textEntryInteraction":
+		qt := types.QuestionType(types.QTypeTextArea)
+		if interaction.XMLName.Local == "textEntryInteraction" || interaction.attr("expectedLines") == "1" {
+			qt = types.QTypeInputText
+		}
+		q = c.question(textOr(id, key), qt, label, &text.FreeText{})
+		q.Required = atoi(interaction.attr("minStrings")) > 0
+	default:
+		c.report.add(IssueDropped, src, "", "%s is not supported", interaction.XMLName.Local)
+		return nil
+	}
+
+	c.scoring(src, key, q, n)
+	c.reportUnsupported(src, q.NameId, n, "modalFeedback", "templateDeclaration", "templateProcessing")
+	return q
+}
+
+// qtiInteractions returns the interactions of an element of an item body, the element itself if it is one.
+func qtiInteractions(n *xmlNode) []*xmlNode {
+	if strings.HasSuffix(n.XMLName.Local, "Interaction") {
+		return []*xmlNode{n}
+	}
+	var res []*xmlNode
+	for _, c := range n.Nodes {
+		res = append(res, qtiInteractions(c)...)
+	}
+	return res
+}
+
+func (c *qtiConverter) choiceItem(src, key, raw, label string, n *xmlNode) *question.Question {
+	maxChoices := 1
+	if v := n.attr("maxChoices"); v != "" {
+		maxChoices = atoi(v)
+	}
+	qt := types.QuestionType(types.QTypeRadio)
+	if maxChoices != 1 {
+		qt = types.QTypeCheckbox
+	}
+	q := c.question(raw, qt, label, nil)
+
+	var options []option
+	for _, sc := range n.children("simpleChoice") {
+		id := sc.attr("identifier")
+		options = append(options, option{value: id, label: sc.text(), nameId: id})
+	}
+	q.Value = c.choice(key, q, options)
+	q.Required = atoi(n.attr("minChoices")) > 0
+	if n.attr("shuffle") == "true" {
+		c.report.add(IssueDropped, src, q.NameId, "shuffling the choices is not supported")
+	}
+	if maxChoices > 1 {
+		c.report.add(IssueApproximated, src, q.NameId, "at most %d choices converted to a checkbox question without limit", maxChoices)
+	}
+	return q
+}
+
+// scoring converts the correct response and the score of an item into the ScoringMetadataKey metadata.
+func (c *qtiConverter) scoring(src, key string, q *question.Question, n *xmlNode) {
+	var correct []any
+	for _, rd := range n.children("responseDeclaration") {
+		if rd.attr("identifier") != qtiResponse {
+			continue
+		}
+		for _, v := range rd.child("correctResponse").children("value") {
+			value := v.text()
+			if ref, ok := c.choices[key]; ok {
+				value = ref.options[value]
+			}
+			if value != "" {
+				correct = append(correct, value)
+			}
+		}
+	}
+	rp := n.child("responseProcessing")
+	if len(correct) == 0 {
+		if rp != nil {
+			c.report.add(IssueDropped, src, q.NameId, "response processing without correct response is not supported")
+		}
+		return
+	}
+
+	scoring := map[string]any{"correct": correct}
+	switch {
+	case rp == nil:
+	case strings.HasSuffix(rp.attr("template"), "match_correct"):
+		scoring["points"] = 1
+	default:
+		points, ok := qtiScorePoints(rp)
+		if !ok {
+			c.report.add(IssueDropped, src, q.NameId, "response processing '%s' is not supported", textOr(rp.attr("template"), "custom"))
+			break
+		}
+		scoring["points"] = points
+	}
+	if q.Metadata == nil {
+		q.Metadata = map[string]any{}
+	}
+	q.Metadata[ScoringMetadataKey] = scoring
+}
+
+// qtiScorePoints returns the score set by a response processing matching the correct response.
+func qtiScorePoints(rp *xmlNode) (float64, bool) {
+	cond := rp.child("responseCondition")
+	if cond == nil || len(rp.Nodes) != 1 {
+		return 0, false
+	}
+	rif := cond.child("responseIf")
+	if rif == nil || rif.child("match") == nil || rif.child("match").child("correct") == nil {
+		return 0, false
+	}
+	set := rif.child("setOutcomeValue")
+	if set == nil || set.attr("identifier") != qtiScore || set.child("baseValue") == nil {
+		return 0, false
+	}
+	points, err := strconv.ParseFloat(set.child("baseValue").text(), 64)
+	return points, err == nil
+}
+
+// preConditions converts the preConditions of a section or item reference into DependsOn.
+func (c *qtiConverter) preConditions(src, nameId string, n *xmlNode, target *[][]question.DependsOn) {
+	pcs := n.children("preCondition")
+	if len(pcs) == 0 {
+		return
+	}
+	cond := condition{{}}
+	for _, pc := range pcs {
+		var err error
+		if len(pc.Nodes) != 1 {
+			err = fmt.Errorf("expected one expression")
+		}
+		var sub condition
+		if err == nil {
+			sub, err = qtiCondition(pc.Nodes[0])
+		}
+		if err == nil {
+			cond = cond.and(sub)
+			continue
+		}
+		c.report.add(IssueDropped, src, nameId, "preCondition cannot be expressed as dependsOn (%s), the element is always shown", err)
+		return
+	}
+	deps, err := c.dependsOn(cond)
+	if err != nil {
+		c.report.add(IssueDropped, src, nameId, "preCondition cannot be expressed as dependsOn (%s), the element is always shown", err)
+		return
+	}
+	*target = deps
+}
+
+// qtiCondition converts an expression testing responses into a condition.
+// Grammar: expr := or(expr+) | and(expr+) | match(variable, baseValue) | member(baseValue, variable).
+func qtiCondition(n *xmlNode) (condition, error) {
+	switch op := n.XMLName.Local; op {
+	case "or", "and":
+		var res condition
+		for i, operand := range n.Nodes {
+			sub, err := qtiCondition(operand)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case i == 0:
+				res = sub
+			case op == "or":
+				res = res.or(sub)
+			default:
+				res = res.and(sub)
+			}
+		}
+		return res, nil
+	case "match", "member":
+		variable, value := n.child("variable"), n.child("baseValue")
+		if variable == nil || value == nil || len(n.Nodes) != 2 {
+			return nil, fmt.Errorf("%s must compare a response variable and a base value", op)
+		}
+		item := strings.TrimSuffix(variable.attr("identifier"), "."+qtiResponse)
+		return selected(item, value.text()), nil
+	default:
+		return nil, fmt.Errorf("unsupported expression '%s'", op)
+	}
+}
+
+// reportUnsupported reports the child elements with the given names.
+func (c *qtiConverter) reportUnsupported(src, nameId string, n *xmlNode, names ...string) {
+	for _, name := range names {
+		if n.child(name) != nil {
+			c.report.add(IssueDropped, src, nameId, "%s is not supported", name)
+		}
+	}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(s))
+	return n
+}
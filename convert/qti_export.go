@@ -0,0 +1,531 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strconv"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// ------------ QTI 2.1 documents ------------ //
+
+type qtiManifest struct {
+	XMLName    xml.Name      `xml:"manifest"`
+	Namespace  string        `xml:"xmlns,attr"`
+	Identifier string        `xml:"identifier,attr"`
+	Schema     string        `xml:"metadata>schema"`
+	Version    string        `xml:"metadata>schemaversion"`
+	Orgs       struct{}      `xml:"organizations"`
+	Resources  []qtiResource `xml:"resources>resource"`
+}
+
+type qtiResource struct {
+	Identifier   string          `xml:"identifier,attr"`
+	Type         string          `xml:"type,attr"`
+	Href         string          `xml:"href,attr"`
+	File         qtiHref         `xml:"file"`
+	Dependencies []qtiDependency `xml:"dependency"`
+}
+
+type qtiHref struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiAssessmentTest struct {
+	XMLName           xml.Name                `xml:"assessmentTest"`
+	Namespace         string                  `xml:"xmlns,attr"`
+	XSINamespace      string                  `xml:"xmlns:xsi,attr"`
+	SchemaLocation    string                  `xml:"xsi:schemaLocation,attr"`
+	Identifier        string                  `xml:"identifier,attr"`
+	Title             string                  `xml:"title,attr"`
+	Outcomes          []qtiOutcomeDeclaration `xml:"outcomeDeclaration"`
+	Part              qtiTestPart             `xml:"testPart"`
+	OutcomeProcessing *qtiOutcomeProcessing   `xml:"outcomeProcessing"`
+}
+
+type qtiTestPart struct {
+	Identifier     string        `xml:"identifier,attr"`
+	NavigationMode string        `xml:"navigationMode,attr"`
+	SubmissionMode string        `xml:"submissionMode,attr"`
+	Sections       []*qtiSection `xml:"assessmentSection"`
+}
+
+type qtiSection struct {
+	XMLName       xml.Name           `xml:"assessmentSection"`
+	Identifier    string             `xml:"identifier,attr"`
+	Title         string             `xml:"title,attr"`
+	Visible       bool               `xml:"visible,attr"`
+	PreConditions []*qtiPreCondition `xml:"preCondition"`
+	// Parts are the item references and subsections, in order.
+	Parts []any
+}
+
+type qtiItemRef struct {
+	XMLName       xml.Name           `xml:"assessmentItemRef"`
+	Identifier    string             `xml:"identifier,attr"`
+	Href          string             `xml:"href,attr"`
+	PreConditions []*qtiPreCondition `xml:"preCondition"`
+}
+
+// qtiPreCondition is a preCondition, holding a single expression.
+type qtiPreCondition struct {
+	Expression *qtiExpression
+}
+
+// qtiExpression is an expression element: or, and, match, member, variable or baseValue.
+type qtiExpression struct {
+	XMLName    xml.Name
+	Identifier string `xml:"identifier,attr,omitempty"`
+	BaseType   string `xml:"baseType,attr,omitempty"`
+	Value      string `xml:",chardata"`
+	Operands   []*qtiExpression
+}
+
+type qtiOutcomeProcessing struct {
+	Set struct {
+		Identifier string `xml:"identifier,attr"`
+		Sum        struct {
+			Variables qtiTestVariables `xml:"testVariables"`
+		} `xml:"sum"`
+	} `xml:"setOutcomeValue"`
+}
+
+type qtiTestVariables struct {
+	VariableIdentifier string `xml:"variableIdentifier,attr"`
+}
+
+type qtiAssessmentItem struct {
+	XMLName        xml.Name                 `xml:"assessmentItem"`
+	Namespace      string                   `xml:"xmlns,attr"`
+	XSINamespace   string                   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string                   `xml:"xsi:schemaLocation,attr"`
+	Identifier     string                   `xml:"identifier,attr"`
+	Title          string                   `xml:"title,attr"`
+	Adaptive       bool                     `xml:"adaptive,attr"`
+	TimeDependent  bool                     `xml:"timeDependent,attr"`
+	Responses      []qtiResponseDeclaration `xml:"responseDeclaration"`
+	Outcomes       []qtiOutcomeDeclaration  `xml:"outcomeDeclaration"`
+	Body           qtiItemBody              `xml:"itemBody"`
+	Processing     *qtiResponseProcessing   `xml:"responseProcessing"`
+}
+
+type qtiResponseDeclaration struct {
+	Identifier  string     `xml:"identifier,attr"`
+	Cardinality string     `xml:"cardinality,attr"`
+	BaseType    string     `xml:"baseType,attr"`
+	Correct     *qtiValues `xml:"correctResponse"`
+}
+
+type qtiOutcomeDeclaration struct {
+	Identifier  string     `xml:"identifier,attr"`
+	Cardinality string     `xml:"cardinality,attr"`
+	BaseType    string     `xml:"baseType,attr"`
+	Default     *qtiValues `xml:"defaultValue"`
+}
+
+type qtiValues struct {
+	Values []string `xml:"value"`
+}
+
+type qtiItemBody struct {
+	Text   string                  `xml:"div,omitempty"`
+	Choice *qtiChoiceInteraction   `xml:"choiceInteraction"`
+	Slider *qtiSliderInteraction   `xml:"sliderInteraction"`
+	Input  *qtiExtendedInteraction `xml:"extendedTextInteraction"`
+}
+
+type qtiChoiceInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	MinChoices         int               `xml:"minChoices,attr,omitempty"`
+	Prompt             string            `xml:"prompt"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiSimpleChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiSliderInteraction struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	LowerBound         int    `xml:"lowerBound,attr"`
+	UpperBound         int    `xml:"upperBound,attr"`
+	Step               int    `xml:"step,attr"`
+	Prompt             string `xml:"prompt"`
+}
+
+type qtiExtendedInteraction struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	ExpectedLines      int    `xml:"expectedLines,attr,omitempty"`
+	MinStrings         int    `xml:"minStrings,attr,omitempty"`
+	Prompt             string `xml:"prompt"`
+}
+
+type qtiResponseProcessing struct {
+	Template  string                `xml:"template,attr,omitempty"`
+	Condition *qtiResponseCondition `xml:"responseCondition"`
+}
+
+// qtiResponseCondition sets the score if the response matches the correct response.
+type qtiResponseCondition struct {
+	Match struct {
+		Variable qtiHrefIdentifier `xml:"variable"`
+		Correct  qtiHrefIdentifier `xml:"correct"`
+	} `xml:"responseIf>match"`
+	Set struct {
+		Identifier string `xml:"identifier,attr"`
+		Value      struct {
+			BaseType string `xml:"baseType,attr"`
+			Value    string `xml:",chardata"`
+		} `xml:"baseValue"`
+	} `xml:"responseIf>setOutcomeValue"`
+}
+
+type qtiHrefIdentifier struct {
+	Identifier string `xml:"identifier,attr"`
+}
+
+// ------------ export ------------ //
+
+// qtiExporter converts a survey into a QTI package.
+type qtiExporter struct {
+	survey  *surveygo.Survey
+	report  *Report
+	items   []*qtiAssessmentItem
+	visited map[string]bool
+
+	// cardinalities are the response cardinalities of the exported choice questions, by name id.
+	cardinalities map[string]string
+}
+
+// ToQTI converts a survey into an IMS QTI 2.1 package: a zip with an imsmanifest.xml, an assessment test and
+// one item per question.
+// Groups become assessment sections, choice questions and toggles choice interactions, sliders slider
+// interactions, text questions extended text interactions and information texts items without interaction.
+// The ScoringMetadataKey metadata becomes the correct response of the item, scored with the match correct
+// template or with a response condition for points other than 1. DependsOn and the groups of options become
+// preConditions. Other questions, hidden questions and groups, descriptions and defaults are reported.
+// Args:
+//   - survey: the survey to convert
+//
+// Returns:
+//   - []byte: the package zip
+//   - *Report: the elements of the survey that could not be converted as is
+//   - error: if the package cannot be written
+func ToQTI(survey *surveygo.Survey) ([]byte, *Report, error) {
+	e := &qtiExporter{survey: survey, report: &Report{}, visited: map[string]bool{}, cardinalities: map[string]string{}}
+	if survey.Description != nil && *survey.Description != "" {
+		e.report.add(IssueDropped, survey.NameId, "", "the survey description is not supported")
+	}
+
+	test := &qtiAssessmentTest{
+		Namespace:      qtiNamespace,
+		XSINamespace:   "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     survey.NameId,
+		Title:          survey.Title,
+		Part:           qtiTestPart{Identifier: "part", NavigationMode: "linear", SubmissionMode: "individual"},
+	}
+	for _, nameId := range survey.GroupsOrder {
+		if s := e.section(nameId, nil); s != nil {
+			test.Part.Sections = append(test.Part.Sections, s)
+		}
+	}
+	for _, item := range e.items {
+		if item.Processing != nil {
+			test.Outcomes = []qtiOutcomeDeclaration{{Identifier: qtiScore, Cardinality: "single", BaseType: "float"}}
+			test.OutcomeProcessing = &qtiOutcomeProcessing{}
+			test.OutcomeProcessing.Set.Identifier = qtiScore
+			test.OutcomeProcessing.Set.Sum.Variables.VariableIdentifier = qtiScore
+			break
+		}
+	}
+
+	manifest := &qtiManifest{
+		Namespace:  qtiCPNamespace,
+		Identifier: "manifest-" + survey.NameId,
+		Schema:     "QTIv2.1 Package",
+		Version:    "1.0.0",
+	}
+	testResource := qtiResource{Identifier: "test-" + survey.NameId, Type: qtiTestType, Href: qtiTestFile, File: qtiHref{qtiTestFile}}
+	files := map[string]any{qtiTestFile: test}
+	names := []string{qtiTestFile}
+	var itemResources []qtiResource
+	for _, item := range e.items {
+		href := path.Join(qtiItemsDir, item.Identifier+".xml")
+		files[href] = item
+		names = append(names, href)
+		itemResources = append(itemResources, qtiResource{Identifier: "item-" + item.Identifier, Type: qtiItemType, Href: href, File: qtiHref{href}})
+		testResource.Dependencies = append(testResource.Dependencies, qtiDependency{"item-" + item.Identifier})
+	}
+	manifest.Resources = append([]qtiResource{testResource}, itemResources...)
+	files[qtiManifestFile] = manifest
+	names = append([]string{qtiManifestFile}, names...)
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		data, err := xml.MarshalIndent(files[name], "", "  ")
+		if err != nil {
+			return nil, nil, fmt.Errorf("marshalling QTI %s: %w", name, err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			return nil, nil, fmt.Errorf("writing QTI %s: %w", name, err)
+		}
+		if _, err = w.Write(append([]byte(xml.Header), data...)); err != nil {
+			return nil, nil, fmt.Errorf("writing QTI %s: %w", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("writing QTI package: %w", err)
+	}
+	return buf.Bytes(), e.report, nil
+}
+
+// section converts a group into a section, nil if the group is not exported.
+// preConditions are the conditions of the option opening the group, if any.
+func (e *qtiExporter) section(nameId string, preConditions []*qtiPreCondition) *qtiSection {
+	g, ok := e.survey.Groups[nameId]
+	if !ok || e.visited[nameId] {
+		return nil
+	}
+	e.visited[nameId] = true
+	switch {
+	case g.IsExternalSurvey:
+		e.report.add(IssueDropped, nameId, "", "external survey groups are not supported")
+		return nil
+	case g.Hidden || g.Disabled:
+		e.report.add(IssueDropped, nameId, "", "hidden and disabled groups are not exported")
+		return nil
+	case g.AllowRepeat:
+		e.report.add(IssueApproximated, nameId, "", "repeatable group exported as a section answered once")
+	}
+	if g.Description != nil && *g.Description != "" {
+		e.report.add(IssueDropped, nameId, "", "the group description is not supported")
+	}
+
+	s := &qtiSection{Identifier: nameId, Title: derefString(g.Title), Visible: true}
+	s.PreConditions = append(preConditions, e.preConditions(g.DependsOn)...)
+	for _, qId := range g.QuestionsIds {
+		q, ok := e.survey.Questions[qId]
+		if !ok || !e.item(q) {
+			continue
+		}
+		s.Parts = append(s.Parts, &qtiItemRef{
+			Identifier:    qId,
+			Href:          path.Join(qtiItemsDir, qId+".xml"),
+			PreConditions: e.preConditions(q.DependsOn),
+		})
+
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
+		for _, o := range c.Options {
+			for _, sub := range o.GroupsIds {
+				cond := &qtiPreCondition{Expression: e.selected(qId, o.NameId)}
+				if subSection := e.section(sub, []*qtiPreCondition{cond}); subSection != nil {
+					s.Parts = append(s.Parts, subSection)
+				}
+			}
+		}
+	}
+	for _, sub := range g.GroupsOrder {
+		if subSection := e.section(sub, nil); subSection != nil {
+			s.Parts = append(s.Parts, subSection)
+		}
+	}
+	return s
+}
+
+// item converts a question into an item, returning false if the question is not exported.
+func (e *qtiExporter) item(q *question.Question) bool {
+	if !q.Visible {
+		e.report.add(IssueDropped, q.NameId, "", "invisible questions are not exported")
+		return false
+	}
+
+	item := &qtiAssessmentItem{
+		Namespace:      qtiNamespace,
+		XSINamespace:   "http://www.w3.org/2001/XMLSchema-instance",
+		SchemaLocation: qtiSchemaLocation,
+		Identifier:     q.NameId,
+		Title:          q.Label,
+	}
+	response := qtiResponseDeclaration{Identifier: qtiResponse, Cardinality: "single"}
+	switch v := q.Value.(type) {
+	case *choice.Choice:
+		interaction := &qtiChoiceInteraction{ResponseIdentifier: qtiResponse, MaxChoices: 1, Prompt: q.Label}
+		response.BaseType = "identifier"
+		switch q.QTyp {
+		case types.QTypeCheckbox, types.QTypeMultipleSelect:
+			interaction.MaxChoices = 0
+			response.Cardinality = "multiple"
+		}
+		if q.QTyp != types.QTypeRadio && q.QTyp != types.QTypeCheckbox {
+			e.report.add(IssueApproximated, q.NameId, "", "%s question exported as a choice interaction", q.QTyp)
+		}
+		if q.Required {
+			interaction.MinChoices = 1
+		}
+		for _, o := range v.Options {
+			interaction.Choices = append(interaction.Choices, qtiSimpleChoice{Identifier: o.NameId, Text: o.Label})
+		}
+		if len(v.Defaults) > 0 {
+			e.report.add(IssueDropped, q.NameId, "", "default options are not supported")
+		}
+		item.Body.Choice = interaction
+		e.cardinalities[q.NameId] = response.Cardinality
+	case *choice.Slider:
+		response.BaseType = "integer"
+		item.Body.Slider = &qtiSliderInteraction{ResponseIdentifier: qtiResponse, LowerBound: v.Min, UpperBound: v.Max, Step: v.Step, Prompt: q.Label}
+		if v.Unit != "" || v.Default != 0 {
+			e.report.add(IssueDropped, q.NameId, "", "slider unit and default are not supported")
+		}
+	case *text.FreeText:
+		response.BaseType = "string"
+		interaction := &qtiExtendedInteraction{ResponseIdentifier: qtiResponse, Prompt: q.Label}
+		if q.QTyp == types.QTypeInputText {
+			interaction.ExpectedLines = 1
+		}
+		if q.Required {
+			interaction.MinStrings = 1
+		}
+		if v.Min != nil && *v.Min > 0 || v.Max != nil {
+			e.report.add(IssueDropped, q.NameId, "", "text length limits are not supported")
+		}
+		item.Body.Input = interaction
+	case *text.InformationText:
+		item.Body.Text = v.Text
+	default:
+		e.report.add(IssueDropped, q.NameId, "", "%s questions are not supported", q.QTyp)
+		return false
+	}
+
+	if item.Body.Text == "" {
+		item.Responses = []qtiResponseDeclaration{response}
+		e.scoring(q, item)
+	}
+	if _, ok := q.Metadata[descriptionKey]; ok {
+		e.report.add(IssueDropped, q.NameId, "", "the question description is not supported")
+	}
+	if q.AnswerExpr != "" {
+		e.report.add(IssueDropped, q.NameId, "", "answerExpr is not supported")
+	}
+	e.items = append(e.items, item)
+	return true
+}
+
+// scoring converts the ScoringMetadataKey metadata of a question into the correct response and the score
+// of its item.
+func (e *qtiExporter) scoring(q *question.Question, item *qtiAssessmentItem) {
+	scoring, ok := q.Metadata[ScoringMetadataKey].(map[string]any)
+	if !ok {
+		return
+	}
+
+	var options map[string]bool
+	if c, err := choice.CastToChoice(q.Value); err == nil {
+		options = map[string]bool{}
+		for _, o := range c.Options {
+			options[o.NameId] = true
+		}
+	}
+	var correct []string
+	switch values := scoring["correct"].(type) {
+	case []string:
+		correct = values
+	case []any:
+		for _, v := range values {
+			correct = append(correct, value(v))
+		}
+	case string:
+		correct = []string{values}
+	}
+	var valid []string
+	for _, v := range correct {
+		if options != nil && !options[v] {
+			e.report.add(IssueDropped, q.NameId, "", "correct answer '%s' is not an option", v)
+			continue
+		}
+		valid = append(valid, v)
+	}
+	if len(valid) == 0 {
+		return
+	}
+	item.Responses[0].Correct = &qtiValues{Values: valid}
+
+	points := 1.0
+	if p, ok := scoring["points"]; ok {
+		if f, err := strconv.ParseFloat(value(p), 64); err == nil {
+			points = f
+		}
+	}
+	item.Outcomes = []qtiOutcomeDeclaration{{Identifier: qtiScore, Cardinality: "single", BaseType: "float", Default: &qtiValues{Values: []string{"0"}}}}
+	if points == 1 {
+		item.Processing = &qtiResponseProcessing{Template: qtiMatchCorrect}
+		return
+	}
+	cond := &qtiResponseCondition{}
+	cond.Match.Variable.Identifier = qtiResponse
+	cond.Match.Correct.Identifier = qtiResponse
+	cond.Set.Identifier = qtiScore
+	cond.Set.Value.BaseType = "float"
+	cond.Set.Value.Value = value(points)
+	item.Processing = &qtiResponseProcessing{Condition: cond}
+}
+
+// preConditions converts a DependsOn into a preCondition.
+func (e *qtiExporter) preConditions(dependsOn [][]question.DependsOn) []*qtiPreCondition {
+	var ors []*qtiExpression
+	for _, and := range dependsOn {
+		var ands []*qtiExpression
+		for _, d := range and {
+			ands = append(ands, e.selected(d.QuestionNameId, d.OptionNameId))
+		}
+		if expr := qtiOperator("and", ands); expr != nil {
+			ors = append(ors, expr)
+		}
+	}
+	if expr := qtiOperator("or", ors); expr != nil {
+		return []*qtiPreCondition{{Expression: expr}}
+	}
+	return nil
+}
+
+// selected returns the expression testing that a choice question has an option selected: match for single
+// cardinality responses, member for multiple cardinality ones.
+func (e *qtiExporter) selected(questionNameId, optionNameId string) *qtiExpression {
+	variable := &qtiExpression{XMLName: xml.Name{Local: "variable"}, Identifier: questionNameId + "." + qtiResponse}
+	baseValue := &qtiExpression{XMLName: xml.Name{Local: "baseValue"}, BaseType: "identifier", Value: optionNameId}
+	if e.cardinalities[questionNameId] == "multiple" {
+		return &qtiExpression{XMLName: xml.Name{Local: "member"}, Operands: []*qtiExpression{baseValue, variable}}
+	}
+	return &qtiExpression{XMLName: xml.Name{Local: "match"}, Operands: []*qtiExpression{variable, baseValue}}
+}
+
+// qtiOperator returns the operator applied to the operands, the operand itself if there is only one.
+func qtiOperator(op string, operands []*qtiExpression) *qtiExpression {
+	switch len(operands) {
+	case 0:
+		return nil
+	case 1:
+		return operands[0]
+	}
+	return &qtiExpression{XMLName: xml.Name{Local: op}, Operands: operands}
+}
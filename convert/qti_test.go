@@ -0,0 +1,129 @@
+package convert
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types"
+)
+
+const qtiSurvey = `{
+  "nameId": "geo-quiz",
+  "title": "Geography quiz",
+  "version": "1",
+  "questions": {
+    "capital": {"nameId": "capital", "visible": true, "type": "radio", "label": "Capital of France?", "required": true,
+      "metadata": {"scoring": {"correct": ["capital-paris"], "points": 2}},
+      "value": {"options": [
+        {"nameId": "capital-paris", "label": "Paris", "groupsIds": ["bonus"]},
+        {"nameId": "capital-lyon", "label": "Lyon"}
+      ]}},
+    "rivers": {"nameId": "rivers", "visible": true, "type": "checkbox", "label": "French rivers",
+      "metadata": {"scoring": {"correct": ["rivers-loire", "rivers-seine", "rivers-nile"]}},
+      "value": {"options": [
+        {"nameId": "rivers-loire", "label": "Loire"},
+        {"nameId": "rivers-seine", "label": "Seine"},
+        {"nameId": "rivers-danube", "label": "Danube"}
+      ]}},
+    "why": {"nameId": "why", "visible": true, "type": "text_area", "label": "Why?",
+      "dependsOn": [[{"questionNameId": "rivers", "optionNameId": "rivers-danube"}]],
+      "value": {}},
+    "mail": {"nameId": "mail", "visible": true, "type": "email", "label": "Email", "value": {}},
+    "height": {"nameId": "height", "visible": true, "type": "slider", "label": "Eiffel tower height",
+      "value": {"min": 100, "max": 500, "step": 10}},
+    "thanks": {"nameId": "thanks", "visible": true, "type": "information", "label": "Thanks", "value": {"text": "Well done!"}}
+  },
+  "groups": {
+    "main": {"nameId": "main", "title": "Main", "questionsIds": ["capital", "rivers", "why", "mail"]},
+    "bonus": {"nameId": "bonus", "title": "Bonus", "questionsIds": ["height", "thanks"]}
+  },
+  "groupsOrder": ["main"]
+}`
+
+func TestToQTI(t *testing.T) {
+	s, err := surveygo.ParseFromBytes([]byte(qtiSurvey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, report, err := ToQTI(s)
+	if err != nil {
+		t.Fatalf("ToQTI: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	var names []string
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(data)
+		names = append(names, f.Name)
+	}
+	want := []string{"imsmanifest.xml", "test.xml", "items/capital.xml", "items/height.xml", "items/thanks.xml", "items/rivers.xml", "items/why.xml"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v", names)
+	}
+	for _, want := range []string{
+		`<simpleChoice identifier="capital-paris">Paris</simpleChoice>`,
+		`<choiceInteraction responseIdentifier="RESPONSE" shuffle="false" maxChoices="1" minChoices="1">`,
+		`<setOutcomeValue identifier="SCORE">`,
+	} {
+		if !strings.Contains(files["items/capital.xml"], want) {
+			t.Errorf("items/capital.xml does not contain %s:\n%s", want, files["items/capital.xml"])
+		}
+	}
+	if !strings.Contains(files["items/rivers.xml"], qtiMatchCorrect) {
+		t.Errorf("items/rivers.xml:\n%s", files["items/rivers.xml"])
+	}
+	if !strings.Contains(files["test.xml"], `<assessmentSection identifier="bonus" title="Bonus" visible="true">`) {
+		t.Errorf("test.xml:\n%s", files["test.xml"])
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	if want := []string{"dropped rivers", "dropped mail"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+
+	// the package imports back into an equivalent survey
+	back, report, err := FromQTI(b)
+	if err != nil {
+		t.Fatalf("FromQTI: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("import report:\n%s", report)
+	}
+	if back.NameId != "geo-quiz" || back.Title != "Geography quiz" || len(back.Questions) != 5 {
+		t.Errorf("imported survey %s %s %v", back.NameId, back.Title, back.Questions)
+	}
+	if q := back.Questions["rivers"]; q.QTyp != types.QTypeCheckbox {
+		t.Errorf("rivers type = %s", q.QTyp)
+	}
+	if q := back.Questions["capital"]; !q.Required || !reflect.DeepEqual(q.Metadata[ScoringMetadataKey], map[string]any{"correct": []any{"capital-paris"}, "points": 2.0}) {
+		t.Errorf("capital = %v %v", q.Required, q.Metadata)
+	}
+	if deps := dependsOnString(back.Groups["bonus"].DependsOn); deps != "capital-paris" {
+		t.Errorf("bonus dependsOn = %s", deps)
+	}
+	if deps := dependsOnString(back.Questions["why"].DependsOn); deps != "rivers-danube" {
+		t.Errorf("why dependsOn = %s", deps)
+	}
+	if q := back.Questions["thanks"]; q.QTyp != types.QTypeInformation {
+		t.Errorf("thanks type = %s", q.QTyp)
+	}
+}
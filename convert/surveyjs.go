@@ -0,0 +1,685 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// surveyJSSurveyLogic are the survey properties holding logic that has no surveygo equivalent.
+var surveyJSSurveyLogic = []string{"triggers", "calculatedValues", "completedHtmlOnCondition", "navigateToUrlOnCondition"}
+
+// surveyJSElementLogic are the element properties holding logic that has no surveygo equivalent.
+var surveyJSElementLogic = []string{
+	"enableIf", "requiredIf", "choicesVisibleIf", "choicesEnableIf", "defaultValueExpression", "setValueIf",
+	"setValueExpression", "resetValueIf", "validators", "correctAnswer", "choicesByUrl", "choicesFromQuestion",
+	"minSelectedChoices", "maxSelectedChoices", "minPanelCount", "maxPanelCount",
+}
+
+// surveyJSConverter converts a SurveyJS definition.
+type surveyJSConverter struct {
+	*builder
+
+	// locale is the locale of the texts taken from localized strings, "default" if empty.
+	locale string
+
+	// translated is true if the definition has localized strings in other locales.
+	translated bool
+}
+
+// FromSurveyJS converts a SurveyJS survey JSON definition into a survey.
+// Pages and panels become groups, dynamic panels repeatable groups, elements questions and choices options
+// (with the choice value as option value). Matrices become a group with one radio question per row and
+// multiple texts a group with one text question per item.
+// visibleIf expressions comparing choice answers with =, contains, anyof, allof and notempty, combined
+// with and / or, become DependsOn. Other expressions, unsupported elements and logic properties
+// (triggers, validators, enableIf, ...) are not converted and are listed in the report.
+// Args:
+//   - b: the SurveyJS JSON definition
+//   - options: the import options (optional)
+//
+// Returns:
+//   - *surveygo.Survey: the converted survey, validated
+//   - *Report: the constructs that could not be converted as is
+//   - error: if the definition is not valid JSON or the converted survey is not valid
+func FromSurveyJS(b []byte, options ...*ImportOptions) (*surveygo.Survey, *Report, error) {
+	var def map[string]any
+	if err := json.Unmarshal(b, &def); err != nil {
+		return nil, nil, fmt.Errorf("unmarshalling SurveyJS survey: %w", err)
+	}
+
+	o := importOptions(options)
+	c := &surveyJSConverter{locale: o.Locale}
+	if c.locale == "" {
+		c.locale = str(def, "locale")
+	}
+
+	title := c.text(def["title"])
+	nameId := o.NameId
+	if nameId == "" {
+		nameId = strings.ToLower(title)
+	}
+	c.builder = newBuilder(nameId, title, o.Version)
+	if d := c.text(def["description"]); d != "" {
+		c.survey.Description = &d
+	}
+
+	for _, key := range surveyJSSurveyLogic {
+		if _, ok := def[key]; ok {
+			c.report.add(IssueDropped, key, "", "survey %s are not supported", key)
+		}
+	}
+
+	pages := list(def, "pages")
+	if len(pages) == 0 {
+		// single page surveys may list their elements at the root
+		pages = []any{def}
+	}
+	for i, p := range pages {
+		c.page(fmt.Sprintf("pages[%d]", i), asMap(p))
+	}
+
+	c.resolveConditions(c.parseCondition)
+	if c.translated {
+		locale := c.locale
+		if locale == "" {
+			locale = "default"
+		}
+		c.report.add(IssueApproximated, "survey", "", "only the '%s' locale of the localized strings is converted", locale)
+	}
+	return c.build()
+}
+
+func (c *surveyJSConverter) page(path string, m map[string]any) {
+	name := str(m, "name")
+	src := sourceName(name, path)
+	g := c.group(nil, name, "page", c.text(m["title"]))
+	c.container(src, g, m)
+	c.elements(path, g, append(list(m, "elements"), list(m, "questions")...))
+}
+
+// container converts the properties shared by pages and panels.
+func (c *surveyJSConverter) container(src string, g *question.Group, m map[string]any) {
+	if d := c.text(m["description"]); d != "" {
+		g.Description = &d
+	}
+	visibleIf := str(m, "visibleIf")
+	if v, ok := m["visible"].(bool); ok && !v && visibleIf == "" {
+		g.Hidden = true
+	}
+	c.onCondition(src, g.NameId, visibleIf, &g.DependsOn)
+	if boolean(m, "readOnly") {
+		c.report.add(IssueDropped, src, g.NameId, "read-only containers are not supported")
+	}
+	c.reportLogic(src, g.NameId, m)
+}
+
+func (c *surveyJSConverter) elements(path string, g *question.Group, elements []any) {
+	for i, e := range elements {
+		c.element(fmt.Sprintf("%s.elements[%d]", path, i), g, asMap(e))
+	}
+}
+
+func (c *surveyJSConverter) element(path string, g *question.Group, m map[string]any) {
+	name := str(m, "name")
+	src := sourceName(name, path)
+	label := c.text(m["title"])
+	if label == "" {
+		label = name
+	}
+
+	switch typ := str(m, "type"); typ {
+	case "panel":
+		sub := c.group(g, name, "panel", c.text(m["title"]))
+		c.container(src, sub, m)
+		c.elements(path, sub, list(m, "elements"))
+	case "paneldynamic":
+		sub := c.group(g, name, "panel", c.text(m["title"]))
+		sub.AllowRepeat = true
+		c.container(src, sub, m)
+		c.elements(path+".templateElements", sub, list(m, "templateElements"))
+	case "matrix":
+		c.matrix(src, g, name, label, m)
+	case "multipletext":
+		c.multipleText(src, g, name, label, m)
+	default:
+		if q := c.convertQuestion(src, name, label, typ, m); q != nil {
+			c.addQuestion(g, q)
+		}
+	}
+}
+
+// convertQuestion converts a question element, nil if the element type is not supported.
+func (c *surveyJSConverter) convertQuestion(src, name, label, typ string, m map[string]any) *question.Question {
+	var q *question.Question
+	switch typ {
+	case "radiogroup", "checkbox", "dropdown", "tagbox", "imagepicker":
+		q = c.choiceQuestion(src, name, label, typ, m)
+	case "boolean":
+		q = c.question(name, types.QTypeToggle, label, nil)
+		q.Value = c.choice(name, q, []option{
+			{value: valueOr(m["valueTrue"], "true"), label: textOr(c.text(m["labelTrue"]), "Yes")},
+			{value: valueOr(m["valueFalse"], "false"), label: textOr(c.text(m["labelFalse"]), "No")},
+		})
+		c.defaults(src, name, q, m)
+	case "rating":
+		q = c.rating(src, name, label, m)
+	case "text":
+		q = c.textQuestion(src, name, label, m)
+	case "comment":
+		q = c.question(name, types.QTypeTextArea, label, freeText(m))
+	case "html":
+		q = c.question(name, types.QTypeInformation, label, &text.InformationText{Text: textOr(c.text(m["html"]), label)})
+	case "image":
+		q = c.question(name, types.QTypeInformation, label, &text.InformationText{Text: textOr(str(m, "imageLink"), label)})
+		c.report.add(IssueApproximated, src, q.NameId, "image element converted to an information text with the image link")
+	case "file":
+		q = c.file(src, name, label, m)
+	default:
+		c.report.add(IssueDropped, src, "", "%s element '%s' is not supported", typ, name)
+		return nil
+	}
+
+	q.Required = boolean(m, "isRequired")
+	q.Disabled = boolean(m, "readOnly")
+	visibleIf := str(m, "visibleIf")
+	if v, ok := m["visible"].(bool); ok && !v && visibleIf == "" {
+		q.Visible = false
+	}
+	c.onCondition(src, q.NameId, visibleIf, &q.DependsOn)
+	if d := c.text(m["description"]); d != "" {
+		q.Metadata = map[string]any{descriptionKey: d}
+	}
+	if p := c.text(m["placeholder"]); p != "" {
+		questionBase(q.Value).Placeholder = &p
+	}
+	c.reportLogic(src, q.NameId, m)
+	return q
+}
+
+func (c *surveyJSConverter) choiceQuestion(src, name, label, typ string, m map[string]any) *question.Question {
+	multiple := typ == "checkbox" || typ == "tagbox" || typ == "imagepicker" && boolean(m, "multiSelect")
+	qt := map[string]types.QuestionType{"radiogroup": types.QTypeRadio, "checkbox": types.QTypeCheckbox, "dropdown": types.QTypeSingleSelect, "tagbox": types.QTypeMultipleSelect}[typ]
+	if typ == "imagepicker" {
+		qt = types.QTypeRadio
+		if multiple {
+			qt = types.QTypeCheckbox
+		}
+	}
+	q := c.question(name, qt, label, nil)
+
+	var options []option
+	for _, item := range list(m, "choices") {
+		if im, ok := item.(map[string]any); ok {
+			options = append(options, option{value: value(im["value"]), label: c.text(im["text"])})
+			if str(im, "visibleIf") != "" || str(im, "enableIf") != "" {
+				c.report.add(IssueDropped, src, q.NameId, "conditions of choice '%s' are not supported", value(im["value"]))
+			}
+			continue
+		}
+		options = append(options, option{value: value(item)})
+	}
+	if len(options) == 0 {
+		if maxValue, ok := number(m, "choicesMax"); ok {
+			minValue, _ := number(m, "choicesMin")
+			step, ok := number(m, "choicesStep")
+			if !ok || step <= 0 {
+				step = 1
+			}
+			for v := minValue; v <= maxValue; v += step {
+				options = append(options, option{value: value(v)})
+			}
+		}
+	}
+	if boolean(m, "hasNone") || boolean(m, "showNoneItem") {
+		options = append(options, option{value: "none", label: textOr(c.text(m["noneText"]), "None")})
+	}
+	if boolean(m, "hasOther") || boolean(m, "showOtherItem") {
+		options = append(options, option{value: "other", label: textOr(c.text(m["otherText"]), "Other (describe)")})
+		c.report.add(IssueApproximated, src, q.NameId, "the comment of the other choice is not converted")
+	}
+	if boolean(m, "hasSelectAll") || boolean(m, "showSelectAllItem") {
+		c.report.add(IssueDropped, src, q.NameId, "the select all choice is not supported")
+	}
+	if boolean(m, "hasComment") || boolean(m, "showCommentArea") {
+		c.report.add(IssueDropped, src, q.NameId, "the comment area is not supported")
+	}
+	if typ == "imagepicker" {
+		c.report.add(IssueApproximated, src, q.NameId, "image picker converted to a %s question without images", qt)
+	}
+	if len(options) == 0 {
+		c.report.add(IssueApproximated, src, q.NameId, "%s element without choices converted to a free text question", typ)
+		q.QTyp, q.Value = types.QTypeInputText, &text.FreeText{}
+		return q
+	}
+
+	q.Value = c.choice(name, q, options)
+	c.defaults(src, name, q, m)
+	return q
+}
+
+// defaults converts the default value of a choice question into default options.
+func (c *surveyJSConverter) defaults(src, name string, q *question.Question, m map[string]any) {
+	v, ok := m["defaultValue"]
+	if !ok {
+		return
+	}
+	var values []string
+	if l, ok := v.([]any); ok {
+		for _, item := range l {
+			values = append(values, value(item))
+		}
+	} else {
+		values = []string{value(v)}
+	}
+	questionBase(q.Value).Defaults = c.optionNameIds(src, c.choices[name], values)
+}
+
+// rating converts a rating into a slider, or into a radio question if it has explicit values
+// or a scale starting or ending at 0 (sliders require a non-zero minimum and maximum).
+func (c *surveyJSConverter) rating(src, name, label string, m map[string]any) *question.Question {
+	minValue, ok := number(m, "rateMin")
+	if !ok {
+		minValue = 1
+	}
+	maxValue, ok := number(m, "rateMax")
+	if !ok {
+		maxValue = 5
+	}
+	step, ok := number(m, "rateStep")
+	if !ok || step <= 0 {
+		step = 1
+	}
+
+	rateValues := list(m, "rateValues")
+	integral := minValue == math.Trunc(minValue) && maxValue == math.Trunc(maxValue) && step == math.Trunc(step)
+	if len(rateValues) == 0 && minValue != 0 && maxValue != 0 && integral {
+		slider := &choice.Slider{Min: int(minValue), Max: int(maxValue), Step: int(step)}
+		if d, ok := number(m, "defaultValue"); ok {
+			slider.Default = int(d)
+		}
+		return c.question(name, types.QTypeSlider, label, slider)
+	}
+
+	var options []option
+	for _, item := range rateValues {
+		if im, ok := item.(map[string]any); ok {
+			options = append(options, option{value: value(im["value"]), label: c.text(im["text"])})
+			continue
+		}
+		options = append(options, option{value: value(item)})
+	}
+	generated := len(options) == 0
+	for v := minValue; generated && v <= maxValue; v += step {
+		options = append(options, option{value: value(v)})
+	}
+	q := c.question(name, types.QTypeRadio, label, nil)
+	q.Value = c.choice(name, q, options)
+	if generated {
+		c.report.add(IssueApproximated, src, q.NameId, "rating from %s to %s converted to a radio question", value(minValue), value(maxValue))
+	}
+	c.defaults(src, name, q, m)
+	return q
+}
+
+func (c *surveyJSConverter) textQuestion(src, name, label string, m map[string]any) *question.Question {
+	inputType := str(m, "inputType")
+	switch inputType {
+	case "email":
+		return c.question(name, types.QTypeEmail, label, &text.Email{})
+	case "tel":
+		return c.question(name, types.QTypeTelephone, label, &text.Telephone{})
+	case "date":
+		return c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "2006-01-02", Type: text.DateTypeFormatDate})
+	case "datetime", "datetime-local":
+		return c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "2006-01-02T15:04", Type: text.DateTypeFormatDateTime})
+	case "time":
+		return c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "15:04", Type: text.DateTypeFormatTime})
+	case "range":
+		minValue, _ := number(m, "min")
+		maxValue, _ := number(m, "max")
+		step, ok := number(m, "step")
+		if !ok || step <= 0 {
+			step = 1
+		}
+		if minValue != 0 && maxValue != 0 {
+			return c.question(name, types.QTypeSlider, label, &choice.Slider{Min: int(minValue), Max: int(maxValue), Step: int(step)})
+		}
+	}
+
+	q := c.question(name, types.QTypeInputText, label, freeText(m))
+	switch inputType {
+	case "", "text", "url", "search":
+	default:
+		c.report.add(IssueApproximated, src, q.NameId, "%s input converted to a free text question", inputType)
+	}
+	return q
+}
+
+func (c *surveyJSConverter) file(src, name, label string, m map[string]any) *question.Question {
+	doc := &asset.DocumentAsset{}
+	for _, t := range strings.Split(str(m, "acceptedTypes"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			doc.AllowedContentTypes = append(doc.AllowedContentTypes, t)
+		}
+	}
+	if size, ok := number(m, "maxSize"); ok && size > 0 {
+		maxSize := int64(size)
+		doc.MaxSize = &maxSize
+	}
+	q := c.question(name, types.QTypeDocument, label, doc)
+	if boolean(m, "allowMultiple") {
+		c.report.add(IssueApproximated, src, q.NameId, "multiple files are allowed without limit, MaxFiles is left to its default")
+	}
+	return q
+}
+
+// matrix converts a single choice matrix into a group with one radio question per row.
+// Rows are referenced in conditions as {matrix.row}, like SurveyJS does.
+func (c *surveyJSConverter) matrix(src string, g *question.Group, name, label string, m map[string]any) {
+	sub := c.group(g, name, "matrix", label)
+	c.container(src, sub, m)
+
+	var columns []option
+	for _, col := range list(m, "columns") {
+		if cm, ok := col.(map[string]any); ok {
+			columns = append(columns, option{value: value(cm["value"]), label: c.text(cm["text"])})
+			continue
+		}
+		columns = append(columns, option{value: value(col)})
+	}
+
+	for _, row := range list(m, "rows") {
+		rowValue, rowLabel := value(row), ""
+		if rm, ok := row.(map[string]any); ok {
+			rowValue, rowLabel = value(rm["value"]), c.text(rm["text"])
+		}
+		q := c.question(name+"-"+rowValue, types.QTypeRadio, textOr(rowLabel, rowValue), nil)
+		q.Value = c.choice(name+"."+rowValue, q, columns)
+		q.Required = boolean(m, "isAllRowRequired") || boolean(m, "isRequired")
+		c.addQuestion(sub, q)
+	}
+	c.report.add(IssueApproximated, src, sub.NameId, "matrix converted to a group with one radio question per row")
+}
+
+// multipleText converts a multiple text into a group with one text question per item.
+func (c *surveyJSConverter) multipleText(src string, g *question.Group, name, label string, m map[string]any) {
+	sub := c.group(g, name, "texts", label)
+	c.container(src, sub, m)
+	for _, item := range list(m, "items") {
+		im := asMap(item)
+		itemName := str(im, "name")
+		q := c.question(name+"-"+itemName, types.QTypeInputText, textOr(c.text(im["title"]), itemName), &text.FreeText{})
+		q.Required = boolean(im, "isRequired") || boolean(m, "isRequired")
+		c.addQuestion(sub, q)
+	}
+	c.report.add(IssueApproximated, src, sub.NameId, "multiple text converted to a group with one text question per item")
+}
+
+// reportLogic reports the logic properties of an element.
+func (c *surveyJSConverter) reportLogic(src, nameId string, m map[string]any) {
+	for _, key := range surveyJSElementLogic {
+		if v, ok := m[key]; ok && v != nil && v != "" {
+			c.report.add(IssueDropped, src, nameId, "%s is not supported", key)
+		}
+	}
+}
+
+// text returns the text of a string or of a localized string in the converter locale.
+func (c *surveyJSConverter) text(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case map[string]any:
+		if len(t) > 1 {
+			c.translated = true
+		}
+		for _, locale := range []string{c.locale, "default", "en"} {
+			if s, ok := t[locale].(string); ok && locale != "" {
+				return s
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := t[k].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// parseCondition parses a SurveyJS expression into a condition.
+// Grammar: or := and ('or' and)*, and := term ('and' term)*, term := '(' or ')' | comparison.
+func (c *surveyJSConverter) parseCondition(expr string) (condition, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	s := &tokenStream{tokens: tokens}
+	res, err := c.parseOr(s)
+	if err != nil {
+		return nil, err
+	}
+	if t := s.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected '%s'", t.text)
+	}
+	return res, nil
+}
+
+func (c *surveyJSConverter) parseOr(s *tokenStream) (condition, error) {
+	res, err := c.parseAnd(s)
+	for err == nil && s.accept("or", "||") {
+		var right condition
+		if right, err = c.parseAnd(s); err == nil {
+			res = res.or(right)
+		}
+	}
+	return res, err
+}
+
+func (c *surveyJSConverter) parseAnd(s *tokenStream) (condition, error) {
+	res, err := c.parseTerm(s)
+	for err == nil && s.accept("and", "&&") {
+		var right condition
+		if right, err = c.parseTerm(s); err == nil {
+			res = res.and(right)
+		}
+	}
+	return res, err
+}
+
+func (c *surveyJSConverter) parseTerm(s *tokenStream) (condition, error) {
+	if s.accept("(") {
+		res, err := c.parseOr(s)
+		if err != nil {
+			return nil, err
+		}
+		return res, s.expect(")")
+	}
+
+	// literal = {question} is accepted as {question} = literal
+	if lit, ok := s.literal(); ok {
+		if !s.accept("=", "==", "equal") {
+			return nil, fmt.Errorf("unsupported comparison of '%s'", lit)
+		}
+		t := s.next()
+		if t.kind != tokenVar {
+			return nil, fmt.Errorf("expected a question reference, got '%s'", t.text)
+		}
+		return selected(surveyJSVar(t.text), lit), nil
+	}
+
+	t := s.next()
+	if t.kind != tokenVar {
+		return nil, fmt.Errorf("unsupported expression '%s'", t.text)
+	}
+	name := surveyJSVar(t.text)
+
+	op := s.next()
+	if op.kind != tokenSymbol && op.kind != tokenWord {
+		return nil, fmt.Errorf("expected an operator after '{%s}'", t.text)
+	}
+	switch op.text {
+	case "notempty", "isnotempty":
+		ref, ok := c.choices[name]
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a choice question", name)
+		}
+		var res condition
+		for _, v := range ref.values {
+			res = res.or(selected(name, v))
+		}
+		return res, nil
+	case "=", "==", "equal", "contains", "anyof", "allof":
+	default:
+		return nil, fmt.Errorf("operator '%s' is not supported", op.text)
+	}
+
+	values, err := surveyJSValues(s)
+	if err != nil {
+		return nil, err
+	}
+	var res condition
+	for i, v := range values {
+		switch {
+		case i == 0:
+			res = selected(name, v)
+		case op.text == "anyof":
+			res = res.or(selected(name, v))
+		default:
+			res = res.and(selected(name, v))
+		}
+	}
+	return res, nil
+}
+
+// surveyJSValues parses a literal or an array of literals.
+func surveyJSValues(s *tokenStream) ([]string, error) {
+	if !s.accept("[") {
+		lit, ok := s.literal()
+		if !ok {
+			return nil, fmt.Errorf("expected a value, got '%s'", s.peek().text)
+		}
+		return []string{lit}, nil
+	}
+	var values []string
+	for !s.accept("]") {
+		lit, ok := s.literal()
+		if !ok {
+			return nil, fmt.Errorf("expected a value, got '%s'", s.peek().text)
+		}
+		values = append(values, lit)
+		if !s.accept(",") && s.peek().text != "]" {
+			return nil, fmt.Errorf("expected ',' or ']', got '%s'", s.peek().text)
+		}
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("empty array")
+	}
+	return values, nil
+}
+
+// surveyJSVar returns the question name of a reference, without the dynamic panel prefix.
+func surveyJSVar(v string) string {
+	return strings.TrimPrefix(v, "panel.")
+}
+
+// ------------ JSON helpers ------------ //
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func str(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func list(m map[string]any, key string) []any {
+	l, _ := m[key].([]any)
+	return l
+}
+
+func boolean(m map[string]any, key string) bool {
+	b, _ := m[key].(bool)
+	return b
+}
+
+func number(m map[string]any, key string) (float64, bool) {
+	switch n := m[key].(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// freeText returns a free text value limited to maxLength, if positive.
+func freeText(m map[string]any) *text.FreeText {
+	return limitedText(number(m, "maxLength"))
+}
+
+// limitedText returns a free text value limited to n characters, if set and positive.
+// Min is set along with Max, as the validation of Max requires it.
+func limitedText(n float64, ok bool) *text.FreeText {
+	if !ok || n <= 0 {
+		return &text.FreeText{}
+	}
+	minLength, maxLength := 0, int(n)
+	return &text.FreeText{Min: &minLength, Max: &maxLength}
+}
+
+// value returns the string representation of a JSON value, as referenced by conditions.
+func value(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	return fmt.Sprint(v)
+}
+
+func valueOr(v any, fallback string) string {
+	if s := value(v); s != "" {
+		return s
+	}
+	return fallback
+}
+
+func textOr(s, fallback string) string {
+	if strings.TrimSpace(s) != "" {
+		return s
+	}
+	return fallback
+}
+
+// sourceName returns the name of a source element, its path if it has no name.
+func sourceName(name, path string) string {
+	if name != "" {
+		return name
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+package convert
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+func TestFromSurveyJS(t *testing.T) {
+	b, err := os.ReadFile("testdata/surveyjs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, report, err := FromSurveyJS(b)
+	if err != nil {
+		t.Fatalf("FromSurveyJS: %v", err)
+	}
+
+	if s.NameId != "customer-feedback" || s.Title != "Customer feedback" || s.Version != "1" {
+		t.Errorf("unexpected survey %s %q %s", s.NameId, s.Title, s.Version)
+	}
+	if !reflect.DeepEqual(s.GroupsOrder, []string{"visit", "contact"}) {
+		t.Errorf("groups order = %v", s.GroupsOrder)
+	}
+
+	// questions following a subgroup go to continuation groups, keeping the source order
+	contact := s.Groups["contact"]
+	if !reflect.DeepEqual(contact.QuestionsIds, []string{"email", "visit-date"}) ||
+		!reflect.DeepEqual(contact.GroupsOrder, []string{"kids", "contact-cont", "quality", "contact-cont-2"}) {
+		t.Errorf("contact group = %v %v", contact.QuestionsIds, contact.GroupsOrder)
+	}
+	if !s.Groups["kids"].AllowRepeat {
+		t.Error("dynamic panel should be a repeatable group")
+	}
+	if got := s.Groups["quality"].QuestionsIds; !reflect.DeepEqual(got, []string{"quality-staff", "quality-food"}) {
+		t.Errorf("matrix rows = %v", got)
+	}
+
+	wantTypes := map[string]types.QuestionType{
+		"visited": types.QTypeToggle, "store": types.QTypeRadio, "bought": types.QTypeCheckbox, "gift-note": types.QTypeTextArea,
+		"nps": types.QTypeRadio, "stars": types.QTypeSlider, "age": types.QTypeInputText, "email": types.QTypeEmail,
+		"visit-date": types.QTypeDateTime, "kid-menu": types.QTypeSingleSelect, "thanks": types.QTypeInformation,
+		"receipt": types.QTypeDocument,
+	}
+	for nameId, want := range wantTypes {
+		if q, ok := s.Questions[nameId]; !ok || q.QTyp != want {
+			t.Errorf("question %s: want type %s, got %v", nameId, want, q)
+		}
+	}
+	if _, ok := s.Questions["costs"]; ok {
+		t.Error("unsupported matrixdropdown should not be converted")
+	}
+
+	store := s.Questions["store"].Value.(*choice.Choice)
+	var labels []string
+	for _, o := range store.Options {
+		labels = append(labels, o.NameId+"="+o.Label)
+	}
+	if want := []string{"store-north=North", "store-south=south", "store-3=3", "store-other=Other (describe)"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("store options = %v", labels)
+	}
+	if d := s.Questions["bought"].Value.(*choice.Choice).Defaults; !reflect.DeepEqual(d, []string{"bought-food"}) {
+		t.Errorf("bought defaults = %v", d)
+	}
+	if ft := s.Questions["gift-note"].Value.(*text.FreeText); ft.Max == nil || *ft.Max != 200 {
+		t.Errorf("gift-note max = %v", ft.Max)
+	}
+	if sl := s.Questions["stars"].Value.(*choice.Slider); sl.Min != 1 || sl.Max != 5 || sl.Step != 1 {
+		t.Errorf("stars slider = %+v", sl)
+	}
+
+	wantDeps := map[string][][]question.DependsOn{
+		"store": {{{QuestionNameId: "visited", OptionNameId: "visited-true"}}},
+		"gift-note": {
+			{{QuestionNameId: "bought", OptionNameId: "bought-gifts"}, {QuestionNameId: "store", OptionNameId: "store-north"}},
+			{{QuestionNameId: "bought", OptionNameId: "bought-gifts"}, {QuestionNameId: "store", OptionNameId: "store-south"}},
+		},
+		"kid-size": {{{QuestionNameId: "kid-menu", OptionNameId: "kid-menu-large"}}},
+	}
+	for nameId, want := range wantDeps {
+		if got := s.Questions[nameId].DependsOn; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s dependsOn = %v, want %v", nameId, got, want)
+		}
+	}
+	if got := s.Groups["contact"].DependsOn; len(got) != 3 || got[2][0].OptionNameId != "nps-2" {
+		t.Errorf("contact dependsOn = %v", got)
+	}
+	if s.Questions["adult-only"].DependsOn != nil {
+		t.Error("unsupported condition should be dropped")
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	want := []string{
+		"dropped triggers",
+		"approximated store",
+		"approximated nps",
+		"approximated age",
+		"dropped age",
+		"approximated quality",
+		"dropped costs",
+		"dropped adult-only",
+		"approximated survey",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+	if report.Lossless() || len(report.Dropped()) != 4 || len(report.Approximated()) != 5 {
+		t.Errorf("unexpected report counts: %d dropped, %d approximated", len(report.Dropped()), len(report.Approximated()))
+	}
+}
+
+func TestFromSurveyJS_Options(t *testing.T) {
+	def := `{"title": {"default": "Hello", "es": "Hola"}, "locale": "en",
+		"elements": [{"type": "radiogroup", "name": "1st", "title": {"default": "First", "es": "Primero"}, "choices": ["a", "b"]}]}`
+
+	s, _, err := FromSurveyJS([]byte(def), &ImportOptions{NameId: "greeting", Version: "2.0", Locale: "es"})
+	if err != nil {
+		t.Fatalf("FromSurveyJS: %v", err)
+	}
+	if s.NameId != "greeting" || s.Version != "2.0" || s.Title != "Hola" {
+		t.Errorf("unexpected survey %s %s %s", s.NameId, s.Version, s.Title)
+	}
+	// invalid name ids are fixed: must start with a letter and have at least 3 characters
+	q, ok := s.Questions["question-1st"]
+	if !ok || q.Label != "Primero" {
+		t.Fatalf("questions = %v", s.Questions)
+	}
+	if o := q.Value.(*choice.Choice).Options; o[0].NameId != "question-1st-a" {
+		t.Errorf("option name id = %s", o[0].NameId)
+	}
+}
+
+func TestFromSurveyJS_Conditions(t *testing.T) {
+	c := &surveyJSConverter{builder: newBuilder("conditions", "", "")}
+	q := c.question("color", types.QTypeCheckbox, "Color", nil)
+	q.Value = c.choice("color", q, []option{{value: "red"}, {value: "blue"}, {value: "green"}})
+
+	tests := []struct {
+		expr string
+		want string
+		err  string
+	}{
+		{expr: "{color} = 'red'", want: "red"},
+		{expr: "'red' == {color}", want: "red"},
+		{expr: "{color} anyof ['red', 'blue']", want: "red | blue"},
+		{expr: "{color} allof ['red', 'blue']", want: "red & blue"},
+		{expr: "{color} notempty", want: "red | blue | green"},
+		{expr: "({color} = 'red' or {color} = 'blue') and {color} contains 'green'", want: "red & green | blue & green"},
+		{expr: "{color} != 'red'", err: "operator '!=' is not supported"},
+		{expr: "age({color}) > 1", err: "unsupported expression 'age'"},
+		{expr: "{color} = 'pink'", err: "'pink' is not an option of 'color'"},
+		{expr: "{size} = 'xl'", err: "'size' is not a choice question"},
+	}
+	for _, tt := range tests {
+		cond, err := c.parseCondition(tt.expr)
+		var deps [][]question.DependsOn
+		if err == nil {
+			deps, err = c.dependsOn(cond)
+		}
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: want error %q, got %v", tt.expr, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		var ors []string
+		for _, and := range deps {
+			var ands []string
+			for _, d := range and {
+				ands = append(ands, strings.TrimPrefix(d.OptionNameId, "color-"))
+			}
+			ors = append(ors, strings.Join(ands, " & "))
+		}
+		if got := strings.Join(ors, " | "); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// typeformDefaultThankYouScreen is the ref of the thank you screen Typeform adds to every form.
+const typeformDefaultThankYouScreen = "default_tys"
+
+// typeformConverter converts a Typeform form.
+type typeformConverter struct {
+	*builder
+
+	// loose is the group of the top-level questions, nil after a group field.
+	loose *question.Group
+
+	// steps are the steps of the top-level fields, in form order.
+	steps []*step
+
+	// stepOf are the indexes of the steps of the fields, the step of their group for nested fields.
+	// Key: field ref.
+	stepOf map[string]int
+
+	// nested are the refs of the fields inside a group.
+	nested map[string]bool
+
+	// single are the refs of the single choice fields, the only ones jumps can depend on.
+	single map[string]bool
+
+	// choiceValues are the option values of the choices. Key: field ref, then choice ref.
+	choiceValues map[string]map[string]string
+}
+
+// FromTypeform converts a Typeform form (the JSON of the Create API form definition, read from a local
+// file) into a survey.
+// Top-level questions are gathered in untitled groups and group fields become groups. Multiple choice,
+// picture choice and dropdown fields become radio, checkbox and single select questions, yes/no and legal
+// fields toggles, opinion scales and ratings sliders, NPS and scales starting at 0 radio questions, dates
+// date_time questions, file uploads document questions and statements and welcome screens information texts.
+// Logic jumps depending on the answer of single choice fields become the DependsOn of the fields they lead
+// to or skip. Other logic actions, hidden fields, variables and thank you screens are reported.
+// Args:
+//   - b: the form JSON
+//   - options: optional import options
+//
+// Returns:
+//   - *surveygo.Survey: the converted survey, validated
+//   - *Report: the constructs of the form that could not be converted as is
+//   - error: if the JSON is invalid or the converted survey is not valid
+func FromTypeform(b []byte, options ...*ImportOptions) (*surveygo.Survey, *Report, error) {
+	var form map[string]any
+	if err := json.Unmarshal(b, &form); err != nil {
+		return nil, nil, fmt.Errorf("unmarshalling Typeform form: %w", err)
+	}
+
+	o := importOptions(options)
+	title := str(form, "title")
+	nameId := o.NameId
+	if nameId == "" {
+		nameId = title
+	}
+	c := &typeformConverter{
+		builder:      newBuilder(nameId, title, o.Version),
+		stepOf:       map[string]int{},
+		nested:       map[string]bool{},
+		single:       map[string]bool{},
+		choiceValues: map[string]map[string]string{},
+	}
+
+	for _, screen := range list(form, "welcome_screens") {
+		sm := asMap(screen)
+		screenTitle := str(sm, "title")
+		info := &text.InformationText{Text: textOr(str(asMap(sm["properties"]), "description"), screenTitle)}
+		c.addQuestion(c.current(), c.question(textOr(screenTitle, "welcome"), types.QTypeInformation, screenTitle, info))
+	}
+	for i, field := range list(form, "fields") {
+		c.field(fmt.Sprintf("fields[%d]", i), nil, asMap(field))
+	}
+	for i, screen := range list(form, "thankyou_screens") {
+		if sm := asMap(screen); str(sm, "ref") != typeformDefaultThankYouScreen {
+			c.report.add(IssueDropped, fmt.Sprintf("thankyou_screens[%d]", i), "", "thank you screen '%s' is not supported", str(sm, "title"))
+		}
+	}
+	if len(list(form, "hidden")) > 0 {
+		c.report.add(IssueDropped, "hidden", "", "hidden fields are not supported")
+	}
+	if len(asMap(form["variables"])) > 0 {
+		c.report.add(IssueDropped, "variables", "", "variables are not supported")
+	}
+
+	for i, logic := range list(form, "logic") {
+		c.logic(fmt.Sprintf("logic[%d]", i), asMap(logic))
+	}
+	c.resolveJumps(c.steps)
+	return c.build()
+}
+
+// current returns the group of the top-level questions.
+func (c *typeformConverter) current() *question.Group {
+	if c.loose == nil {
+		c.loose = c.group(nil, "", "questions", "")
+	}
+	return c.loose
+}
+
+func (c *typeformConverter) addStep(src, ref, nameId string, target *[][]question.DependsOn) {
+	c.stepOf[ref] = len(c.steps)
+	c.steps = append(c.steps, &step{source: src, nameId: nameId, target: target, next: len(c.steps) + 1})
+}
+
+// field converts a field, added to parent or, for top-level fields, to the survey.
+func (c *typeformConverter) field(path string, parent *question.Group, m map[string]any) {
+	typ, ref, title := str(m, "type"), str(m, "ref"), str(m, "title")
+	props := asMap(m["properties"])
+	src := sourceName(ref, path)
+
+	if parent != nil {
+		c.stepOf[ref] = len(c.steps) - 1
+		c.nested[ref] = true
+	}
+
+	if fields := list(props, "fields"); len(fields) > 0 {
+		if parent == nil {
+			c.loose = nil
+		}
+		g := c.group(parent, title, "group", title)
+		if d := str(props, "description"); d != "" {
+			g.Description = &d
+		}
+		if parent == nil {
+			c.addStep(src, ref, g.NameId, &g.DependsOn)
+		}
+		for i, f := range fields {
+			c.field(fmt.Sprintf("%s.properties.fields[%d]", path, i), g, asMap(f))
+		}
+		if typ != "group" && typ != "inline_group" {
+			c.report.add(IssueApproximated, src, g.NameId, "%s field converted to a group of its fields", typ)
+		}
+		return
+	}
+
+	q := c.convertQuestion(src, ref, typ, title, m)
+	if q == nil {
+		return
+	}
+	if parent == nil {
+		c.addQuestion(c.current(), q)
+		c.addStep(src, ref, q.NameId, &q.DependsOn)
+	} else {
+		c.addQuestion(parent, q)
+	}
+}
+
+// convertQuestion converts a question field, nil if its type is not supported.
+func (c *typeformConverter) convertQuestion(src, ref, typ, title string, m map[string]any) *question.Question {
+	props := asMap(m["properties"])
+	validations := asMap(m["validations"])
+
+	var q *question.Question
+	switch typ {
+	case "multiple_choice", "picture_choice", "dropdown":
+		q = c.choiceQuestion(src, ref, typ, title, props)
+	case "yes_no", "legal":
+		yes, no := "Yes", "No"
+		if typ == "legal" {
+			yes, no = "I accept", "I don't accept"
+		}
+		q = c.question(title, types.QTypeToggle, title, nil)
+		q.Value = c.choice(ref, q, []option{{value: "true", label: yes}, {value: "false", label: no}})
+		c.single[ref] = true
+	case "short_text":
+		q = c.question(title, types.QTypeInputText, title, limitedText(number(validations, "max_length")))
+	case "long_text":
+		q = c.question(title, types.QTypeTextArea, title, limitedText(number(validations, "max_length")))
+	case "email":
+		q = c.question(title, types.QTypeEmail, title, &text.Email{})
+	case "phone_number":
+		q = c.question(title, types.QTypeTelephone, title, &text.Telephone{})
+	case "number", "website":
+		q = c.question(title, types.QTypeInputText, title, &text.FreeText{})
+		c.report.add(IssueApproximated, src, q.NameId, "%s field converted to a free text question", typ)
+	case "date":
+		q = c.question(title, types.QTypeDateTime, title, &text.DateTime{Format: "2006-01-02", Type: text.DateTypeFormatDate})
+	case "file_upload":
+		q = c.question(title, types.QTypeDocument, title, &asset.DocumentAsset{})
+	case "opinion_scale":
+		steps, ok := number(props, "steps")
+		if !ok || steps < 2 {
+			steps = 11
+		}
+		low := 0
+		if boolean(props, "start_at_one") {
+			low = 1
+		}
+		q = c.scale(src, ref, title, title, low, low+int(steps)-1)
+		c.single[ref] = q.QTyp == types.QTypeRadio
+		if len(asMap(props["labels"])) > 0 {
+			c.report.add(IssueDropped, src, q.NameId, "the labels of the scale are not supported")
+		}
+	case "nps":
+		q = c.scale(src, ref, title, title, 0, 10)
+		c.single[ref] = true
+	case "rating":
+		steps, ok := number(props, "steps")
+		if !ok || steps < 1 {
+			steps = 5
+		}
+		q = c.question(title, types.QTypeSlider, title, &choice.Slider{Min: 1, Max: int(steps), Step: 1})
+	case "statement":
+		q = c.question(title, types.QTypeInformation, title, &text.InformationText{Text: textOr(str(props, "description"), title)})
+	default:
+		c.report.add(IssueDropped, src, "", "%s field '%s' is not supported", typ, title)
+		return nil
+	}
+
+	q.Required = boolean(validations, "required")
+	if d := str(props, "description"); d != "" && typ != "statement" {
+		q.Metadata = map[string]any{descriptionKey: d}
+	}
+	if m["attachment"] != nil {
+		c.report.add(IssueDropped, src, q.NameId, "the attachment of the field is not supported")
+	}
+	return q
+}
+
+func (c *typeformConverter) choiceQuestion(src, ref, typ, title string, props map[string]any) *question.Question {
+	multiple := typ != "dropdown" && boolean(props, "allow_multiple_selection")
+	qt := types.QuestionType(types.QTypeRadio)
+	switch {
+	case typ == "dropdown":
+		qt = types.QTypeSingleSelect
+	case multiple:
+		qt = types.QTypeCheckbox
+	}
+	q := c.question(title, qt, title, nil)
+
+	values := map[string]string{}
+	var options []option
+	for _, item := range list(props, "choices") {
+		cm := asMap(item)
+		label := str(cm, "label")
+		values[str(cm, "ref")] = label
+		options = append(options, option{value: label})
+	}
+	if boolean(props, "allow_other_choice") {
+		options = append(options, option{value: "other", label: "Other"})
+		c.report.add(IssueApproximated, src, q.NameId, "the text of the other choice is not converted")
+	}
+	if len(options) == 0 {
+		c.report.add(IssueApproximated, src, q.NameId, "%s field without choices converted to a free text question", typ)
+		q.QTyp, q.Value = types.QTypeInputText, &text.FreeText{}
+		return q
+	}
+
+	q.Value = c.choice(ref, q, options)
+	c.choiceValues[ref] = values
+	c.single[ref] = !multiple
+	if boolean(props, "randomize") {
+		c.report.add(IssueDropped, src, q.NameId, "randomizing the choices is not supported")
+	}
+	if typ == "picture_choice" {
+		c.report.add(IssueApproximated, src, q.NameId, "picture choice converted to a %s question without pictures", qt)
+	}
+	return q
+}
+
+// logic converts the jumps of the logic of a field into the jumps of its step.
+func (c *typeformConverter) logic(src string, m map[string]any) {
+	ref := str(m, "ref")
+	if typ := str(m, "type"); typ != "field" {
+		c.report.add(IssueDropped, src, "", "%s logic is not supported", typ)
+		return
+	}
+	index, ok := c.stepOf[ref]
+	if !ok {
+		c.report.add(IssueDropped, src, "", "logic of unknown field '%s'", ref)
+		return
+	}
+	st := c.steps[index]
+
+	type jump struct {
+		condition map[string]any
+		to        int
+	}
+	var jumps []jump
+	unconditional := true
+	for _, action := range list(m, "actions") {
+		am := asMap(action)
+		if name := str(am, "action"); name != "jump" {
+			c.report.add(IssueDropped, src, st.nameId, "%s action is not supported", name)
+			continue
+		}
+		to, ok := c.jumpTarget(asMap(asMap(asMap(am["details"])["to"])))
+		switch {
+		case !ok:
+			c.report.add(IssueDropped, src, st.nameId, "jump to an unknown field")
+			continue
+		case to <= index:
+			c.report.add(IssueApproximated, src, st.nameId, "jump back to a previous field is not supported, it continues with the next field")
+			continue
+		}
+		cond := asMap(am["condition"])
+		unconditional = unconditional && str(cond, "op") == "always"
+		jumps = append(jumps, jump{condition: cond, to: to})
+	}
+	if len(jumps) == 0 {
+		return
+	}
+	if c.nested[ref] {
+		c.report.add(IssueApproximated, src, st.nameId, "jumps of a field inside a group apply after the whole group")
+	}
+	if unconditional {
+		st.next = jumps[0].to
+		return
+	}
+
+	switch {
+	case !c.single[ref]:
+		c.report.add(IssueDropped, src, st.nameId, "jumps depending on the answer of '%s' are not supported, only single choice fields are", ref)
+		return
+	case st.question != "" && st.question != ref:
+		c.report.add(IssueDropped, src, st.nameId, "jumps depending on more than one field of a group are not supported")
+		return
+	}
+	targets := map[string]int{}
+	for _, v := range c.choices[ref].values {
+		for _, j := range jumps {
+			matched, err := c.matches(j.condition, ref, v)
+			if err != nil {
+				c.report.add(IssueDropped, src, st.nameId, "jump condition cannot be converted (%s)", err)
+				return
+			}
+			if matched {
+				targets[v] = j.to
+				break
+			}
+		}
+	}
+	st.question, st.jumps = ref, targets
+}
+
+// jumpTarget returns the index of the step a jump leads to, the number of steps for the end of the form.
+func (c *typeformConverter) jumpTarget(to map[string]any) (int, bool) {
+	switch str(to, "type") {
+	case "field":
+		index, ok := c.stepOf[value(to["value"])]
+		return index, ok
+	case "thankyou", "outcome":
+		return len(c.steps), true
+	}
+	return 0, false
+}
+
+// matches evaluates a logic condition of a field for one of its option values.
+func (c *typeformConverter) matches(cond map[string]any, ref, v string) (bool, error) {
+	op := str(cond, "op")
+	switch op {
+	case "always":
+		return true, nil
+	case "and", "or":
+		for _, item := range list(cond, "vars") {
+			matched, err := c.matches(asMap(item), ref, v)
+			if err != nil {
+				return false, err
+			}
+			if matched == (op == "or") {
+				return matched, nil
+			}
+		}
+		return op == "and", nil
+	case "is", "equal", "is_not", "not_equal", "lower_than", "lower_equal_than", "greater_than", "greater_equal_than":
+		var expected string
+		for _, item := range list(cond, "vars") {
+			vm := asMap(item)
+			switch typ := str(vm, "type"); typ {
+			case "field":
+				if field := value(vm["value"]); field != ref {
+					return false, fmt.Errorf("condition on another field '%s'", field)
+				}
+			case "choice":
+				label, ok := c.choiceValues[ref][value(vm["value"])]
+				if !ok {
+					return false, fmt.Errorf("unknown choice '%s'", value(vm["value"]))
+				}
+				expected = label
+			case "constant":
+				expected = value(vm["value"])
+			default:
+				return false, fmt.Errorf("%s variables are not supported", typ)
+			}
+		}
+		switch op {
+		case "is", "equal":
+			return v == expected, nil
+		case "is_not", "not_equal":
+			return v != expected, nil
+		}
+		x, errX := strconv.ParseFloat(v, 64)
+		y, errY := strconv.ParseFloat(expected, 64)
+		if errX != nil || errY != nil {
+			return false, fmt.Errorf("operator '%s' on a non-numeric value", op)
+		}
+		return op == "lower_than" && x < y || op == "lower_equal_than" && x <= y ||
+			op == "greater_than" && x > y || op == "greater_equal_than" && x >= y, nil
+	}
+	return false, fmt.Errorf("operator '%s' is not supported", op)
+}
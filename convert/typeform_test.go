@@ -0,0 +1,140 @@
+package convert
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+func TestFromTypeform(t *testing.T) {
+	b, err := os.ReadFile("testdata/typeform.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, report, err := FromTypeform(b, &ImportOptions{NameId: "pets", Version: "2"})
+	if err != nil {
+		t.Fatalf("FromTypeform: %v", err)
+	}
+
+	if s.NameId != "pets" || s.Title != "Pet survey" || s.Version != "2" {
+		t.Errorf("unexpected survey %s %q %s", s.NameId, s.Title, s.Version)
+	}
+	if want := []string{"questions", "About-your-dog", "questions-2"}; !reflect.DeepEqual(s.GroupsOrder, want) {
+		t.Errorf("groups order = %v", s.GroupsOrder)
+	}
+	if got := s.Groups["questions"].QuestionsIds; !reflect.DeepEqual(got, []string{"question-Hi", "Do-you-have-a-pet", "Which-pet"}) {
+		t.Errorf("questions group = %v", got)
+	}
+
+	wantTypes := map[string]types.QuestionType{
+		"question-Hi": types.QTypeInformation, "Do-you-have-a-pet": types.QTypeToggle, "Which-pet": types.QTypeRadio,
+		"Dog-name": types.QTypeInputText, "Walks-per-day": types.QTypeSlider, "Cat-name": types.QTypeInputText,
+		"Why-no-pet": types.QTypeTextArea, "Recommend-us": types.QTypeRadio, "We-are-sorry": types.QTypeInformation,
+		"Birthday": types.QTypeDateTime,
+	}
+	for nameId, want := range wantTypes {
+		if q, ok := s.Questions[nameId]; !ok || q.QTyp != want {
+			t.Errorf("question %s: want type %s, got %v", nameId, want, q)
+		}
+	}
+	if len(s.Questions) != len(wantTypes) {
+		t.Errorf("unsupported payment field should not be converted: %d questions", len(s.Questions))
+	}
+	if ft := s.Questions["Dog-name"].Value.(*text.FreeText); ft.Max == nil || *ft.Max != 30 {
+		t.Errorf("dog name max = %v", ft.Max)
+	}
+	if sl := s.Questions["Walks-per-day"].Value.(*choice.Slider); sl.Min != 1 || sl.Max != 5 {
+		t.Errorf("walks slider = %+v", sl)
+	}
+	if n := len(s.Questions["Recommend-us"].Value.(*choice.Choice).Options); n != 11 {
+		t.Errorf("nps options = %d", n)
+	}
+
+	// jumps: "no" skips to why_not, cats skip the dog group, both pets join at nps and nps >= 7 skips the apology
+	wantDeps := map[string]string{
+		"Do-you-have-a-pet": "",
+		"Which-pet":         "Do-you-have-a-pet-true",
+		"Cat-name":          "Do-you-have-a-pet-true & Which-pet-Cat",
+		"Why-no-pet":        "Do-you-have-a-pet-false",
+		"Recommend-us":      "",
+		"We-are-sorry":      "Recommend-us-0 | Recommend-us-1 | Recommend-us-2 | Recommend-us-3 | Recommend-us-4 | Recommend-us-5 | Recommend-us-6",
+		"Birthday":          "",
+	}
+	for nameId, want := range wantDeps {
+		if got := dependsOnString(s.Questions[nameId].DependsOn); got != want {
+			t.Errorf("%s dependsOn = %s, want %s", nameId, got, want)
+		}
+	}
+	if got, want := dependsOnString(s.Groups["About-your-dog"].DependsOn), "Do-you-have-a-pet-true & Which-pet-Dog | Do-you-have-a-pet-true & Which-pet-other"; got != want {
+		t.Errorf("dog group dependsOn = %s, want %s", got, want)
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	want := []string{
+		"approximated pet_kind",
+		"dropped pet_kind",
+		"approximated nps",
+		"dropped pay",
+		"dropped thankyou_screens[0]",
+		"dropped variables",
+		"dropped logic[1]",
+		"approximated has_pet",
+		"approximated pet_kind",
+		"approximated nps",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+}
+
+func TestFromTypeform_Logic(t *testing.T) {
+	form := `{"title": "Logic", "fields": [
+		{"ref": "color", "title": "Color", "type": "multiple_choice", "properties": {"allow_multiple_selection": true,
+			"choices": [{"ref": "red", "label": "Red"}, {"ref": "blue", "label": "Blue"}]}},
+		{"ref": "size", "title": "Size", "type": "dropdown", "properties": {"choices": [{"ref": "s", "label": "S"}, {"ref": "l", "label": "L"}]}},
+		{"ref": "name", "title": "Name", "type": "short_text"},
+		{"ref": "end", "title": "End", "type": "statement"}
+	], "logic": [
+		{"type": "field", "ref": "color", "actions": [{"action": "jump", "details": {"to": {"type": "field", "value": "end"}},
+			"condition": {"op": "is", "vars": [{"type": "field", "value": "color"}, {"type": "choice", "value": "red"}]}}]},
+		{"type": "field", "ref": "size", "actions": [{"action": "jump", "details": {"to": {"type": "thankyou", "value": "default_tys"}},
+			"condition": {"op": "and", "vars": [
+				{"op": "is", "vars": [{"type": "field", "value": "size"}, {"type": "choice", "value": "l"}]},
+				{"op": "is", "vars": [{"type": "field", "value": "color"}, {"type": "choice", "value": "red"}]}
+			]}}]},
+		{"type": "field", "ref": "name", "actions": [{"action": "jump", "details": {"to": {"type": "field", "value": "color"}},
+			"condition": {"op": "always", "vars": []}}]}
+	]}`
+
+	s, report, err := FromTypeform([]byte(form))
+	if err != nil {
+		t.Fatalf("FromTypeform: %v", err)
+	}
+	for _, q := range s.Questions {
+		if q.DependsOn != nil {
+			t.Errorf("%s dependsOn = %s", q.NameId, dependsOnString(q.DependsOn))
+		}
+	}
+
+	wantMessages := []string{
+		"jumps depending on the answer of 'color' are not supported, only single choice fields are",
+		"jump condition cannot be converted (condition on another field 'color')",
+		"jump back to a previous field is not supported, it continues with the next field",
+	}
+	if len(report.Issues) != len(wantMessages) {
+		t.Fatalf("report:\n%s", report)
+	}
+	for i, want := range wantMessages {
+		if report.Issues[i].Message != want {
+			t.Errorf("issue %d = %s, want %s", i, report.Issues[i].Message, want)
+		}
+	}
+}
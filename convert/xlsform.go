@@ -0,0 +1,676 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsformSurveySheet   = "survey"
+	xlsformChoicesSheet  = "choices"
+	xlsformSettingsSheet = "settings"
+
+	// xlsformLabelsKey is the metadata key of the localized labels of questions, groups and options.
+	// Value: map of XLSForm language (e.g. "English (en)") to label.
+	xlsformLabelsKey = "labels"
+)
+
+// xlsformUnsupportedTypes are the XLSForm question types without surveygo equivalent.
+var xlsformUnsupportedTypes = map[string]bool{
+	"calculate": true, "hidden": true, "geopoint": true, "geotrace": true, "geoshape": true, "barcode": true,
+	"rank": true, "select_one_from_file": true, "select_multiple_from_file": true, "background-audio": true,
+	"xml-external": true, "csv-external": true, "start": true, "end": true, "today": true, "deviceid": true,
+	"subscriberid": true, "simserial": true, "phonenumber": true, "username": true, "email": true, "audit": true,
+	"start-geopoint": true,
+}
+
+// xlsformStringLength matches a string-length constraint clause, e.g. "string-length(.) <= 10".
+var xlsformStringLength = regexp.MustCompile(`^string-length\(\s*\.\s*\)\s*(<=|<|>=|>|=)\s*(\d+)$`)
+
+var xlsformAnd = regexp.MustCompile(`(?i)\s+and\s+`)
+
+// xlsformRow is a row of a sheet, keyed by column: lower-cased header, with the language of
+// translated columns kept as is (e.g. "label::English (en)").
+type xlsformRow map[string]string
+
+// xlsformChoice is a row of the choices sheet.
+type xlsformChoice struct {
+	name string
+	row  xlsformRow
+}
+
+// xlsformConverter converts an XLSForm workbook.
+type xlsformConverter struct {
+	*builder
+
+	// lang is the language of the labels, "" for the columns without language.
+	lang string
+
+	// langs are the languages of the translated label columns.
+	langs []string
+
+	// lists are the choice lists of the choices sheet. Key: list name.
+	lists map[string][]xlsformChoice
+
+	// stack are the open groups, loose the group of the questions outside any group.
+	stack []*question.Group
+	loose *question.Group
+}
+
+// FromXLSForm converts an XLSForm (ODK) workbook into a survey.
+// The survey sheet becomes questions, begin group / begin repeat rows groups (repeatable for repeats),
+// select_one and select_multiple radio and checkbox questions (single and multi select with the minimal
+// appearance) with the options of the choices sheet, and the settings sheet the survey name id, title and
+// version. relevant expressions testing choice answers (selected(), = and != "" with and / or) become DependsOn,
+// string-length constraints text length limits and hints question descriptions. The labels of the
+// label::language columns are kept in the "labels" metadata of questions, groups and options.
+// Questions outside any group are gathered in untitled groups.
+// Args:
+//   - r: the XLSForm workbook
+//   - options: the import options (optional)
+//
+// Returns:
+//   - *surveygo.Survey: the converted survey, validated
+//   - *Report: the constructs that could not be converted as is
+//   - error: if the workbook cannot be read, has no survey sheet, has unbalanced groups or the converted survey is not valid
+func FromXLSForm(r io.Reader, options ...*ImportOptions) (*surveygo.Survey, *Report, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening XLSForm workbook: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	surveyRows, err := readXLSFormSheet(f, xlsformSurveySheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if surveyRows == nil {
+		return nil, nil, fmt.Errorf("XLSForm workbook has no %s sheet", xlsformSurveySheet)
+	}
+	choiceRows, err := readXLSFormSheet(f, xlsformChoicesSheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	settingsRows, err := readXLSFormSheet(f, xlsformSettingsSheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	settings := xlsformRow{}
+	for _, row := range settingsRows {
+		if len(row) > 0 {
+			settings = row
+			break
+		}
+	}
+
+	o := importOptions(options)
+	c := &xlsformConverter{lists: map[string][]xlsformChoice{}}
+	c.langs = xlsformLanguages(surveyRows, choiceRows)
+	c.lang = c.language(o.Locale, settings["default_language"], surveyRows)
+
+	nameId, version := o.NameId, o.Version
+	if nameId == "" {
+		nameId = settings["form_id"]
+	}
+	if version == "" {
+		version = settings["version"]
+	}
+	c.builder = newBuilder(nameId, settings["form_title"], version)
+
+	for i, row := range choiceRows {
+		list := row["list_name"]
+		if list == "" {
+			list = row["list name"]
+		}
+		if list == "" || row["name"] == "" {
+			continue
+		}
+		c.lists[list] = append(c.lists[list], xlsformChoice{name: row["name"], row: row})
+		for _, col := range []string{"image", "media::image", "audio", "media::audio", "video", "media::video"} {
+			if row[col] != "" {
+				c.report.add(IssueDropped, fmt.Sprintf("choices row %d", i+2), "", "media of choice '%s' are not supported", row["name"])
+			}
+		}
+	}
+
+	for i, row := range surveyRows {
+		if err = c.row(fmt.Sprintf("survey row %d", i+2), row); err != nil {
+			return nil, nil, err
+		}
+	}
+	if len(c.stack) > 0 {
+		return nil, nil, fmt.Errorf("XLSForm group '%s' is not closed", c.stack[len(c.stack)-1].NameId)
+	}
+
+	c.resolveConditions(c.parseCondition)
+	return c.build()
+}
+
+// readXLSFormSheet reads the rows of a sheet after the header row, nil if the workbook has no such sheet.
+// Empty rows are kept empty, so that the row n of the sheet is at index n-2.
+func readXLSFormSheet(f *excelize.File, name string) ([]xlsformRow, error) {
+	sheet := ""
+	for _, s := range f.GetSheetList() {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			sheet = s
+			break
+		}
+	}
+	if sheet == "" {
+		return nil, nil
+	}
+
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("reading XLSForm %s sheet: %w", name, err)
+	}
+	if len(rows) == 0 {
+		return []xlsformRow{}, nil
+	}
+
+	headers := make([]string, len(rows[0]))
+	for i, h := range rows[0] {
+		col, lang, ok := strings.Cut(strings.TrimSpace(h), "::")
+		headers[i] = strings.ToLower(strings.TrimSpace(col))
+		if ok {
+			headers[i] += "::" + strings.TrimSpace(lang)
+		}
+	}
+
+	res := []xlsformRow{}
+	for _, cells := range rows[1:] {
+		row := xlsformRow{}
+		for i, v := range cells {
+			if v = strings.TrimSpace(v); v != "" && i < len(headers) && headers[i] != "" {
+				row[headers[i]] = v
+			}
+		}
+		res = append(res, row)
+	}
+	return res, nil
+}
+
+// xlsformLanguages returns the languages of the translated label columns, sorted.
+func xlsformLanguages(sheets ...[]xlsformRow) []string {
+	seen := map[string]bool{}
+	for _, rows := range sheets {
+		for _, row := range rows {
+			for col := range row {
+				if lang, ok := strings.CutPrefix(col, "label::"); ok {
+					seen[lang] = true
+				}
+			}
+		}
+	}
+	langs := make([]string, 0, len(seen))
+	for lang := range seen {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// language returns the language of the labels: the requested locale, the default language of the
+// settings, the columns without language if used, or the first language.
+func (c *xlsformConverter) language(locale, defaultLanguage string, rows []xlsformRow) string {
+	for _, want := range []string{locale, defaultLanguage} {
+		if want == "" {
+			continue
+		}
+		for _, lang := range c.langs {
+			if lang == want || strings.HasSuffix(lang, "("+want+")") {
+				return lang
+			}
+		}
+	}
+	for _, row := range rows {
+		if row["label"] != "" {
+			return ""
+		}
+	}
+	if len(c.langs) > 0 {
+		return c.langs[0]
+	}
+	return ""
+}
+
+// text returns a column of a row in the converter language, falling back to the column without language.
+func (c *xlsformConverter) text(row xlsformRow, col string) string {
+	if c.lang != "" {
+		if v := row[col+"::"+c.lang]; v != "" {
+			return v
+		}
+	}
+	return row[col]
+}
+
+// labels returns the translated labels of a row, nil if the workbook has no translations.
+func (c *xlsformConverter) labels(row xlsformRow) map[string]any {
+	var labels map[string]any
+	for _, lang := range c.langs {
+		if v := row["label::"+lang]; v != "" {
+			if labels == nil {
+				labels = map[string]any{}
+			}
+			labels[lang] = v
+		}
+	}
+	return labels
+}
+
+func (c *xlsformConverter) row(path string, row xlsformRow) error {
+	fields := strings.Fields(strings.ReplaceAll(strings.ToLower(row["type"]), "_", " "))
+	name := row["name"]
+	src := sourceName(name, path)
+
+	switch {
+	case len(fields) == 0:
+		return nil
+	case len(fields) == 2 && fields[0] == "begin" && (fields[1] == "group" || fields[1] == "repeat"):
+		c.beginGroup(src, name, fields[1] == "repeat", row)
+	case len(fields) == 2 && fields[0] == "end" && (fields[1] == "group" || fields[1] == "repeat"):
+		if len(c.stack) == 0 {
+			return fmt.Errorf("XLSForm %s: '%s' without begin", path, row["type"])
+		}
+		c.stack = c.stack[:len(c.stack)-1]
+	default:
+		if q := c.convertQuestion(src, name, row); q != nil {
+			c.addQuestion(c.current(), q)
+		}
+	}
+	return nil
+}
+
+// current returns the open group, or the group of the questions outside any group.
+func (c *xlsformConverter) current() *question.Group {
+	if len(c.stack) > 0 {
+		return c.stack[len(c.stack)-1]
+	}
+	if c.loose == nil {
+		c.loose = c.group(nil, "", "questions", "")
+	}
+	return c.loose
+}
+
+func (c *xlsformConverter) beginGroup(src, name string, repeat bool, row xlsformRow) {
+	var parent *question.Group
+	if len(c.stack) > 0 {
+		parent = c.stack[len(c.stack)-1]
+	} else {
+		c.loose = nil
+	}
+
+	g := c.group(parent, name, "group", c.text(row, "label"))
+	g.AllowRepeat = repeat
+	if hint := c.text(row, "hint"); hint != "" {
+		g.Description = &hint
+	}
+	if labels := c.labels(row); labels != nil {
+		g.Metadata = map[string]any{xlsformLabelsKey: labels}
+	}
+	if relevant := row["relevant"]; isXLSFormFalse(relevant) {
+		g.Hidden = true
+	} else {
+		c.onCondition(src, g.NameId, relevant, &g.DependsOn)
+	}
+	if row["repeat_count"] != "" {
+		c.report.add(IssueDropped, src, g.NameId, "repeat_count '%s' is not supported", row["repeat_count"])
+	}
+	c.stack = append(c.stack, g)
+}
+
+// convertQuestion converts a question row, nil if the question type is not supported.
+func (c *xlsformConverter) convertQuestion(src, name string, row xlsformRow) *question.Question {
+	fields := strings.Fields(row["type"])
+	base := strings.ToLower(fields[0])
+	label := c.text(row, "label")
+	appearance := strings.Fields(strings.ToLower(row["appearance"]))
+
+	var q *question.Question
+	switch {
+	case base == "select_one" || base == "select_multiple":
+		q = c.selectQuestion(src, name, label, base == "select_multiple", fields, appearance, row)
+	case base == "text":
+		qt := types.QuestionType(types.QTypeInputText)
+		if containsAny(appearance, "multiline") {
+			qt = types.QTypeTextArea
+		}
+		q = c.question(name, qt, label, c.textValue(src, name, row))
+	case base == "integer" || base == "decimal":
+		q = c.question(name, types.QTypeInputText, label, &text.FreeText{})
+		c.report.add(IssueApproximated, src, q.NameId, "%s question converted to a free text question", base)
+	case base == "range":
+		q = c.rangeQuestion(src, name, label, row)
+	case base == "note":
+		q = c.question(name, types.QTypeInformation, label, &text.InformationText{Text: textOr(label, name)})
+	case base == "date":
+		q = c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "2006-01-02", Type: text.DateTypeFormatDate})
+	case base == "time":
+		q = c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "15:04", Type: text.DateTypeFormatTime})
+	case base == "datetime":
+		q = c.question(name, types.QTypeDateTime, label, &text.DateTime{Format: "2006-01-02T15:04", Type: text.DateTypeFormatDateTime})
+	case base == "image":
+		q = c.question(name, types.QTypeImage, label, &asset.ImageAsset{})
+	case base == "audio":
+		q = c.question(name, types.QTypeAudio, label, &asset.AudioAsset{})
+	case base == "video":
+		q = c.question(name, types.QTypeVideo, label, &asset.VideoAsset{})
+	case base == "file":
+		q = c.question(name, types.QTypeDocument, label, &asset.DocumentAsset{})
+	case base == "acknowledge":
+		q = c.question(name, types.QTypeToggle, label, nil)
+		q.Value = c.choice(name, q, []option{{value: "OK", label: "OK"}})
+		c.report.add(IssueApproximated, src, q.NameId, "acknowledge question converted to a toggle with a single OK option")
+	default:
+		if xlsformUnsupportedTypes[base] {
+			c.report.add(IssueDropped, src, "", "%s question '%s' is not supported", base, name)
+		} else {
+			c.report.add(IssueDropped, src, "", "unknown question type '%s'", row["type"])
+		}
+		return nil
+	}
+
+	q.Required = isXLSFormTrue(row["required"])
+	q.Disabled = isXLSFormTrue(row["read_only"])
+	if relevant := row["relevant"]; isXLSFormFalse(relevant) {
+		q.Visible = false
+	} else {
+		c.onCondition(src, q.NameId, relevant, &q.DependsOn)
+	}
+	if hint := c.text(row, "hint"); hint != "" {
+		q.Metadata = map[string]any{descriptionKey: hint}
+	}
+	if labels := c.labels(row); labels != nil {
+		if q.Metadata == nil {
+			q.Metadata = map[string]any{}
+		}
+		q.Metadata[xlsformLabelsKey] = labels
+	}
+	for _, col := range []string{"calculation", "choice_filter", "trigger"} {
+		if row[col] != "" {
+			c.report.add(IssueDropped, src, q.NameId, "%s '%s' is not supported", col, row[col])
+		}
+	}
+	return q
+}
+
+func (c *xlsformConverter) selectQuestion(src, name, label string, multiple bool, fields, appearance []string, row xlsformRow) *question.Question {
+	qt := types.QuestionType(types.QTypeRadio)
+	switch {
+	case multiple && containsAny(appearance, "minimal"):
+		qt = types.QTypeMultipleSelect
+	case multiple:
+		qt = types.QTypeCheckbox
+	case containsAny(appearance, "minimal"):
+		qt = types.QTypeSingleSelect
+	}
+	q := c.question(name, qt, label, nil)
+
+	listName := ""
+	if len(fields) > 1 {
+		listName = fields[1]
+	}
+	list, ok := c.lists[listName]
+	if !ok {
+		c.report.add(IssueApproximated, src, q.NameId, "choice list '%s' not found, converted to a free text question", listName)
+		q.QTyp, q.Value = types.QTypeInputText, &text.FreeText{}
+		return q
+	}
+
+	options := make([]option, 0, len(list)+1)
+	for _, ch := range list {
+		options = append(options, option{value: ch.name, label: textOr(c.text(ch.row, "label"), ch.name)})
+	}
+	if len(fields) > 2 && strings.EqualFold(fields[2], "or_other") {
+		options = append(options, option{value: "other", label: "Other"})
+		c.report.add(IssueApproximated, src, q.NameId, "the specify other text of or_other is not converted")
+	}
+	ch := c.choice(name, q, options)
+	for i, o := range ch.Options {
+		if i < len(list) {
+			if labels := c.labels(list[i].row); labels != nil {
+				o.Metadata = map[string]any{xlsformLabelsKey: labels}
+			}
+		}
+	}
+	q.Value = ch
+
+	if d := row["default"]; d != "" {
+		values := []string{d}
+		if multiple {
+			values = strings.Fields(d)
+		}
+		ch.Defaults = c.optionNameIds(src, c.choices[name], values)
+	}
+	return q
+}
+
+// textValue returns the free text value of a text question, with the length limits of its constraint.
+func (c *xlsformConverter) textValue(src, name string, row xlsformRow) *text.FreeText {
+	ft := &text.FreeText{}
+	if d := row["default"]; d != "" {
+		ft.Defaults = []string{d}
+	}
+	constraint := row["constraint"]
+	if constraint == "" {
+		return ft
+	}
+
+	minLength, maxLength, ok := stringLengthBounds(constraint)
+	if !ok || maxLength == nil || minLength != nil && *minLength >= *maxLength {
+		c.report.add(IssueDropped, src, "", "constraint '%s' of '%s' is not supported", constraint, name)
+		return ft
+	}
+	if minLength == nil {
+		zero := 0
+		minLength = &zero
+	}
+	ft.Min, ft.Max = minLength, maxLength
+	return ft
+}
+
+// stringLengthBounds parses a constraint made of string-length clauses joined by and.
+func stringLengthBounds(constraint string) (minLength, maxLength *int, ok bool) {
+	for _, clause := range xlsformAnd.Split(strings.TrimSpace(constraint), -1) {
+		clause = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(clause), "("), ")"))
+		m := xlsformStringLength.FindStringSubmatch(clause)
+		if m == nil {
+			return nil, nil, false
+		}
+		n, _ := strconv.Atoi(m[2])
+		lower, upper := n, n
+		switch m[1] {
+		case "<":
+			upper = n - 1
+		case ">":
+			lower = n + 1
+		}
+		if m[1] != "<" && m[1] != "<=" {
+			minLength = &lower
+		}
+		if m[1] != ">" && m[1] != ">=" {
+			maxLength = &upper
+		}
+	}
+	return minLength, maxLength, true
+}
+
+// rangeQuestion converts a range into a slider, or into a radio question if it starts or ends at 0
+// (sliders require a non-zero minimum and maximum).
+func (c *xlsformConverter) rangeQuestion(src, name, label string, row xlsformRow) *question.Question {
+	params := map[string]float64{"start": 1, "end": 10, "step": 1}
+	for _, p := range strings.Fields(row["parameters"]) {
+		k, v, _ := strings.Cut(p, "=")
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			params[strings.ToLower(k)] = f
+		}
+	}
+	start, end, step := params["start"], params["end"], params["step"]
+	if step <= 0 {
+		step = 1
+	}
+
+	if start != 0 && end != 0 {
+		slider := &choice.Slider{Min: int(start), Max: int(end), Step: int(step)}
+		if d, err := strconv.Atoi(row["default"]); err == nil {
+			slider.Default = d
+		}
+		return c.question(name, types.QTypeSlider, label, slider)
+	}
+
+	var options []option
+	for v := start; v <= end; v += step {
+		options = append(options, option{value: value(v)})
+	}
+	q := c.question(name, types.QTypeRadio, label, nil)
+	q.Value = c.choice(name, q, options)
+	c.report.add(IssueApproximated, src, q.NameId, "range from %s to %s converted to a radio question", value(start), value(end))
+	return q
+}
+
+// parseCondition parses an XLSForm relevant expression into a condition.
+// Grammar: or := and ('or' and)*, and := term ('and' term)*,
+// term := '(' or ')' | selected(${q}, 'v') | ${q} = 'v' | 'v' = ${q} | ${q} != "".
+func (c *xlsformConverter) parseCondition(expr string) (condition, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	s := &tokenStream{tokens: tokens}
+	res, err := c.parseOr(s)
+	if err != nil {
+		return nil, err
+	}
+	if t := s.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected '%s'", t.text)
+	}
+	return res, nil
+}
+
+func (c *xlsformConverter) parseOr(s *tokenStream) (condition, error) {
+	res, err := c.parseAnd(s)
+	for err == nil && s.accept("or") {
+		var right condition
+		if right, err = c.parseAnd(s); err == nil {
+			res = res.or(right)
+		}
+	}
+	return res, err
+}
+
+func (c *xlsformConverter) parseAnd(s *tokenStream) (condition, error) {
+	res, err := c.parseTerm(s)
+	for err == nil && s.accept("and") {
+		var right condition
+		if right, err = c.parseTerm(s); err == nil {
+			res = res.and(right)
+		}
+	}
+	return res, err
+}
+
+func (c *xlsformConverter) parseTerm(s *tokenStream) (condition, error) {
+	if s.accept("(") {
+		res, err := c.parseOr(s)
+		if err != nil {
+			return nil, err
+		}
+		return res, s.expect(")")
+	}
+
+	if s.accept("selected") {
+		if err := s.expect("("); err != nil {
+			return nil, err
+		}
+		t := s.next()
+		if t.kind != tokenVar {
+			return nil, fmt.Errorf("expected a question reference, got '%s'", t.text)
+		}
+		if err := s.expect(","); err != nil {
+			return nil, err
+		}
+		lit, ok := s.literal()
+		if !ok {
+			return nil, fmt.Errorf("expected a value, got '%s'", s.peek().text)
+		}
+		return selected(t.text, lit), s.expect(")")
+	}
+
+	if lit, ok := s.literal(); ok {
+		if !s.accept("=") {
+			return nil, fmt.Errorf("unsupported comparison of '%s'", lit)
+		}
+		t := s.next()
+		if t.kind != tokenVar {
+			return nil, fmt.Errorf("expected a question reference, got '%s'", t.text)
+		}
+		return selected(t.text, lit), nil
+	}
+
+	t := s.next()
+	if t.kind != tokenVar {
+		return nil, fmt.Errorf("unsupported expression '%s'", t.text)
+	}
+	switch {
+	case s.accept("="):
+		lit, ok := s.literal()
+		if !ok {
+			return nil, fmt.Errorf("expected a value, got '%s'", s.peek().text)
+		}
+		return selected(t.text, lit), nil
+	case s.accept("!="):
+		if lit, ok := s.literal(); !ok || lit != "" {
+			break
+		}
+		// answered: any option selected
+		ref, ok := c.choices[t.text]
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a choice question", t.text)
+		}
+		var res condition
+		for _, v := range ref.values {
+			res = res.or(selected(t.text, v))
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("unsupported comparison of '${%s}'", t.text)
+}
+
+func isXLSFormTrue(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "yes", "true", "true()", "1":
+		return true
+	}
+	return false
+}
+
+func isXLSFormFalse(v string) bool {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "no", "false", "false()", "0":
+		return true
+	}
+	return false
+}
+
+func containsAny(list []string, values ...string) bool {
+	for _, v := range values {
+		for _, l := range list {
+			if l == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,477 @@
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsformName matches the names accepted by XLSForm for questions, groups and choices.
+var xlsformName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
+// xlsformExporter converts a survey into XLSForm sheets.
+type xlsformExporter struct {
+	survey *surveygo.Survey
+	report *Report
+
+	// langs are the languages of the "labels" metadata, sorted.
+	langs []string
+
+	rows     []xlsformRow
+	choices  []xlsformRow
+	names    map[string]bool
+	visited  map[string]bool
+	choiceOf map[string]string // key: option name id, value: choice name
+}
+
+// ToXLSForm converts a survey into an XLSForm (ODK) workbook with survey, choices and settings sheets.
+// Groups become begin group / begin repeat rows (untitled groups without conditions are flattened),
+// choice questions and toggles select_one and select_multiple rows with one choice list per question,
+// sliders ranges, text length limits string-length constraints and DependsOn, option groups, hidden
+// groups and invisible questions relevant expressions. The "labels" metadata written by FromXLSForm
+// becomes label::language columns. Choices are named after the option value when it is a valid
+// XLSForm name, after the option name id otherwise.
+// Args:
+//   - survey: the survey to convert
+//
+// Returns:
+//   - []byte: the XLSForm workbook
+//   - *Report: the elements of the survey that could not be converted as is
+//   - error: if the workbook cannot be written
+func ToXLSForm(survey *surveygo.Survey) ([]byte, *Report, error) {
+	e := &xlsformExporter{
+		survey:   survey,
+		report:   &Report{},
+		names:    map[string]bool{},
+		visited:  map[string]bool{},
+		choiceOf: map[string]string{},
+	}
+	e.langs = e.languages()
+
+	// questions are referenced by name in relevant expressions: they keep their name id
+	for nameId := range survey.Questions {
+		e.names[nameId] = true
+	}
+	for _, q := range survey.Questions {
+		if c, err := choice.CastToChoice(q.Value); err == nil {
+			e.nameChoices(c)
+		}
+	}
+	if survey.Description != nil && *survey.Description != "" {
+		e.report.add(IssueDropped, survey.NameId, "", "the survey description is not supported")
+	}
+
+	for _, nameId := range survey.GroupsOrder {
+		e.group(nameId, "")
+	}
+	return e.workbook()
+}
+
+// languages returns the languages of the "labels" metadata of questions, groups and options.
+func (e *xlsformExporter) languages() []string {
+	seen := map[string]bool{}
+	collect := func(metadata map[string]any) {
+		for lang := range metadataLabels(metadata) {
+			seen[lang] = true
+		}
+	}
+	for _, g := range e.survey.Groups {
+		collect(g.Metadata)
+	}
+	for _, q := range e.survey.Questions {
+		collect(q.Metadata)
+		if c, err := choice.CastToChoice(q.Value); err == nil {
+			for _, o := range c.Options {
+				collect(o.Metadata)
+			}
+		}
+	}
+	langs := make([]string, 0, len(seen))
+	for lang := range seen {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// nameChoices names the choices of the options of a question.
+func (e *xlsformExporter) nameChoices(c *choice.Choice) {
+	used := map[string]bool{}
+	for _, o := range c.Options {
+		name := o.NameId
+		if v, ok := o.Value.(string); ok && xlsformName.MatchString(v) && !used[v] {
+			name = v
+		}
+		used[name] = true
+		e.choiceOf[o.NameId] = name
+	}
+}
+
+// name returns an unused XLSForm name for a group.
+func (e *xlsformExporter) name(nameId string) string {
+	name := nameId
+	for n := 2; e.names[name]; n++ {
+		name = fmt.Sprintf("%s_%d", nameId, n)
+	}
+	e.names[name] = true
+	return name
+}
+
+// group writes a group, its questions, its subgroups and the groups opened by the options of its questions.
+// trigger is the relevant expression of the option opening the group, if any.
+func (e *xlsformExporter) group(nameId, trigger string) {
+	g, ok := e.survey.Groups[nameId]
+	if !ok || e.visited[nameId] {
+		return
+	}
+	e.visited[nameId] = true
+	if g.IsExternalSurvey {
+		e.report.add(IssueDropped, nameId, "", "external survey groups are not supported")
+		return
+	}
+
+	relevant := joinRelevant(" and ", trigger, e.relevant(nameId, g.DependsOn))
+	if g.Hidden || g.Disabled {
+		relevant = "false()"
+	}
+	title := derefString(g.Title)
+	description := derefString(g.Description)
+	inline := !g.AllowRepeat && title == "" && description == "" && relevant == "" && len(metadataLabels(g.Metadata)) == 0
+
+	kind := "group"
+	if g.AllowRepeat {
+		kind = "repeat"
+	}
+	if !inline {
+		row := xlsformRow{"type": "begin " + kind, "name": e.name(nameId), "label": title, "hint": description, "relevant": relevant}
+		e.labels(row, g.Metadata)
+		e.rows = append(e.rows, row)
+	}
+
+	for _, q := range g.QuestionsIds {
+		e.question(q)
+	}
+	for _, sub := range g.GroupsOrder {
+		e.group(sub, "")
+	}
+	for _, q := range g.QuestionsIds {
+		c, err := choice.CastToChoice(e.survey.Questions[q].Value)
+		if err != nil {
+			continue
+		}
+		for _, o := range c.Options {
+			for _, sub := range o.GroupsIds {
+				e.group(sub, fmt.Sprintf("selected(${%s}, '%s')", q, e.choiceOf[o.NameId]))
+			}
+		}
+	}
+
+	if !inline {
+		e.rows = append(e.rows, xlsformRow{"type": "end " + kind})
+	}
+}
+
+func (e *xlsformExporter) question(nameId string) {
+	q, ok := e.survey.Questions[nameId]
+	if !ok {
+		return
+	}
+
+	row := xlsformRow{"name": nameId, "label": q.Label}
+	if q.Required {
+		row["required"] = "yes"
+	}
+	if q.Disabled {
+		row["read_only"] = "yes"
+	}
+	if !q.Visible {
+		row["relevant"] = "false()"
+	} else {
+		row["relevant"] = e.relevant(nameId, q.DependsOn)
+	}
+	if d, ok := q.Metadata[descriptionKey].(string); ok {
+		row["hint"] = d
+	}
+	e.labels(row, q.Metadata)
+	if q.AnswerExpr != "" {
+		e.report.add(IssueDropped, nameId, "", "answerExpr is not supported")
+	}
+
+	switch v := q.Value.(type) {
+	case *choice.Choice:
+		e.choiceQuestion(row, q, v)
+	case *choice.Slider:
+		row["type"] = "range"
+		row["parameters"] = fmt.Sprintf("start=%d end=%d step=%d", v.Min, v.Max, v.Step)
+		if v.Default != 0 {
+			row["default"] = fmt.Sprint(v.Default)
+		}
+		if v.Unit != "" {
+			e.report.add(IssueDropped, nameId, "", "slider unit '%s' is not supported", v.Unit)
+		}
+	case *text.FreeText:
+		row["type"] = "text"
+		if q.QTyp == types.QTypeTextArea {
+			row["appearance"] = "multiline"
+		}
+		var clauses []string
+		if v.Min != nil && *v.Min > 0 {
+			clauses = append(clauses, fmt.Sprintf("string-length(.) >= %d", *v.Min))
+		}
+		if v.Max != nil {
+			clauses = append(clauses, fmt.Sprintf("string-length(.) <= %d", *v.Max))
+		}
+		row["constraint"] = strings.Join(clauses, " and ")
+		if len(v.Defaults) > 0 {
+			row["default"] = v.Defaults[0]
+		}
+	case *text.Email:
+		row["type"] = "text"
+		domain := `[^@\s]+\.[^@\s]+`
+		if len(v.AllowedDomains) > 0 {
+			quoted := make([]string, len(v.AllowedDomains))
+			for i, d := range v.AllowedDomains {
+				quoted[i] = regexp.QuoteMeta(d)
+			}
+			domain = "(" + strings.Join(quoted, "|") + ")"
+		}
+		row["constraint"] = fmt.Sprintf(`regex(., '^[^@\s]+@%s$')`, domain)
+		e.report.add(IssueApproximated, nameId, "", "email question exported as a text question with a regex constraint")
+	case *text.Telephone:
+		row["type"] = "text"
+		row["appearance"] = "numbers"
+		e.report.add(IssueApproximated, nameId, "", "telephone question exported as a text question with the numbers appearance")
+	case *text.IdentificationNumber:
+		row["type"] = "text"
+		e.report.add(IssueApproximated, nameId, "", "identification number question exported as a text question")
+	case *text.InformationText:
+		row["type"] = "note"
+		row["label"] = v.Text
+		if q.Label != "" && q.Label != v.Text {
+			e.report.add(IssueApproximated, nameId, "", "information label replaced by its text")
+		}
+	case *text.DateTime:
+		row["type"] = map[text.DateTypeFormat]string{text.DateTypeFormatDate: "date", text.DateTypeFormatTime: "time"}[v.Type]
+		if row["type"] == "" {
+			row["type"] = "dateTime"
+		}
+	case *asset.ImageAsset:
+		row["type"] = "image"
+		e.fileLimits(nameId, v.MaxFiles, v.AllowedContentTypes)
+	case *asset.AudioAsset:
+		row["type"] = "audio"
+		e.fileLimits(nameId, v.MaxFiles, v.AllowedContentTypes)
+	case *asset.VideoAsset:
+		row["type"] = "video"
+		e.fileLimits(nameId, v.MaxFiles, v.AllowedContentTypes)
+	case *asset.DocumentAsset:
+		row["type"] = "file"
+		e.fileLimits(nameId, v.MaxFiles, v.AllowedContentTypes)
+	default:
+		e.report.add(IssueDropped, nameId, "", "%s questions are not supported", q.QTyp)
+		return
+	}
+
+	if b := questionBase(q.Value); b.Placeholder != nil && *b.Placeholder != "" && row["type"] != "note" {
+		e.report.add(IssueDropped, nameId, "", "placeholder is not supported")
+	}
+	e.rows = append(e.rows, row)
+}
+
+// choiceQuestion writes a choice question or toggle as a select with a choice list named after the question.
+func (e *xlsformExporter) choiceQuestion(row xlsformRow, q *question.Question, c *choice.Choice) {
+	kind := "select_one"
+	switch q.QTyp {
+	case types.QTypeCheckbox:
+		kind = "select_multiple"
+	case types.QTypeMultipleSelect:
+		kind = "select_multiple"
+		row["appearance"] = "minimal"
+	case types.QTypeSingleSelect:
+		row["appearance"] = "minimal"
+	}
+	row["type"] = kind + " " + q.NameId
+
+	for _, o := range c.Options {
+		choiceRow := xlsformRow{"list_name": q.NameId, "name": e.choiceOf[o.NameId], "label": o.Label}
+		e.labels(choiceRow, o.Metadata)
+		e.choices = append(e.choices, choiceRow)
+	}
+
+	var defaults []string
+	for _, d := range c.Defaults {
+		if name, ok := e.choiceOf[d]; ok {
+			defaults = append(defaults, name)
+		}
+	}
+	if kind == "select_one" && len(defaults) > 1 {
+		defaults = defaults[:1]
+	}
+	row["default"] = strings.Join(defaults, " ")
+}
+
+func (e *xlsformExporter) fileLimits(nameId string, maxFiles int, contentTypes []string) {
+	if maxFiles > 1 {
+		e.report.add(IssueApproximated, nameId, "", "a single file is allowed instead of %d", maxFiles)
+	}
+	if len(contentTypes) > 0 {
+		e.report.add(IssueDropped, nameId, "", "allowed content types are not supported")
+	}
+}
+
+// relevant returns the relevant expression of a DependsOn.
+func (e *xlsformExporter) relevant(nameId string, dependsOn [][]question.DependsOn) string {
+	var ors []string
+	for _, and := range dependsOn {
+		var ands []string
+		for _, d := range and {
+			name, ok := e.choiceOf[d.OptionNameId]
+			if !ok {
+				e.report.add(IssueDropped, nameId, "", "dependsOn option '%s' of '%s' not found", d.OptionNameId, d.QuestionNameId)
+				continue
+			}
+			ands = append(ands, fmt.Sprintf("selected(${%s}, '%s')", d.QuestionNameId, name))
+		}
+		if expr := joinRelevant(" and ", ands...); expr != "" {
+			ors = append(ors, expr)
+		}
+	}
+	return joinRelevant(" or ", ors...)
+}
+
+// labels writes the label::language columns of the "labels" metadata.
+func (e *xlsformExporter) labels(row xlsformRow, metadata map[string]any) {
+	for lang, label := range metadataLabels(metadata) {
+		row["label::"+lang] = label
+	}
+}
+
+// workbook writes the sheets. Columns without values are omitted.
+func (e *xlsformExporter) workbook() ([]byte, *Report, error) {
+	labelCols := make([]string, len(e.langs))
+	for i, lang := range e.langs {
+		labelCols[i] = "label::" + lang
+	}
+	surveyCols := append(append([]string{"type", "name", "label"}, labelCols...),
+		"hint", "required", "relevant", "read_only", "constraint", "appearance", "default", "parameters")
+	choicesCols := append([]string{"list_name", "name", "label"}, labelCols...)
+	settings := []xlsformRow{{"form_title": e.survey.Title, "form_id": e.survey.NameId, "version": e.survey.Version}}
+
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	sheets := []struct {
+		name string
+		cols []string
+		rows []xlsformRow
+	}{
+		{xlsformSurveySheet, surveyCols, e.rows},
+		{xlsformChoicesSheet, choicesCols, e.choices},
+		{xlsformSettingsSheet, []string{"form_title", "form_id", "version"}, settings},
+	}
+	for i, sheet := range sheets {
+		var err error
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), sheet.name)
+		} else {
+			_, err = f.NewSheet(sheet.name)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating %s sheet: %w", sheet.name, err)
+		}
+		if err = writeXLSFormSheet(f, sheet.name, sheet.cols, sheet.rows); err != nil {
+			return nil, nil, fmt.Errorf("writing %s sheet: %w", sheet.name, err)
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, nil, fmt.Errorf("writing XLSForm workbook: %w", err)
+	}
+	return buf.Bytes(), e.report, nil
+}
+
+func writeXLSFormSheet(f *excelize.File, sheet string, cols []string, rows []xlsformRow) error {
+	var used []string
+	for _, col := range cols {
+		for _, row := range rows {
+			if row[col] != "" {
+				used = append(used, col)
+				break
+			}
+		}
+	}
+
+	header := make([]any, len(used))
+	for i, col := range used {
+		header[i] = col
+	}
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+	for r, row := range rows {
+		values := make([]any, len(used))
+		for i, col := range used {
+			values[i] = row[col]
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, r+2)
+		if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// metadataLabels returns the "labels" metadata, as decoded from JSON or set by FromXLSForm.
+func metadataLabels(metadata map[string]any) map[string]string {
+	res := map[string]string{}
+	switch labels := metadata[xlsformLabelsKey].(type) {
+	case map[string]any:
+		for lang, v := range labels {
+			if s, ok := v.(string); ok && s != "" {
+				res[lang] = s
+			}
+		}
+	case map[string]string:
+		for lang, s := range labels {
+			if s != "" {
+				res[lang] = s
+			}
+		}
+	}
+	return res
+}
+
+// joinRelevant joins the non-empty expressions, wrapping them in parentheses if there are several.
+func joinRelevant(sep string, exprs ...string) string {
+	var parts []string
+	for _, e := range exprs {
+		if e != "" {
+			parts = append(parts, e)
+		}
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	for i, p := range parts {
+		if strings.Contains(p, " and ") || strings.Contains(p, " or ") {
+			parts[i] = "(" + p + ")"
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
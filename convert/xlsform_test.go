@@ -0,0 +1,270 @@
+package convert
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsform builds a workbook with the given sheets, the first row of each sheet being its header.
+func xlsform(t *testing.T, sheets map[string][][]any) *bytes.Buffer {
+	t.Helper()
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	for name, rows := range sheets {
+		if _, err := f.NewSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		for i, row := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := f.SetSheetRow(name, cell, &row); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := f.DeleteSheet("Sheet1"); err != nil {
+		t.Fatal(err)
+	}
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestFromXLSForm(t *testing.T) {
+	wb := xlsform(t, map[string][][]any{
+		"survey": {
+			{"type", "name", "label::English (en)", "label::Español (es)", "hint::English (en)", "required", "relevant", "constraint", "appearance", "default", "parameters"},
+			{"note", "welcome", "Welcome", "Bienvenido"},
+			{"begin group", "profile", "Profile", "Perfil"},
+			{"select_one yes_no", "adult", "Are you an adult?", "¿Es adulto?", "", "yes"},
+			{"text", "name", "Name", "Nombre", "Your full name", "true()", "", "string-length(.) >= 2 and string-length(.) <= 40"},
+			{"select_multiple pets", "pets", "Pets", "Mascotas", "", "", "selected(${adult}, 'yes')", "", "", "dog"},
+			{"begin repeat", "pet", "Pet", "Mascota", "", "", "selected(${pets}, 'dog') or selected(${pets}, 'cat')"},
+			{"text", "pet_name", "Pet name", "Nombre", "", "", "", "", "multiline"},
+			{"end repeat"},
+			{"select_one pets", "favorite", "Favorite", "Favorito", "", "", "", "", "minimal"},
+			{"end group"},
+			{"range", "score", "Score", "Puntaje", "", "", "", "", "", "", "start=1 end=10 step=1"},
+			{"integer", "age", "Age", "Edad", "", "", "${adult} = 'yes' and ${age} > 18"},
+			{"geopoint", "location", "Location", "Ubicación"},
+			{"date", "birthday", "Birthday", "Cumpleaños", "", "", "false()"},
+		},
+		"choices": {
+			{"list_name", "name", "label::English (en)", "label::Español (es)"},
+			{"yes_no", "yes", "Yes", "Sí"},
+			{"yes_no", "no", "No", "No"},
+			{"pets", "dog", "Dog", "Perro"},
+			{"pets", "cat", "Cat", "Gato"},
+		},
+		"settings": {
+			{"form_title", "form_id", "version", "default_language"},
+			{"Pet census", "pet_census", "2024010101", "English (en)"},
+		},
+	})
+
+	s, report, err := FromXLSForm(wb)
+	if err != nil {
+		t.Fatalf("FromXLSForm: %v", err)
+	}
+
+	if s.NameId != "pet_census" || s.Title != "Pet census" || s.Version != "2024010101" {
+		t.Errorf("unexpected survey %s %q %s", s.NameId, s.Title, s.Version)
+	}
+	// questions outside any group are gathered in untitled groups, keeping the source order
+	if want := []string{"questions", "profile", "questions-2"}; !reflect.DeepEqual(s.GroupsOrder, want) {
+		t.Errorf("groups order = %v", s.GroupsOrder)
+	}
+	profile := s.Groups["profile"]
+	if !reflect.DeepEqual(profile.QuestionsIds, []string{"adult", "name", "pets"}) ||
+		!reflect.DeepEqual(profile.GroupsOrder, []string{"pet", "profile-cont"}) {
+		t.Errorf("profile group = %v %v", profile.QuestionsIds, profile.GroupsOrder)
+	}
+	if pet := s.Groups["pet"]; !pet.AllowRepeat || len(pet.DependsOn) != 2 {
+		t.Errorf("pet group = %+v", pet)
+	}
+
+	wantTypes := map[string]types.QuestionType{
+		"welcome": types.QTypeInformation, "adult": types.QTypeRadio, "name": types.QTypeInputText,
+		"pets": types.QTypeCheckbox, "pet_name": types.QTypeTextArea, "favorite": types.QTypeSingleSelect,
+		"score": types.QTypeSlider, "age": types.QTypeInputText, "birthday": types.QTypeDateTime,
+	}
+	for nameId, want := range wantTypes {
+		if q, ok := s.Questions[nameId]; !ok || q.QTyp != want {
+			t.Errorf("question %s: want type %s, got %v", nameId, want, q)
+		}
+	}
+	if _, ok := s.Questions["location"]; ok {
+		t.Error("unsupported geopoint should not be converted")
+	}
+
+	name := s.Questions["name"]
+	if ft := name.Value.(*text.FreeText); !name.Required || *ft.Min != 2 || *ft.Max != 40 {
+		t.Errorf("name = %+v %+v", name, ft)
+	}
+	if name.Metadata[descriptionKey] != "Your full name" {
+		t.Errorf("name metadata = %v", name.Metadata)
+	}
+	adult := s.Questions["adult"]
+	if adult.Label != "Are you an adult?" || adult.Metadata[xlsformLabelsKey].(map[string]any)["Español (es)"] != "¿Es adulto?" {
+		t.Errorf("adult = %q %v", adult.Label, adult.Metadata)
+	}
+	if d := s.Questions["pets"].Value.(*choice.Choice).Defaults; !reflect.DeepEqual(d, []string{"pets-dog"}) {
+		t.Errorf("pets defaults = %v", d)
+	}
+	if sl := s.Questions["score"].Value.(*choice.Slider); sl.Min != 1 || sl.Max != 10 || sl.Step != 1 {
+		t.Errorf("score slider = %+v", sl)
+	}
+	if s.Questions["birthday"].Visible {
+		t.Error("birthday should not be visible")
+	}
+
+	wantDeps := map[string][][]question.DependsOn{
+		"pets": {{{QuestionNameId: "adult", OptionNameId: "adult-yes"}}},
+		"age":  nil,
+	}
+	for nameId, want := range wantDeps {
+		if got := s.Questions[nameId].DependsOn; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s dependsOn = %v, want %v", nameId, got, want)
+		}
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	want := []string{"approximated age", "dropped location", "dropped age"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+}
+
+func TestFromXLSForm_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		sheets map[string][][]any
+		err    string
+	}{
+		{
+			name:   "no survey sheet",
+			sheets: map[string][][]any{"choices": {{"list_name", "name", "label"}}},
+			err:    "no survey sheet",
+		},
+		{
+			name:   "unclosed group",
+			sheets: map[string][][]any{"survey": {{"type", "name", "label"}, {"begin group", "profile", "Profile"}}},
+			err:    "group 'profile' is not closed",
+		},
+		{
+			name:   "end without begin",
+			sheets: map[string][][]any{"survey": {{"type", "name", "label"}, {"end group"}}},
+			err:    "survey row 2: 'end group' without begin",
+		},
+	}
+	for _, tt := range tests {
+		_, _, err := FromXLSForm(xlsform(t, tt.sheets))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: want error %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+const xlsformSurvey = `{
+  "nameId": "pet-census",
+  "title": "Pet census",
+  "version": "3",
+  "questions": {
+    "adult": {"nameId": "adult", "visible": true, "type": "radio", "label": "Adult?", "required": true,
+      "value": {"options": [
+        {"nameId": "adult-yes", "label": "Yes", "value": "yes", "groupsIds": ["pets-group"]},
+        {"nameId": "adult-no", "label": "No", "value": "not sure"}
+      ]}},
+    "pets": {"nameId": "pets", "visible": true, "type": "multi_select", "label": "Pets",
+      "value": {"defaults": ["pets-dog"], "options": [
+        {"nameId": "pets-dog", "label": "Dog", "value": "dog"},
+        {"nameId": "pets-cat", "label": "Cat", "value": "cat"}
+      ]}},
+    "pet-name": {"nameId": "pet-name", "visible": true, "type": "input_text", "label": "Pet name",
+      "dependsOn": [[{"questionNameId": "pets", "optionNameId": "pets-dog"}], [{"questionNameId": "pets", "optionNameId": "pets-cat"}]],
+      "value": {"min": 2, "max": 20}},
+    "score": {"nameId": "score", "visible": true, "type": "slider", "label": "Score", "value": {"min": 1, "max": 5, "step": 1, "unit": "stars"}},
+    "mail": {"nameId": "mail", "visible": false, "type": "email", "label": "Email", "value": {}}
+  },
+  "groups": {
+    "intro": {"nameId": "intro", "questionsIds": ["adult", "score", "mail"]},
+    "pets-group": {"nameId": "pets-group", "title": "Pets", "questionsIds": ["pets", "pet-name"]}
+  },
+  "groupsOrder": ["intro"]
+}`
+
+func TestToXLSForm(t *testing.T) {
+	s, err := surveygo.ParseFromBytes([]byte(xlsformSurvey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, report, err := ToXLSForm(s)
+	if err != nil {
+		t.Fatalf("ToXLSForm: %v", err)
+	}
+
+	f, err := excelize.OpenReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	rows, err := f.GetRows("survey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, row := range rows {
+		lines = append(lines, strings.TrimRight(strings.Join(row, "|"), "|"))
+	}
+	want := []string{
+		"type|name|label|required|relevant|constraint|appearance|default|parameters",
+		"select_one adult|adult|Adult?|yes",
+		"range|score|Score||||||start=1 end=5 step=1",
+		"text|mail|Email||false()|regex(., '^[^@\\s]+@[^@\\s]+\\.[^@\\s]+$')",
+		"begin group|pets-group|Pets||selected(${adult}, 'yes')",
+		"select_multiple pets|pets|Pets||||minimal|dog",
+		"text|pet-name|Pet name||selected(${pets}, 'dog') or selected(${pets}, 'cat')|string-length(.) >= 2 and string-length(.) <= 20",
+		"end group",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("survey sheet:\n%s", strings.Join(lines, "\n"))
+	}
+	// option values that are not valid XLSForm names are replaced by the option name id
+	if choices, _ := f.GetRows("choices"); len(choices) != 5 || strings.Join(choices[2], "|") != "adult|adult-no|No" {
+		t.Errorf("choices sheet = %v", choices)
+	}
+
+	var got []string
+	for _, i := range report.Issues {
+		got = append(got, string(i.Kind)+" "+i.Source)
+	}
+	if want := []string{"dropped score", "approximated mail"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("report:\n%s", report)
+	}
+
+	// the workbook imports back into an equivalent survey
+	back, _, err := FromXLSForm(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("FromXLSForm: %v", err)
+	}
+	if back.NameId != "pet-census" || back.Version != "3" || len(back.Questions) != len(s.Questions) {
+		t.Errorf("imported survey %s %s %v", back.NameId, back.Version, back.Questions)
+	}
+	if deps := back.Groups["pets-group"].DependsOn; len(deps) != 1 || deps[0][0].OptionNameId != "adult-yes" {
+		t.Errorf("pets-group dependsOn = %v", deps)
+	}
+}
@@ -0,0 +1,642 @@
+package surveygo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+)
+
+// ChangeKind is the kind of structural change between two survey versions.
+type ChangeKind string
+
+const (
+	// ChangeAdded the element exists only in the new survey.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved the element exists only in the old survey.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified a field of the element changed.
+	ChangeModified ChangeKind = "modified"
+	// ChangeReordered the same elements are listed in a different order.
+	ChangeReordered ChangeKind = "reordered"
+)
+
+// ChangeTarget is the kind of element affected by a change.
+type ChangeTarget string
+
+const (
+	ChangeTargetSurvey   ChangeTarget = "survey"
+	ChangeTargetGroup    ChangeTarget = "group"
+	ChangeTargetQuestion ChangeTarget = "question"
+	ChangeTargetOption   ChangeTarget = "option"
+)
+
+// Change is a single structural difference between two survey versions.
+type Change struct {
+	// Kind is the kind of change.
+	Kind ChangeKind `json:"kind" bson:"kind"`
+
+	// Target is the kind of element affected.
+	Target ChangeTarget `json:"target" bson:"target"`
+
+	// Path locates the element in the survey document, e.g. "questions.q1.options.opt1".
+	Path string `json:"path" bson:"path"`
+
+	// Field is the modified field (only for ChangeModified and ChangeReordered), e.g. "required" or "value.max".
+	Field string `json:"field,omitempty" bson:"field,omitempty"`
+
+	// Old is the previous value, if any.
+	Old any `json:"old,omitempty" bson:"old,omitempty"`
+
+	// New is the new value, if any.
+	New any `json:"new,omitempty" bson:"new,omitempty"`
+
+	// Breaking is true when answers that were valid for the old survey may be rejected
+	// or left incomplete by the new one.
+	Breaking bool `json:"breaking" bson:"breaking"`
+
+	// Reason explains why the change is breaking.
+	Reason string `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// SurveyDiff is the result of comparing two versions of a survey.
+type SurveyDiff struct {
+	// NameId is the name id of the new survey.
+	NameId string `json:"nameId" bson:"nameId"`
+
+	// OldVersion is the version of the old survey.
+	OldVersion string `json:"oldVersion" bson:"oldVersion"`
+
+	// NewVersion is the version of the new survey.
+	NewVersion string `json:"newVersion" bson:"newVersion"`
+
+	// Changes is the list of changes, sorted by path.
+	Changes []*Change `json:"changes" bson:"changes"`
+}
+
+// Diff compares two versions of a survey and reports the structural changes between them:
+// added, removed and modified questions, groups and options, DependsOn changes and reorderings.
+// Each change is classified as breaking when answers valid for the old survey may no longer pass
+// ReviewAnswers on the new one (e.g. a removed option, an incompatible type change or a tightened constraint),
+// or when previously complete answers may become incomplete (e.g. a new required question).
+// Args:
+//   - from: the old survey
+//   - to: the new survey
+//
+// Returns:
+//   - *SurveyDiff: the changes between both surveys
+//   - error: if any of the surveys is nil
+func Diff(from, to *Survey) (*SurveyDiff, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("both surveys are required to compute a diff")
+	}
+
+	d := &differ{from: from, to: to}
+	d.diffSurvey()
+	d.diffGroups()
+	d.diffQuestions()
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Path < d.changes[j].Path
+	})
+
+	return &SurveyDiff{
+		NameId:     to.NameId,
+		OldVersion: from.Version,
+		NewVersion: to.Version,
+		Changes:    d.changes,
+	}, nil
+}
+
+// HasBreakingChanges returns true if at least one change is breaking.
+func (d *SurveyDiff) HasBreakingChanges() bool {
+	return slices.ContainsFunc(d.Changes, func(c *Change) bool { return c.Breaking })
+}
+
+// BreakingChanges returns the breaking changes.
+func (d *SurveyDiff) BreakingChanges() []*Change {
+	var res []*Change
+	for _, c := range d.Changes {
+		if c.Breaking {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// ToJson returns a JSON string representation of the diff.
+func (d *SurveyDiff) ToJson() (string, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Changelog returns a human-readable changelog, listing breaking changes first.
+func (d *SurveyDiff) Changelog() string {
+	var sb strings.Builder
+
+	breaking := d.BreakingChanges()
+	fmt.Fprintf(&sb, "%s: %s -> %s (%d changes, %d breaking)\n", d.NameId, d.OldVersion, d.NewVersion, len(d.Changes), len(breaking))
+
+	if len(d.Changes) == 0 {
+		sb.WriteString("\nNo changes.\n")
+		return sb.String()
+	}
+
+	if len(breaking) > 0 {
+		sb.WriteString("\nBreaking changes:\n")
+		for _, c := range breaking {
+			fmt.Fprintf(&sb, "  - %s\n", c)
+		}
+	}
+
+	if len(breaking) < len(d.Changes) {
+		sb.WriteString("\nNon-breaking changes:\n")
+		for _, c := range d.Changes {
+			if !c.Breaking {
+				fmt.Fprintf(&sb, "  - %s\n", c)
+			}
+		}
+	}
+
+	return sb.String()
+}
+
+// String returns a one-line human-readable description of the change.
+func (c *Change) String() string {
+	var s string
+	switch c.Kind {
+	case ChangeAdded:
+		s = fmt.Sprintf("%s '%s' added", c.Target, c.Path)
+	case ChangeRemoved:
+		s = fmt.Sprintf("%s '%s' removed", c.Target, c.Path)
+	case ChangeReordered:
+		s = fmt.Sprintf("%s '%s': %s reordered", c.Target, c.Path, c.Field)
+	default:
+		s = fmt.Sprintf("%s '%s': %s changed from %s to %s", c.Target, c.Path, c.Field, formatChangeValue(c.Old), formatChangeValue(c.New))
+	}
+
+	if c.Reason != "" {
+		s += " (" + c.Reason + ")"
+	}
+	return s
+}
+
+// formatChangeValue formats a change value for the changelog.
+func formatChangeValue(v any) string {
+	if v == nil {
+		return "<none>"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("'%s'", s)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// differ accumulates the changes between two surveys.
+type differ struct {
+	from    *Survey
+	to      *Survey
+	changes []*Change
+}
+
+func (d *differ) add(c *Change) {
+	d.changes = append(d.changes, c)
+}
+
+// diffSurvey compares the survey level fields.
+func (d *differ) diffSurvey() {
+	const path = "survey"
+
+	d.modified(ChangeTargetSurvey, path, "nameId", d.from.NameId, d.to.NameId, "")
+	d.modified(ChangeTargetSurvey, path, "title", d.from.Title, d.to.Title, "")
+	d.modified(ChangeTargetSurvey, path, "description", derefString(d.from.Description), derefString(d.to.Description), "")
+	d.modified(ChangeTargetSurvey, path, "metadata", d.from.Metadata, d.to.Metadata, "")
+	d.diffOrder(ChangeTargetSurvey, path, "groupsOrder", d.from.GroupsOrder, d.to.GroupsOrder)
+}
+
+// diffGroups compares the groups of both surveys.
+func (d *differ) diffGroups() {
+	for _, nameId := range unionKeys(d.from.Groups, d.to.Groups) {
+		path := "groups." + nameId
+		og, oldOk := d.from.Groups[nameId]
+		ng, newOk := d.to.Groups[nameId]
+
+		switch {
+		case !newOk:
+			c := &Change{Kind: ChangeRemoved, Target: ChangeTargetGroup, Path: path}
+			if og.AllowRepeat {
+				c.Breaking = true
+				c.Reason = "answers of the repeatable group become unknown"
+			}
+			d.add(c)
+		case !oldOk:
+			d.add(&Change{Kind: ChangeAdded, Target: ChangeTargetGroup, Path: path})
+		default:
+			d.diffGroup(path, og, ng)
+		}
+	}
+}
+
+// diffGroup compares two versions of the same group.
+func (d *differ) diffGroup(path string, og, ng *question.Group) {
+	requiredInside := d.to.groupHasRequiredQuestions(ng)
+	const requiredReason = "group with required questions may become visible"
+
+	d.modified(ChangeTargetGroup, path, "title", derefString(og.Title), derefString(ng.Title), "")
+	d.modified(ChangeTargetGroup, path, "description", derefString(og.Description), derefString(ng.Description), "")
+	d.modified(ChangeTargetGroup, path, "metadata", og.Metadata, ng.Metadata, "")
+	d.modified(ChangeTargetGroup, path, "isExternalSurvey", og.IsExternalSurvey, ng.IsExternalSurvey, "")
+	d.modified(ChangeTargetGroup, path, "allowRepeat", og.AllowRepeat, ng.AllowRepeat, "answers of the group change their nesting")
+	d.modified(ChangeTargetGroup, path, "hidden", og.Hidden, ng.Hidden, breakingIf(og.Hidden && !ng.Hidden && requiredInside, requiredReason))
+	d.modified(ChangeTargetGroup, path, "disabled", og.Disabled, ng.Disabled, breakingIf(og.Disabled && !ng.Disabled && requiredInside, requiredReason))
+	d.modified(ChangeTargetGroup, path, "dependsOn", og.DependsOn, ng.DependsOn, breakingIf(requiredInside, requiredReason))
+	d.diffOrder(ChangeTargetGroup, path, "groupsOrder", og.GroupsOrder, ng.GroupsOrder)
+
+	// membership changes are reported on each question, only report pure reorderings here
+	if sameElements(og.QuestionsIds, ng.QuestionsIds) {
+		d.diffOrder(ChangeTargetGroup, path, "questionsIds", og.QuestionsIds, ng.QuestionsIds)
+	}
+}
+
+// diffQuestions compares the questions of both surveys.
+func (d *differ) diffQuestions() {
+	oldGroups := d.from.questionGroups()
+	newGroups := d.to.questionGroups()
+
+	for _, nameId := range unionKeys(d.from.Questions, d.to.Questions) {
+		path := "questions." + nameId
+		oq, oldOk := d.from.Questions[nameId]
+		nq, newOk := d.to.Questions[nameId]
+
+		switch {
+		case !newOk:
+			d.add(&Change{
+				Kind:     ChangeRemoved,
+				Target:   ChangeTargetQuestion,
+				Path:     path,
+				Breaking: true,
+				Reason:   "existing answers for the question become unknown",
+			})
+		case !oldOk:
+			c := &Change{Kind: ChangeAdded, Target: ChangeTargetQuestion, Path: path}
+			if nq.Required && nq.Visible {
+				c.Breaking = true
+				c.Reason = "existing answers lack a required question"
+			}
+			d.add(c)
+		default:
+			d.diffQuestion(path, oq, nq, oldGroups[nameId], newGroups[nameId])
+		}
+	}
+}
+
+// diffQuestion compares two versions of the same question.
+func (d *differ) diffQuestion(path string, oq, nq *question.Question, oldGroup, newGroup string) {
+	const requiredReason = "required question may become visible"
+
+	if oq.QTyp != nq.QTyp {
+		d.add(&Change{
+			Kind:     ChangeModified,
+			Target:   ChangeTargetQuestion,
+			Path:     path,
+			Field:    "type",
+			Old:      oq.QTyp,
+			New:      nq.QTyp,
+			Breaking: !isCompatibleTypeChange(oq.QTyp, nq.QTyp),
+			Reason:   breakingIf(!isCompatibleTypeChange(oq.QTyp, nq.QTyp), "answers are not valid for the new type"),
+		})
+	}
+
+	d.modified(ChangeTargetQuestion, path, "label", oq.Label, nq.Label, "")
+	d.modified(ChangeTargetQuestion, path, "required", oq.Required, nq.Required, breakingIf(!oq.Required && nq.Required, "question became required"))
+	d.modified(ChangeTargetQuestion, path, "visible", oq.Visible, nq.Visible, breakingIf(!oq.Visible && nq.Visible && nq.Required, requiredReason))
+	d.modified(ChangeTargetQuestion, path, "disabled", oq.Disabled, nq.Disabled, "")
+	d.modified(ChangeTargetQuestion, path, "metadata", oq.Metadata, nq.Metadata, "")
+	d.modified(ChangeTargetQuestion, path, "answerExpr", oq.AnswerExpr, nq.AnswerExpr, "")
+	d.modified(ChangeTargetQuestion, path, "dependsOn", oq.DependsOn, nq.DependsOn, breakingIf(nq.Required, requiredReason))
+
+	if oldGroup != newGroup {
+		nestingChanged := d.from.isRepeatGroup(oldGroup) || d.to.isRepeatGroup(newGroup)
+		d.add(&Change{
+			Kind:     ChangeModified,
+			Target:   ChangeTargetQuestion,
+			Path:     path,
+			Field:    "group",
+			Old:      nilIfEmpty(oldGroup),
+			New:      nilIfEmpty(newGroup),
+			Breaking: nestingChanged,
+			Reason:   breakingIf(nestingChanged, "answers of the question change their nesting"),
+		})
+	}
+
+	// values of different structs (e.g. text to choice) are only reported as a type change
+	if reflect.TypeOf(oq.Value) != reflect.TypeOf(nq.Value) {
+		return
+	}
+
+	d.diffValue(path, nq.QTyp, oq.Value, nq.Value)
+}
+
+// diffValue compares the type specific values of a question field by field.
+func (d *differ) diffValue(path string, qt types.QuestionType, oldValue, newValue any) {
+	oldFields, oldErr := toFieldMap(oldValue)
+	newFields, newErr := toFieldMap(newValue)
+	if oldErr != nil || newErr != nil {
+		d.modified(ChangeTargetQuestion, path, "value", oldValue, newValue, "")
+		return
+	}
+
+	for _, field := range unionKeys(oldFields, newFields) {
+		if field == "options" {
+			continue
+		}
+		o, n := oldFields[field], newFields[field]
+		d.modified(ChangeTargetQuestion, path, "value."+field, o, n, constraintChangeReason(qt, field, o, n))
+	}
+
+	oc, oldErr := choice.CastToChoice(oldValue)
+	nc, newErr := choice.CastToChoice(newValue)
+	if oldErr == nil && newErr == nil {
+		d.diffOptions(path, qt, oc.Options, nc.Options)
+	}
+}
+
+// diffOptions compares the options of two versions of a choice question.
+func (d *differ) diffOptions(path string, qt types.QuestionType, oldOptions, newOptions []*choice.Option) {
+	oldById := make(map[string]*choice.Option, len(oldOptions))
+	var oldIds []string
+	for _, o := range oldOptions {
+		oldById[o.NameId] = o
+		oldIds = append(oldIds, o.NameId)
+	}
+
+	newById := make(map[string]*choice.Option, len(newOptions))
+	var newIds []string
+	for _, o := range newOptions {
+		newById[o.NameId] = o
+		newIds = append(newIds, o.NameId)
+	}
+
+	for _, nameId := range unionKeys(oldById, newById) {
+		optPath := path + ".options." + nameId
+		oo, oldOk := oldById[nameId]
+		no, newOk := newById[nameId]
+
+		switch {
+		case !newOk:
+			// only simple choice answers reference options, toggle answers are booleans
+			removedBreaks := types.IsSimpleChoiceType(qt)
+			d.add(&Change{
+				Kind:     ChangeRemoved,
+				Target:   ChangeTargetOption,
+				Path:     optPath,
+				Breaking: removedBreaks,
+				Reason:   breakingIf(removedBreaks, "answers selecting the option become invalid"),
+			})
+		case !oldOk:
+			d.add(&Change{Kind: ChangeAdded, Target: ChangeTargetOption, Path: optPath})
+		default:
+			d.modified(ChangeTargetOption, optPath, "label", oo.Label, no.Label, "")
+			d.modified(ChangeTargetOption, optPath, "value", oo.Value, no.Value, "")
+			d.modified(ChangeTargetOption, optPath, "groupsIds", oo.GroupsIds, no.GroupsIds, "")
+			d.modified(ChangeTargetOption, optPath, "metadata", oo.Metadata, no.Metadata, "")
+		}
+	}
+
+	if sameElements(oldIds, newIds) {
+		d.diffOrder(ChangeTargetQuestion, path, "options", oldIds, newIds)
+	}
+}
+
+// modified adds a ChangeModified if the old and new values differ.
+// A non-empty breakingReason marks the change as breaking.
+func (d *differ) modified(target ChangeTarget, path, field string, oldValue, newValue any, breakingReason string) {
+	if isEmptyValue(oldValue) && isEmptyValue(newValue) {
+		return
+	}
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	d.add(&Change{
+		Kind:     ChangeModified,
+		Target:   target,
+		Path:     path,
+		Field:    field,
+		Old:      nilIfEmpty(oldValue),
+		New:      nilIfEmpty(newValue),
+		Breaking: breakingReason != "",
+		Reason:   breakingReason,
+	})
+}
+
+// diffOrder adds a ChangeReordered when both lists have the same elements in a different order,
+// or a ChangeModified when the elements differ.
+func (d *differ) diffOrder(target ChangeTarget, path, field string, oldList, newList []string) {
+	if slices.Equal(oldList, newList) {
+		return
+	}
+
+	kind := ChangeModified
+	if sameElements(oldList, newList) {
+		kind = ChangeReordered
+	}
+
+	d.add(&Change{Kind: kind, Target: target, Path: path, Field: field, Old: oldList, New: newList})
+}
+
+// questionGroups returns a map with the group name id of each question.
+func (s *Survey) questionGroups() map[string]string {
+	res := make(map[string]string)
+	for _, g := range s.Groups {
+		if g.IsExternalSurvey {
+			continue
+		}
+		for _, qId := range g.QuestionsIds {
+			res[qId] = g.NameId
+		}
+	}
+	return res
+}
+
+// isRepeatGroup returns true if the group exists and allows repetition.
+func (s *Survey) isRepeatGroup(nameId string) bool {
+	g, ok := s.Groups[nameId]
+	return ok && g.AllowRepeat
+}
+
+// groupHasRequiredQuestions returns true if the group contains at least one required question.
+func (s *Survey) groupHasRequiredQuestions(g *question.Group) bool {
+	if g.IsExternalSurvey {
+		return false
+	}
+	for _, qId := range g.QuestionsIds {
+		if q, ok := s.Questions[qId]; ok && q.Required {
+			return true
+		}
+	}
+	return false
+}
+
+// compatibleTypeChanges lists the type changes that keep existing answers valid.
+// Key: old type, Value: new types accepting the answers of the old type.
+var compatibleTypeChanges = map[types.QuestionType][]types.QuestionType{
+	types.QTypeSingleSelect:   {types.QTypeRadio, types.QTypeMultipleSelect, types.QTypeCheckbox},
+	types.QTypeRadio:          {types.QTypeSingleSelect, types.QTypeMultipleSelect, types.QTypeCheckbox},
+	types.QTypeMultipleSelect: {types.QTypeCheckbox},
+	types.QTypeCheckbox:       {types.QTypeMultipleSelect},
+	types.QTypeTextArea:       {types.QTypeInputText},
+	types.QTypeInputText:      {types.QTypeTextArea},
+}
+
+// isCompatibleTypeChange returns true if answers of the old type are valid for the new type.
+func isCompatibleTypeChange(oldType, newType types.QuestionType) bool {
+	return slices.Contains(compatibleTypeChanges[oldType], newType)
+}
+
+// constraintChangeReason returns the breaking reason for a change in a type specific field,
+// or an empty string if the change does not restrict the accepted answers.
+func constraintChangeReason(qt types.QuestionType, field string, oldValue, newValue any) string {
+	const reason = "constraint tightened"
+
+	switch field {
+	case "min", "minFiles":
+		o, oldOk := oldValue.(float64)
+		n, newOk := newValue.(float64)
+		return breakingIf(newOk && (!oldOk || n > o), reason)
+	case "max", "maxFiles", "maxSize":
+		o, oldOk := oldValue.(float64)
+		n, newOk := newValue.(float64)
+		return breakingIf(newOk && (!oldOk || n < o), reason)
+	case "step":
+		// values on the old steps stay on the new ones only if the old step is a multiple of the new one
+		o, _ := oldValue.(float64)
+		n, newOk := newValue.(float64)
+		return breakingIf(newOk && n > 0 && math.Mod(o, n) != 0, "answers may not fall on a step")
+	case "allowedDomains", "allowedCountryCodes", "allowedContentTypes":
+		o := toStringSlice(oldValue)
+		n := toStringSlice(newValue)
+		if len(n) == 0 {
+			return ""
+		}
+		if len(o) == 0 {
+			return reason
+		}
+		for _, v := range o {
+			if !slices.Contains(n, v) {
+				return reason
+			}
+		}
+	case "format", "type":
+		if qt == types.QTypeDateTime {
+			return "answers are parsed with the " + field
+		}
+	}
+
+	return ""
+}
+
+// breakingIf returns the reason if the condition is true, an empty string otherwise.
+func breakingIf(cond bool, reason string) string {
+	if cond {
+		return reason
+	}
+	return ""
+}
+
+// toFieldMap converts a question value to a map of its JSON fields.
+func toFieldMap(v any) (map[string]any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// toStringSlice converts a decoded JSON array to a string slice.
+func toStringSlice(v any) []string {
+	list, _ := v.([]any)
+	var res []string
+	for _, item := range list {
+		res = append(res, fmt.Sprintf("%v", item))
+	}
+	return res
+}
+
+// unionKeys returns the sorted union of the keys of both maps.
+func unionKeys[V any](a, b map[string]V) []string {
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sameElements returns true if both lists contain the same elements, regardless of order.
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := slices.Clone(a)
+	bs := slices.Clone(b)
+	slices.Sort(as)
+	slices.Sort(bs)
+	return slices.Equal(as, bs)
+}
+
+// isEmptyValue returns true for nil, zero and empty values.
+func isEmptyValue(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map:
+		return rv.Len() == 0
+	default:
+		return rv.IsZero()
+	}
+}
+
+// nilIfEmpty returns nil for empty non-boolean values so they are omitted from the JSON output.
+func nilIfEmpty(v any) any {
+	if _, ok := v.(bool); ok {
+		return v
+	}
+	if isEmptyValue(v) {
+		return nil
+	}
+	return v
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,285 @@
+package surveygo
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/rendis/surveygo/v2/question/types"
+)
+
+const diffBaseSurvey = `{
+  "nameId": "household",
+  "title": "Household",
+  "version": "1",
+  "groupsOrder": ["grp-main", "grp-members"],
+  "groups": {
+    "grp-main": {"nameId": "grp-main", "questionsIds": ["q-city", "q-pets", "q-rooms", "q-mail"]},
+    "grp-members": {"nameId": "grp-members", "allowRepeat": true, "questionsIds": ["q-name"]}
+  },
+  "questions": {
+    "q-city": {"nameId": "q-city", "visible": true, "type": "input_text", "label": "City", "value": {"min": 0, "max": 50}},
+    "q-pets": {"nameId": "q-pets", "visible": true, "type": "single_select", "label": "Pets",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-rooms": {"nameId": "q-rooms", "visible": true, "type": "slider", "label": "Rooms", "value": {"min": 1, "max": 10, "step": 1}},
+    "q-mail": {"nameId": "q-mail", "visible": true, "type": "email", "label": "Mail", "value": {"allowedDomains": ["a.com"]}},
+    "q-name": {"nameId": "q-name", "visible": true, "type": "input_text", "label": "Name", "value": {}}
+  }
+}`
+
+func parseDiffSurvey(t *testing.T, modify func(m map[string]any)) *Survey {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal([]byte(diffBaseSurvey), &m); err != nil {
+		t.Fatalf("unmarshal base survey: %v", err)
+	}
+	if modify != nil {
+		modify(m)
+	}
+	b, _ := json.Marshal(m)
+	s, err := ParseFromBytes(b)
+	if err != nil {
+		t.Fatalf("ParseFromBytes: %v", err)
+	}
+	return s
+}
+
+func findChange(d *SurveyDiff, path, field string) *Change {
+	for _, c := range d.Changes {
+		if c.Path == path && c.Field == field {
+			return c
+		}
+	}
+	return nil
+}
+
+func questionValue(m map[string]any, nameId string) map[string]any {
+	return m["questions"].(map[string]any)[nameId].(map[string]any)
+}
+
+func TestDiff_NoChanges(t *testing.T) {
+	d, err := Diff(parseDiffSurvey(t, nil), parseDiffSurvey(t, nil))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(d.Changes) != 0 {
+		t.Fatalf("expected no changes, got %v", d.Changes)
+	}
+	if !strings.Contains(d.Changelog(), "No changes.") {
+		t.Errorf("unexpected changelog:\n%s", d.Changelog())
+	}
+}
+
+func TestDiff_Classification(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(m map[string]any)
+		path     string
+		field    string
+		kind     ChangeKind
+		breaking bool
+	}{
+		{
+			name: "removed option",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-pets")["value"] = map[string]any{"options": []any{map[string]any{"nameId": "dog", "label": "Dog"}}}
+			},
+			path: "questions.q-pets.options.cat", kind: ChangeRemoved, breaking: true,
+		},
+		{
+			name: "added option",
+			modify: func(m map[string]any) {
+				opts := questionValue(m, "q-pets")["value"].(map[string]any)["options"].([]any)
+				questionValue(m, "q-pets")["value"].(map[string]any)["options"] = append(opts, map[string]any{"nameId": "fish", "label": "Fish"})
+			},
+			path: "questions.q-pets.options.fish", kind: ChangeAdded, breaking: false,
+		},
+		{
+			name: "reordered options",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-pets")["value"] = map[string]any{"options": []any{
+					map[string]any{"nameId": "cat", "label": "Cat"}, map[string]any{"nameId": "dog", "label": "Dog"},
+				}}
+			},
+			path: "questions.q-pets", field: "options", kind: ChangeReordered, breaking: false,
+		},
+		{
+			name:   "single to multiple select",
+			modify: func(m map[string]any) { questionValue(m, "q-pets")["type"] = "multi_select" },
+			path:   "questions.q-pets", field: "type", kind: ChangeModified, breaking: false,
+		},
+		{
+			name:   "text to email",
+			modify: func(m map[string]any) { questionValue(m, "q-name")["type"] = "email" },
+			path:   "questions.q-name", field: "type", kind: ChangeModified, breaking: true,
+		},
+		{
+			name:   "tightened max",
+			modify: func(m map[string]any) { questionValue(m, "q-city")["value"] = map[string]any{"min": 0, "max": 20} },
+			path:   "questions.q-city", field: "value.max", kind: ChangeModified, breaking: true,
+		},
+		{
+			name: "loosened slider range",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-rooms")["value"] = map[string]any{"min": 1, "max": 20, "step": 1}
+			},
+			path: "questions.q-rooms", field: "value.max", kind: ChangeModified, breaking: false,
+		},
+		{
+			name: "coarser slider step",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-rooms")["value"] = map[string]any{"min": 1, "max": 10, "step": 2}
+			},
+			path: "questions.q-rooms", field: "value.step", kind: ChangeModified, breaking: true,
+		},
+		{
+			name: "removed allowed domain",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-mail")["value"] = map[string]any{"allowedDomains": []any{"b.com"}}
+			},
+			path: "questions.q-mail", field: "value.allowedDomains", kind: ChangeModified, breaking: true,
+		},
+		{
+			name:   "became required",
+			modify: func(m map[string]any) { questionValue(m, "q-city")["required"] = true },
+			path:   "questions.q-city", field: "required", kind: ChangeModified, breaking: true,
+		},
+		{
+			name:   "label change",
+			modify: func(m map[string]any) { questionValue(m, "q-city")["label"] = "Town" },
+			path:   "questions.q-city", field: "label", kind: ChangeModified, breaking: false,
+		},
+		{
+			name: "dependsOn on optional question",
+			modify: func(m map[string]any) {
+				questionValue(m, "q-city")["dependsOn"] = []any{[]any{map[string]any{"questionNameId": "q-pets", "optionNameId": "dog"}}}
+			},
+			path: "questions.q-city", field: "dependsOn", kind: ChangeModified, breaking: false,
+		},
+		{
+			name: "question moved into repeat group",
+			modify: func(m map[string]any) {
+				groups := m["groups"].(map[string]any)
+				groups["grp-main"].(map[string]any)["questionsIds"] = []any{"q-pets", "q-rooms", "q-mail"}
+				groups["grp-members"].(map[string]any)["questionsIds"] = []any{"q-name", "q-city"}
+			},
+			path: "questions.q-city", field: "group", kind: ChangeModified, breaking: true,
+		},
+		{
+			name: "removed question",
+			modify: func(m map[string]any) {
+				delete(m["questions"].(map[string]any), "q-mail")
+				m["groups"].(map[string]any)["grp-main"].(map[string]any)["questionsIds"] = []any{"q-city", "q-pets", "q-rooms"}
+			},
+			path: "questions.q-mail", kind: ChangeRemoved, breaking: true,
+		},
+		{
+			name: "added required question",
+			modify: func(m map[string]any) {
+				m["questions"].(map[string]any)["q-age"] = map[string]any{
+					"nameId": "q-age", "visible": true, "required": true, "type": "input_text", "label": "Age", "value": map[string]any{},
+				}
+				m["groups"].(map[string]any)["grp-main"].(map[string]any)["questionsIds"] = []any{"q-city", "q-pets", "q-rooms", "q-mail", "q-age"}
+			},
+			path: "questions.q-age", kind: ChangeAdded, breaking: true,
+		},
+		{
+			name:   "reordered groups",
+			modify: func(m map[string]any) { m["groupsOrder"] = []any{"grp-members", "grp-main"} },
+			path:   "survey", field: "groupsOrder", kind: ChangeReordered, breaking: false,
+		},
+		{
+			name: "repeat flag toggled",
+			modify: func(m map[string]any) {
+				m["groups"].(map[string]any)["grp-members"].(map[string]any)["allowRepeat"] = false
+			},
+			path: "groups.grp-members", field: "allowRepeat", kind: ChangeModified, breaking: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Diff(parseDiffSurvey(t, nil), parseDiffSurvey(t, tt.modify))
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			c := findChange(d, tt.path, tt.field)
+			if c == nil {
+				t.Fatalf("change %s %s not found in:\n%s", tt.path, tt.field, d.Changelog())
+			}
+			if c.Kind != tt.kind {
+				t.Errorf("kind = %s, want %s", c.Kind, tt.kind)
+			}
+			if c.Breaking != tt.breaking {
+				t.Errorf("breaking = %v, want %v (%s)", c.Breaking, tt.breaking, c)
+			}
+			if d.HasBreakingChanges() != (len(d.BreakingChanges()) > 0) {
+				t.Errorf("HasBreakingChanges inconsistent with BreakingChanges")
+			}
+		})
+	}
+}
+
+func TestDiff_Outputs(t *testing.T) {
+	d, err := Diff(parseDiffSurvey(t, nil), parseDiffSurvey(t, func(m map[string]any) {
+		m["version"] = "2"
+		questionValue(m, "q-pets")["value"] = map[string]any{"options": []any{map[string]any{"nameId": "dog", "label": "Doggo"}}}
+	}))
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	log := d.Changelog()
+	for _, want := range []string{
+		"household: 1 -> 2 (2 changes, 1 breaking)",
+		"Breaking changes:\n  - option 'questions.q-pets.options.cat' removed",
+		"Non-breaking changes:\n  - option 'questions.q-pets.options.dog': label changed from 'Dog' to 'Doggo'",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("changelog missing %q:\n%s", want, log)
+		}
+	}
+
+	js, err := d.ToJson()
+	if err != nil {
+		t.Fatalf("ToJson: %v", err)
+	}
+	var decoded SurveyDiff
+	if err := json.Unmarshal([]byte(js), &decoded); err != nil {
+		t.Fatalf("decoding JSON changelog: %v", err)
+	}
+	if decoded.OldVersion != "1" || decoded.NewVersion != "2" || len(decoded.Changes) != 2 {
+		t.Errorf("unexpected JSON changelog: %s", js)
+	}
+}
+
+func TestConstraintChangeReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		qt       types.QuestionType
+		field    string
+		old, new any
+		breaking bool
+	}{
+		{"finer slider step", types.QTypeSlider, "step", 2.0, 1.0, false},
+		{"coarser slider step", types.QTypeSlider, "step", 2.0, 4.0, true},
+		{"misaligned slider step", types.QTypeSlider, "step", 2.0, 3.0, true},
+		{"fewer max files", types.QTypeImage, "maxFiles", 3.0, 2.0, true},
+		{"more max files", types.QTypeImage, "maxFiles", 3.0, 5.0, false},
+		{"more min files", types.QTypeDocument, "minFiles", nil, 1.0, true},
+		{"smaller max size", types.QTypeVideo, "maxSize", 2048.0, 1024.0, true},
+		{"removed content type", types.QTypeAudio, "allowedContentTypes", []any{"audio/mpeg", "audio/ogg"}, []any{"audio/mpeg"}, true},
+		{"added content type", types.QTypeAudio, "allowedContentTypes", []any{"audio/mpeg"}, []any{"audio/mpeg", "audio/ogg"}, false},
+		{"date type", types.QTypeDateTime, "type", "date", "datetime", true},
+		{"slider unit", types.QTypeSlider, "unit", "rooms", "bedrooms", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason := constraintChangeReason(tt.qt, tt.field, tt.old, tt.new)
+			if (reason != "") != tt.breaking {
+				t.Errorf("reason = %q, want breaking %v", reason, tt.breaking)
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+package surveygo
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// jsonSchemaDialect is the JSON Schema dialect of the generated schemas.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// DefinitionJSONSchema returns a JSON Schema (draft 2020-12) describing the survey definition format,
+// including the shape of each question value, selected by the question type.
+// The schema covers the structural validations of ParseFromBytes. Cross-reference checks (e.g. groups
+// referencing existing questions) are only performed by ValidateSurvey.
+func DefinitionJSONSchema() map[string]any {
+	nameId := map[string]any{"$ref": "#/$defs/nameId"}
+	nameIdList := map[string]any{"type": "array", "items": nameId}
+
+	return map[string]any{
+		"$schema":     jsonSchemaDialect,
+		"$id":         "https://github.com/rendis/surveygo/v2/survey.schema.json",
+		"title":       "Survey",
+		"description": "surveygo survey definition",
+		"type":        "object",
+		"required":    []string{"nameId", "title", "version", "questions", "groups", "groupsOrder"},
+		"properties": map[string]any{
+			"nameId":      nameId,
+			"title":       map[string]any{"type": "string", "minLength": 1},
+			"version":     map[string]any{"type": "string", "minLength": 1},
+			"description": map[string]any{"type": "string"},
+			"questions": map[string]any{
+				"description":          "questions keyed by their nameId",
+				"type":                 "object",
+				"additionalProperties": map[string]any{"$ref": "#/$defs/question"},
+			},
+			"groups": map[string]any{
+				"description":          "groups keyed by their nameId",
+				"type":                 "object",
+				"additionalProperties": map[string]any{"$ref": "#/$defs/group"},
+			},
+			"groupsOrder": nameIdList,
+			"metadata":    map[string]any{"type": "object"},
+		},
+		"$defs": map[string]any{
+			"nameId": map[string]any{
+				"type":    "string",
+				"pattern": questionNameIdRegexValidator.String(),
+			},
+			"dependsOn": map[string]any{
+				"description": "outer list is evaluated as OR, inner lists as AND",
+				"type":        "array",
+				"items": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type":     "object",
+						"required": []string{"questionNameId", "optionNameId"},
+						"properties": map[string]any{
+							"questionNameId": nameId,
+							"optionNameId":   nameId,
+						},
+					},
+				},
+			},
+			"group":    groupDefinitionSchema(nameId, nameIdList),
+			"question": questionDefinitionSchema(nameId),
+
+			"option": map[string]any{
+				"type":     "object",
+				"required": []string{"nameId", "label"},
+				"properties": map[string]any{
+					"nameId":    nameId,
+					"label":     map[string]any{"type": "string", "minLength": 1},
+					"value":     map[string]any{},
+					"groupsIds": nameIdList,
+					"metadata":  map[string]any{"type": "object"},
+				},
+			},
+
+			"choiceValue": valueSchema([]string{"options"}, map[string]any{
+				"options": map[string]any{"type": "array", "minItems": 1, "items": map[string]any{"$ref": "#/$defs/option"}},
+			}),
+			"sliderValue": valueSchema([]string{"min", "max", "step"}, map[string]any{
+				"min":     map[string]any{"type": "integer"},
+				"max":     map[string]any{"type": "integer"},
+				"step":    map[string]any{"type": "integer", "minimum": 1},
+				"default": map[string]any{"type": "integer"},
+				"unit":    map[string]any{"type": "string", "minLength": 1},
+			}),
+			"freeTextValue": valueSchema(nil, map[string]any{
+				"min": map[string]any{"type": "integer", "minimum": 0, "description": "minimum answer length"},
+				"max": map[string]any{"type": "integer", "minimum": 0, "description": "maximum answer length"},
+			}),
+			"emailValue": valueSchema(nil, map[string]any{
+				"allowedDomains": map[string]any{"type": "array", "items": map[string]any{"type": "string", "minLength": 1}},
+			}),
+			"telephoneValue": valueSchema(nil, map[string]any{
+				"allowedCountryCodes": map[string]any{"type": "array", "items": map[string]any{"type": "string", "minLength": 1}},
+			}),
+			"informationValue": valueSchema([]string{"text"}, map[string]any{
+				"text": map[string]any{"type": "string", "minLength": 1},
+			}),
+			"identificationNumberValue": valueSchema(nil, nil),
+			"dateTimeValue": valueSchema([]string{"format", "type"}, map[string]any{
+				"format": map[string]any{"type": "string", "minLength": 1, "description": "Go time layout, e.g. 2006-01-02"},
+				"type": map[string]any{"enum": []string{
+					string(text.DateTypeFormatDate), string(text.DateTypeFormatTime), string(text.DateTypeFormatDateTime),
+				}},
+			}),
+			"externalQuestionValue": valueSchema([]string{"externalType"}, map[string]any{
+				"externalType": map[string]any{"type": "string", "minLength": 1},
+				"description":  map[string]any{"type": "string"},
+				"src":          map[string]any{"type": "string"},
+			}),
+			"imageValue": assetValueSchema(map[string]any{
+				"altText": map[string]any{"type": "string", "maxLength": 255},
+			}),
+			"videoValue": assetValueSchema(map[string]any{
+				"caption": map[string]any{"type": "string", "maxLength": 255},
+			}),
+			"audioValue": assetValueSchema(map[string]any{
+				"caption": map[string]any{"type": "string", "maxLength": 255},
+			}),
+			"documentValue": assetValueSchema(map[string]any{
+				"caption": map[string]any{"type": "string", "maxLength": 255},
+			}),
+		},
+	}
+}
+
+// questionValueDefs maps each question type to the definition of its value.
+var questionValueDefs = []struct {
+	types []types.QuestionType
+	def   string
+}{
+	// toggles are parsed as choices, see question.Question.UnmarshalJSON
+	{[]types.QuestionType{types.QTypeSingleSelect, types.QTypeMultipleSelect, types.QTypeRadio, types.QTypeCheckbox, types.QTypeToggle}, "choiceValue"},
+	{[]types.QuestionType{types.QTypeSlider}, "sliderValue"},
+	{[]types.QuestionType{types.QTypeTextArea, types.QTypeInputText}, "freeTextValue"},
+	{[]types.QuestionType{types.QTypeEmail}, "emailValue"},
+	{[]types.QuestionType{types.QTypeTelephone}, "telephoneValue"},
+	{[]types.QuestionType{types.QTypeInformation}, "informationValue"},
+	{[]types.QuestionType{types.QTypeIdentificationNumber}, "identificationNumberValue"},
+	{[]types.QuestionType{types.QTypeDateTime}, "dateTimeValue"},
+	{[]types.QuestionType{types.QTypeExternalQuestion}, "externalQuestionValue"},
+	{[]types.QuestionType{types.QTypeImage}, "imageValue"},
+	{[]types.QuestionType{types.QTypeVideo}, "videoValue"},
+	{[]types.QuestionType{types.QTypeAudio}, "audioValue"},
+	{[]types.QuestionType{types.QTypeDocument}, "documentValue"},
+}
+
+// questionDefinitionSchema returns the schema of a question, selecting the value shape by type.
+func questionDefinitionSchema(nameId map[string]any) map[string]any {
+	var allTypes []string
+	var conditions []any
+	for _, d := range questionValueDefs {
+		var names []string
+		for _, t := range d.types {
+			names = append(names, string(t))
+		}
+		allTypes = append(allTypes, names...)
+		conditions = append(conditions, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"type": map[string]any{"enum": names}},
+				"required":   []string{"type"},
+			},
+			"then": map[string]any{
+				"properties": map[string]any{"value": map[string]any{"$ref": "#/$defs/" + d.def}},
+			},
+		})
+	}
+
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"nameId", "type", "value"},
+		"properties": map[string]any{
+			"nameId":     nameId,
+			"visible":    map[string]any{"type": "boolean"},
+			"type":       map[string]any{"enum": allTypes},
+			"label":      map[string]any{"type": "string", "minLength": 1},
+			"required":   map[string]any{"type": "boolean"},
+			"metadata":   map[string]any{"type": "object"},
+			"position":   map[string]any{"type": "integer", "minimum": 1, "description": "calculated automatically"},
+			"disabled":   map[string]any{"type": "boolean"},
+			"dependsOn":  map[string]any{"$ref": "#/$defs/dependsOn"},
+			"answerExpr": map[string]any{"type": "string", "description": "expr-lang expression for custom answer processing"},
+			"value":      map[string]any{"type": "object"},
+		},
+		"allOf": conditions,
+	}
+}
+
+// groupDefinitionSchema returns the schema of a group.
+func groupDefinitionSchema(nameId, nameIdList map[string]any) map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []string{"nameId"},
+		"properties": map[string]any{
+			"nameId":           nameId,
+			"title":            map[string]any{"type": "string"},
+			"description":      map[string]any{"type": "string"},
+			"hidden":           map[string]any{"type": "boolean"},
+			"disabled":         map[string]any{"type": "boolean"},
+			"isExternalSurvey": map[string]any{"type": "boolean"},
+			"allowRepeat":      map[string]any{"type": "boolean"},
+			"questionsIds":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"groupsOrder":      nameIdList,
+			"metadata":         map[string]any{"type": "object"},
+			"position":         map[string]any{"type": "integer", "minimum": 1, "description": "calculated automatically"},
+			"dependsOn":        map[string]any{"$ref": "#/$defs/dependsOn"},
+		},
+		"if": map[string]any{
+			"properties": map[string]any{"isExternalSurvey": map[string]any{"const": true}},
+			"required":   []string{"isExternalSurvey"},
+		},
+		"then": map[string]any{
+			"properties": map[string]any{"questionsIds": map[string]any{"minItems": 1, "maxItems": 1}},
+			"required":   []string{"questionsIds"},
+		},
+	}
+}
+
+// valueSchema returns the schema of a question value with the common fields of types.QBase.
+func valueSchema(required []string, properties map[string]any) map[string]any {
+	props := map[string]any{
+		"placeholder": map[string]any{"type": "string", "minLength": 1},
+		"metadata":    map[string]any{"type": "object"},
+		"collapsible": map[string]any{"type": "boolean"},
+		"collapsed":   map[string]any{"type": "boolean"},
+		"color":       map[string]any{"type": "string", "minLength": 1},
+		"defaults":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	}
+	for k, v := range properties {
+		props[k] = v
+	}
+
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// assetValueSchema returns the schema of an asset question value.
+func assetValueSchema(properties map[string]any) map[string]any {
+	props := map[string]any{
+		"tags":                map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"maxSize":             map[string]any{"type": "integer", "exclusiveMinimum": 0},
+		"allowedContentTypes": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		"maxFiles":            map[string]any{"type": "integer", "minimum": 1},
+		"minFiles":            map[string]any{"type": "integer", "minimum": 0},
+	}
+	for k, v := range properties {
+		props[k] = v
+	}
+	return valueSchema(nil, props)
+}
+
+// AnswersJSONSchema returns a JSON Schema (draft 2020-12) for the Answers of this survey.
+// Each question is an array of answers constrained by its type (option enums, text lengths, slider range, etc.),
+// repeatable groups are arrays of objects holding the answers of their instances, and unknown keys are rejected.
+// A question is listed as required only when it is required, visible and always shown: neither the question
+// nor any of its enclosing groups are hidden, disabled or depend on other answers.
+func (s *Survey) AnswersJSONSchema() map[string]any {
+	b := &answersSchemaBuilder{survey: s, visited: map[string]bool{}, optionOpened: map[string]bool{}}
+	for _, g := range s.Groups {
+		for _, groupNameId := range s.optionGroups(g) {
+			b.optionOpened[groupNameId] = true
+		}
+	}
+
+	root := b.newObject()
+	for _, groupNameId := range s.GroupsOrder {
+		b.addGroup(root, groupNameId, true)
+	}
+
+	// groups not reachable from GroupsOrder are answered at the top level
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		b.addGroup(root, groupNameId, true)
+	}
+
+	schema := root.schema()
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = s.Title
+	if s.NameId != "" {
+		schema["$id"] = "urn:surveygo:" + s.NameId + ":" + s.Version + ":answers"
+	}
+	return schema
+}
+
+// answersSchemaObject is an object schema under construction.
+type answersSchemaObject struct {
+	properties map[string]any
+	required   []string
+}
+
+func (o *answersSchemaObject) schema() map[string]any {
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           o.properties,
+		"additionalProperties": false,
+	}
+	if len(o.required) > 0 {
+		schema["required"] = o.required
+	}
+	return schema
+}
+
+// answersSchemaBuilder builds the answers schema following the group tree of a survey.
+type answersSchemaBuilder struct {
+	survey       *Survey
+	visited      map[string]bool
+	optionOpened map[string]bool // groups opened by a choice option
+}
+
+func (b *answersSchemaBuilder) newObject() *answersSchemaObject {
+	return &answersSchemaObject{properties: map[string]any{}}
+}
+
+// addGroup adds the answers of a group and its subgroups to the object.
+// Repeatable groups become an array property holding an object per instance.
+func (b *answersSchemaBuilder) addGroup(obj *answersSchemaObject, groupNameId string, alwaysShown bool) {
+	g, ok := b.survey.Groups[groupNameId]
+	if !ok || b.visited[groupNameId] {
+		return
+	}
+	b.visited[groupNameId] = true
+
+	alwaysShown = alwaysShown && !g.Hidden && !g.Disabled && len(g.DependsOn) == 0 && !b.optionOpened[groupNameId]
+
+	target := obj
+	if g.AllowRepeat {
+		target = b.newObject()
+	}
+
+	if !g.IsExternalSurvey {
+		for _, questionNameId := range g.QuestionsIds {
+			q, ok := b.survey.Questions[questionNameId]
+			if !ok {
+				continue
+			}
+			target.properties[q.NameId] = questionAnswersSchema(q)
+			if alwaysShown && q.Required && q.Visible && !q.Disabled && len(q.DependsOn) == 0 {
+				target.required = append(target.required, q.NameId)
+			}
+		}
+	}
+
+	// subgroups in the group order follow the group, groups opened by an option are conditional
+	for _, child := range g.GroupsOrder {
+		b.addGroup(target, child, alwaysShown)
+	}
+	for _, child := range b.survey.optionGroups(g) {
+		b.addGroup(target, child, false)
+	}
+
+	if !g.AllowRepeat {
+		return
+	}
+
+	groupSchema := map[string]any{
+		"type":  "array",
+		"items": target.schema(),
+	}
+	if g.Title != nil {
+		groupSchema["title"] = *g.Title
+	}
+	obj.properties[g.NameId] = groupSchema
+}
+
+// optionGroups returns the groups opened by the options of the choice questions of a group.
+func (s *Survey) optionGroups(g *question.Group) []string {
+	var res []string
+	for _, questionNameId := range g.QuestionsIds {
+		q, ok := s.Questions[questionNameId]
+		if !ok || !types.IsSimpleChoiceType(q.QTyp) {
+			continue
+		}
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
+		for _, o := range c.Options {
+			res = append(res, o.GroupsIds...)
+		}
+	}
+	return res
+}
+
+// questionAnswersSchema returns the schema of the answers of a question.
+func questionAnswersSchema(q *question.Question) map[string]any {
+	schema := map[string]any{"type": "array"}
+	if q.Label != "" {
+		schema["title"] = q.Label
+	}
+
+	single := func() {
+		schema["minItems"] = 1
+		schema["maxItems"] = 1
+	}
+
+	switch q.QTyp {
+	case types.QTypeSingleSelect:
+		schema["items"] = optionsEnumSchema(q)
+		schema["maxItems"] = 1
+	case types.QTypeRadio:
+		schema["items"] = optionsEnumSchema(q)
+		single()
+	case types.QTypeMultipleSelect, types.QTypeCheckbox:
+		schema["items"] = optionsEnumSchema(q)
+	case types.QTypeToggle:
+		schema["items"] = map[string]any{"type": "boolean"}
+		single()
+	case types.QTypeSlider:
+		items := map[string]any{"type": "integer"}
+		if sl, err := choice.CastToSlider(q.Value); err == nil {
+			items["minimum"] = sl.Min
+			items["maximum"] = sl.Max
+		}
+		schema["items"] = items
+		single()
+	case types.QTypeTextArea, types.QTypeInputText:
+		items := map[string]any{"type": "string"}
+		if ft, err := text.CastToFreeText(q.Value); err == nil {
+			if ft.Min != nil {
+				items["minLength"] = *ft.Min
+			}
+			if ft.Max != nil {
+				items["maxLength"] = *ft.Max
+			}
+		}
+		schema["items"] = items
+		single()
+	case types.QTypeEmail:
+		items := map[string]any{"type": "string", "format": "email"}
+		if e, err := text.CastToEmail(q.Value); err == nil && len(e.AllowedDomains) > 0 {
+			items["pattern"] = suffixPattern(e.AllowedDomains)
+		}
+		schema["items"] = items
+		single()
+	case types.QTypeTelephone:
+		schema["items"] = map[string]any{"type": []string{"string", "integer"}}
+		schema["minItems"] = 1
+		schema["maxItems"] = 2
+		schema["description"] = "[phone number] or [country code, phone number]"
+		if t, err := text.CastToTelephone(q.Value); err == nil && len(t.AllowedCountryCodes) > 0 {
+			schema["prefixItems"] = []any{map[string]any{"type": "string", "pattern": prefixPattern(t.AllowedCountryCodes)}}
+		}
+	case types.QTypeDateTime:
+		items := map[string]any{"type": "string"}
+		if dt, err := text.CastToDateTime(q.Value); err == nil {
+			items["description"] = "Go time layout '" + dt.Format + "'"
+		}
+		schema["items"] = items
+		single()
+	case types.QTypeInformation, types.QTypeIdentificationNumber:
+		schema["maxItems"] = 1
+	}
+
+	return schema
+}
+
+// optionsEnumSchema returns a string schema restricted to the option name ids of a choice question.
+func optionsEnumSchema(q *question.Question) map[string]any {
+	items := map[string]any{"type": "string"}
+	c, err := choice.CastToChoice(q.Value)
+	if err != nil {
+		return items
+	}
+
+	var ids []string
+	for _, o := range c.Options {
+		ids = append(ids, o.NameId)
+	}
+	items["enum"] = ids
+	return items
+}
+
+// suffixPattern returns a regular expression matching strings ending with any of the given suffixes.
+func suffixPattern(suffixes []string) string {
+	quoted := make([]string, 0, len(suffixes))
+	for _, s := range suffixes {
+		quoted = append(quoted, regexp.QuoteMeta(s))
+	}
+	return "(" + strings.Join(quoted, "|") + ")$"
+}
+
+// prefixPattern returns a regular expression matching strings starting with any of the given prefixes.
+func prefixPattern(prefixes []string) string {
+	quoted := make([]string, 0, len(prefixes))
+	for _, p := range prefixes {
+		quoted = append(quoted, regexp.QuoteMeta(p))
+	}
+	return "^(" + strings.Join(quoted, "|") + ")"
+}
@@ -0,0 +1,208 @@
+package surveygo
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+func compileSchema(t *testing.T, schema map[string]any) *jsonschema.Schema {
+	t.Helper()
+	// round trip through JSON so the compiler sees plain JSON values
+	b, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("marshalling schema: %v", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytesReader(b))
+	if err != nil {
+		t.Fatalf("unmarshalling schema: %v", err)
+	}
+
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource("schema.json", doc); err != nil {
+		t.Fatalf("adding schema: %v", err)
+	}
+	sch, err := c.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("compiling schema: %v", err)
+	}
+	return sch
+}
+
+func validateJSON(sch *jsonschema.Schema, data string) error {
+	v, err := jsonschema.UnmarshalJSON(bytesReader([]byte(data)))
+	if err != nil {
+		return err
+	}
+	return sch.Validate(v)
+}
+
+func TestDefinitionJSONSchema(t *testing.T) {
+	sch := compileSchema(t, DefinitionJSONSchema())
+
+	files, _ := filepath.Glob(filepath.Join("render", "testdata", "sample*.json"))
+	files = append(files, filepath.Join("store", "mongo", "testdata", "all_types.json"))
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("reading %s: %v", f, err)
+		}
+		if _, err := ParseFromBytes(data); err != nil {
+			continue // answers files
+		}
+		if err := validateJSON(sch, string(data)); err != nil {
+			t.Errorf("%s does not match the definition schema: %v", f, err)
+		}
+	}
+
+	toggle := `{"nameId":"news","title":"t","version":"1","groups":{},"groupsOrder":[],
+		"questions":{"subscribe":{"nameId":"subscribe","type":"toggle","label":"Subscribe?",
+			"value":{"options":[{"nameId":"subscribe-on","label":"On"},{"nameId":"subscribe-off","label":"Off"}]}}}}`
+	if err := validateJSON(sch, toggle); err != nil {
+		t.Errorf("toggle with options does not match the definition schema: %v", err)
+	}
+
+	invalid := map[string]string{
+		"missing title": `{"nameId":"s1","version":"1","questions":{},"groups":{},"groupsOrder":[]}`,
+		"bad nameId":    `{"nameId":"1s","title":"t","version":"1","questions":{},"groups":{},"groupsOrder":[]}`,
+		"unknown type": `{"nameId":"s1","title":"t","version":"1","groups":{},"groupsOrder":[],
+			"questions":{"q1":{"nameId":"q1","type":"nope","value":{}}}}`,
+		"choice without options": `{"nameId":"s1","title":"t","version":"1","groups":{},"groupsOrder":[],
+			"questions":{"q1":{"nameId":"q1","type":"radio","value":{}}}}`,
+		"toggle without options": `{"nameId":"s1","title":"t","version":"1","groups":{},"groupsOrder":[],
+			"questions":{"q1":{"nameId":"q1","type":"toggle","value":{"onLabel":"On","offLabel":"Off"}}}}`,
+		"slider without step": `{"nameId":"s1","title":"t","version":"1","groups":{},"groupsOrder":[],
+			"questions":{"q1":{"nameId":"q1","type":"slider","value":{"min":1,"max":2}}}}`,
+		"bad date type": `{"nameId":"s1","title":"t","version":"1","groups":{},"groupsOrder":[],
+			"questions":{"q1":{"nameId":"q1","type":"date_time","value":{"format":"2006","type":"year"}}}}`,
+	}
+	for name, data := range invalid {
+		if err := validateJSON(sch, data); err == nil {
+			t.Errorf("%s: expected a schema validation error", name)
+		}
+	}
+}
+
+const answersSchemaSurvey = `{
+  "nameId": "household", "title": "Household", "version": "1",
+  "groupsOrder": ["grp-main", "grp-members"],
+  "groups": {
+    "grp-main": {"nameId": "grp-main", "questionsIds": ["q-city", "q-pets", "q-mail", "q-rooms", "q-owner"]},
+    "grp-members": {"nameId": "grp-members", "allowRepeat": true, "questionsIds": ["q-name"], "groupsOrder": ["grp-jobs"]},
+    "grp-jobs": {"nameId": "grp-jobs", "allowRepeat": true, "questionsIds": ["q-job"]},
+    "grp-dog": {"nameId": "grp-dog", "questionsIds": ["q-dog-name"]}
+  },
+  "questions": {
+    "q-city": {"nameId": "q-city", "visible": true, "required": true, "type": "input_text", "label": "City", "value": {"min": 2, "max": 20}},
+    "q-pets": {"nameId": "q-pets", "visible": true, "type": "checkbox", "label": "Pets",
+      "value": {"options": [{"nameId": "dog", "label": "Dog", "groupsIds": ["grp-dog"]}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-mail": {"nameId": "q-mail", "visible": true, "type": "email", "label": "Mail", "value": {"allowedDomains": ["example.com"]}},
+    "q-rooms": {"nameId": "q-rooms", "visible": true, "type": "slider", "label": "Rooms", "value": {"min": 1, "max": 10, "step": 1}},
+    "q-owner": {"nameId": "q-owner", "visible": true, "type": "toggle", "label": "Owner", "value": {"options": [{"nameId": "owner", "label": "Yes"}]}},
+    "q-name": {"nameId": "q-name", "visible": true, "required": true, "type": "input_text", "label": "Name", "value": {}},
+    "q-job": {"nameId": "q-job", "visible": true, "type": "input_text", "label": "Job", "value": {}},
+    "q-dog-name": {"nameId": "q-dog-name", "visible": true, "required": true, "type": "input_text", "label": "Dog name", "value": {}}
+  }
+}`
+
+func TestAnswersJSONSchema(t *testing.T) {
+	s, err := ParseFromJsonStr(answersSchemaSurvey)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+
+	schema := s.AnswersJSONSchema()
+	if req, _ := schema["required"].([]string); len(req) != 1 || req[0] != "q-city" {
+		t.Errorf("required = %v, want [q-city]", schema["required"])
+	}
+	sch := compileSchema(t, schema)
+
+	valid := []string{
+		`{"q-city": ["Lima"]}`,
+		`{"q-city": ["Lima"], "q-pets": ["dog", "cat"], "q-dog-name": ["Rex"], "q-mail": ["me@example.com"],
+		  "q-rooms": [3], "q-owner": [true],
+		  "grp-members": [{"q-name": ["Ana"], "grp-jobs": [{"q-job": ["Nurse"]}, {}]}]}`,
+	}
+	for _, data := range valid {
+		if err := validateJSON(sch, data); err != nil {
+			t.Errorf("expected valid answers %s: %v", data, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"missing required":         `{"q-pets": ["cat"]}`,
+		"unknown option":           `{"q-city": ["Lima"], "q-pets": ["fish"]}`,
+		"text too short":           `{"q-city": ["L"]}`,
+		"slider out of range":      `{"q-city": ["Lima"], "q-rooms": [11]}`,
+		"toggle not bool":          `{"q-city": ["Lima"], "q-owner": ["yes"]}`,
+		"domain not allowed":       `{"q-city": ["Lima"], "q-mail": ["me@other.com"]}`,
+		"unknown question":         `{"q-city": ["Lima"], "q-nope": ["x"]}`,
+		"repeat instance required": `{"q-city": ["Lima"], "grp-members": [{}]}`,
+		"nested unknown":           `{"q-city": ["Lima"], "grp-members": [{"q-name": ["Ana"], "grp-jobs": [{"q-city": ["x"]}]}]}`,
+	}
+	for name, data := range invalid {
+		if err := validateJSON(sch, data); err == nil {
+			t.Errorf("%s: expected a schema validation error", name)
+		}
+	}
+}
+
+func bytesReader(b []byte) *bytes.Reader {
+	return bytes.NewReader(b)
+}
+
+const schemaReviewerSurvey = `{
+  "nameId": "shop", "title": "Shop", "version": "1",
+  "groupsOrder": ["grp-main"],
+  "groups": {"grp-main": {"nameId": "grp-main", "questionsIds": ["q-size", "q-colors", "q-note"]}},
+  "questions": {
+    "q-size": {"nameId": "q-size", "visible": true, "type": "radio", "label": "Size",
+      "value": {"options": [{"nameId": "small", "label": "S"}, {"nameId": "large", "label": "L"}]}},
+    "q-colors": {"nameId": "q-colors", "visible": true, "type": "checkbox", "label": "Colors",
+      "value": {"options": [{"nameId": "red", "label": "Red"}, {"nameId": "blue", "label": "Blue"}]}},
+    "q-note": {"nameId": "q-note", "visible": true, "type": "input_text", "label": "Note", "value": {"min": 2, "max": 4}}
+  }
+}`
+
+// TestAnswersJSONSchema_MatchesReviewer checks that the answers schema accepts the answers accepted by
+// ReviewAnswers and rejects the others.
+func TestAnswersJSONSchema_MatchesReviewer(t *testing.T) {
+	s, err := ParseFromJsonStr(schemaReviewerSurvey)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+	sch := compileSchema(t, s.AnswersJSONSchema())
+
+	cases := map[string]string{
+		"radio single answer":       `{"q-size": ["small"]}`,
+		"radio two answers":         `{"q-size": ["small", "large"]}`,
+		"radio unknown option":      `{"q-size": ["medium"]}`,
+		"checkbox answers":          `{"q-colors": ["red", "blue"]}`,
+		"checkbox repeated answers": `{"q-colors": ["red", "red"]}`,
+		"checkbox unknown option":   `{"q-colors": ["green"]}`,
+		"text ascii max length":     `{"q-note": ["abcd"]}`,
+		"text ascii too long":       `{"q-note": ["abcde"]}`,
+		"text multibyte max length": `{"q-note": ["ñáéí"]}`,
+		"text multibyte too long":   `{"q-note": ["ñáéíó"]}`,
+		"text multibyte too short":  `{"q-note": ["ñ"]}`,
+	}
+	for name, data := range cases {
+		answers, err := ParseAnswers(bytes.NewReader([]byte(data)))
+		if err != nil {
+			t.Fatalf("%s: ParseAnswers: %v", name, err)
+		}
+		resume, err := s.ReviewAnswers(answers)
+		if err != nil {
+			t.Fatalf("%s: ReviewAnswers: %v", name, err)
+		}
+		reviewerValid := len(resume.InvalidAnswers) == 0
+		schemaErr := validateJSON(sch, data)
+		if reviewerValid != (schemaErr == nil) {
+			t.Errorf("%s: reviewer valid = %v, schema error = %v", name, reviewerValid, schemaErr)
+		}
+	}
+}
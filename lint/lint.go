@@ -0,0 +1,161 @@
+// Package lint reports design smells in survey definitions.
+// Unlike Survey.ValidateSurvey, which rejects inconsistent surveys, the linter reports findings
+// on surveys that are valid but likely to behave unexpectedly (e.g. a group that can never be shown).
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// Severity is the severity of a finding.
+type Severity string
+
+const (
+	// SeverityError the survey will not behave as intended.
+	SeverityError Severity = "error"
+	// SeverityWarning the survey is likely to behave unexpectedly.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo the survey could be improved.
+	SeverityInfo Severity = "info"
+)
+
+// severityRank is used to sort findings, most severe first.
+var severityRank = map[Severity]int{
+	SeverityError:   0,
+	SeverityWarning: 1,
+	SeverityInfo:    2,
+}
+
+// Code identifies the kind of finding.
+type Code string
+
+const (
+	// CodeUnreachableGroup a group is not reachable from the survey groups order,
+	// neither through a groups order nor through the groups opened by an option.
+	CodeUnreachableGroup Code = "unreachable-group"
+	// CodeUnassignedQuestion a question is not assigned to any group.
+	CodeUnassignedQuestion Code = "unassigned-question"
+	// CodeUnsatisfiableDependsOn a DependsOn condition can never be satisfied.
+	CodeUnsatisfiableDependsOn Code = "unsatisfiable-depends-on"
+	// CodeRequiredInHiddenGroup a required question belongs to a hidden group.
+	CodeRequiredInHiddenGroup Code = "required-in-hidden-group"
+	// CodeEmptyGroup a group has neither questions nor subgroups.
+	CodeEmptyGroup Code = "empty-group"
+	// CodeDuplicateLabel two questions of a group, or two options of a question, share a label.
+	CodeDuplicateLabel Code = "duplicate-label"
+	// CodeUnknownDefault a question default references an unknown option.
+	CodeUnknownDefault Code = "unknown-default"
+	// CodeInvalidAnswerExpr a question AnswerExpr does not compile.
+	CodeInvalidAnswerExpr Code = "invalid-answer-expr"
+)
+
+// Location locates a finding in the survey definition.
+type Location struct {
+	// GroupNameId is the name id of the group, if any.
+	GroupNameId string `json:"groupNameId,omitempty"`
+
+	// QuestionNameId is the name id of the question, if any.
+	QuestionNameId string `json:"questionNameId,omitempty"`
+
+	// OptionNameId is the name id of the option, if any.
+	OptionNameId string `json:"optionNameId,omitempty"`
+
+	// Field is the field of the element, if any (e.g. "dependsOn[1]" or "answerExpr").
+	Field string `json:"field,omitempty"`
+}
+
+// String returns a human-readable representation of the location.
+func (l Location) String() string {
+	var parts []string
+	if l.GroupNameId != "" {
+		parts = append(parts, fmt.Sprintf("group '%s'", l.GroupNameId))
+	}
+	if l.QuestionNameId != "" {
+		parts = append(parts, fmt.Sprintf("question '%s'", l.QuestionNameId))
+	}
+	if l.OptionNameId != "" {
+		parts = append(parts, fmt.Sprintf("option '%s'", l.OptionNameId))
+	}
+	if l.Field != "" {
+		parts = append(parts, l.Field)
+	}
+	if len(parts) == 0 {
+		return "survey"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Finding is a single linter finding.
+type Finding struct {
+	// Severity is the severity of the finding.
+	Severity Severity `json:"severity"`
+
+	// Code identifies the kind of finding.
+	Code Code `json:"code"`
+
+	// Location locates the finding in the survey definition.
+	Location Location `json:"location"`
+
+	// Message describes the finding.
+	Message string `json:"message"`
+}
+
+// String returns a one-line human-readable representation of the finding.
+func (f *Finding) String() string {
+	return fmt.Sprintf("%s [%s] %s: %s", f.Severity, f.Code, f.Location, f.Message)
+}
+
+// Rule inspects a survey and returns its findings.
+type Rule func(s *surveygo.Survey) []*Finding
+
+// DefaultRules are the rules run by Lint.
+var DefaultRules = []Rule{
+	UnreachableGroups,
+	UnassignedQuestions,
+	UnsatisfiableDependsOn,
+	RequiredInHiddenGroups,
+	EmptyGroups,
+	DuplicateLabels,
+	UnknownDefaults,
+	InvalidAnswerExprs,
+}
+
+// Lint runs the given rules (DefaultRules if none) over the survey.
+// Findings are sorted by severity, code and location.
+func Lint(s *surveygo.Survey, rules ...Rule) []*Finding {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	var findings []*Finding
+	for _, rule := range rules {
+		findings = append(findings, rule(s)...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if severityRank[a.Severity] != severityRank[b.Severity] {
+			return severityRank[a.Severity] < severityRank[b.Severity]
+		}
+		if a.Code != b.Code {
+			return a.Code < b.Code
+		}
+		return a.Location.String() < b.Location.String()
+	})
+
+	return findings
+}
+
+// HasSeverity returns true if at least one finding has the given severity or a higher one.
+func HasSeverity(findings []*Finding, severity Severity) bool {
+	for _, f := range findings {
+		if severityRank[f.Severity] <= severityRank[severity] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+const smellySurvey = `{
+  "nameId": "smelly", "title": "Smelly", "version": "1",
+  "groupsOrder": ["g-main", "g-hidden", "g-empty"],
+  "groups": {
+    "g-main": {"nameId": "g-main", "questionsIds": ["q-color", "q-pets", "q-owner", "q-a", "q-b", "q-expr", "q-exprok",
+      "q-dep1", "q-dep2", "q-dep3", "q-dep4", "q-def"]},
+    "g-red": {"nameId": "g-red", "questionsIds": ["q-red-note"]},
+    "g-orphan": {"nameId": "g-orphan", "questionsIds": ["q-orphan"]},
+    "g-hidden": {"nameId": "g-hidden", "hidden": true, "questionsIds": ["q-secret"]},
+    "g-empty": {"nameId": "g-empty"}
+  },
+  "questions": {
+    "q-color": {"nameId": "q-color", "visible": true, "type": "radio", "label": "Color",
+      "value": {"options": [{"nameId": "red", "label": "Red", "groupsIds": ["g-red"]}, {"nameId": "blue", "label": "Blue"}]}},
+    "q-pets": {"nameId": "q-pets", "visible": true, "type": "checkbox", "label": "Pets",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-owner": {"nameId": "q-owner", "visible": true, "type": "toggle", "label": "Owner",
+      "value": {"options": [{"nameId": "owner-yes", "label": "Yes"}]}},
+    "q-a": {"nameId": "q-a", "visible": true, "type": "input_text", "label": "Name", "value": {}},
+    "q-b": {"nameId": "q-b", "visible": true, "type": "input_text", "label": " name ", "value": {}},
+    "q-expr": {"nameId": "q-expr", "visible": true, "type": "input_text", "label": "Expr", "answerExpr": "ans[0] +", "value": {}},
+    "q-exprok": {"nameId": "q-exprok", "visible": true, "type": "radio", "label": "Expr ok", "answerExpr": "options[ans[0]]",
+      "value": {"options": [{"nameId": "opt-x1", "label": "Same"}, {"nameId": "opt-x2", "label": "same"}]}},
+    "q-dep1": {"nameId": "q-dep1", "visible": true, "type": "input_text", "label": "Dep 1", "value": {},
+      "dependsOn": [[{"questionNameId": "q-color", "optionNameId": "red"}, {"questionNameId": "q-color", "optionNameId": "blue"}]]},
+    "q-dep2": {"nameId": "q-dep2", "visible": true, "type": "input_text", "label": "Dep 2", "value": {},
+      "dependsOn": [[{"questionNameId": "q-color", "optionNameId": "red"}, {"questionNameId": "q-color", "optionNameId": "blue"}],
+                    [{"questionNameId": "q-pets", "optionNameId": "dog"}]]},
+    "q-dep3": {"nameId": "q-dep3", "visible": true, "type": "input_text", "label": "Dep 3", "value": {},
+      "dependsOn": [[{"questionNameId": "q-pets", "optionNameId": "dog"}, {"questionNameId": "q-pets", "optionNameId": "cat"}]]},
+    "q-dep4": {"nameId": "q-dep4", "visible": true, "type": "input_text", "label": "Dep 4", "value": {},
+      "dependsOn": [[{"questionNameId": "q-owner", "optionNameId": "owner-yes"}]]},
+    "q-def": {"nameId": "q-def", "visible": true, "type": "single_select", "label": "Def",
+      "value": {"defaults": ["opt-s1", "zzz"], "options": [{"nameId": "opt-s1", "label": "S1"}]}},
+    "q-red-note": {"nameId": "q-red-note", "visible": true, "type": "input_text", "label": "Why red", "value": {}},
+    "q-orphan": {"nameId": "q-orphan", "visible": true, "type": "input_text", "label": "Orphan", "value": {}},
+    "q-secret": {"nameId": "q-secret", "visible": true, "required": true, "type": "input_text", "label": "Secret", "value": {}},
+    "q-loose": {"nameId": "q-loose", "visible": true, "type": "input_text", "label": "Loose", "value": {}}
+  }
+}`
+
+func TestLint(t *testing.T) {
+	s, err := surveygo.ParseFromJsonStr(smellySurvey)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+
+	findings := Lint(s)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+
+	want := []string{
+		"error [invalid-answer-expr] question 'q-expr' answerExpr: expression does not compile",
+		"error [unknown-default] question 'q-def' defaults[1]: default 'zzz' is not an option of the question",
+		"error [unsatisfiable-depends-on] question 'q-dep1' dependsOn[0]: options 'red' and 'blue' of single answer question 'q-color' are mutually exclusive, the element can never be shown",
+		"error [unsatisfiable-depends-on] question 'q-dep4' dependsOn[0]: question 'q-owner' is of type 'toggle', its answers never match option 'owner-yes', the element can never be shown",
+		"warning [duplicate-label] question 'q-exprok' option 'opt-x2' label: label 'same' is also used by option 'opt-x1'",
+		"warning [empty-group] group 'g-empty': group has no questions and no subgroups",
+		"warning [required-in-hidden-group] group 'g-hidden' question 'q-secret': required question belongs to a hidden group",
+		"warning [unassigned-question] question 'q-loose': question is not assigned to any group",
+		"warning [unreachable-group] group 'g-orphan': group is not reachable from the survey groups order, any group order or any option",
+		"warning [unsatisfiable-depends-on] question 'q-dep2' dependsOn[0]: options 'red' and 'blue' of single answer question 'q-color' are mutually exclusive",
+		"info [duplicate-label] group 'g-main' question 'q-b' label: label ' name ' is also used by question 'q-a'",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("finding %d:\n got: %s\nwant: %s", i, got[i], want[i])
+		}
+	}
+
+	if !HasSeverity(findings, SeverityError) {
+		t.Error("HasSeverity(error) = false, want true")
+	}
+}
+
+func TestLint_CleanSurvey(t *testing.T) {
+	s, err := surveygo.ParseFromJsonStr(`{
+	  "nameId": "clean", "title": "Clean", "version": "1",
+	  "groupsOrder": ["g-one"],
+	  "groups": {"g-one": {"nameId": "g-one", "questionsIds": ["q-one", "q-two"]}},
+	  "questions": {
+	    "q-one": {"nameId": "q-one", "visible": true, "type": "radio", "label": "Q1", "answerExpr": "options[ans[0]]",
+	      "value": {"defaults": ["opt-one"], "options": [{"nameId": "opt-one", "label": "One"}, {"nameId": "opt-two", "label": "Two"}]}},
+	    "q-two": {"nameId": "q-two", "visible": true, "type": "input_text", "label": "Q2", "value": {},
+	      "dependsOn": [[{"questionNameId": "q-one", "optionNameId": "opt-one"}]]}
+	  }
+	}`)
+	if err != nil {
+		t.Fatalf("ParseFromJsonStr: %v", err)
+	}
+
+	findings := Lint(s)
+	if len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+	if HasSeverity(findings, SeverityInfo) {
+		t.Error("HasSeverity(info) = true, want false")
+	}
+}
@@ -0,0 +1,345 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/expr-lang/expr"
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+)
+
+// UnreachableGroups reports groups that can never be shown: groups that are not reachable from the
+// survey groups order, neither through the groups order of a group nor through the groups opened by an option.
+func UnreachableGroups(s *surveygo.Survey) []*Finding {
+	reached := make(map[string]bool)
+
+	var visit func(groupNameId string)
+	visit = func(groupNameId string) {
+		g, ok := s.Groups[groupNameId]
+		if !ok || reached[groupNameId] {
+			return
+		}
+		reached[groupNameId] = true
+
+		for _, child := range g.GroupsOrder {
+			visit(child)
+		}
+		for _, questionNameId := range g.QuestionsIds {
+			for _, o := range simpleChoiceOptions(s, questionNameId) {
+				for _, child := range o.GroupsIds {
+					visit(child)
+				}
+			}
+		}
+	}
+
+	for _, groupNameId := range s.GroupsOrder {
+		visit(groupNameId)
+	}
+
+	var findings []*Finding
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		if reached[groupNameId] {
+			continue
+		}
+		findings = append(findings, &Finding{
+			Severity: SeverityWarning,
+			Code:     CodeUnreachableGroup,
+			Location: Location{GroupNameId: groupNameId},
+			Message:  "group is not reachable from the survey groups order, any group order or any option",
+		})
+	}
+	return findings
+}
+
+// UnassignedQuestions reports questions that do not belong to any group.
+func UnassignedQuestions(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+	assignments := s.GetQuestionsAssignments()
+	for _, questionNameId := range sortedKeys(assignments) {
+		if assignments[questionNameId] != "" {
+			continue
+		}
+		findings = append(findings, &Finding{
+			Severity: SeverityWarning,
+			Code:     CodeUnassignedQuestion,
+			Location: Location{QuestionNameId: questionNameId},
+			Message:  "question is not assigned to any group",
+		})
+	}
+	return findings
+}
+
+// UnsatisfiableDependsOn reports DependsOn conditions that can never be satisfied:
+//   - AND groups requiring two different options of the same single answer question (single_select, radio)
+//   - conditions on questions whose answers are not option name ids (e.g. toggle or slider)
+//
+// It is an error when no OR group can be satisfied, and a warning when only some of them cannot.
+func UnsatisfiableDependsOn(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+
+	for _, questionNameId := range sortedKeys(s.Questions) {
+		q := s.Questions[questionNameId]
+		findings = append(findings, unsatisfiableDependsOn(s, q.DependsOn, Location{QuestionNameId: questionNameId})...)
+	}
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		g := s.Groups[groupNameId]
+		findings = append(findings, unsatisfiableDependsOn(s, g.DependsOn, Location{GroupNameId: groupNameId})...)
+	}
+
+	return findings
+}
+
+func unsatisfiableDependsOn(s *surveygo.Survey, dependsOn [][]question.DependsOn, loc Location) []*Finding {
+	var findings []*Finding
+
+	unsatisfiable := 0
+	for orIdx, andGroup := range dependsOn {
+		reason := unsatisfiableReason(s, andGroup)
+		if reason == "" {
+			continue
+		}
+		unsatisfiable++
+
+		l := loc
+		l.Field = fmt.Sprintf("dependsOn[%d]", orIdx)
+		findings = append(findings, &Finding{
+			Severity: SeverityWarning,
+			Code:     CodeUnsatisfiableDependsOn,
+			Location: l,
+			Message:  reason,
+		})
+	}
+
+	// no OR group can be satisfied: the element is never shown
+	if unsatisfiable > 0 && unsatisfiable == len(dependsOn) {
+		for _, f := range findings {
+			f.Severity = SeverityError
+			f.Message += ", the element can never be shown"
+		}
+	}
+
+	return findings
+}
+
+// unsatisfiableReason returns why an AND group can never be satisfied, or an empty string if it can.
+func unsatisfiableReason(s *surveygo.Survey, andGroup []question.DependsOn) string {
+	selected := make(map[string]string) // key: question name id, value: required option name id
+
+	for _, dep := range andGroup {
+		q, ok := s.Questions[dep.QuestionNameId]
+		if !ok {
+			continue // reported by ValidateSurvey
+		}
+
+		if !types.IsSimpleChoiceType(q.QTyp) {
+			return fmt.Sprintf("question '%s' is of type '%s', its answers never match option '%s'", q.NameId, q.QTyp, dep.OptionNameId)
+		}
+
+		if q.QTyp != types.QTypeSingleSelect && q.QTyp != types.QTypeRadio {
+			continue
+		}
+
+		if other, ok := selected[q.NameId]; ok && other != dep.OptionNameId {
+			return fmt.Sprintf("options '%s' and '%s' of single answer question '%s' are mutually exclusive", other, dep.OptionNameId, q.NameId)
+		}
+		selected[q.NameId] = dep.OptionNameId
+	}
+
+	return ""
+}
+
+// RequiredInHiddenGroups reports required questions that belong to a hidden group.
+// Hidden groups are never active, so their questions can never be answered.
+func RequiredInHiddenGroups(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		g := s.Groups[groupNameId]
+		if !g.Hidden || g.IsExternalSurvey {
+			continue
+		}
+		for _, questionNameId := range g.QuestionsIds {
+			q, ok := s.Questions[questionNameId]
+			if !ok || !q.Required {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Severity: SeverityWarning,
+				Code:     CodeRequiredInHiddenGroup,
+				Location: Location{GroupNameId: groupNameId, QuestionNameId: questionNameId},
+				Message:  "required question belongs to a hidden group",
+			})
+		}
+	}
+	return findings
+}
+
+// EmptyGroups reports groups without questions and without subgroups.
+func EmptyGroups(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		g := s.Groups[groupNameId]
+		if len(g.QuestionsIds) > 0 || len(g.GroupsOrder) > 0 {
+			continue
+		}
+		findings = append(findings, &Finding{
+			Severity: SeverityWarning,
+			Code:     CodeEmptyGroup,
+			Location: Location{GroupNameId: groupNameId},
+			Message:  "group has no questions and no subgroups",
+		})
+	}
+	return findings
+}
+
+// DuplicateLabels reports questions of the same group, and options of the same question, sharing a label.
+// Labels are compared ignoring case and surrounding spaces.
+func DuplicateLabels(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+
+	for _, groupNameId := range sortedKeys(s.Groups) {
+		g := s.Groups[groupNameId]
+		if g.IsExternalSurvey {
+			continue
+		}
+		seen := make(map[string]string) // key: normalized label, value: question name id
+		for _, questionNameId := range g.QuestionsIds {
+			q, ok := s.Questions[questionNameId]
+			if !ok || q.Label == "" {
+				continue
+			}
+			key := normalizeLabel(q.Label)
+			if first, dup := seen[key]; dup {
+				findings = append(findings, &Finding{
+					Severity: SeverityInfo,
+					Code:     CodeDuplicateLabel,
+					Location: Location{GroupNameId: groupNameId, QuestionNameId: questionNameId, Field: "label"},
+					Message:  fmt.Sprintf("label '%s' is also used by question '%s'", q.Label, first),
+				})
+				continue
+			}
+			seen[key] = questionNameId
+		}
+	}
+
+	for _, questionNameId := range sortedKeys(s.Questions) {
+		seen := make(map[string]string) // key: normalized label, value: option name id
+		for _, o := range simpleChoiceOptions(s, questionNameId) {
+			key := normalizeLabel(o.Label)
+			if first, dup := seen[key]; dup {
+				findings = append(findings, &Finding{
+					Severity: SeverityWarning,
+					Code:     CodeDuplicateLabel,
+					Location: Location{QuestionNameId: questionNameId, OptionNameId: o.NameId, Field: "label"},
+					Message:  fmt.Sprintf("label '%s' is also used by option '%s'", o.Label, first),
+				})
+				continue
+			}
+			seen[key] = o.NameId
+		}
+	}
+
+	return findings
+}
+
+// UnknownDefaults reports defaults of choice questions that are not option name ids of the question.
+func UnknownDefaults(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+	for _, questionNameId := range sortedKeys(s.Questions) {
+		q := s.Questions[questionNameId]
+		if !types.IsSimpleChoiceType(q.QTyp) {
+			continue
+		}
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
+
+		options := make(map[string]bool, len(c.Options))
+		for _, o := range c.Options {
+			options[o.NameId] = true
+		}
+
+		for i, d := range c.Defaults {
+			if options[d] {
+				continue
+			}
+			findings = append(findings, &Finding{
+				Severity: SeverityError,
+				Code:     CodeUnknownDefault,
+				Location: Location{QuestionNameId: questionNameId, Field: fmt.Sprintf("defaults[%d]", i)},
+				Message:  fmt.Sprintf("default '%s' is not an option of the question", d),
+			})
+		}
+	}
+	return findings
+}
+
+// InvalidAnswerExprs reports AnswerExpr expressions that do not compile.
+// Expressions are compiled with the environment used by the render package:
+// ans ([]any) and, for simple choice questions, options (map[string]string).
+func InvalidAnswerExprs(s *surveygo.Survey) []*Finding {
+	var findings []*Finding
+	for _, questionNameId := range sortedKeys(s.Questions) {
+		q := s.Questions[questionNameId]
+		if q.AnswerExpr == "" {
+			continue
+		}
+
+		env := map[string]any{"ans": []any{}}
+		if options := simpleChoiceOptions(s, questionNameId); len(options) > 0 {
+			labels := make(map[string]string, len(options))
+			for _, o := range options {
+				labels[o.NameId] = o.Label
+			}
+			env["options"] = labels
+		}
+
+		if _, err := expr.Compile(q.AnswerExpr, expr.Env(env)); err != nil {
+			findings = append(findings, &Finding{
+				Severity: SeverityError,
+				Code:     CodeInvalidAnswerExpr,
+				Location: Location{QuestionNameId: questionNameId, Field: "answerExpr"},
+				Message:  fmt.Sprintf("expression does not compile: %s", firstLine(err.Error())),
+			})
+		}
+	}
+	return findings
+}
+
+// simpleChoiceOptions returns the options of a simple choice question, nil for any other question.
+func simpleChoiceOptions(s *surveygo.Survey, questionNameId string) []*choice.Option {
+	q, ok := s.Questions[questionNameId]
+	if !ok || !types.IsSimpleChoiceType(q.QTyp) {
+		return nil
+	}
+	c, err := choice.CastToChoice(q.Value)
+	if err != nil {
+		return nil
+	}
+	return c.Options
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
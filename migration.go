@@ -0,0 +1,618 @@
+package surveygo
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/reviewer"
+)
+
+// MigrationSpec describes how the answers of a survey version are carried over to another version.
+// Steps are applied to every answer, top-level or inside a group instance, in this order:
+// DropQuestions, RenameOptions, RenameQuestions or SplitQuestions, MapValues and MoveToGroup.
+// The instances of repeatable groups are carried over to the group with the same name id, or the one
+// given by RenameGroups, as long as it is nested in the same repeatable groups.
+type MigrationSpec struct {
+	// DropQuestions is a list of question name ids (source survey) whose answers are discarded.
+	DropQuestions []string `json:"dropQuestions,omitempty" bson:"dropQuestions,omitempty"`
+
+	// RenameOptions renames the selected options of a question.
+	// Key: question name id (source survey), Value: map of old option name id to new option name id.
+	// Mapping several old options to the same new option merges them.
+	RenameOptions map[string]map[string]string `json:"renameOptions,omitempty" bson:"renameOptions,omitempty"`
+
+	// RenameQuestions renames questions.
+	// Key: question name id (source survey), Value: question name id (target survey).
+	RenameQuestions map[string]string `json:"renameQuestions,omitempty" bson:"renameQuestions,omitempty"`
+
+	// SplitQuestions copies the answers of a question to several questions, usually with a MapValues
+	// expression for each of them (e.g. a full name split into first and last name).
+	// Key: question name id (source survey), Value: question name ids (target survey).
+	SplitQuestions map[string][]string `json:"splitQuestions,omitempty" bson:"splitQuestions,omitempty"`
+
+	// MapValues transforms the answers of a question with an expr-lang expression (https://github.com/expr-lang/expr).
+	// Key: question name id (target survey), Value: expression. The question must receive the answers of a
+	// source question, with the same name id, renamed or split.
+	// Environment: ans ([]any) + options (map[nameId]label of the source question, only for choice types).
+	// A list result replaces the answers, a nil result discards them and any other result becomes a single answer.
+	MapValues map[string]string `json:"mapValues,omitempty" bson:"mapValues,omitempty"`
+
+	// RenameGroups renames repeatable groups, keeping the answers of their instances.
+	// Key: group name id (source survey), Value: group name id (target survey).
+	RenameGroups map[string]string `json:"renameGroups,omitempty" bson:"renameGroups,omitempty"`
+
+	// MoveToGroup moves questions into a repeatable group of the target survey.
+	// Key: question name id (target survey), Value: group name id (target survey).
+	// Top-level answers become the first instance of the group and answers inside another group instance
+	// keep their instance index. When the group is nested in other repeatable groups, the answers go to
+	// the instances of those groups the answers were in, or to their first instances.
+	MoveToGroup map[string]string `json:"moveToGroup,omitempty" bson:"moveToGroup,omitempty"`
+}
+
+// MigrationIssue describes an answer that could not be migrated.
+type MigrationIssue struct {
+	// QuestionNameId is the name id of the question (or group) of the answer.
+	QuestionNameId string `json:"questionNameId,omitempty" bson:"questionNameId,omitempty"`
+
+	// Path locates the answer, e.g. "q1" or "grp1.0.q1" for the first instance of group grp1.
+	Path string `json:"path,omitempty" bson:"path,omitempty"`
+
+	// Answer is the answer that could not be migrated.
+	Answer any `json:"answer,omitempty" bson:"answer,omitempty"`
+
+	// Error is the reason why the answer was discarded.
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// MigrationResult is the result of migrating answers between survey versions.
+type MigrationResult struct {
+	// Answers are the migrated answers. Answers that could not be migrated are not included.
+	Answers Answers `json:"answers" bson:"answers"`
+
+	// Resume is the result of reviewing the migrated answers against the target survey.
+	Resume *SurveyResume `json:"resume,omitempty" bson:"resume,omitempty"`
+
+	// Issues is the list of answers that could not be migrated.
+	Issues []*MigrationIssue `json:"issues,omitempty" bson:"issues,omitempty"`
+}
+
+// MigrateAnswers migrates answers given for a survey version to another version of the survey.
+// Answers are transformed according to the spec, answers of questions that do not exist in the target
+// survey or that are not valid for it are discarded and reported as issues, and the result is reviewed
+// with ReviewAnswers against the target survey.
+// Args:
+//   - from: the survey the answers were given for
+//   - to: the target survey
+//   - spec: the migration spec (optional, nil keeps matching question and option name ids)
+//   - ans: the answers to migrate, they are not modified
+//
+// Returns:
+//   - *MigrationResult: the migrated answers, its resume and the issues found
+//   - error: if the spec is not valid for the surveys
+func MigrateAnswers(from, to *Survey, spec *MigrationSpec, ans Answers) (*MigrationResult, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("source and target surveys are required to migrate answers")
+	}
+	if spec == nil {
+		spec = &MigrationSpec{}
+	}
+
+	m, err := newAnswersMigrator(from, to, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := m.migrateSet(ans, "", nil)
+	m.applyMoves(migrated)
+	m.reviewSet(migrated, "")
+
+	resume, err := to.ReviewAnswers(Answers(migrated))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error reviewing migrated answers"), err)
+	}
+
+	return &MigrationResult{
+		Answers: migrated,
+		Resume:  resume,
+		Issues:  m.issues,
+	}, nil
+}
+
+// groupInstance is an instance of a repeatable group.
+type groupInstance struct {
+	group string
+	index int
+}
+
+// pendingMove is an answer waiting to be moved into a group instance.
+type pendingMove struct {
+	target   []groupInstance // from the outermost repeatable group to the group of the move
+	question string
+	values   []any
+}
+
+// answersMigrator holds the state of a migration.
+type answersMigrator struct {
+	from     *Survey
+	to       *Survey
+	spec     *MigrationSpec
+	dropped  map[string]bool
+	programs map[string]*vm.Program
+	repeats  map[string][]string // key: group of a move, value: its repeatable groups, see repeatPath
+	moves    []pendingMove
+	issues   []*MigrationIssue
+}
+
+// newAnswersMigrator validates the spec against both surveys and compiles its expressions.
+func newAnswersMigrator(from, to *Survey, spec *MigrationSpec) (*answersMigrator, error) {
+	var errs []error
+
+	m := &answersMigrator{
+		from:     from,
+		to:       to,
+		spec:     spec,
+		dropped:  make(map[string]bool),
+		programs: make(map[string]*vm.Program),
+		repeats:  make(map[string][]string),
+	}
+
+	for _, nameId := range spec.DropQuestions {
+		if !from.isQuestion(nameId) {
+			errs = append(errs, fmt.Errorf("drop question '%s' not found in source survey", nameId))
+		}
+		m.dropped[nameId] = true
+	}
+
+	for _, nameId := range sortedKeys(spec.RenameOptions) {
+		if !from.isQuestion(nameId) {
+			errs = append(errs, fmt.Errorf("rename options of question '%s' not found in source survey", nameId))
+		}
+	}
+
+	for _, oldId := range sortedKeys(spec.RenameQuestions) {
+		newId := spec.RenameQuestions[oldId]
+		if !from.isQuestion(oldId) {
+			errs = append(errs, fmt.Errorf("rename question '%s' not found in source survey", oldId))
+		}
+		if !to.isQuestion(newId) {
+			errs = append(errs, fmt.Errorf("renamed question '%s' not found in target survey", newId))
+		}
+	}
+
+	for _, oldId := range sortedKeys(spec.RenameGroups) {
+		newId := spec.RenameGroups[oldId]
+		if !from.isRepeatGroup(oldId) {
+			errs = append(errs, fmt.Errorf("rename group '%s' is not a repeatable group of the source survey", oldId))
+		}
+		if !to.isRepeatGroup(newId) {
+			errs = append(errs, fmt.Errorf("renamed group '%s' is not a repeatable group of the target survey", newId))
+		}
+	}
+
+	for _, nameId := range sortedKeys(spec.SplitQuestions) {
+		targets := spec.SplitQuestions[nameId]
+		if !from.isQuestion(nameId) {
+			errs = append(errs, fmt.Errorf("split question '%s' not found in source survey", nameId))
+		}
+		if _, ok := spec.RenameQuestions[nameId]; ok {
+			errs = append(errs, fmt.Errorf("question '%s' is both renamed and split", nameId))
+		}
+		if len(targets) == 0 {
+			errs = append(errs, fmt.Errorf("split question '%s' has no target questions", nameId))
+		}
+		for _, targetId := range targets {
+			if !to.isQuestion(targetId) {
+				errs = append(errs, fmt.Errorf("split question '%s' target '%s' not found in target survey", nameId, targetId))
+			}
+		}
+	}
+
+	// target questions receiving the answers of a source question
+	received := make(map[string]bool)
+	for nameId := range from.Questions {
+		if !m.dropped[nameId] {
+			for _, targetId := range m.targets(nameId) {
+				received[targetId] = true
+			}
+		}
+	}
+
+	for _, nameId := range sortedKeys(spec.MapValues) {
+		expression := spec.MapValues[nameId]
+		if !to.isQuestion(nameId) {
+			errs = append(errs, fmt.Errorf("map values question '%s' not found in target survey", nameId))
+			continue
+		}
+		program, err := expr.Compile(expression)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid map values expression for question '%s': %s", nameId, err))
+			continue
+		}
+		if !received[nameId] {
+			errs = append(errs, fmt.Errorf("map values question '%s' does not receive the answers of any source question", nameId))
+			continue
+		}
+		m.programs[nameId] = program
+	}
+
+	for _, nameId := range sortedKeys(spec.MoveToGroup) {
+		groupNameId := spec.MoveToGroup[nameId]
+		if !to.isQuestion(nameId) {
+			errs = append(errs, fmt.Errorf("moved question '%s' not found in target survey", nameId))
+		}
+		if !to.isRepeatGroup(groupNameId) {
+			errs = append(errs, fmt.Errorf("group '%s' for moved question '%s' is not a repeatable group of the target survey", groupNameId, nameId))
+			continue
+		}
+		m.repeats[groupNameId] = to.repeatPath(groupNameId)
+	}
+
+	if len(errs) > 0 {
+		errs = append([]error{fmt.Errorf("invalid migration spec")}, errs...)
+		return nil, errors.Join(errs...)
+	}
+
+	return m, nil
+}
+
+// migrateSet migrates a set of answers, either the top-level answers or the answers of a group instance.
+// The instances are the group instances the set is in, from the outermost, nil for top-level answers.
+func (m *answersMigrator) migrateSet(set map[string][]any, path string, instances []groupInstance) map[string][]any {
+	res := make(map[string][]any, len(set))
+
+	for _, nameId := range sortedKeys(set) {
+		values := set[nameId]
+		itemPath := path + nameId
+
+		// group answers
+		if m.from.isGroup(nameId) && !m.from.isQuestion(nameId) {
+			targetId := nameId
+			if renamed, ok := m.spec.RenameGroups[nameId]; ok {
+				targetId = renamed
+			}
+			groupInstances, ok := m.migrateGroup(nameId, targetId, values, itemPath, instances)
+			if !ok {
+				continue
+			}
+			if _, exists := res[targetId]; exists {
+				m.issue(nameId, itemPath, values, fmt.Sprintf("group '%s' already has answers", targetId))
+				continue
+			}
+			res[targetId] = groupInstances
+			continue
+		}
+
+		if m.dropped[nameId] {
+			continue
+		}
+
+		if !m.from.isQuestion(nameId) {
+			m.issue(nameId, itemPath, values, fmt.Sprintf("question '%s' not found in source survey", nameId))
+			continue
+		}
+
+		values = m.renameOptions(nameId, values)
+		for _, targetId := range m.targets(nameId) {
+			m.migrateAnswer(res, nameId, targetId, itemPath, values, instances)
+		}
+	}
+
+	return res
+}
+
+// targets returns the questions of the target survey receiving the answers of a source question.
+func (m *answersMigrator) targets(nameId string) []string {
+	if targets, ok := m.spec.SplitQuestions[nameId]; ok {
+		return targets
+	}
+	if renamed, ok := m.spec.RenameQuestions[nameId]; ok {
+		return []string{renamed}
+	}
+	return []string{nameId}
+}
+
+// migrateAnswer migrates the answers of a source question to a question of the target survey.
+func (m *answersMigrator) migrateAnswer(res map[string][]any, nameId, targetId, itemPath string, values []any, instances []groupInstance) {
+	if program, ok := m.programs[targetId]; ok {
+		mapped, err := m.mapValues(program, nameId, values)
+		if err != nil {
+			m.issue(nameId, itemPath, values, fmt.Sprintf("error mapping values for question '%s': %s", targetId, err))
+			return
+		}
+		if mapped == nil {
+			return
+		}
+		values = mapped
+	}
+
+	if !m.to.isQuestion(targetId) {
+		m.issue(nameId, itemPath, values, fmt.Sprintf("question '%s' not found in target survey", targetId))
+		return
+	}
+
+	if groupNameId, ok := m.spec.MoveToGroup[targetId]; ok {
+		m.moves = append(m.moves, pendingMove{
+			target:   m.moveTarget(groupNameId, instances),
+			question: targetId,
+			values:   values,
+		})
+		return
+	}
+
+	if _, exists := res[targetId]; exists {
+		m.issue(nameId, itemPath, values, fmt.Sprintf("question '%s' already has answers", targetId))
+		return
+	}
+
+	res[targetId] = values
+}
+
+// migrateGroup migrates the answers of each instance of a repeatable group to the target group.
+// The parents are the instances the group answers are in, with the group name ids of the target survey.
+func (m *answersMigrator) migrateGroup(nameId, targetId string, values []any, path string, parents []groupInstance) ([]any, bool) {
+	if !m.to.isRepeatGroup(targetId) {
+		m.issue(nameId, path, values, fmt.Sprintf("group '%s' is not a repeatable group of the target survey", targetId))
+		return nil, false
+	}
+
+	// the answers stay in the instances of the same repeatable groups
+	repeats := m.to.repeatPath(targetId)
+	outer := make([]string, 0, len(parents))
+	for _, p := range parents {
+		outer = append(outer, p.group)
+	}
+	if !slices.Equal(outer, repeats[:len(repeats)-1]) {
+		m.issue(nameId, path, values, fmt.Sprintf("group '%s' is nested in other repeatable groups in the target survey", targetId))
+		return nil, false
+	}
+
+	groupAnswers, err := reviewer.ExtractGroupNestedAnswers(values)
+	if err != nil {
+		m.issue(nameId, path, values, err.Error())
+		return nil, false
+	}
+
+	instances := make([]any, 0, len(groupAnswers))
+	for i, instance := range groupAnswers {
+		current := append(slices.Clone(parents), groupInstance{group: targetId, index: i})
+		migrated := m.migrateSet(instance, fmt.Sprintf("%s.%d.", path, i), current)
+		instances = append(instances, toInstanceMap(migrated))
+	}
+
+	return instances, true
+}
+
+// moveTarget returns the instances a moved answer goes to, given the instances the answer was in.
+// The repeatable groups enclosing the group of the move keep the instance of the answer in the same group,
+// the group of the move takes the innermost remaining instance, and the first instance is used otherwise.
+func (m *answersMigrator) moveTarget(groupNameId string, instances []groupInstance) []groupInstance {
+	repeats := m.repeats[groupNameId]
+	outer := repeats[:len(repeats)-1]
+
+	target := make([]groupInstance, 0, len(repeats))
+	for _, g := range outer {
+		index := 0
+		for _, in := range instances {
+			if in.group == g {
+				index = in.index
+			}
+		}
+		target = append(target, groupInstance{group: g, index: index})
+	}
+
+	index := 0
+	for _, in := range instances {
+		if !slices.Contains(outer, in.group) {
+			index = in.index
+		}
+	}
+	return append(target, groupInstance{group: groupNameId, index: index})
+}
+
+// applyMoves places the moved answers into their group instances, creating the instances when needed.
+func (m *answersMigrator) applyMoves(res map[string][]any) {
+	for _, mv := range m.moves {
+		path := ""
+		for _, in := range mv.target {
+			path += fmt.Sprintf("%s.%d.", in.group, in.index)
+		}
+		path += mv.question
+
+		instance, err := groupInstanceAnswers(res, mv.target)
+		if err != nil {
+			m.issue(mv.question, path, mv.values, err.Error())
+			continue
+		}
+		if _, exists := instance[mv.question]; exists {
+			m.issue(mv.question, path, mv.values, fmt.Sprintf("question '%s' already has answers", mv.question))
+			continue
+		}
+
+		instance[mv.question] = mv.values
+	}
+}
+
+// groupInstanceAnswers returns the answers of a nested group instance, creating the missing instances.
+func groupInstanceAnswers(res map[string][]any, target []groupInstance) (map[string]any, error) {
+	get := func(group string) ([]any, bool) { return res[group], true }
+	set := func(group string, instances []any) { res[group] = instances }
+
+	var instance map[string]any
+	for _, in := range target {
+		instances, ok := get(in.group)
+		if !ok {
+			return nil, fmt.Errorf("invalid answers for group '%s'", in.group)
+		}
+		for len(instances) <= in.index {
+			instances = append(instances, map[string]any{})
+		}
+		set(in.group, instances)
+
+		current, ok := instances[in.index].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid answers for group '%s'", in.group)
+		}
+		instance = current
+		get = func(group string) ([]any, bool) {
+			if current[group] == nil {
+				return nil, true
+			}
+			v, ok := current[group].([]any)
+			return v, ok
+		}
+		set = func(group string, instances []any) { current[group] = instances }
+	}
+	return instance, nil
+}
+
+// reviewSet removes the answers that are not valid for the target survey.
+func (m *answersMigrator) reviewSet(set map[string][]any, path string) {
+	for _, nameId := range sortedKeys(set) {
+		values := set[nameId]
+		itemPath := path + nameId
+
+		if m.to.isQuestion(nameId) {
+			if invalid := m.to.reviewQuestion(nameId, values, map[string]int{}); invalid != nil {
+				m.issue(nameId, itemPath, values, invalid.Error)
+				delete(set, nameId)
+			}
+			continue
+		}
+
+		for i, instance := range values {
+			answersPack, ok := instance.(map[string]any)
+			if !ok {
+				continue
+			}
+			nested := make(map[string][]any, len(answersPack))
+			for k, v := range answersPack {
+				nested[k], _ = v.([]any)
+			}
+			m.reviewSet(nested, fmt.Sprintf("%s.%d.", itemPath, i))
+			for k := range answersPack {
+				if _, kept := nested[k]; !kept {
+					delete(answersPack, k)
+				}
+			}
+		}
+	}
+}
+
+// renameOptions applies the option renames of the question, merging duplicated options.
+func (m *answersMigrator) renameOptions(nameId string, values []any) []any {
+	renames, ok := m.spec.RenameOptions[nameId]
+	if !ok {
+		return values
+	}
+
+	res := make([]any, 0, len(values))
+	for _, v := range values {
+		if s, isString := v.(string); isString {
+			if renamed, found := renames[s]; found {
+				v = renamed
+			}
+		}
+		if slices.Contains(res, v) {
+			continue
+		}
+		res = append(res, v)
+	}
+	return res
+}
+
+// mapValues runs a map values expression over the answers of a question.
+func (m *answersMigrator) mapValues(program *vm.Program, sourceId string, values []any) ([]any, error) {
+	env := map[string]any{"ans": values}
+	if values == nil {
+		env["ans"] = []any{}
+	}
+
+	if q, ok := m.from.Questions[sourceId]; ok {
+		if c, err := choice.CastToChoice(q.Value); err == nil {
+			options := make(map[string]string, len(c.Options))
+			for _, o := range c.Options {
+				options[o.NameId] = o.Label
+			}
+			env["options"] = options
+		}
+	}
+
+	out, err := expr.Run(program, env)
+	if err != nil {
+		return nil, err
+	}
+
+	if out == nil {
+		return nil, nil
+	}
+
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Slice {
+		return []any{out}, nil
+	}
+
+	res := make([]any, rv.Len())
+	for i := range res {
+		res[i] = rv.Index(i).Interface()
+	}
+	return res, nil
+}
+
+func (m *answersMigrator) issue(nameId, path string, answer any, msg string) {
+	m.issues = append(m.issues, &MigrationIssue{
+		QuestionNameId: nameId,
+		Path:           path,
+		Answer:         answer,
+		Error:          msg,
+	})
+}
+
+// repeatPath returns the repeatable groups whose instances hold the answers of a group, from the outermost
+// to the group itself. Groups are nested through the groups order of another group or the options of its
+// questions.
+func (s *Survey) repeatPath(groupNameId string) []string {
+	parents := make(map[string]string)
+	for _, nameId := range sortedKeys(s.Groups) {
+		g := s.Groups[nameId]
+		children := append(slices.Clone(g.GroupsOrder), s.optionGroups(g)...)
+		for _, child := range children {
+			if _, ok := parents[child]; !ok && child != nameId {
+				parents[child] = nameId
+			}
+		}
+	}
+
+	var res []string
+	visited := make(map[string]bool)
+	for nameId := groupNameId; nameId != "" && !visited[nameId]; nameId = parents[nameId] {
+		visited[nameId] = true
+		if s.isRepeatGroup(nameId) {
+			res = append([]string{nameId}, res...)
+		}
+	}
+	return res
+}
+
+// toInstanceMap converts a set of answers to the representation of a group instance.
+func toInstanceMap(set map[string][]any) map[string]any {
+	res := make(map[string]any, len(set))
+	for k, v := range set {
+		res[k] = v
+	}
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,329 @@
+package surveygo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const migrationFromSurvey = `{
+  "nameId": "household", "title": "Household", "version": "1",
+  "groupsOrder": ["grp-main", "grp-kids"],
+  "groups": {
+    "grp-main": {"nameId": "grp-main", "questionsIds": ["q-color", "q-pet", "q-old", "q-phone", "q-dropme", "q-age"]},
+    "grp-kids": {"nameId": "grp-kids", "allowRepeat": true, "questionsIds": ["q-kid"]}
+  },
+  "questions": {
+    "q-color": {"nameId": "q-color", "visible": true, "type": "radio", "label": "Color",
+      "value": {"options": [{"nameId": "red", "label": "Red"}, {"nameId": "crimson", "label": "Crimson"}, {"nameId": "blue", "label": "Blue"}]}},
+    "q-pet": {"nameId": "q-pet", "visible": true, "type": "single_select", "label": "Pet",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}, {"nameId": "cat", "label": "Cat"}]}},
+    "q-old": {"nameId": "q-old", "visible": true, "type": "input_text", "label": "Old", "value": {}},
+    "q-phone": {"nameId": "q-phone", "visible": true, "type": "input_text", "label": "Phone", "value": {}},
+    "q-dropme": {"nameId": "q-dropme", "visible": true, "type": "input_text", "label": "Drop", "value": {}},
+    "q-age": {"nameId": "q-age", "visible": true, "type": "input_text", "label": "Age", "value": {}},
+    "q-kid": {"nameId": "q-kid", "visible": true, "type": "input_text", "label": "Kid", "value": {}}
+  }
+}`
+
+const migrationToSurvey = `{
+  "nameId": "household", "title": "Household", "version": "2",
+  "groupsOrder": ["grp-main", "grp-kids"],
+  "groups": {
+    "grp-main": {"nameId": "grp-main", "questionsIds": ["q-colour", "q-pet", "q-years"]},
+    "grp-kids": {"nameId": "grp-kids", "allowRepeat": true, "questionsIds": ["q-kid", "q-phone"]}
+  },
+  "questions": {
+    "q-colour": {"nameId": "q-colour", "visible": true, "type": "radio", "label": "Colour",
+      "value": {"options": [{"nameId": "red", "label": "Red"}, {"nameId": "blue", "label": "Blue"}]}},
+    "q-pet": {"nameId": "q-pet", "visible": true, "type": "single_select", "label": "Pet",
+      "value": {"options": [{"nameId": "dog", "label": "Dog"}]}},
+    "q-years": {"nameId": "q-years", "visible": true, "type": "slider", "label": "Age", "value": {"min": 1, "max": 120, "step": 1}},
+    "q-phone": {"nameId": "q-phone", "visible": true, "type": "input_text", "label": "Phone", "value": {}},
+    "q-kid": {"nameId": "q-kid", "visible": true, "type": "input_text", "label": "Kid", "value": {}}
+  }
+}`
+
+func TestMigrateAnswers(t *testing.T) {
+	from, err := ParseFromJsonStr(migrationFromSurvey)
+	if err != nil {
+		t.Fatalf("parsing source survey: %v", err)
+	}
+	to, err := ParseFromJsonStr(migrationToSurvey)
+	if err != nil {
+		t.Fatalf("parsing target survey: %v", err)
+	}
+
+	spec := &MigrationSpec{
+		DropQuestions:   []string{"q-dropme"},
+		RenameOptions:   map[string]map[string]string{"q-color": {"crimson": "red"}},
+		RenameQuestions: map[string]string{"q-color": "q-colour", "q-age": "q-years"},
+		MapValues:       map[string]string{"q-years": "int(ans[0])"},
+		MoveToGroup:     map[string]string{"q-phone": "grp-kids"},
+	}
+
+	ans := Answers{
+		"q-color":  {"crimson"},
+		"q-pet":    {"cat"},
+		"q-old":    {"legacy"},
+		"q-phone":  {"555-1234"},
+		"q-dropme": {"bye"},
+		"q-age":    {"34"},
+		"grp-kids": {map[string]any{"q-kid": []any{"Ann"}}, map[string]any{"q-kid": []any{"Bob"}}},
+	}
+
+	res, err := MigrateAnswers(from, to, spec, ans)
+	if err != nil {
+		t.Fatalf("MigrateAnswers: %v", err)
+	}
+
+	want := Answers{
+		"q-colour": {"red"},
+		"q-years":  {34},
+		"grp-kids": {
+			map[string]any{"q-kid": []any{"Ann"}, "q-phone": []any{"555-1234"}},
+			map[string]any{"q-kid": []any{"Bob"}},
+		},
+	}
+	if !reflect.DeepEqual(res.Answers, want) {
+		t.Errorf("migrated answers = %#v\nwant %#v", res.Answers, want)
+	}
+
+	issues := map[string]string{}
+	for _, issue := range res.Issues {
+		issues[issue.Path] = issue.Error
+	}
+	if len(issues) != 2 {
+		t.Errorf("expected 2 issues, got %v", issues)
+	}
+	if !strings.Contains(issues["q-old"], "not found in target survey") {
+		t.Errorf("q-old issue = %q", issues["q-old"])
+	}
+	if !strings.Contains(issues["q-pet"], "not found in options") {
+		t.Errorf("q-pet issue = %q", issues["q-pet"])
+	}
+
+	if res.Resume == nil || len(res.Resume.InvalidAnswers) > 0 {
+		t.Fatalf("expected a valid resume, got %+v", res.Resume)
+	}
+	if res.Resume.GroupsResume["grp-kids"].AnswerGroups != 2 {
+		t.Errorf("grp-kids instances = %d, want 2", res.Resume.GroupsResume["grp-kids"].AnswerGroups)
+	}
+
+	// the input answers are not modified
+	if ans["q-color"][0] != "crimson" || len(ans["grp-kids"][0].(map[string]any)) != 1 {
+		t.Errorf("input answers were modified: %v", ans)
+	}
+}
+
+func TestMigrateAnswers_InvalidSpec(t *testing.T) {
+	from, _ := ParseFromJsonStr(migrationFromSurvey)
+	to, _ := ParseFromJsonStr(migrationToSurvey)
+
+	spec := &MigrationSpec{
+		RenameQuestions: map[string]string{"q-missing": "q-colour", "q-old": "q-missing"},
+		MapValues:       map[string]string{"q-years": "ans[0] +"},
+		MoveToGroup:     map[string]string{"q-phone": "grp-main"},
+	}
+
+	_, err := MigrateAnswers(from, to, spec, Answers{})
+	if err == nil {
+		t.Fatal("expected an invalid spec error")
+	}
+	for _, want := range []string{
+		"rename question 'q-missing' not found in source survey",
+		"renamed question 'q-missing' not found in target survey",
+		"invalid map values expression for question 'q-years'",
+		"group 'grp-main' for moved question 'q-phone' is not a repeatable group",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestMigrateAnswers_MoveToNestedGroup(t *testing.T) {
+	from, err := ParseFromJsonStr(`{
+  "nameId": "homes", "title": "Homes", "version": "1",
+  "groupsOrder": ["grp-homes"],
+  "groups": {
+    "grp-homes": {"nameId": "grp-homes", "allowRepeat": true, "questionsIds": ["q-address", "q-room"]}
+  },
+  "questions": {
+    "q-address": {"nameId": "q-address", "visible": true, "type": "input_text", "label": "Address", "value": {}},
+    "q-room": {"nameId": "q-room", "visible": true, "type": "input_text", "label": "Room", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing source survey: %v", err)
+	}
+	to, err := ParseFromJsonStr(`{
+  "nameId": "homes", "title": "Homes", "version": "2",
+  "groupsOrder": ["grp-homes"],
+  "groups": {
+    "grp-homes": {"nameId": "grp-homes", "allowRepeat": true, "questionsIds": ["q-address"], "groupsOrder": ["grp-rooms"]},
+    "grp-rooms": {"nameId": "grp-rooms", "allowRepeat": true, "questionsIds": ["q-room"]}
+  },
+  "questions": {
+    "q-address": {"nameId": "q-address", "visible": true, "type": "input_text", "label": "Address", "value": {}},
+    "q-room": {"nameId": "q-room", "visible": true, "type": "input_text", "label": "Room", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing target survey: %v", err)
+	}
+
+	ans := Answers{
+		"grp-homes": {
+			map[string]any{"q-address": []any{"Elm St"}, "q-room": []any{"kitchen"}},
+			map[string]any{"q-address": []any{"Oak St"}, "q-room": []any{"garage"}},
+		},
+	}
+
+	res, err := MigrateAnswers(from, to, &MigrationSpec{MoveToGroup: map[string]string{"q-room": "grp-rooms"}}, ans)
+	if err != nil {
+		t.Fatalf("MigrateAnswers: %v", err)
+	}
+
+	want := Answers{
+		"grp-homes": {
+			map[string]any{"q-address": []any{"Elm St"}, "grp-rooms": []any{map[string]any{"q-room": []any{"kitchen"}}}},
+			map[string]any{"q-address": []any{"Oak St"}, "grp-rooms": []any{map[string]any{"q-room": []any{"garage"}}}},
+		},
+	}
+	if !reflect.DeepEqual(res.Answers, want) {
+		t.Errorf("migrated answers = %#v\nwant %#v", res.Answers, want)
+	}
+	if len(res.Issues) > 0 {
+		t.Errorf("unexpected issues: %+v", res.Issues[0])
+	}
+	if res.Resume == nil || len(res.Resume.InvalidAnswers) > 0 {
+		t.Errorf("expected a valid resume, got %+v", res.Resume)
+	}
+}
+
+func TestMigrateAnswers_SplitQuestions(t *testing.T) {
+	from, err := ParseFromJsonStr(`{
+  "nameId": "people", "title": "People", "version": "1",
+  "groupsOrder": ["grp-main"],
+  "groups": {"grp-main": {"nameId": "grp-main", "questionsIds": ["q-name"]}},
+  "questions": {
+    "q-name": {"nameId": "q-name", "visible": true, "type": "input_text", "label": "Name", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing source survey: %v", err)
+	}
+	to, err := ParseFromJsonStr(`{
+  "nameId": "people", "title": "People", "version": "2",
+  "groupsOrder": ["grp-main"],
+  "groups": {"grp-main": {"nameId": "grp-main", "questionsIds": ["q-first", "q-last", "q-nick"]}},
+  "questions": {
+    "q-first": {"nameId": "q-first", "visible": true, "type": "input_text", "label": "First name", "value": {}},
+    "q-last": {"nameId": "q-last", "visible": true, "type": "input_text", "label": "Last name", "value": {}},
+    "q-nick": {"nameId": "q-nick", "visible": true, "type": "input_text", "label": "Nickname", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing target survey: %v", err)
+	}
+
+	spec := &MigrationSpec{
+		SplitQuestions: map[string][]string{"q-name": {"q-first", "q-last"}},
+		MapValues: map[string]string{
+			"q-first": `split(ans[0], " ")[0]`,
+			"q-last":  `split(ans[0], " ")[1]`,
+		},
+	}
+	res, err := MigrateAnswers(from, to, spec, Answers{"q-name": {"Ada Lovelace"}})
+	if err != nil {
+		t.Fatalf("MigrateAnswers: %v", err)
+	}
+	want := Answers{"q-first": {"Ada"}, "q-last": {"Lovelace"}}
+	if !reflect.DeepEqual(res.Answers, want) || len(res.Issues) > 0 {
+		t.Errorf("migrated answers = %#v, issues = %v\nwant %#v", res.Answers, res.Issues, want)
+	}
+
+	// a map values expression of a question receiving no answers is rejected
+	spec = &MigrationSpec{
+		SplitQuestions:  map[string][]string{"q-name": {"q-first", "q-missing"}},
+		RenameQuestions: map[string]string{"q-name": "q-first"},
+		MapValues:       map[string]string{"q-nick": `ans`},
+	}
+	_, err = MigrateAnswers(from, to, spec, Answers{})
+	if err == nil {
+		t.Fatal("expected an invalid spec error")
+	}
+	for _, want := range []string{
+		"question 'q-name' is both renamed and split",
+		"split question 'q-name' target 'q-missing' not found in target survey",
+		"map values question 'q-nick' does not receive the answers of any source question",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestMigrateAnswers_Groups(t *testing.T) {
+	from, err := ParseFromJsonStr(`{
+  "nameId": "family", "title": "Family", "version": "1",
+  "groupsOrder": ["grp-kids", "grp-pets"],
+  "groups": {
+    "grp-kids": {"nameId": "grp-kids", "allowRepeat": true, "questionsIds": ["q-name"]},
+    "grp-pets": {"nameId": "grp-pets", "allowRepeat": true, "questionsIds": ["q-pet"]}
+  },
+  "questions": {
+    "q-name": {"nameId": "q-name", "visible": true, "type": "input_text", "label": "Name", "value": {}},
+    "q-pet": {"nameId": "q-pet", "visible": true, "type": "input_text", "label": "Pet", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing source survey: %v", err)
+	}
+	to, err := ParseFromJsonStr(`{
+  "nameId": "family", "title": "Family", "version": "2",
+  "groupsOrder": ["grp-children"],
+  "groups": {
+    "grp-children": {"nameId": "grp-children", "allowRepeat": true, "questionsIds": ["q-name"], "groupsOrder": ["grp-pets"]},
+    "grp-pets": {"nameId": "grp-pets", "allowRepeat": true, "questionsIds": ["q-pet"]}
+  },
+  "questions": {
+    "q-name": {"nameId": "q-name", "visible": true, "type": "input_text", "label": "Name", "value": {}},
+    "q-pet": {"nameId": "q-pet", "visible": true, "type": "input_text", "label": "Pet", "value": {}}
+  }
+}`)
+	if err != nil {
+		t.Fatalf("parsing target survey: %v", err)
+	}
+
+	ans := Answers{
+		"grp-kids": {map[string]any{"q-name": []any{"Ann"}}, map[string]any{"q-name": []any{"Bob"}}},
+		"grp-pets": {map[string]any{"q-pet": []any{"Rex"}}},
+	}
+	res, err := MigrateAnswers(from, to, &MigrationSpec{RenameGroups: map[string]string{"grp-kids": "grp-children"}}, ans)
+	if err != nil {
+		t.Fatalf("MigrateAnswers: %v", err)
+	}
+
+	// the renamed group keeps its instances, the group moved into another repeatable group is reported
+	want := Answers{
+		"grp-children": {map[string]any{"q-name": []any{"Ann"}}, map[string]any{"q-name": []any{"Bob"}}},
+	}
+	if !reflect.DeepEqual(res.Answers, want) {
+		t.Errorf("migrated answers = %#v\nwant %#v", res.Answers, want)
+	}
+	if len(res.Issues) != 1 || res.Issues[0].Path != "grp-pets" || !strings.Contains(res.Issues[0].Error, "nested in other repeatable groups") {
+		t.Errorf("issues = %+v", res.Issues)
+	}
+
+	_, err = MigrateAnswers(from, to, &MigrationSpec{RenameGroups: map[string]string{"q-name": "grp-missing"}}, ans)
+	for _, want := range []string{
+		"rename group 'q-name' is not a repeatable group of the source survey",
+		"renamed group 'grp-missing' is not a repeatable group of the target survey",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+}
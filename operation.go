@@ -101,7 +101,11 @@ func (s *Survey) checkConsistency() error {
 			continue
 		}
 
-		c, _ := choice.CastToChoice(q.Value)
+		// complex choice types without options (e.g. slider) have nothing to check
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
 		var options = c.GetOptionsGroups() // key: option name id, value: list of group name ids
 
 		// build option map for this question (for DependsOn validation)
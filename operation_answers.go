@@ -166,6 +166,20 @@ func (s *Survey) GetRequiredAndOptionalQuestions() map[string]bool {
 	return requiredAndOptionalQuestions
 }
 
+// GetVisibleQuestions returns the questions visible for the given answers, with the name id of their group.
+// Questions are visible if they are visible and satisfy their dependsOn conditions, within groups that
+// are visible, enabled and satisfy their dependsOn conditions.
+// The key is the question name id and the value the group name id.
+func (s *Survey) GetVisibleQuestions(ans Answers) map[string]string {
+	return s.getVisibleQuestionFromActiveGroups(ans)
+}
+
+// EvaluateDependsOn checks if dependsOn conditions (OR of AND groups) are satisfied by the given answers,
+// with the same semantics as the dependsOn of questions and groups. Empty conditions are always satisfied.
+func (s *Survey) EvaluateDependsOn(dependsOn [][]question.DependsOn, ans Answers) bool {
+	return s.evaluateDependsOn(dependsOn, ans)
+}
+
 // GroupAnswersByType groups the answers by the type of the question.
 func (s *Survey) GroupAnswersByType(ans Answers) map[types.QuestionType]Answers {
 	var res = make(map[types.QuestionType]Answers)
@@ -200,7 +200,10 @@ func (s *Survey) RemoveGroup(groupNameId string) error {
 	// remove group from options groups
 	for _, q := range s.Questions {
 		if types.IsChoiceType(q.QTyp) {
-			c, _ := choice.CastToChoice(q.Value)
+			c, err := choice.CastToChoice(q.Value)
+			if err != nil {
+				continue
+			}
 			if removed := c.RemoveGroupId(groupNameId); removed {
 				break
 			}
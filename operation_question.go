@@ -235,9 +235,8 @@ func (s *Survey) addQuestion(q *question.Question) error {
 		return fmt.Errorf("question nameId '%s' already exists", q.NameId)
 	}
 
-	// if question is choice type, check if options groups exist
-	if types.IsChoiceType(q.QTyp) {
-		c, _ := choice.CastToChoice(q.Value)
+	// if question is choice type with options, check if options groups exist
+	if c, err := choice.CastToChoice(q.Value); err == nil && types.IsChoiceType(q.QTyp) {
 		optionsGroups := c.GetOptionsGroups()
 		for _, ogs := range optionsGroups {
 			for _, og := range ogs {
@@ -1,9 +1,11 @@
 package surveygo
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 )
 
 // ------------ Deserializers ------------- //
@@ -40,6 +42,64 @@ func ParseFromBytes(b []byte) (*Survey, error) {
 	return survey, nil
 }
 
+// ParseAnswers decodes a JSON answers object, keyed by question or group name id. Integral numbers are
+// decoded as int (e.g. slider answers), other numbers as float64, null answers are dropped and scalar
+// values are wrapped in a single answer list.
+// Args:
+//   - r: the reader of the JSON answers object
+//
+// Returns:
+//   - Answers: the decoded answers
+//   - error: if the answers cannot be read or are not a JSON object
+func ParseAnswers(r io.Reader) (Answers, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var raw map[string]any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("error decoding answers: %w", err)
+	}
+	if raw == nil {
+		return nil, errors.New("answers must be a JSON object")
+	}
+
+	answers := make(Answers, len(raw))
+	for k, v := range raw {
+		switch val := normalizeNumbers(v).(type) {
+		case nil:
+		case []any:
+			answers[k] = val
+		default:
+			answers[k] = []any{val}
+		}
+	}
+	return answers, nil
+}
+
+// normalizeNumbers converts the json.Number values of a decoded JSON value.
+func normalizeNumbers(v any) any {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return int(i)
+		}
+		f, _ := val.Float64()
+		return f
+	case []any:
+		for i := range val {
+			val[i] = normalizeNumbers(val[i])
+		}
+		return val
+	case map[string]any:
+		for k := range val {
+			val[k] = normalizeNumbers(val[k])
+		}
+		return val
+	default:
+		return v
+	}
+}
+
 // -------------- Serializers -------------- //
 
 // ToMap returns a map representation of the survey.
@@ -67,3 +127,39 @@ func (s *Survey) ToJson() (string, error) {
 	}
 	return string(b), nil
 }
+
+// CanonicalJSON returns a canonical JSON representation of the survey, for stable hashes and diffs:
+// object keys are sorted, the computed Position fields of questions and groups are removed and the
+// output is indented with two spaces and ends with a new line. The surveygo fmt command formats definitions
+// the same way, keeping the fields unknown to Survey.
+func (s *Survey) CanonicalJSON() ([]byte, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	// decode into maps, which are encoded with sorted keys, keeping numbers as marshalled
+	var m map[string]any
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"questions", "groups"} {
+		elements, _ := m[key].(map[string]any)
+		for _, e := range elements {
+			if e, ok := e.(map[string]any); ok {
+				delete(e, "position")
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,32 @@
+package surveygo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAnswers(t *testing.T) {
+	answers, err := ParseAnswers(strings.NewReader(`{"q-city": "Lisbon", "q-rooms": 4, "q-ratio": 0.5, "q-pets": ["dog"],
+		"q-none": null, "grp-members": [{"q-age": [34]}]}`))
+	if err != nil {
+		t.Fatalf("ParseAnswers: %v", err)
+	}
+
+	want := Answers{
+		"q-city":      {"Lisbon"},
+		"q-rooms":     {4},
+		"q-ratio":     {0.5},
+		"q-pets":      {"dog"},
+		"grp-members": {map[string]any{"q-age": []any{34}}},
+	}
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("answers = %#v", answers)
+	}
+
+	for _, invalid := range []string{`null`, `[1]`, `{`} {
+		if _, err = ParseAnswers(strings.NewReader(invalid)); err == nil {
+			t.Errorf("ParseAnswers(%s): expected error", invalid)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/rendis/surveygo/v2/question/types/external"
 	"github.com/rendis/surveygo/v2/question/types/text"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
 	"go.mongodb.org/mongo-driver/bson/bsontype"
 )
 
@@ -128,10 +129,24 @@ func (q *Question) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalBSONValue implements the bson.ValueMarshaler interface.
+// The typed Value is stored as an embedded document under "value", mirroring UnmarshalBSONValue.
+func (q Question) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	var tq = struct {
+		BaseQuestion `bson:",inline"`
+		Value        any `bson:"value,omitempty"`
+	}{
+		BaseQuestion: q.BaseQuestion,
+		Value:        q.Value,
+	}
+	return bson.MarshalValue(tq)
+}
+
+// UnmarshalBSONValue implements the bson.ValueUnmarshaler interface.
 func (q *Question) UnmarshalBSONValue(_ bsontype.Type, b []byte) error {
 	var bq BaseQuestion
 
-	if err := bson.Unmarshal(b, &bq); err != nil {
+	if err := unmarshalBSONDocument(b, &bq); err != nil {
 		return fmt.Errorf("BSON unmarshal error, %s", err)
 	}
 
@@ -208,7 +223,7 @@ func unmarshalBSONQuestionByType[T any](b []byte) (*T, error) {
 		Value *T `bson:"value"`
 	}{}
 
-	if err := bson.Unmarshal(b, &tq); err != nil {
+	if err := unmarshalBSONDocument(b, &tq); err != nil {
 		return nil, err
 	}
 
@@ -218,3 +233,15 @@ func unmarshalBSONQuestionByType[T any](b []byte) (*T, error) {
 
 	return tq.Value, nil
 }
+
+// unmarshalBSONDocument decodes a BSON document into v.
+// Embedded documents held by untyped fields (e.g. Metadata) are decoded as bson.M instead of bson.D,
+// so they keep their object shape when the question is serialized back to JSON.
+func unmarshalBSONDocument(b []byte, v any) error {
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(b))
+	if err != nil {
+		return err
+	}
+	dec.DefaultDocumentM()
+	return dec.Decode(v)
+}
@@ -12,7 +12,7 @@ import (
 type FreeText struct {
 	types.QBase `json:",inline" bson:",inline"`
 
-	// Min is an optional minimum length for the text area field.
+	// Min is an optional minimum length for the text area field, in characters (Unicode code points).
 	// Validations:
 	// - optional
 	// - if defined:
@@ -20,7 +20,7 @@ type FreeText struct {
 	//   * if max is defined, must be less than to max
 	Min *int `json:"min,omitempty" bson:"min,omitempty" validate:"omitempty,min=0,ltfield=Max"`
 
-	// Max is an optional maximum length for the text area field.
+	// Max is an optional maximum length for the text area field, in characters (Unicode code points).
 	// Validations:
 	// - optional
 	// - if defined:
@@ -2,6 +2,8 @@ package render
 
 import (
 	"encoding/json"
+	"math"
+	"strconv"
 	"strings"
 
 	surveygo "github.com/rendis/surveygo/v2"
@@ -103,6 +105,27 @@ func extractToggleValue(ans []any) bool {
 	}
 }
 
+func extractNumberValue(ans []any) (float64, bool) {
+	if len(ans) == 0 {
+		return 0, false
+	}
+	switch v := ans[0].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil && !math.IsNaN(f)
+	}
+	return 0, false
+}
+
 func extractGroupInstances(ans []any) []surveygo.Answers {
 	if len(ans) == 0 {
 		return nil
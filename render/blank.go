@@ -0,0 +1,762 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// BlankFormOptions configures the printable blank questionnaire.
+type BlankFormOptions struct {
+	// RepeatInstances is the number of blank instances printed for each repeatable group. Defaults to 3.
+	RepeatInstances int
+
+	// TextAreaLines is the number of writing lines printed for text area questions. Defaults to 4.
+	TextAreaLines int
+}
+
+// blankForm is the paper model of a survey, shared by the HTML and TipTap outputs.
+type blankForm struct {
+	Title       string
+	Description string
+	Sections    []*blankSection
+}
+
+// blankSection is a group of the survey. Top-level sections are numbered by position ("2"),
+// nested ones by their parent ("2.1").
+type blankSection struct {
+	NameId      string
+	Number      string
+	Title       string
+	Description string
+	Conditions  []string // "complete only if" instructions
+	Instances   []string // titles of the blank instances of a repeatable group, nil otherwise
+	Items       []blankItem
+
+	dependsOn [][]question.DependsOn
+	openedBy  [][]question.DependsOn
+}
+
+// blankItem is either a question or a nested section.
+type blankItem struct {
+	Question *blankQuestion
+	Section  *blankSection
+}
+
+// blankQuestion is a question printed without answer.
+type blankQuestion struct {
+	NameId     string
+	Number     string
+	Label      string
+	Kind       string
+	Required   bool
+	Hint       string
+	Conditions []string
+	Text       string // information text
+	Options    []*blankOption
+	Scale      []int // slider values, when few enough to print a box per value
+	Lines      int   // writing lines
+
+	dependsOn [][]question.DependsOn
+}
+
+// blankOption is an option of a choice question, with the sections it leads to.
+type blankOption struct {
+	NameId string
+	Label  string
+	GoTo   []string // section numbers
+
+	opens []string // group name ids opened by the option
+}
+
+// maxScaleBoxes is the maximum number of slider values printed as boxes, a writing line is printed otherwise.
+const maxScaleBoxes = 11
+
+// BlankFormHTML generates a printable blank questionnaire for offline collection.
+//
+// Questions are numbered following their survey position ("5"); questions of nested groups, which have no
+// position, are numbered after the preceding question ("5a"). Options are printed as checkboxes and free
+// text questions as writing lines. Options opening groups, and the DependsOn conditions of groups and
+// questions, are printed as "go to section" and "only if you answered" instructions. Repeatable groups
+// get opts.RepeatInstances blank instances.
+//
+// Args:
+//   - survey: the survey to render
+//   - opts: the blank form options (optional)
+//
+// Returns:
+//   - *HTMLResult: the HTML document and its print stylesheet
+//   - error: if the group tree of the survey is invalid (e.g. cycles) or the template fails
+func BlankFormHTML(survey *surveygo.Survey, opts *BlankFormOptions) (*HTMLResult, error) {
+	form, err := buildBlankForm(survey, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = blankTmpl.ExecuteTemplate(&buf, "blank", form); err != nil {
+		return nil, fmt.Errorf("executing blank form template: %w", err)
+	}
+
+	return &HTMLResult{HTML: buf.Bytes(), CSS: []byte(blankCSS)}, nil
+}
+
+// BlankFormTipTap generates a blank questionnaire as a TipTap document, with the same content as BlankFormHTML.
+// Options are printed as unchecked task items.
+//
+// Args:
+//   - survey: the survey to render
+//   - opts: the blank form options (optional)
+//
+// Returns:
+//   - *TipTapNode: the TipTap document
+//   - error: if the group tree of the survey is invalid (e.g. cycles)
+func BlankFormTipTap(survey *surveygo.Survey, opts *BlankFormOptions) (*TipTapNode, error) {
+	form, err := buildBlankForm(survey, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := TipTapNode{Type: "doc"}
+	doc.Content = append(doc.Content, heading(1, form.Title))
+	if form.Description != "" {
+		doc.Content = append(doc.Content, ttParagraph(textNode(form.Description)))
+	}
+	for _, sec := range form.Sections {
+		doc.Content = append(doc.Content, blankSectionToNodes(sec, 0)...)
+	}
+
+	return &doc, nil
+}
+
+func buildBlankForm(survey *surveygo.Survey, opts *BlankFormOptions) (*blankForm, error) {
+	if _, err := buildGroupTree(survey); err != nil {
+		return nil, fmt.Errorf("building group tree: %w", err)
+	}
+
+	if opts == nil {
+		opts = &BlankFormOptions{}
+	}
+	b := &blankBuilder{
+		survey:        survey,
+		instances:     opts.RepeatInstances,
+		textAreaLines: opts.TextAreaLines,
+		visited:       map[string]bool{},
+		sections:      map[string]*blankSection{},
+		questions:     map[string]*blankQuestion{},
+	}
+	if b.instances <= 0 {
+		b.instances = 3
+	}
+	if b.textAreaLines <= 0 {
+		b.textAreaLines = 4
+	}
+
+	form := &blankForm{Title: survey.Title, Description: derefStr(survey.Description)}
+	for i, groupNameId := range survey.GroupsOrder {
+		number := strconv.Itoa(i + 1)
+		if g, ok := survey.Groups[groupNameId]; ok && g.Position > 0 {
+			number = strconv.Itoa(g.Position)
+		}
+		if sec := b.section(groupNameId, number); sec != nil {
+			form.Sections = append(form.Sections, sec)
+		}
+	}
+
+	b.resolve()
+	return form, nil
+}
+
+// blankBuilder builds the paper model of a survey. Sections and questions are built first, then their
+// instructions are resolved, since options can lead to sections printed further on.
+type blankBuilder struct {
+	survey        *surveygo.Survey
+	instances     int
+	textAreaLines int
+
+	visited   map[string]bool
+	sections  map[string]*blankSection  // key: group name id
+	questions map[string]*blankQuestion // key: question name id
+	order     []*blankSection           // sections in print order
+	qorder    []*blankQuestion          // questions in print order
+
+	lastNumber string // number of the last question numbered by position
+	suffix     int    // questions numbered after lastNumber
+}
+
+// section builds a group and its descendants, in the same order as the fillable form: the groups opened by
+// an option follow its question, then the groups of GroupsOrder. Hidden and disabled groups are skipped.
+func (b *blankBuilder) section(nameId, number string) *blankSection {
+	g, ok := b.survey.Groups[nameId]
+	if !ok || b.visited[nameId] || g.Hidden || g.Disabled {
+		return nil
+	}
+	b.visited[nameId] = true
+
+	sec := &blankSection{
+		NameId:      nameId,
+		Number:      number,
+		Title:       derefStr(g.Title),
+		Description: derefStr(g.Description),
+		dependsOn:   g.DependsOn,
+	}
+	if sec.Title == "" {
+		sec.Title = nameId
+	}
+	if g.AllowRepeat {
+		for i := 1; i <= b.instances; i++ {
+			sec.Instances = append(sec.Instances, fmt.Sprintf("%s #%d", sec.Title, i))
+		}
+	}
+	b.sections[nameId] = sec
+	b.order = append(b.order, sec)
+
+	children := 0
+	child := func(childNameId string) {
+		if c := b.section(childNameId, fmt.Sprintf("%s.%d", number, children+1)); c != nil {
+			sec.Items = append(sec.Items, blankItem{Section: c})
+			children++
+		}
+	}
+
+	for _, questionNameId := range g.QuestionsIds {
+		q, ok := b.survey.Questions[questionNameId]
+		if !ok || !q.Visible {
+			continue
+		}
+		bq := b.question(q)
+		sec.Items = append(sec.Items, blankItem{Question: bq})
+
+		for _, o := range bq.Options {
+			for _, groupNameId := range o.opens {
+				child(groupNameId)
+			}
+		}
+	}
+
+	for _, childNameId := range g.GroupsOrder {
+		child(childNameId)
+	}
+
+	return sec
+}
+
+// question builds a question and numbers it.
+func (b *blankBuilder) question(q *question.Question) *blankQuestion {
+	bq := &blankQuestion{
+		NameId:    q.NameId,
+		Label:     q.Label,
+		Kind:      string(q.QTyp),
+		Required:  q.Required,
+		dependsOn: q.DependsOn,
+	}
+	if bq.Label == "" {
+		bq.Label = q.NameId
+	}
+
+	if q.Position > 0 {
+		bq.Number = strconv.Itoa(q.Position)
+		b.lastNumber, b.suffix = bq.Number, 0
+	} else {
+		b.suffix++
+		bq.Number = b.lastNumber + letterSuffix(b.suffix)
+	}
+	b.questions[q.NameId] = bq
+	b.qorder = append(b.qorder, bq)
+
+	switch v := q.Value.(type) {
+	case *choice.Choice:
+		for _, o := range v.Options {
+			bq.Options = append(bq.Options, &blankOption{NameId: o.NameId, Label: o.Label, opens: o.GroupsIds})
+		}
+		switch q.QTyp {
+		case types.QTypeSingleSelect, types.QTypeRadio:
+			bq.Hint = "Mark one."
+		case types.QTypeMultipleSelect, types.QTypeCheckbox:
+			bq.Hint = "Mark all that apply."
+		}
+	case *choice.Slider:
+		unit := ""
+		if v.Unit != "" {
+			unit = " " + v.Unit
+		}
+		if v.Step > 0 && (v.Max-v.Min)/v.Step < maxScaleBoxes {
+			for val := v.Min; val <= v.Max; val += v.Step {
+				bq.Scale = append(bq.Scale, val)
+			}
+			bq.Hint = "Mark one."
+			if v.Unit != "" {
+				bq.Hint = fmt.Sprintf("Mark one (%s).", v.Unit)
+			}
+		} else {
+			bq.Lines = 1
+			bq.Hint = fmt.Sprintf("Write a number from %d to %d%s.", v.Min, v.Max, unit)
+		}
+	case *text.FreeText:
+		bq.Lines = 1
+		if q.QTyp == types.QTypeTextArea {
+			bq.Lines = b.textAreaLines
+		}
+	case *text.Telephone:
+		bq.Lines = 1
+		if len(v.AllowedCountryCodes) > 0 {
+			bq.Hint = "Country code: " + strings.Join(v.AllowedCountryCodes, ", ") + "."
+		}
+	case *text.Email:
+		bq.Lines = 1
+		if len(v.AllowedDomains) > 0 {
+			bq.Hint = "Allowed domains: " + strings.Join(v.AllowedDomains, ", ") + "."
+		}
+	case *text.DateTime:
+		bq.Lines = 1
+		bq.Hint = "Format: " + humanLayout(v.Format) + "."
+	case *text.InformationText:
+		bq.Text = v.Text
+	case *asset.ImageAsset:
+		bq.Kind, bq.Hint = "asset", attachHint("image", v.MaxFiles)
+	case *asset.VideoAsset:
+		bq.Kind, bq.Hint = "asset", attachHint("video", v.MaxFiles)
+	case *asset.AudioAsset:
+		bq.Kind, bq.Hint = "asset", attachHint("audio recording", v.MaxFiles)
+	case *asset.DocumentAsset:
+		bq.Kind, bq.Hint = "asset", attachHint("document", v.MaxFiles)
+	default:
+		bq.Lines = 1
+	}
+
+	return bq
+}
+
+// resolve sets the instructions of the built sections and questions.
+func (b *blankBuilder) resolve() {
+	// options opening groups lead to their sections
+	for _, bq := range b.qorder {
+		for _, o := range bq.Options {
+			for _, groupNameId := range o.opens {
+				if sec, ok := b.sections[groupNameId]; ok {
+					o.GoTo = appendUnique(o.GoTo, sec.Number)
+					sec.openedBy = append(sec.openedBy, []question.DependsOn{{QuestionNameId: bq.NameId, OptionNameId: o.NameId}})
+				}
+			}
+		}
+	}
+
+	for _, sec := range b.order {
+		// single conditions of the DependsOn of a section also lead to it
+		for _, and := range sec.dependsOn {
+			if len(and) != 1 {
+				continue
+			}
+			if o := b.option(and[0]); o != nil {
+				o.GoTo = appendUnique(o.GoTo, sec.Number)
+			}
+		}
+
+		if c := b.conditionText(sec.openedBy); c != "" {
+			sec.Conditions = append(sec.Conditions, "Complete this section only if you answered "+c+".")
+		}
+		if c := b.conditionText(sec.dependsOn); c != "" {
+			sec.Conditions = append(sec.Conditions, "Complete this section only if you answered "+c+".")
+		}
+	}
+
+	for _, bq := range b.qorder {
+		if c := b.conditionText(bq.dependsOn); c != "" {
+			bq.Conditions = append(bq.Conditions, "Answer only if you answered "+c+".")
+		}
+	}
+}
+
+// option returns the option of a condition, nil if it is not printed.
+func (b *blankBuilder) option(dep question.DependsOn) *blankOption {
+	bq, ok := b.questions[dep.QuestionNameId]
+	if !ok {
+		return nil
+	}
+	for _, o := range bq.Options {
+		if o.NameId == dep.OptionNameId {
+			return o
+		}
+	}
+	return nil
+}
+
+// conditionText describes OR-of-ANDs conditions, e.g. `"Yes" to question 3 and "Dog" to question 4`.
+func (b *blankBuilder) conditionText(conditions [][]question.DependsOn) string {
+	var ors []string
+	for _, and := range conditions {
+		var ands []string
+		for _, dep := range and {
+			label, number := dep.OptionNameId, dep.QuestionNameId
+			if bq, ok := b.questions[dep.QuestionNameId]; ok {
+				number = bq.Number
+			}
+			if o := b.option(dep); o != nil {
+				label = o.Label
+			}
+			ands = append(ands, fmt.Sprintf("%q to question %s", label, number))
+		}
+		if len(ands) > 0 {
+			ors = append(ors, strings.Join(ands, " and "))
+		}
+	}
+	return strings.Join(ors, ", or ")
+}
+
+// letterSuffix returns the letters numbering the n-th question after a numbered one: a, b, ..., z, aa, ab...
+func letterSuffix(n int) string {
+	var s string
+	for ; n > 0; n = (n - 1) / 26 {
+		s = string(rune('a'+(n-1)%26)) + s
+	}
+	return s
+}
+
+// humanLayout describes a Go time layout for respondents, e.g. "02/01/2006" as "DD/MM/YYYY".
+func humanLayout(layout string) string {
+	return strings.NewReplacer(
+		"2006", "YYYY", "01", "MM", "02", "DD", "15", "hh", "03", "hh", "04", "mm", "05", "ss", "PM", "AM/PM",
+	).Replace(layout)
+}
+
+func attachHint(kind string, maxFiles int) string {
+	switch maxFiles {
+	case 0:
+		return fmt.Sprintf("Attach %s files to this form.", kind)
+	case 1:
+		return fmt.Sprintf("Attach one %s to this form.", kind)
+	default:
+		return fmt.Sprintf("Attach up to %d %s files to this form.", maxFiles, kind)
+	}
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// --- TipTap ---
+
+func blankSectionToNodes(sec *blankSection, depth int) []TipTapNode {
+	nodes := []TipTapNode{sectionTitle(depth, sec.Number+" "+sec.Title)}
+	if sec.Description != "" {
+		nodes = append(nodes, ttParagraph(textNode(sec.Description)))
+	}
+	for _, c := range sec.Conditions {
+		nodes = append(nodes, ttParagraph(italicText(c)))
+	}
+
+	if sec.Instances == nil {
+		return append(nodes, blankItemsToNodes(sec.Items, depth+1)...)
+	}
+
+	for i, title := range sec.Instances {
+		if i > 0 {
+			nodes = append(nodes, TipTapNode{Type: "horizontalRule"})
+		}
+		nodes = append(nodes, sectionTitle(depth+1, title))
+		nodes = append(nodes, blankItemsToNodes(sec.Items, depth+2)...)
+	}
+	return nodes
+}
+
+func blankItemsToNodes(items []blankItem, depth int) []TipTapNode {
+	var nodes []TipTapNode
+	for _, item := range items {
+		if item.Section != nil {
+			nodes = append(nodes, blankSectionToNodes(item.Section, depth)...)
+			continue
+		}
+
+		q := item.Question
+		label := q.Number + ". " + q.Label
+		if q.Required {
+			label += " *"
+		}
+		nodes = append(nodes, ttParagraph(boldText(label)))
+		for _, c := range q.Conditions {
+			nodes = append(nodes, ttParagraph(italicText(c)))
+		}
+		if q.Text != "" {
+			nodes = append(nodes, ttParagraph(textNode(q.Text)))
+		}
+		if q.Hint != "" {
+			nodes = append(nodes, ttParagraph(italicText(q.Hint)))
+		}
+
+		if len(q.Options) > 0 {
+			var tasks []TipTapNode
+			for _, o := range q.Options {
+				text := o.Label
+				if len(o.GoTo) > 0 {
+					text += " → go to section " + strings.Join(o.GoTo, ", ")
+				}
+				tasks = append(tasks, TipTapNode{
+					Type:    "taskItem",
+					Attrs:   map[string]any{"checked": false},
+					Content: []TipTapNode{ttParagraph(textNode(text))},
+				})
+			}
+			nodes = append(nodes, TipTapNode{Type: "taskList", Content: tasks})
+		}
+
+		if len(q.Scale) > 0 {
+			var boxes []string
+			for _, v := range q.Scale {
+				boxes = append(boxes, "☐ "+strconv.Itoa(v))
+			}
+			nodes = append(nodes, ttParagraph(textNode(strings.Join(boxes, "   "))))
+		}
+
+		for i := 0; i < q.Lines; i++ {
+			nodes = append(nodes, ttParagraph(textNode(strings.Repeat("_", 60))))
+		}
+	}
+	return nodes
+}
+
+func italicText(s string) TipTapNode {
+	return TipTapNode{
+		Type:  "text",
+		Text:  s,
+		Marks: []TipTapMark{{Type: "italic"}},
+	}
+}
+
+// --- HTML ---
+
+const blankCSS = `/* Blank Questionnaire — Print Styles */
+
+@page {
+  size: A4;
+  margin: 18mm 16mm;
+}
+
+.blank-body {
+  font-family: Georgia, "Times New Roman", serif;
+  font-size: 12pt;
+  color: #000;
+  line-height: 1.4;
+  max-width: 800px;
+  margin: 0 auto;
+  padding: 24px;
+}
+
+.blank-title {
+  font-size: 18pt;
+  margin: 0 0 8px;
+}
+
+.blank-description {
+  margin: 0 0 16px;
+}
+
+.blank-section {
+  margin: 16px 0;
+}
+
+.blank-section-title {
+  font-size: 14pt;
+  margin: 0 0 6px;
+  padding-bottom: 4px;
+  border-bottom: 1.5px solid #000;
+}
+
+.blank-section .blank-section-title {
+  font-size: 12.5pt;
+  border-bottom-width: 1px;
+}
+
+.blank-instance {
+  margin: 10px 0;
+  padding: 8px 10px;
+  border: 1px dashed #555;
+  break-inside: avoid;
+}
+
+.blank-instance-title {
+  font-weight: 700;
+  margin-bottom: 6px;
+}
+
+.blank-condition,
+.blank-hint {
+  font-style: italic;
+  font-size: 10.5pt;
+  margin: 2px 0 4px;
+}
+
+.blank-question {
+  margin: 10px 0 14px;
+  break-inside: avoid;
+}
+
+.blank-question-label {
+  font-weight: 700;
+}
+
+.blank-required {
+  margin-left: 2px;
+}
+
+.blank-options {
+  list-style: none;
+  margin: 4px 0 0;
+  padding: 0;
+}
+
+.blank-option {
+  margin: 3px 0;
+}
+
+.blank-box {
+  display: inline-block;
+  width: 11px;
+  height: 11px;
+  border: 1px solid #000;
+  margin-right: 8px;
+  vertical-align: -1px;
+}
+
+.blank-goto {
+  font-style: italic;
+  margin-left: 6px;
+}
+
+.blank-scale {
+  display: flex;
+  flex-wrap: wrap;
+  gap: 14px;
+  margin-top: 4px;
+}
+
+.blank-line {
+  height: 26px;
+  border-bottom: 1px solid #000;
+}
+
+.blank-attachment {
+  height: 60px;
+  margin-top: 4px;
+  border: 1px dashed #000;
+}
+
+@media print {
+  .blank-body {
+    max-width: none;
+    padding: 0;
+  }
+
+  .blank-section-title {
+    break-after: avoid;
+  }
+}
+`
+
+const blankTemplatesStr = `
+{{- define "blank" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="card.css">
+</head>
+<body class="blank-body">
+  <h1 class="blank-title">{{.Title}}</h1>
+  {{- if .Description}}
+  <p class="blank-description">{{.Description}}</p>
+  {{- end}}
+  {{- range .Sections}}
+  {{template "blank-section" .}}
+  {{- end}}
+</body>
+</html>
+{{- end -}}
+
+{{- define "blank-section" -}}
+<section class="blank-section blank-section--{{.NameId}}">
+  <h2 class="blank-section-title">{{.Number}} {{.Title}}</h2>
+  {{- if .Description}}
+  <p class="blank-description">{{.Description}}</p>
+  {{- end}}
+  {{- range .Conditions}}
+  <p class="blank-condition">{{.}}</p>
+  {{- end}}
+  {{- if .Instances}}
+  {{- $items := .Items}}
+  {{- range .Instances}}
+  <div class="blank-instance">
+    <div class="blank-instance-title">{{.}}</div>
+    {{- template "blank-items" $items}}
+  </div>
+  {{- end}}
+  {{- else}}
+  {{- template "blank-items" .Items}}
+  {{- end}}
+</section>
+{{- end -}}
+
+{{- define "blank-items" -}}
+{{- range .}}
+{{- if .Section}}
+{{template "blank-section" .Section}}
+{{- else}}
+{{template "blank-question" .Question}}
+{{- end}}
+{{- end}}
+{{- end -}}
+
+{{- define "blank-question" -}}
+<div class="blank-question blank-question--{{.Kind}}">
+  <div class="blank-question-label">{{.Number}}. {{.Label}}{{if .Required}}<span class="blank-required">*</span>{{end}}</div>
+  {{- range .Conditions}}
+  <p class="blank-condition">{{.}}</p>
+  {{- end}}
+  {{- if .Text}}
+  <p class="blank-text">{{.Text}}</p>
+  {{- end}}
+  {{- if .Hint}}
+  <p class="blank-hint">{{.Hint}}</p>
+  {{- end}}
+  {{- if .Options}}
+  <ul class="blank-options">
+    {{- range .Options}}
+    <li class="blank-option"><span class="blank-box"></span>{{.Label}}{{if .GoTo}}<span class="blank-goto">&rarr; go to section {{join .GoTo ", "}}</span>{{end}}</li>
+    {{- end}}
+  </ul>
+  {{- end}}
+  {{- if .Scale}}
+  <div class="blank-scale">
+    {{- range .Scale}}
+    <span class="blank-scale-value"><span class="blank-box"></span>{{.}}</span>
+    {{- end}}
+  </div>
+  {{- end}}
+  {{- range lines .Lines}}
+  <div class="blank-line"></div>
+  {{- end}}
+  {{- if eq .Kind "asset"}}
+  <div class="blank-attachment"></div>
+  {{- end}}
+</div>
+{{- end -}}
+`
+
+var blankTmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"join":  strings.Join,
+	"lines": func(n int) []struct{} { return make([]struct{}, n) },
+}).Parse(blankTemplatesStr))
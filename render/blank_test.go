@@ -0,0 +1,153 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+)
+
+func TestBlankFormHTML(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	res, err := BlankFormHTML(survey, &BlankFormOptions{RepeatInstances: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(res.HTML)
+
+	checks := []string{
+		`<link rel="stylesheet" href="card.css">`,
+		// numbering follows positions, nested questions are lettered
+		`1. Pets<span class="blank-required">*</span>`,
+		`1a. Breed`,
+		`2. Dog name`,
+		`8a. Job`,
+		`17. CV`,
+		// instructions
+		`Dog<span class="blank-goto">&rarr; go to section 1.1</span>`,
+		`Yes<span class="blank-goto">&rarr; go to section 2.1</span>`,
+		`Complete this section only if you answered &#34;Dog&#34; to question 1.`,
+		`Answer only if you answered &#34;Dog&#34; to question 1.`,
+		// hints
+		`Mark all that apply.`,
+		`Format: DD/MM/YYYY.`,
+		`We never share your data.`,
+	}
+	for _, c := range checks {
+		if !strings.Contains(html, c) {
+			t.Errorf("expected output to contain %q", c)
+		}
+	}
+
+	// 2 members, each with 2 jobs
+	if n := strings.Count(html, `<div class="blank-instance-title">Members #`); n != 2 {
+		t.Errorf("expected 2 member instances, got %d", n)
+	}
+	if n := strings.Count(html, `<div class="blank-instance-title">Jobs #`); n != 4 {
+		t.Errorf("expected 4 job instances, got %d", n)
+	}
+
+	if strings.Contains(html, "Secret") {
+		t.Error("hidden group should not be rendered")
+	}
+	if !strings.Contains(string(res.CSS), "@page") {
+		t.Error("expected print CSS")
+	}
+	if !strings.Contains(string(res.WithCSSPath("blank.css").HTML), `href="blank.css"`) {
+		t.Error("expected CSS path to be replaced")
+	}
+}
+
+func TestBlankFormTipTap(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	doc, err := BlankFormTipTap(survey, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var texts []string
+	var tasks, unchecked int
+	var walk func(n TipTapNode)
+	walk = func(n TipTapNode) {
+		if n.Type == "taskItem" {
+			tasks++
+			if checked, _ := n.Attrs["checked"].(bool); !checked {
+				unchecked++
+			}
+		}
+		if n.Text != "" {
+			texts = append(texts, n.Text)
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(*doc)
+	all := strings.Join(texts, "\n")
+
+	for _, c := range []string{"1 Main", "1. Pets *", "Dog → go to section 1.1", "Members #3", "Jobs #3", "Format: DD/MM/YYYY."} {
+		if !strings.Contains(all, c) {
+			t.Errorf("expected document to contain %q", c)
+		}
+	}
+	if tasks == 0 || tasks != unchecked {
+		t.Errorf("expected unchecked task items for options, got %d of %d unchecked", unchecked, tasks)
+	}
+}
+
+func TestBlankForm_DependsOnConditions(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	survey.Groups["grp-contact"].DependsOn = [][]question.DependsOn{
+		{{QuestionNameId: "q-kind", OptionNameId: "house"}},
+		{{QuestionNameId: "q-kind", OptionNameId: "flat"}, {QuestionNameId: "q-owner", OptionNameId: "owner-yes"}},
+	}
+
+	res, err := BlankFormHTML(survey, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(res.HTML)
+
+	want := `Complete this section only if you answered &#34;House&#34; to question 3, or &#34;Flat&#34; to question 3 and &#34;Yes&#34; to question 5.`
+	if !strings.Contains(html, want) {
+		t.Errorf("expected condition %q", want)
+	}
+	// only single conditions lead to the section
+	if !strings.Contains(html, `House<span class="blank-goto">&rarr; go to section 3</span>`) {
+		t.Error("expected House to lead to section 3")
+	}
+	if strings.Contains(html, `Flat<span class="blank-goto">`) {
+		t.Error("Flat alone should not lead to section 3")
+	}
+}
+
+func TestBlankForm_Cycle(t *testing.T) {
+	survey := &surveygo.Survey{
+		NameId:      "test",
+		Title:       "Test",
+		Version:     "1",
+		GroupsOrder: []string{"grp-a"},
+		Groups: map[string]*question.Group{
+			"grp-a": {NameId: "grp-a", GroupsOrder: []string{"grp-a"}},
+		},
+		Questions: map[string]*question.Question{},
+	}
+
+	if _, err := BlankFormHTML(survey, nil); err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+	if _, err := BlankFormTipTap(survey, nil); err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+}
+
+func TestLetterSuffix(t *testing.T) {
+	for n, want := range map[int]string{1: "a", 2: "b", 26: "z", 27: "aa", 28: "ab", 53: "ba"} {
+		if got := letterSuffix(n); got != want {
+			t.Errorf("letterSuffix(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
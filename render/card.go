@@ -22,8 +22,9 @@ var selectTypes = map[string]bool{
 
 func buildSurveyCard(survey *surveygo.Survey, tree *GroupTree, questions []GroupQuestions, answers surveygo.Answers) (*SurveyCard, error) {
 	card := &SurveyCard{
-		SurveyId: survey.NameId,
-		Title:    survey.Title,
+		SurveyId:      survey.NameId,
+		SurveyVersion: survey.Version,
+		Title:         survey.Title,
 	}
 
 	gqIndex := make(map[string]GroupQuestions, len(questions))
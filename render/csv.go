@@ -2,7 +2,6 @@ package render
 
 import (
 	"bytes"
-	"encoding/csv"
 	"fmt"
 
 	surveygo "github.com/rendis/surveygo/v2"
@@ -22,48 +21,82 @@ type csvColumn struct {
 	optionID   string // non-empty for multi_select/checkbox boolean columns
 }
 
-func generateMatrix(survey *surveygo.Survey, tree *GroupTree, questions []GroupQuestions, answers surveygo.Answers, cm *CheckMark) [][]string {
+// key returns the key of the column values in a row.
+func (c csvColumn) key() string {
+	return columnKey(c.questionID, c.optionID)
+}
+
+// columnKey returns the row key of a question column, or of one of its option columns.
+func columnKey(questionID, optionID string) string {
+	if optionID == "" {
+		return questionID
+	}
+	return questionID + "\x00" + optionID
+}
+
+func generateMatrix(survey *surveygo.Survey, tree *GroupTree, questions []GroupQuestions, answers surveygo.Answers, cfg *csvConfig) [][]string {
+	gqIndex := indexGroupQuestions(questions)
+
+	// 1. Build column headers via DFS of group tree.
+	cols := buildCSVColumns(survey, tree, gqIndex, cfg)
+
+	// 2. Build rows via cartesian product DFS.
+	records := answerRecords(survey, tree, gqIndex, cols, answers, cfg)
+
+	// 3. Prepend the header row.
+	matrix := make([][]string, 0, 1+len(records))
+	matrix = append(matrix, columnHeaders(cols))
+	return append(matrix, records...)
+}
+
+func indexGroupQuestions(questions []GroupQuestions) map[string]GroupQuestions {
 	gqIndex := make(map[string]GroupQuestions, len(questions))
 	for _, gq := range questions {
 		gqIndex[gq.GroupNameId] = gq
 	}
+	return gqIndex
+}
 
-	// 1. Build column headers via DFS of group tree.
+func buildCSVColumns(survey *surveygo.Survey, tree *GroupTree, gqIndex map[string]GroupQuestions, cfg *csvConfig) []csvColumn {
 	var cols []csvColumn
 	for _, root := range tree.Roots {
-		buildColumns(root, survey, gqIndex, &cols)
-	}
-
-	// 2. Build rows via cartesian product DFS.
-	rows := []map[string]string{make(map[string]string)}
-	for _, root := range tree.Roots {
-		rows = fillRows(root, answers, survey, gqIndex, cols, rows, cm)
+		buildColumns(root, survey, gqIndex, &cols, cfg, false)
 	}
+	return cols
+}
 
-	// 3. Convert to [][]string (header row + data rows).
+func columnHeaders(cols []csvColumn) []string {
 	headers := make([]string, len(cols))
 	for i, c := range cols {
 		headers[i] = c.header
 	}
+	return headers
+}
+
+// answerRecords returns the data rows of the answers of a response, repeatable groups expanded
+// via cartesian product.
+func answerRecords(survey *surveygo.Survey, tree *GroupTree, gqIndex map[string]GroupQuestions, cols []csvColumn, answers surveygo.Answers, cfg *csvConfig) [][]string {
+	rows := []map[string]string{make(map[string]string)}
+	for _, root := range tree.Roots {
+		rows = fillRows(root, answers, survey, gqIndex, cols, rows, cfg)
+	}
 
-	matrix := make([][]string, 0, 1+len(rows))
-	matrix = append(matrix, headers)
+	records := make([][]string, 0, len(rows))
 	for _, row := range rows {
 		record := make([]string, len(cols))
 		for i, c := range cols {
-			record[i] = row[c.header]
+			record[i] = row[c.key()]
 		}
-		matrix = append(matrix, record)
+		records = append(records, record)
 	}
-
-	return matrix
+	return records
 }
 
-func generateCSV(survey *surveygo.Survey, tree *GroupTree, questions []GroupQuestions, answers surveygo.Answers, cm *CheckMark) ([]byte, error) {
-	matrix := generateMatrix(survey, tree, questions, answers, cm)
+func generateCSV(survey *surveygo.Survey, tree *GroupTree, questions []GroupQuestions, answers surveygo.Answers, cfg *csvConfig) ([]byte, error) {
+	matrix := generateMatrix(survey, tree, questions, answers, cfg)
 
 	var buf bytes.Buffer
-	w := csv.NewWriter(&buf)
+	w := cfg.newWriter(&buf)
 	for _, row := range matrix {
 		if err := w.Write(row); err != nil {
 			return nil, fmt.Errorf("writing CSV row: %w", err)
@@ -78,12 +111,23 @@ func generateCSV(survey *surveygo.Survey, tree *GroupTree, questions []GroupQues
 	return buf.Bytes(), nil
 }
 
-func buildColumns(node *GroupNode, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols *[]csvColumn) {
+// buildColumns appends the columns of the selected questions of a group and its descendants.
+// groupIncluded reports whether the group or one of its ancestors is in the include list.
+func buildColumns(node *GroupNode, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols *[]csvColumn, cfg *csvConfig, groupIncluded bool) {
+	if cfg.excludeGroups[node.NameId] {
+		return
+	}
+	groupIncluded = groupIncluded || cfg.includeGroups[node.NameId]
+	before := len(*cols)
+
 	if gq, ok := gqIndex[node.NameId]; ok {
 		for _, q := range gq.Questions {
+			if !cfg.selected(q, groupIncluded) {
+				continue
+			}
 			if q.AnswerExpr != "" {
 				*cols = append(*cols, csvColumn{
-					header:     questionHeader(q),
+					header:     cfg.questionHeader(node.NameId, q),
 					questionID: q.NameId,
 					qType:      q.QuestionType,
 				})
@@ -92,7 +136,7 @@ func buildColumns(node *GroupNode, survey *surveygo.Survey, gqIndex map[string]G
 			if multiSelectTypes[q.QuestionType] {
 				for _, opt := range q.Options {
 					*cols = append(*cols, csvColumn{
-						header:     optionHeader(q, opt),
+						header:     cfg.optionHeader(node.NameId, q, opt),
 						questionID: q.NameId,
 						qType:      q.QuestionType,
 						optionID:   opt.NameId,
@@ -100,7 +144,7 @@ func buildColumns(node *GroupNode, survey *surveygo.Survey, gqIndex map[string]G
 				}
 			} else {
 				*cols = append(*cols, csvColumn{
-					header:     questionHeader(q),
+					header:     cfg.questionHeader(node.NameId, q),
 					questionID: q.NameId,
 					qType:      q.QuestionType,
 				})
@@ -109,23 +153,32 @@ func buildColumns(node *GroupNode, survey *surveygo.Survey, gqIndex map[string]G
 	}
 
 	for _, child := range node.Children {
-		buildColumns(child, survey, gqIndex, cols)
+		buildColumns(child, survey, gqIndex, cols, cfg, groupIncluded)
+	}
+
+	if len(*cols) > before {
+		cfg.withColumns[node.NameId] = true
 	}
 }
 
-func fillRows(node *GroupNode, answers surveygo.Answers, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols []csvColumn, rows []map[string]string, cm *CheckMark) []map[string]string {
+func fillRows(node *GroupNode, answers surveygo.Answers, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols []csvColumn, rows []map[string]string, cfg *csvConfig) []map[string]string {
+	// filtered out groups neither fill columns nor expand rows
+	if cfg.filtered && !cfg.withColumns[node.NameId] {
+		return rows
+	}
+
 	if node.AllowRepeat {
-		rows = expandRepeatGroup(node, answers, survey, gqIndex, cols, rows, cm)
+		rows = expandRepeatGroup(node, answers, survey, gqIndex, cols, rows, cfg)
 	} else {
-		fillGroupValues(node, answers, gqIndex, rows, cm)
+		fillGroupValues(node, answers, gqIndex, rows, cfg)
 		for _, child := range node.Children {
-			rows = fillRows(child, answers, survey, gqIndex, cols, rows, cm)
+			rows = fillRows(child, answers, survey, gqIndex, cols, rows, cfg)
 		}
 	}
 	return rows
 }
 
-func expandRepeatGroup(node *GroupNode, answers surveygo.Answers, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols []csvColumn, rows []map[string]string, cm *CheckMark) []map[string]string {
+func expandRepeatGroup(node *GroupNode, answers surveygo.Answers, survey *surveygo.Survey, gqIndex map[string]GroupQuestions, cols []csvColumn, rows []map[string]string, cfg *csvConfig) []map[string]string {
 	instances := extractGroupInstances(answers[node.NameId])
 	if len(instances) == 0 {
 		return rows
@@ -135,7 +188,7 @@ func expandRepeatGroup(node *GroupNode, answers surveygo.Answers, survey *survey
 	for _, inst := range instances {
 		for _, row := range rows {
 			cloned := cloneRow(row)
-			fillGroupValues(node, inst, gqIndex, []map[string]string{cloned}, cm)
+			fillGroupValues(node, inst, gqIndex, []map[string]string{cloned}, cfg)
 			expanded = append(expanded, cloned)
 		}
 	}
@@ -148,7 +201,7 @@ func expandRepeatGroup(node *GroupNode, answers surveygo.Answers, survey *survey
 		batch := expanded[start:end]
 
 		for _, child := range node.Children {
-			batch = fillRows(child, inst, survey, gqIndex, cols, batch, cm)
+			batch = fillRows(child, inst, survey, gqIndex, cols, batch, cfg)
 		}
 		result = append(result, batch...)
 	}
@@ -156,24 +209,19 @@ func expandRepeatGroup(node *GroupNode, answers surveygo.Answers, survey *survey
 	return result
 }
 
-func fillGroupValues(node *GroupNode, answers surveygo.Answers, gqIndex map[string]GroupQuestions, rows []map[string]string, cm *CheckMark) {
+func fillGroupValues(node *GroupNode, answers surveygo.Answers, gqIndex map[string]GroupQuestions, rows []map[string]string, cfg *csvConfig) {
 	gq, ok := gqIndex[node.NameId]
 	if !ok {
 		return
 	}
 
-	selMark, notSelMark := "true", "false"
-	if cm != nil {
-		selMark, notSelMark = cm.Selected, cm.NotSelected
-	}
-
 	for _, q := range gq.Questions {
 		ans := answers[q.NameId]
 
 		if q.AnswerExpr != "" {
 			if val, ok := evalAnswerExprString(q.AnswerExpr, ans, q.Options); ok {
 				for _, row := range rows {
-					row[questionHeader(q)] = val
+					row[columnKey(q.NameId, "")] = val
 				}
 				continue
 			}
@@ -185,19 +233,18 @@ func fillGroupValues(node *GroupNode, answers surveygo.Answers, gqIndex map[stri
 				selected[v] = true
 			}
 			for _, opt := range q.Options {
-				colName := optionHeader(q, opt)
-				val := notSelMark
+				val := cfg.notSelMark
 				if selected[opt.NameId] {
-					val = selMark
+					val = cfg.selMark
 				}
 				for _, row := range rows {
-					row[colName] = val
+					row[columnKey(q.NameId, opt.NameId)] = val
 				}
 			}
 		} else {
-			val := extractCSVValue(q.QuestionType, ans, selMark, notSelMark)
+			val := cfg.value(q, ans)
 			for _, row := range rows {
-				row[questionHeader(q)] = val
+				row[columnKey(q.NameId, "")] = val
 			}
 		}
 	}
@@ -0,0 +1,243 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CSVHeaderStyle selects the text of the CSV column headers.
+type CSVHeaderStyle string
+
+const (
+	// CSVHeaderLabel uses the question label (or placeholder, or nameId), and "Label - Option label"
+	// for multi-select option columns. Default.
+	CSVHeaderLabel CSVHeaderStyle = "label"
+
+	// CSVHeaderNameId uses the question nameId, and "question.option" nameIds for multi-select option columns.
+	CSVHeaderNameId CSVHeaderStyle = "nameId"
+
+	// CSVHeaderPath prefixes the nameId header with the nameId of the group of the question ("group.question").
+	CSVHeaderPath CSVHeaderStyle = "path"
+)
+
+// CSVValueStyle selects how single select and radio answers are written.
+type CSVValueStyle string
+
+const (
+	// CSVValueNameId writes the option nameId. Default.
+	CSVValueNameId CSVValueStyle = "nameId"
+
+	// CSVValueLabel writes the option label.
+	CSVValueLabel CSVValueStyle = "label"
+
+	// CSVValueOption writes the option Value, or the nameId for options without a value.
+	CSVValueOption CSVValueStyle = "value"
+)
+
+// CSVOptions configures the CSV and row outputs.
+// The zero value produces the default output: comma delimited, label headers and option nameIds.
+type CSVOptions struct {
+	// Delimiter separates the fields, e.g. ';' for spreadsheets in locales using the comma as decimal separator.
+	// Defaults to ','.
+	Delimiter rune
+
+	// BOM prepends a UTF-8 byte order mark, for spreadsheets to detect the encoding.
+	BOM bool
+
+	// HeaderStyle selects the text of the column headers. Defaults to CSVHeaderLabel.
+	HeaderStyle CSVHeaderStyle
+
+	// ValueStyle selects how single select and radio answers are written. Defaults to CSVValueNameId.
+	ValueStyle CSVValueStyle
+
+	// CheckMark controls the selected/not-selected strings of multi-select, checkbox and toggle columns.
+	// Defaults to "true"/"false".
+	CheckMark *CheckMark
+
+	// IncludeQuestions and IncludeGroups, if any is set, restrict the columns to the listed questions and
+	// to the questions of the listed groups and their subgroups.
+	IncludeQuestions []string
+	IncludeGroups    []string
+
+	// ExcludeQuestions and ExcludeGroups remove the listed questions, and the questions of the listed
+	// groups and their subgroups, from the columns. Exclusions take precedence over inclusions.
+	ExcludeQuestions []string
+	ExcludeGroups    []string
+
+	// DateFormat, if set, is the Go layout date_time answers are reformatted to.
+	// Answers not matching the format of their question are written as is.
+	DateFormat string
+
+	// ExcludeInformation removes the columns of information questions.
+	ExcludeInformation bool
+}
+
+// CSVOption is an option of AnswersToCSV and AnswersToRows: a *CSVOptions or a *CheckMark.
+type CSVOption interface {
+	applyCSV(opts *CSVOptions)
+}
+
+// applyCSV sets the options, keeping the CheckMark already set if opts has none.
+func (o *CSVOptions) applyCSV(opts *CSVOptions) {
+	if o == nil {
+		return
+	}
+	cm := opts.CheckMark
+	*opts = *o
+	if opts.CheckMark == nil {
+		opts.CheckMark = cm
+	}
+}
+
+// applyCSV sets the CheckMark of the options.
+func (c *CheckMark) applyCSV(opts *CSVOptions) {
+	if c != nil {
+		opts.CheckMark = c
+	}
+}
+
+// csvConfig is the resolved form of CSVOptions.
+type csvConfig struct {
+	opts                CSVOptions
+	selMark, notSelMark string
+
+	includeQuestions, includeGroups map[string]bool
+	excludeQuestions, excludeGroups map[string]bool
+	filtered                        bool            // include or exclude lists are set
+	withColumns                     map[string]bool // groups whose subtree has columns
+}
+
+// newCSVConfig resolves the given options, later options overriding earlier ones.
+func newCSVConfig(options ...CSVOption) *csvConfig {
+	var opts CSVOptions
+	for _, o := range options {
+		if o != nil {
+			o.applyCSV(&opts)
+		}
+	}
+
+	cfg := &csvConfig{
+		opts:             opts,
+		selMark:          "true",
+		notSelMark:       "false",
+		includeQuestions: toSet(opts.IncludeQuestions),
+		includeGroups:    toSet(opts.IncludeGroups),
+		excludeQuestions: toSet(opts.ExcludeQuestions),
+		excludeGroups:    toSet(opts.ExcludeGroups),
+		withColumns:      make(map[string]bool),
+	}
+	if opts.CheckMark != nil {
+		cfg.selMark, cfg.notSelMark = opts.CheckMark.Selected, opts.CheckMark.NotSelected
+	}
+	cfg.filtered = len(cfg.includeQuestions)+len(cfg.includeGroups)+len(cfg.excludeQuestions)+len(cfg.excludeGroups) > 0 ||
+		opts.ExcludeInformation
+	return cfg
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// selected reports whether a question gets columns.
+// groupIncluded reports whether its group or one of its ancestors is in the include list.
+func (cfg *csvConfig) selected(q QuestionInfo, groupIncluded bool) bool {
+	if cfg.opts.ExcludeInformation && q.QuestionType == "information" {
+		return false
+	}
+	if cfg.excludeQuestions[q.NameId] {
+		return false
+	}
+	if len(cfg.includeQuestions) == 0 && len(cfg.includeGroups) == 0 {
+		return true
+	}
+	return groupIncluded || cfg.includeQuestions[q.NameId]
+}
+
+func (cfg *csvConfig) questionHeader(groupID string, q QuestionInfo) string {
+	switch cfg.opts.HeaderStyle {
+	case CSVHeaderNameId:
+		return q.NameId
+	case CSVHeaderPath:
+		return groupID + "." + q.NameId
+	default:
+		return questionHeader(q)
+	}
+}
+
+func (cfg *csvConfig) optionHeader(groupID string, q QuestionInfo, opt OptionInfo) string {
+	switch cfg.opts.HeaderStyle {
+	case CSVHeaderNameId, CSVHeaderPath:
+		return cfg.questionHeader(groupID, q) + "." + opt.NameId
+	default:
+		return optionHeader(q, opt)
+	}
+}
+
+// value returns the CSV value of a question answer, for questions without option columns.
+func (cfg *csvConfig) value(q QuestionInfo, ans []any) string {
+	switch q.QuestionType {
+	case "single_select", "radio":
+		v := extractSelectValue(ans)
+		if v == "" || cfg.opts.ValueStyle == "" || cfg.opts.ValueStyle == CSVValueNameId {
+			return v
+		}
+		for _, opt := range q.Options {
+			if opt.NameId != v {
+				continue
+			}
+			if cfg.opts.ValueStyle == CSVValueLabel && opt.Label != "" {
+				return opt.Label
+			}
+			if cfg.opts.ValueStyle == CSVValueOption && opt.Value != nil {
+				return fmt.Sprintf("%v", opt.Value)
+			}
+		}
+		return v
+	case "date_time":
+		v := extractTextValue(ans)
+		if v == "" || cfg.opts.DateFormat == "" {
+			return v
+		}
+		t, err := time.Parse(q.Format, v)
+		if err != nil {
+			return v
+		}
+		return t.Format(cfg.opts.DateFormat)
+	default:
+		return extractCSVValue(q.QuestionType, ans, cfg.selMark, cfg.notSelMark)
+	}
+}
+
+// newWriter returns a CSV writer with the configured delimiter, writing the BOM first if enabled.
+func (cfg *csvConfig) newWriter(w io.Writer) *csv.Writer {
+	if cfg.opts.BOM {
+		w = &bomWriter{w: w}
+	}
+	cw := csv.NewWriter(w)
+	if cfg.opts.Delimiter != 0 {
+		cw.Comma = cfg.opts.Delimiter
+	}
+	return cw
+}
+
+// bomWriter writes a UTF-8 byte order mark before the first write.
+type bomWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (b *bomWriter) Write(p []byte) (int, error) {
+	if !b.written {
+		b.written = true
+		if _, err := b.w.Write([]byte("\uFEFF")); err != nil {
+			return 0, err
+		}
+	}
+	return b.w.Write(p)
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bytes"
+	"reflect"
+	"slices"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+func TestAnswersToCSV_DelimiterAndBOM(t *testing.T) {
+	survey := newMultiSelectSurvey()
+	answers := surveygo.Answers{"q-colors": {"red"}}
+
+	out, err := AnswersToCSV(survey, answers, &CSVOptions{Delimiter: ';', BOM: true})
+	if err != nil {
+		t.Fatalf("AnswersToCSV: %v", err)
+	}
+
+	want := "\uFEFFColors - Red;Colors - Green;Colors - Blue\ntrue;false;false\n"
+	if string(out) != want {
+		t.Errorf("CSV = %q, want %q", out, want)
+	}
+}
+
+func TestAnswersToRows_HeaderStyles(t *testing.T) {
+	survey := newMultiSelectSurvey()
+
+	tests := []struct {
+		style CSVHeaderStyle
+		want  []string
+	}{
+		{"", []string{"Colors - Red", "Colors - Green", "Colors - Blue"}},
+		{CSVHeaderNameId, []string{"q-colors.red", "q-colors.green", "q-colors.blue"}},
+		{CSVHeaderPath, []string{"grp-main.q-colors.red", "grp-main.q-colors.green", "grp-main.q-colors.blue"}},
+	}
+	for _, tt := range tests {
+		matrix, err := AnswersToRows(survey, nil, &CSVOptions{HeaderStyle: tt.style})
+		if err != nil {
+			t.Fatalf("AnswersToRows: %v", err)
+		}
+		if !reflect.DeepEqual(matrix[0], tt.want) {
+			t.Errorf("style %q headers = %v, want %v", tt.style, matrix[0], tt.want)
+		}
+	}
+}
+
+func TestAnswersToRows_ValueStyles(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	tests := []struct {
+		style CSVValueStyle
+		want  string
+	}{
+		{"", "tech"},
+		{CSVValueLabel, "Tecnología"},
+		{CSVValueOption, "1"},
+	}
+	for _, tt := range tests {
+		matrix, err := AnswersToRows(survey, answers, &CSVOptions{HeaderStyle: CSVHeaderNameId, ValueStyle: tt.style})
+		if err != nil {
+			t.Fatalf("AnswersToRows: %v", err)
+		}
+		idx := slices.Index(matrix[0], "company_industry")
+		if got := matrix[1][idx]; got != tt.want {
+			t.Errorf("style %q value = %q, want %q", tt.style, got, tt.want)
+		}
+	}
+}
+
+func TestAnswersToRows_ColumnSelection(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	// groups outside the selection do not expand rows
+	matrix, err := AnswersToRows(survey, answers, &CSVOptions{
+		HeaderStyle:      CSVHeaderNameId,
+		IncludeGroups:    []string{"grp-company_info"},
+		IncludeQuestions: []string{"dept_name"},
+		ExcludeQuestions: []string{"company_rut"},
+	})
+	if err != nil {
+		t.Fatalf("AnswersToRows: %v", err)
+	}
+	if want := []string{"company_name", "company_industry", "dept_name"}; !reflect.DeepEqual(matrix[0], want) {
+		t.Errorf("headers = %v, want %v", matrix[0], want)
+	}
+	if len(matrix) != 3 {
+		t.Errorf("expected 3 rows (header + 2 departments), got %d", len(matrix))
+	}
+
+	matrix, err = AnswersToRows(survey, answers, &CSVOptions{HeaderStyle: CSVHeaderNameId, ExcludeGroups: []string{"grp-departments"}})
+	if err != nil {
+		t.Fatalf("AnswersToRows: %v", err)
+	}
+	if len(matrix) != 2 || slices.Contains(matrix[0], "dept_name") || slices.Contains(matrix[0], "emp_name") {
+		t.Errorf("excluded group still present: %v", matrix)
+	}
+}
+
+func TestAnswersToRows_DateFormatAndInformation(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	answers := surveygo.Answers{"q-birth": {"24/12/1990"}}
+
+	matrix, err := AnswersToRows(survey, answers, &CSVOptions{HeaderStyle: CSVHeaderNameId, DateFormat: "2006-01-02", ExcludeInformation: true})
+	if err != nil {
+		t.Fatalf("AnswersToRows: %v", err)
+	}
+	if slices.Contains(matrix[0], "q-info") {
+		t.Error("information question should be excluded")
+	}
+	if got := matrix[1][slices.Index(matrix[0], "q-birth")]; got != "1990-12-24" {
+		t.Errorf("q-birth = %q, want 1990-12-24", got)
+	}
+}
+
+func TestAnswersTo_CSVOptions(t *testing.T) {
+	survey := newToggleSurvey()
+	answers := surveygo.Answers{"q-agree": {true}}
+
+	res, err := AnswersTo(survey, answers, OutputOptions{
+		CSV:        true,
+		CheckMark:  &CheckMark{Selected: "X", NotSelected: ""},
+		CSVOptions: &CSVOptions{HeaderStyle: CSVHeaderNameId},
+	})
+	if err != nil {
+		t.Fatalf("AnswersTo: %v", err)
+	}
+	if !bytes.Equal(res.CSV, []byte("q-agree\nX\n")) {
+		t.Errorf("CSV = %q", res.CSV)
+	}
+}
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// CSV metadata column headers.
+const (
+	// CSVResponseIDHeader is the header of the response id metadata column.
+	CSVResponseIDHeader = "response_id"
+
+	// CSVSubmittedAtHeader is the header of the submission time metadata column.
+	CSVSubmittedAtHeader = "submitted_at"
+)
+
+// CSVWriterOptions configures a CSVWriter.
+type CSVWriterOptions struct {
+	// CSVOptions control the delimiter, BOM, headers, values and columns, as in AnswersToCSV.
+	CSVOptions
+
+	// ResponseID prepends a response_id column.
+	ResponseID bool
+
+	// SubmittedAt prepends a submitted_at column, after the response_id column if any,
+	// holding the submission time in RFC 3339 format.
+	SubmittedAt bool
+}
+
+// CSVWriter streams the answers of many responses of a survey as CSV.
+//
+// Columns are computed once from the survey and the header row is written once, before the
+// first row. Each response is written as the rows AnswersToRows returns for it (one row, or one
+// per combination of repeatable group instances), optionally prefixed by metadata columns.
+// Memory use does not grow with the number of responses written.
+type CSVWriter struct {
+	w       *csv.Writer
+	survey  *surveygo.Survey
+	tree    *GroupTree
+	gqIndex map[string]GroupQuestions
+	cfg     *csvConfig
+	cols    []csvColumn
+	opts    CSVWriterOptions
+	started bool
+}
+
+// NewCSVWriter creates a streaming CSV writer of the answers of a survey.
+// Args:
+//   - w: the writer the CSV is written to
+//   - survey: the survey the answers belong to
+//   - opts: the writer options (optional)
+//
+// Returns:
+//   - *CSVWriter: the writer, ready to write responses
+//   - error: if the survey groups are invalid (e.g. cycles)
+func NewCSVWriter(w io.Writer, survey *surveygo.Survey, opts *CSVWriterOptions) (*CSVWriter, error) {
+	tree, err := buildGroupTree(survey)
+	if err != nil {
+		return nil, fmt.Errorf("building group tree: %w", err)
+	}
+	questions, err := extractGroupQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("extracting questions: %w", err)
+	}
+
+	cw := &CSVWriter{
+		survey:  survey,
+		tree:    tree,
+		gqIndex: indexGroupQuestions(questions),
+	}
+	if opts != nil {
+		cw.opts = *opts
+	}
+	cw.cfg = newCSVConfig(&cw.opts.CSVOptions)
+	cw.w = cw.cfg.newWriter(w)
+	cw.cols = buildCSVColumns(survey, tree, cw.gqIndex, cw.cfg)
+	return cw, nil
+}
+
+// Header returns the header row, metadata columns included.
+func (cw *CSVWriter) Header() []string {
+	return append(cw.metadata(CSVResponseIDHeader, CSVSubmittedAtHeader), columnHeaders(cw.cols)...)
+}
+
+// Write writes the rows of the answers of a response. The submitted_at column, if enabled, is left empty.
+// Args:
+//   - responseID: the id of the response, written to the response_id column if enabled
+//   - answers: the answers of the response
+//
+// Returns:
+//   - error: if the rows cannot be written
+func (cw *CSVWriter) Write(responseID string, answers surveygo.Answers) error {
+	return cw.WriteAt(responseID, time.Time{}, answers)
+}
+
+// WriteAt writes the rows of the answers of a response submitted at the given time.
+// Args:
+//   - responseID: the id of the response, written to the response_id column if enabled
+//   - submittedAt: the submission time, written to the submitted_at column if enabled (empty if zero)
+//   - answers: the answers of the response
+//
+// Returns:
+//   - error: if the rows cannot be written
+func (cw *CSVWriter) WriteAt(responseID string, submittedAt time.Time, answers surveygo.Answers) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+
+	var submitted string
+	if !submittedAt.IsZero() {
+		submitted = submittedAt.Format(time.RFC3339)
+	}
+	meta := cw.metadata(responseID, submitted)
+
+	for _, record := range answerRecords(cw.survey, cw.tree, cw.gqIndex, cw.cols, answers, cw.cfg) {
+		if len(meta) > 0 {
+			record = append(append(make([]string, 0, len(meta)+len(record)), meta...), record...)
+		}
+		if err := cw.w.Write(record); err != nil {
+			return fmt.Errorf("writing CSV row: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows, and the header row if no response was written, to the underlying writer.
+// Returns:
+//   - error: if the rows cannot be written
+func (cw *CSVWriter) Flush() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	if err := cw.w.Error(); err != nil {
+		return fmt.Errorf("flushing CSV: %w", err)
+	}
+	return nil
+}
+
+func (cw *CSVWriter) writeHeader() error {
+	if cw.started {
+		return nil
+	}
+	cw.started = true
+	if err := cw.w.Write(cw.Header()); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+	return nil
+}
+
+// metadata returns the enabled metadata values.
+func (cw *CSVWriter) metadata(responseID, submittedAt string) []string {
+	var meta []string
+	if cw.opts.ResponseID {
+		meta = append(meta, responseID)
+	}
+	if cw.opts.SubmittedAt {
+		meta = append(meta, submittedAt)
+	}
+	return meta
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func readCSV(t *testing.T, b []byte) [][]string {
+	t.Helper()
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV: %v", err)
+	}
+	return records
+}
+
+func TestCSVWriter_MatchesAnswersToRows(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	want, err := AnswersToRows(survey, answers)
+	if err != nil {
+		t.Fatalf("AnswersToRows: %v", err)
+	}
+
+	var buf bytes.Buffer
+	cw, err := NewCSVWriter(&buf, survey, nil)
+	if err != nil {
+		t.Fatalf("NewCSVWriter: %v", err)
+	}
+	if err = cw.Write("r1", answers); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err = cw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if got := readCSV(t, buf.Bytes()); !reflect.DeepEqual(got, want) {
+		t.Errorf("CSVWriter rows differ from AnswersToRows:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestCSVWriter_ManyResponses(t *testing.T) {
+	survey := loadSurvey(t, "sample.json")
+	answers := loadAnswersFile(t, "sample_answers.json")
+
+	var buf bytes.Buffer
+	cw, err := NewCSVWriter(&buf, survey, &CSVWriterOptions{ResponseID: true, SubmittedAt: true})
+	if err != nil {
+		t.Fatalf("NewCSVWriter: %v", err)
+	}
+	submitted := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	if err = cw.WriteAt("r1", submitted, answers); err != nil {
+		t.Fatalf("WriteAt: %v", err)
+	}
+	if err = cw.Write("r2", answers); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err = cw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	records := readCSV(t, buf.Bytes())
+	// 1 header + 2 responses of 2 adults each
+	if len(records) != 5 {
+		t.Fatalf("expected 5 rows, got %d", len(records))
+	}
+	if records[0][0] != CSVResponseIDHeader || records[0][1] != CSVSubmittedAtHeader {
+		t.Errorf("metadata headers = %v", records[0][:2])
+	}
+	for i, want := range [][]string{{"r1", "2024-05-01T10:30:00Z"}, {"r1", "2024-05-01T10:30:00Z"}, {"r2", ""}, {"r2", ""}} {
+		if got := records[i+1][:2]; !reflect.DeepEqual(got, want) {
+			t.Errorf("row %d metadata = %v, want %v", i+1, got, want)
+		}
+	}
+	if !reflect.DeepEqual(records[0], cw.Header()) {
+		t.Errorf("header row = %v, want %v", records[0], cw.Header())
+	}
+}
+
+func TestCSVWriter_HeaderOnly(t *testing.T) {
+	var buf bytes.Buffer
+	cw, err := NewCSVWriter(&buf, newToggleSurvey(), nil)
+	if err != nil {
+		t.Fatalf("NewCSVWriter: %v", err)
+	}
+	if err = cw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err = cw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	records := readCSV(t, buf.Bytes())
+	if len(records) != 1 || records[0][0] != "Agree" {
+		t.Errorf("expected a single header row, got %v", records)
+	}
+}
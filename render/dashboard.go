@@ -0,0 +1,246 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/analytics"
+)
+
+const (
+	dashboardChartWidth  = "900px"
+	dashboardChartHeight = "420px"
+
+	// dashboardPieMaxOptions is the maximum number of options of single answer questions drawn as pies.
+	dashboardPieMaxOptions = 6
+
+	// dashboardMaxWords is the maximum number of words of free text charts.
+	dashboardMaxWords = 20
+)
+
+// DashboardOptions configures ResultsDashboardHTML.
+type DashboardOptions struct {
+	// NPSQuestions are the name ids of the 0-10 slider questions drawn as a Net Promoter Score gauge, see
+	// analytics.NPSSummary. Sliders with other ranges are drawn as histograms.
+	NPSQuestions []string
+
+	// EChartsJS is the source of the ECharts library inlined in the page, replacing the embedded one.
+	EChartsJS []byte
+
+	// RemoteECharts loads the ECharts library from the go-echarts assets host instead of inlining it,
+	// for lighter pages that need network access to show their charts. EChartsJS is ignored.
+	RemoteECharts bool
+
+	// Weighted draws the weighted counts of the results instead of the response counts.
+	Weighted bool
+}
+
+var echartsScriptRe = regexp.MustCompile(`<script src="[^"]*echarts\.min\.js"></script>`)
+
+// renderDashboard renders one chart per summarized question, in GroupsOrder, as a single HTML page.
+func renderDashboard(survey *surveygo.Survey, results *analytics.Results, o *DashboardOptions) ([]byte, error) {
+	questions, err := extractGroupQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("extracting questions: %w", err)
+	}
+
+	summaries := make(map[string]*analytics.QuestionSummary, len(results.Questions))
+	for _, s := range results.Questions {
+		summaries[s.NameId] = s
+	}
+
+	page := components.NewPage()
+	page.SetPageTitle(dashboardTitle(survey)).SetLayout(components.PageFlexLayout)
+
+	for _, gq := range questions {
+		for _, q := range gq.Questions {
+			s, ok := summaries[q.NameId]
+			if !ok {
+				continue
+			}
+			title := opts.Title{Title: q.Label, Subtitle: dashboardSubtitle(survey, gq.GroupNameId, s)}
+			if chart := questionChart(q, s, title, slices.Contains(o.NPSQuestions, q.NameId), o.Weighted); chart != nil {
+				page.AddCharts(chart)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = page.Render(&buf); err != nil {
+		return nil, fmt.Errorf("rendering dashboard: %w", err)
+	}
+	if o.RemoteECharts {
+		return buf.Bytes(), nil
+	}
+	js := o.EChartsJS
+	if len(js) == 0 {
+		js = echartsLibrary()
+	}
+	if len(js) == 0 {
+		return nil, fmt.Errorf("ECharts library not embedded: run go generate ./render, or set EChartsJS or RemoteECharts")
+	}
+
+	script := "<script>" + strings.ReplaceAll(string(js), "</script", `<\/script`) + "</script>"
+	return echartsScriptRe.ReplaceAllLiteral(buf.Bytes(), []byte(script)), nil
+}
+
+func dashboardTitle(survey *surveygo.Survey) string {
+	if survey.Title != "" {
+		return survey.Title
+	}
+	return survey.NameId
+}
+
+// dashboardSubtitle returns the group title and the exposure of the question.
+func dashboardSubtitle(survey *surveygo.Survey, groupID string, s *analytics.QuestionSummary) string {
+	group := groupID
+	if g, ok := survey.Groups[groupID]; ok && g.Title != nil && *g.Title != "" {
+		group = *g.Title
+	}
+	return fmt.Sprintf("%s · answered %d of %d (skip rate %.1f%%)", group, s.Answered, s.Seen, s.SkipRate*100)
+}
+
+// count returns the weighted count if weighted, the count otherwise, rounded for display.
+func count(n int, w float64, weighted bool) float64 {
+	if weighted {
+		return math.Round(w*100) / 100
+	}
+	return float64(n)
+}
+
+// questionChart returns the chart of a question summary, nil if the summary has nothing to draw.
+func questionChart(q QuestionInfo, s *analytics.QuestionSummary, title opts.Title, nps, weighted bool) components.Charter {
+	switch {
+	case s.Choice != nil:
+		return choiceChart(q, s.Choice, title, weighted)
+	case s.Numeric != nil && s.Numeric.NPS != nil && nps:
+		return npsChart(s.Numeric.NPS, title, weighted)
+	case s.Numeric != nil:
+		return histogramChart(s.Numeric, title, weighted)
+	case s.Date != nil && len(s.Date.ByMonth) > 0:
+		return bucketsChart(s.Date.ByMonth, "Answers", title, false, weighted)
+	case s.Text != nil && len(s.Text.Words) > 0:
+		words := s.Text.Words[:min(len(s.Text.Words), dashboardMaxWords)]
+		return bucketsChart(words, "Occurrences", title, true, weighted)
+	}
+	return nil
+}
+
+func chartGlobalOptions(title opts.Title) []charts.GlobalOpts {
+	return []charts.GlobalOpts{
+		charts.WithTitleOpts(title),
+		charts.WithInitializationOpts(opts.Initialization{Width: dashboardChartWidth, Height: dashboardChartHeight}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+	}
+}
+
+// choiceChart draws single answer questions with few options as a pie, other choice questions as bars.
+func choiceChart(q QuestionInfo, cs *analytics.ChoiceSummary, title opts.Title, weighted bool) components.Charter {
+	single := q.QuestionType != "multi_select" && q.QuestionType != "checkbox"
+	if single && len(cs.Options) <= dashboardPieMaxOptions {
+		var data []opts.PieData
+		for _, opt := range cs.Options {
+			data = append(data, opts.PieData{Name: optionCountLabel(opt), Value: count(opt.Count, opt.WeightedCount, weighted)})
+		}
+		pie := charts.NewPie()
+		pie.SetGlobalOptions(append(chartGlobalOptions(title),
+			charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Orient: "vertical", Left: "left", Top: "middle"}))...)
+		pie.AddSeries(q.NameId, data,
+			charts.WithPieChartOpts(opts.PieChart{Radius: []string{"35%", "65%"}, Center: []string{"60%", "55%"}}),
+			charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Formatter: "{b}: {c} ({d}%)"}),
+		)
+		return pie
+	}
+
+	var names []string
+	var data []opts.BarData
+	for _, opt := range cs.Options {
+		names = append(names, optionCountLabel(opt))
+		data = append(data, opts.BarData{Value: count(opt.Count, opt.WeightedCount, weighted)})
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(chartGlobalOptions(title)...)
+	bar.SetXAxis(names).AddSeries("Answers", data, charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Position: "top"}))
+	return bar
+}
+
+func optionCountLabel(opt analytics.OptionCount) string {
+	if opt.Label != "" {
+		return opt.Label
+	}
+	return opt.NameId
+}
+
+func histogramChart(ns *analytics.NumericSummary, title opts.Title, weighted bool) components.Charter {
+	var names []string
+	var data []opts.BarData
+	for _, b := range ns.Histogram {
+		names = append(names, binLabel(b))
+		data = append(data, opts.BarData{Value: count(b.Count, b.WeightedCount, weighted)})
+	}
+	if weighted {
+		title.Subtitle += fmt.Sprintf(" · weighted mean %.2f, median %.2f, sd %.2f", ns.WeightedMean, ns.WeightedMedian, ns.WeightedStdDev)
+	} else {
+		title.Subtitle += fmt.Sprintf(" · mean %.2f, median %.2f, sd %.2f", ns.Mean, ns.Median, ns.StdDev)
+	}
+
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(chartGlobalOptions(title)...)
+	bar.SetXAxis(names).AddSeries("Answers", data, charts.WithBarChartOpts(opts.BarChart{BarCategoryGap: "2%"}))
+	return bar
+}
+
+// binLabel returns the value of unit bins starting at an integer, the range of other bins.
+func binLabel(b analytics.Bin) string {
+	if b.To-b.From == 1 && b.From == math.Trunc(b.From) {
+		return fmt.Sprintf("%g", b.From)
+	}
+	return fmt.Sprintf("%g–%g", b.From, b.To)
+}
+
+// npsChart draws the Net Promoter Score of a 0-10 slider as a gauge.
+func npsChart(nps *analytics.NPSSummary, title opts.Title, weighted bool) components.Charter {
+	score := nps.Score
+	if weighted {
+		score = nps.WeightedScore
+	}
+	score = math.Round(score)
+
+	gauge := charts.NewGauge()
+	gauge.SetGlobalOptions(chartGlobalOptions(title)...)
+	gauge.AddSeries("NPS", []opts.GaugeData{{Name: "NPS", Value: score}},
+		charts.WithSeriesOpts(func(s *charts.SingleSeries) {
+			s.Min, s.Max = -100, 100
+		}),
+	)
+	return gauge
+}
+
+// bucketsChart draws bucket counts as bars, horizontal bars listing the first bucket at the top if horizontal.
+func bucketsChart(buckets []analytics.Bucket, series string, title opts.Title, horizontal, weighted bool) components.Charter {
+	var names []string
+	var data []opts.BarData
+	for _, b := range buckets {
+		names = append(names, b.Key)
+		data = append(data, opts.BarData{Value: count(b.Count, b.WeightedCount, weighted)})
+	}
+
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(chartGlobalOptions(title)...)
+	if horizontal {
+		slices.Reverse(names)
+		slices.Reverse(data)
+		bar.SetXAxis(names).AddSeries(series, data).XYReversal()
+		return bar
+	}
+	bar.SetXAxis(names).AddSeries(series, data)
+	return bar
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/analytics"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+)
+
+func dashboardResults(t *testing.T, survey *surveygo.Survey) *analytics.Results {
+	t.Helper()
+	agg, err := analytics.NewAggregator(survey)
+	if err != nil {
+		t.Fatalf("NewAggregator: %v", err)
+	}
+	agg.Add(surveygo.Answers{
+		"q-pets": {"dog", "cat"}, "q-kind": {"house"}, "q-rooms": {float64(9)},
+		"q-birth": {"24/12/1990"}, "q-notes": {"Quiet neighbourhood"},
+	})
+	agg.Add(surveygo.Answers{
+		"q-pets": {"cat"}, "q-kind": {"flat"}, "q-rooms": {float64(3)},
+		"q-birth": {"02/01/1985"}, "q-notes": {"quiet and green"},
+	})
+	return agg.Results()
+}
+
+func TestResultsDashboardHTML(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := ResultsDashboardHTML(survey, dashboardResults(t, survey), &DashboardOptions{RemoteECharts: true})
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+
+	// one chart per summarized question, in GroupsOrder, titled with the question label
+	last := -1
+	for _, label := range []string{`"Pets"`, `"Kind"`, `"Rooms"`, `"Owner"`, `"Colors"`, `"Birth date"`, `"Notes"`} {
+		i := bytes.Index(out, []byte(label))
+		if i < 0 {
+			t.Fatalf("missing chart %s", label)
+		}
+		if i < last {
+			t.Errorf("chart %s out of order", label)
+		}
+		last = i
+	}
+	for _, typ := range []string{`"type":"pie"`, `"type":"bar"`, `"quiet"`} {
+		if !bytes.Contains(out, []byte(typ)) {
+			t.Errorf("missing %s", typ)
+		}
+	}
+	// information and asset questions have nothing to draw
+	for _, label := range []string{`"Info"`, `"Photo"`} {
+		if bytes.Contains(out, []byte(label)) {
+			t.Errorf("unexpected chart %s", label)
+		}
+	}
+	if bytes.Contains(out, []byte(`"type":"gauge"`)) {
+		t.Error("unexpected gauge without NPS questions")
+	}
+}
+
+func TestResultsDashboardHTML_Options(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := ResultsDashboardHTML(survey, dashboardResults(t, survey), &DashboardOptions{
+		NPSQuestions: []string{"q-rooms"},
+		EChartsJS:    []byte("var echarts = {};"),
+	})
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+
+	if !bytes.Contains(out, []byte(`"type":"gauge"`)) {
+		t.Error("missing NPS gauge")
+	}
+	// one promoter (9) and one detractor (3)
+	if !bytes.Contains(out, []byte(`"value":0`)) {
+		t.Error("NPS gauge value should be 0")
+	}
+	if bytes.Contains(out, []byte("echarts.min.js")) || !bytes.Contains(out, []byte("<script>var echarts = {};</script>")) {
+		t.Error("ECharts library should be inlined")
+	}
+}
+
+func TestResultsDashboardHTML_ECharts(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	results := dashboardResults(t, survey)
+
+	out, err := ResultsDashboardHTML(survey, results, &DashboardOptions{RemoteECharts: true})
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+	if !echartsScriptRe.Match(out) {
+		t.Error("remote ECharts script expected")
+	}
+
+	// the embedded library is inlined by default
+	embedded := echartsLibrary
+	defer func() { echartsLibrary = embedded }()
+	echartsLibrary = func() []byte { return []byte("var echarts = {embedded: true};</script>") }
+
+	out, err = ResultsDashboardHTML(survey, results)
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+	if echartsScriptRe.Match(out) || !bytes.Contains(out, []byte(`<script>var echarts = {embedded: true};<\/script></script>`)) {
+		t.Error("embedded ECharts library should be inlined by default")
+	}
+
+	// without the embedded library the page is not rendered
+	echartsLibrary = func() []byte { return nil }
+	if _, err = ResultsDashboardHTML(survey, results); err == nil || !strings.Contains(err.Error(), "go generate ./render") {
+		t.Errorf("expected an error without the embedded library, got %v", err)
+	}
+}
+
+func TestResultsDashboardHTML_Weighted(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	agg, err := analytics.NewAggregator(survey)
+	if err != nil {
+		t.Fatalf("NewAggregator: %v", err)
+	}
+	if err = agg.AddWeighted(surveygo.Answers{"q-rooms": {float64(9)}}, 3); err != nil {
+		t.Fatalf("AddWeighted: %v", err)
+	}
+	if err = agg.AddWeighted(surveygo.Answers{"q-rooms": {float64(3)}}, 1); err != nil {
+		t.Fatalf("AddWeighted: %v", err)
+	}
+
+	out, err := ResultsDashboardHTML(survey, agg.Results(), &DashboardOptions{NPSQuestions: []string{"q-rooms"}, Weighted: true, RemoteECharts: true})
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+	// weighted promoters 3 of 4, detractors 1 of 4
+	if !bytes.Contains(out, []byte(`"value":50`)) {
+		t.Error("weighted NPS gauge value should be 50")
+	}
+}
+
+func TestResultsDashboardHTML_NPSRange(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	slider, err := choice.CastToSlider(survey.Questions["q-rooms"].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slider.Max = 20
+
+	// sliders not ranging within 0-10 are drawn as histograms
+	out, err := ResultsDashboardHTML(survey, dashboardResults(t, survey), &DashboardOptions{NPSQuestions: []string{"q-rooms"}, RemoteECharts: true})
+	if err != nil {
+		t.Fatalf("ResultsDashboardHTML: %v", err)
+	}
+	if bytes.Contains(out, []byte(`"type":"gauge"`)) {
+		t.Error("unexpected NPS gauge for a 1-20 slider")
+	}
+}
@@ -0,0 +1,21 @@
+package render
+
+import (
+	"embed"
+)
+
+//go:generate curl -sSfL -o assets/echarts/echarts.min.js https://go-echarts.github.io/go-echarts-assets/assets/echarts.min.js
+
+// echartsAssets holds the ECharts library inlined in the dashboards.
+//
+//go:embed assets/echarts
+var echartsAssets embed.FS
+
+// echartsLibrary returns the embedded ECharts library, nil if echarts.min.js was not generated.
+var echartsLibrary = func() []byte {
+	b, err := echartsAssets.ReadFile("assets/echarts/echarts.min.js")
+	if err != nil {
+		return nil
+	}
+	return b
+}
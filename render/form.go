@@ -0,0 +1,874 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/asset"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+// FormOptions configures the fillable form generated by SurveyFormHTML.
+type FormOptions struct {
+	// Action is the URL the answers are POSTed to as JSON. When empty, the answers are only
+	// delivered through the cancelable "surveygo:submit" DOM event (detail.answers).
+	Action string
+
+	// UploadURL is the URL asset files are POSTed to (multipart field "file") before submitting.
+	// The answer of an asset question is the "url" of the JSON responses. When empty, files are
+	// submitted inline as data URLs.
+	UploadURL string
+
+	// StylesheetURL, if set, is linked instead of inlining the default form CSS.
+	StylesheetURL string
+
+	// SubmitLabel is the label of the submit button. Defaults to "Submit".
+	SubmitLabel string
+
+	// AddLabel is the label of the button adding an instance to a repeatable group. Defaults to "Add".
+	AddLabel string
+
+	// RemoveLabel is the label of the button removing an instance of a repeatable group. Defaults to "Remove".
+	RemoveLabel string
+
+	// SuccessMessage is shown when the Action responds with a 2xx status. Defaults to "Thank you, your answers were submitted.".
+	SuccessMessage string
+}
+
+// formConfig is the client-side configuration of the form, serialized in the data-config attribute.
+type formConfig struct {
+	Action         string `json:"action,omitempty"`
+	UploadURL      string `json:"uploadUrl,omitempty"`
+	SuccessMessage string `json:"successMessage"`
+}
+
+// formPage is the template data of a fillable form.
+type formPage struct {
+	Title         string
+	Description   string
+	Config        string
+	StylesheetURL string
+	CSS           template.CSS
+	JS            template.JS
+	SubmitLabel   string
+	AddLabel      string
+	RemoveLabel   string
+	Items         []formItem
+}
+
+// formItem is either a question or a group of a form.
+type formItem struct {
+	Question *formQuestion
+	Group    *formGroup
+}
+
+// formGroup is a group rendered as a fieldset.
+type formGroup struct {
+	NameId      string
+	Title       string
+	Description string
+	Repeat      bool
+	DependsOn   string // JSON OR-of-ANDs conditions, empty if none
+	OpenedBy    string // JSON OR-of-ANDs of the options opening the group, empty if none
+	Items       []formItem
+}
+
+// formQuestion is a question rendered as a form field.
+type formQuestion struct {
+	NameId      string
+	Name        string // input name, unique per repeat instance
+	Label       string
+	Kind        string
+	Required    bool
+	Disabled    bool
+	DependsOn   string
+	Placeholder string
+
+	// choice
+	Options []formOption
+
+	// slider
+	Min, Max, Step, Value int
+	Unit                  string
+
+	// text
+	MinLength, MaxLength int
+	InputType            string
+	Format               string
+	DateType             string
+	Text                 string
+	CountryCodes         []string
+
+	// asset
+	Accept   string
+	Multiple bool
+	MaxSize  int64
+}
+
+// formOption is an option of a choice question.
+type formOption struct {
+	NameId   string
+	Label    string
+	Selected bool
+}
+
+// SurveyFormHTML generates a self-contained fillable HTML form for the survey.
+//
+// Every question type is rendered as an input: selects, radios and checkboxes for choices, a range
+// for sliders, a checkbox for toggles, file inputs for assets, and text, email, telephone and
+// date/time inputs for text questions. Repeatable groups get add/remove controls.
+//
+// The embedded script shows and hides questions and groups as their DependsOn conditions (and the
+// options opening them) are satisfied, and submits the answers of the visible questions in the shape
+// expected by Survey.ReviewAnswers: a list of values per question, and a list of instances per
+// repeatable group.
+//
+// Args:
+//   - survey: the survey to render
+//   - opts: the form options (optional)
+//
+// Returns:
+//   - []byte: the HTML document
+//   - error: if the group tree of the survey is invalid (e.g. cycles) or the template fails
+func SurveyFormHTML(survey *surveygo.Survey, opts *FormOptions) ([]byte, error) {
+	if _, err := buildGroupTree(survey); err != nil {
+		return nil, fmt.Errorf("building group tree: %w", err)
+	}
+
+	if opts == nil {
+		opts = &FormOptions{}
+	}
+
+	cfg, err := json.Marshal(formConfig{
+		Action:         opts.Action,
+		UploadURL:      opts.UploadURL,
+		SuccessMessage: orDefault(opts.SuccessMessage, "Thank you, your answers were submitted."),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encoding form config: %w", err)
+	}
+
+	page := formPage{
+		Title:         survey.Title,
+		Description:   derefStr(survey.Description),
+		Config:        string(cfg),
+		StylesheetURL: opts.StylesheetURL,
+		JS:            template.JS(formJS),
+		SubmitLabel:   orDefault(opts.SubmitLabel, "Submit"),
+		AddLabel:      orDefault(opts.AddLabel, "Add"),
+		RemoveLabel:   orDefault(opts.RemoveLabel, "Remove"),
+	}
+	if opts.StylesheetURL == "" {
+		page.CSS = template.CSS(formCSS)
+	}
+
+	b := newFormBuilder(survey)
+	for _, groupNameId := range survey.GroupsOrder {
+		if g := b.group(groupNameId, false); g != nil {
+			page.Items = append(page.Items, formItem{Group: g})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = formTmpl.ExecuteTemplate(&buf, "form", page); err != nil {
+		return nil, fmt.Errorf("executing form template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formBuilder builds the form items of a survey.
+type formBuilder struct {
+	survey  *surveygo.Survey
+	visited map[string]bool
+	openers map[string][][]question.DependsOn // key: group name id, value: the options opening the group
+}
+
+func newFormBuilder(survey *surveygo.Survey) *formBuilder {
+	b := &formBuilder{survey: survey, visited: map[string]bool{}, openers: map[string][][]question.DependsOn{}}
+	questionNameIds := make([]string, 0, len(survey.Questions))
+	for nameId := range survey.Questions {
+		questionNameIds = append(questionNameIds, nameId)
+	}
+	sort.Strings(questionNameIds)
+
+	for _, nameId := range questionNameIds {
+		q := survey.Questions[nameId]
+		if !types.IsSimpleChoiceType(q.QTyp) {
+			continue
+		}
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
+		for _, o := range c.Options {
+			for _, groupNameId := range o.GroupsIds {
+				b.openers[groupNameId] = append(b.openers[groupNameId], []question.DependsOn{{QuestionNameId: q.NameId, OptionNameId: o.NameId}})
+			}
+		}
+	}
+	return b
+}
+
+// group builds a group and its descendants. Hidden and disabled groups are never active, so they are skipped.
+// Groups opened by options are only shown while one of the options is selected.
+// inRepeat is true inside a repeatable group.
+func (b *formBuilder) group(nameId string, inRepeat bool) *formGroup {
+	g, ok := b.survey.Groups[nameId]
+	if !ok || b.visited[nameId] || g.Hidden || g.Disabled {
+		return nil
+	}
+	b.visited[nameId] = true
+
+	fg := &formGroup{
+		NameId:      nameId,
+		Title:       derefStr(g.Title),
+		Description: derefStr(g.Description),
+		Repeat:      g.AllowRepeat,
+		DependsOn:   conditionsJSON(g.DependsOn),
+		OpenedBy:    conditionsJSON(b.openers[nameId]),
+	}
+	inRepeat = inRepeat || g.AllowRepeat
+
+	for _, questionNameId := range g.QuestionsIds {
+		q, ok := b.survey.Questions[questionNameId]
+		if !ok || !q.Visible {
+			continue
+		}
+		fg.Items = append(fg.Items, formItem{Question: formQuestionOf(q, inRepeat)})
+
+		// groups opened by the options of the question are shown right after it
+		if !types.IsSimpleChoiceType(q.QTyp) {
+			continue
+		}
+		c, err := choice.CastToChoice(q.Value)
+		if err != nil {
+			continue
+		}
+		for _, o := range c.Options {
+			for _, childNameId := range o.GroupsIds {
+				if child := b.group(childNameId, inRepeat); child != nil {
+					fg.Items = append(fg.Items, formItem{Group: child})
+				}
+			}
+		}
+	}
+
+	for _, childNameId := range g.GroupsOrder {
+		if child := b.group(childNameId, inRepeat); child != nil {
+			fg.Items = append(fg.Items, formItem{Group: child})
+		}
+	}
+
+	return fg
+}
+
+// formQuestionOf maps a question to its form field.
+func formQuestionOf(q *question.Question, inRepeat bool) *formQuestion {
+	fq := &formQuestion{
+		NameId:      q.NameId,
+		Name:        q.NameId,
+		Label:       q.Label,
+		Kind:        string(q.QTyp),
+		Required:    q.Required,
+		Disabled:    q.Disabled,
+		DependsOn:   conditionsJSON(q.DependsOn),
+		Placeholder: getPlaceholder(q),
+	}
+	if inRepeat {
+		// replaced by a unique instance id when an instance is added
+		fq.Name = q.NameId + "-__IDX__"
+	}
+	if fq.Label == "" {
+		fq.Label = q.NameId
+	}
+
+	switch v := q.Value.(type) {
+	case *choice.Choice:
+		defaults := make(map[string]bool, len(v.Defaults))
+		for _, d := range v.Defaults {
+			defaults[d] = true
+		}
+		for _, o := range v.Options {
+			fq.Options = append(fq.Options, formOption{NameId: o.NameId, Label: o.Label, Selected: defaults[o.NameId]})
+		}
+	case *choice.Slider:
+		fq.Min, fq.Max, fq.Step, fq.Unit = v.Min, v.Max, v.Step, v.Unit
+		fq.Value = v.Default
+		if fq.Value < v.Min || fq.Value > v.Max {
+			fq.Value = v.Min
+		}
+	case *text.FreeText:
+		fq.MinLength, fq.MaxLength = derefInt(v.Min), derefInt(v.Max)
+	case *text.Telephone:
+		fq.CountryCodes = v.AllowedCountryCodes
+	case *text.DateTime:
+		fq.Format = v.Format
+		fq.DateType = string(v.Type)
+		fq.InputType = map[text.DateTypeFormat]string{
+			text.DateTypeFormatDate:     "date",
+			text.DateTypeFormatTime:     "time",
+			text.DateTypeFormatDateTime: "datetime-local",
+		}[v.Type]
+	case *text.InformationText:
+		fq.Text = v.Text
+	case *asset.ImageAsset:
+		fq.setAsset("image/*", v.AllowedContentTypes, v.MaxFiles, v.MaxSize)
+	case *asset.VideoAsset:
+		fq.setAsset("video/*", v.AllowedContentTypes, v.MaxFiles, v.MaxSize)
+	case *asset.AudioAsset:
+		fq.setAsset("audio/*", v.AllowedContentTypes, v.MaxFiles, v.MaxSize)
+	case *asset.DocumentAsset:
+		fq.setAsset("", v.AllowedContentTypes, v.MaxFiles, v.MaxSize)
+	}
+
+	return fq
+}
+
+// setAsset sets the file input attributes of an asset question.
+func (fq *formQuestion) setAsset(defaultAccept string, contentTypes []string, maxFiles int, maxSize *int64) {
+	fq.Kind = "asset"
+	fq.Accept = defaultAccept
+	if len(contentTypes) > 0 {
+		fq.Accept = strings.Join(contentTypes, ",")
+	}
+	fq.Multiple = maxFiles != 1
+	if maxSize != nil {
+		fq.MaxSize = *maxSize
+	}
+}
+
+// conditionsJSON returns the JSON representation of OR-of-ANDs conditions, empty if there are none.
+func conditionsJSON(conditions [][]question.DependsOn) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(conditions)
+	return string(b)
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+const formTemplatesStr = `
+{{- define "form" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <title>{{.Title}}</title>
+  {{- if .StylesheetURL}}
+  <link rel="stylesheet" href="{{.StylesheetURL}}">
+  {{- else}}
+  <style>{{.CSS}}</style>
+  {{- end}}
+</head>
+<body class="form-body">
+  <form id="surveygo-form" class="form" novalidate data-config="{{.Config}}">
+    <h1 class="form-title">{{.Title}}</h1>
+    {{- if .Description}}
+    <p class="form-description">{{.Description}}</p>
+    {{- end}}
+    {{- range .Items}}
+    {{template "form-item" (formItemData $ .)}}
+    {{- end}}
+    <div class="form-actions">
+      <button type="submit" class="form-submit">{{.SubmitLabel}}</button>
+    </div>
+    <div class="form-status" role="status" hidden></div>
+  </form>
+  <script>{{.JS}}</script>
+</body>
+</html>
+{{- end -}}
+
+{{- define "form-item" -}}
+{{- if .Item.Question}}{{template "form-question" .Item.Question}}
+{{- else}}{{template "form-group" (formGroupData .Page .Item.Group)}}
+{{- end -}}
+{{- end -}}
+
+{{- define "form-group" -}}
+{{- $page := .Page}}
+{{- with .Group}}
+<fieldset class="form-group{{if .Repeat}} form-repeat{{end}}" data-group="{{.NameId}}"
+  {{- if .Repeat}} data-repeat{{end}}
+  {{- if .DependsOn}} data-depends-on="{{.DependsOn}}"{{end}}
+  {{- if .OpenedBy}} data-opened-by="{{.OpenedBy}}"{{end}}>
+  {{- if .Title}}
+  <legend class="form-group-title">{{.Title}}</legend>
+  {{- end}}
+  {{- if .Description}}
+  <p class="form-group-description">{{.Description}}</p>
+  {{- end}}
+  {{- if .Repeat}}
+  <div class="form-instances"></div>
+  <template>
+    <div class="form-instance">
+      {{- range .Items}}
+      {{template "form-item" (formItemData $page .)}}
+      {{- end}}
+      <button type="button" class="form-remove" data-action="remove">{{$page.RemoveLabel}}</button>
+    </div>
+  </template>
+  <button type="button" class="form-add" data-action="add">{{$page.AddLabel}}</button>
+  {{- else}}
+  {{- range .Items}}
+  {{template "form-item" (formItemData $page .)}}
+  {{- end}}
+  {{- end}}
+</fieldset>
+{{- end}}
+{{- end -}}
+
+{{- define "form-question" -}}
+<fieldset class="form-question form-question--{{.Kind}}" data-question="{{.NameId}}" data-kind="{{.Kind}}"
+  {{- if .Required}} data-required{{end}}
+  {{- if .Disabled}} data-disabled disabled{{end}}
+  {{- if .DependsOn}} data-depends-on="{{.DependsOn}}"{{end}}
+  {{- if .Format}} data-format="{{.Format}}" data-date-type="{{.DateType}}"{{end}}>
+  {{- if eq .Kind "information"}}
+  <div class="form-information">{{if .Text}}{{.Text}}{{else}}{{.Label}}{{end}}</div>
+  {{- else if or (eq .Kind "radio") (eq .Kind "checkbox")}}
+  <legend class="form-label">{{.Label}}{{if .Required}} <span class="form-required">*</span>{{end}}</legend>
+  {{- $q := .}}
+  {{- range .Options}}
+  <label class="form-option"><input type="{{$q.Kind}}" name="{{$q.Name}}" value="{{.NameId}}"
+    {{- if .Selected}} checked{{end}}{{if and $q.Required (eq $q.Kind "radio")}} required{{end}}> {{.Label}}</label>
+  {{- end}}
+  {{- else if eq .Kind "toggle"}}
+  <label class="form-toggle"><input type="checkbox" name="{{.Name}}"> {{.Label}}</label>
+  {{- else}}
+  <label class="form-label" for="{{.Name}}">{{.Label}}{{if .Required}} <span class="form-required">*</span>{{end}}</label>
+  {{- if or (eq .Kind "single_select") (eq .Kind "multi_select")}}
+  <select id="{{.Name}}" name="{{.Name}}"{{if eq .Kind "multi_select"}} multiple{{end}}{{if .Required}} required{{end}}>
+    {{- if eq .Kind "single_select"}}
+    <option value="">{{.Placeholder}}</option>
+    {{- end}}
+    {{- range .Options}}
+    <option value="{{.NameId}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>
+    {{- end}}
+  </select>
+  {{- else if eq .Kind "slider"}}
+  <div class="form-slider">
+    <input type="range" id="{{.Name}}" name="{{.Name}}" min="{{.Min}}" max="{{.Max}}" step="{{.Step}}" value="{{.Value}}">
+    <output>{{.Value}}</output>{{if .Unit}} <span class="form-unit">{{.Unit}}</span>{{end}}
+  </div>
+  {{- else if eq .Kind "text_area"}}
+  <textarea id="{{.Name}}" name="{{.Name}}" rows="4"{{template "form-text-attrs" .}}></textarea>
+  {{- else if eq .Kind "telephone"}}
+  <div class="form-telephone">
+    {{- if .CountryCodes}}
+    <select name="{{.Name}}-code" data-role="country-code" aria-label="Country code">
+      {{- range .CountryCodes}}
+      <option value="{{.}}">{{.}}</option>
+      {{- end}}
+    </select>
+    {{- end}}
+    <input type="tel" id="{{.Name}}" name="{{.Name}}"{{template "form-text-attrs" .}}>
+  </div>
+  {{- else if eq .Kind "date_time"}}
+  <input type="{{.InputType}}" id="{{.Name}}" name="{{.Name}}"{{if .Required}} required{{end}}>
+  {{- else if eq .Kind "asset"}}
+  <input type="file" id="{{.Name}}" name="{{.Name}}"{{if .Accept}} accept="{{.Accept}}"{{end}}
+    {{- if .Multiple}} multiple{{end}}{{if .MaxSize}} data-max-size="{{.MaxSize}}"{{end}}{{if .Required}} required{{end}}>
+  {{- else}}
+  <input type="{{if eq .Kind "email"}}email{{else}}text{{end}}" id="{{.Name}}" name="{{.Name}}"{{template "form-text-attrs" .}}>
+  {{- end}}
+  {{- end}}
+</fieldset>
+{{- end -}}
+
+{{- define "form-text-attrs" -}}
+{{- if .Placeholder}} placeholder="{{.Placeholder}}"{{end}}
+{{- if .MinLength}} minlength="{{.MinLength}}"{{end}}
+{{- if .MaxLength}} maxlength="{{.MaxLength}}"{{end}}
+{{- if .Required}} required{{end}}
+{{- end -}}
+`
+
+var formTmpl = template.Must(template.New("").Funcs(template.FuncMap{
+	"formItemData": func(page formPage, item formItem) map[string]any {
+		return map[string]any{"Page": page, "Item": item}
+	},
+	"formGroupData": func(page formPage, g *formGroup) map[string]any {
+		return map[string]any{"Page": page, "Group": g}
+	},
+}).Parse(formTemplatesStr))
+
+const formCSS = `
+.form-body {
+  font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif;
+  font-size: 14px;
+  color: #1a1a1a;
+  line-height: 1.5;
+  max-width: 760px;
+  margin: 0 auto;
+  padding: 24px;
+}
+.form-title { font-size: 20px; margin: 0 0 8px; }
+.form-description { color: #6b7280; margin: 0 0 24px; }
+.form-group {
+  margin: 0 0 20px;
+  padding: 16px;
+  border: 1px solid #e5e7eb;
+  border-radius: 8px;
+}
+.form-group .form-group { border-style: dashed; }
+.form-group-title { font-weight: 600; padding: 0 4px; }
+.form-group-description { color: #6b7280; margin: 0 0 12px; }
+.form-question { border: none; margin: 0 0 14px; padding: 0; min-width: 0; }
+.form-label { display: block; font-weight: 500; margin-bottom: 4px; padding: 0; }
+.form-required { color: #dc2626; }
+.form-option, .form-toggle { display: block; margin: 2px 0; }
+.form-question input[type=text], .form-question input[type=email], .form-question input[type=tel],
+.form-question input[type=date], .form-question input[type=time], .form-question input[type=datetime-local],
+.form-question select, .form-question textarea {
+  box-sizing: border-box;
+  width: 100%;
+  padding: 6px 8px;
+  border: 1px solid #d1d5db;
+  border-radius: 6px;
+  font: inherit;
+}
+.form-telephone { display: flex; gap: 8px; }
+.form-telephone select { width: auto; }
+.form-slider { display: flex; align-items: center; gap: 8px; }
+.form-slider input { flex: 1; }
+.form-information { color: #374151; background: #f9fafb; padding: 8px 12px; border-radius: 6px; }
+.form-instance {
+  margin: 0 0 12px;
+  padding: 12px;
+  border: 1px dashed #d1d5db;
+  border-radius: 6px;
+  background: #fafafa;
+}
+.form-add, .form-remove, .form-submit {
+  font: inherit;
+  padding: 6px 14px;
+  border-radius: 6px;
+  border: 1px solid #d1d5db;
+  background: #fff;
+  cursor: pointer;
+}
+.form-submit { background: #2563eb; border-color: #2563eb; color: #fff; }
+.form-status { margin-top: 16px; padding: 8px 12px; border-radius: 6px; background: #f3f4f6; }
+.form-status--error { background: #fee2e2; color: #991b1b; }
+[hidden] { display: none !important; }
+`
+
+const formJS = `
+(function () {
+  'use strict';
+  var form = document.getElementById('surveygo-form');
+  var config = JSON.parse(form.getAttribute('data-config') || '{}');
+  var seq = 0;
+
+  function attrJSON(el, name) {
+    var v = el.getAttribute(name);
+    return v ? JSON.parse(v) : null;
+  }
+
+  function directChildren(el, selector) {
+    return Array.prototype.filter.call(el.children, function (c) { return c.matches(selector); });
+  }
+
+  // --- values ---
+
+  var monthNames = ['January', 'February', 'March', 'April', 'May', 'June', 'July', 'August', 'September', 'October', 'November', 'December'];
+  var dayNames = ['Sunday', 'Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday'];
+
+  function pad(n, w) { n = String(n); while (n.length < w) { n = '0' + n; } return n; }
+
+  // goFormat formats a date with a Go time layout (e.g. "2006-01-02" or "02/01/2006 15:04").
+  function goFormat(d, layout) {
+    var offset = -d.getTimezoneOffset();
+    var sign = offset < 0 ? '-' : '+';
+    var tz = pad(Math.floor(Math.abs(offset) / 60), 2) + ':' + pad(Math.abs(offset) % 60, 2);
+    var h12 = d.getHours() % 12 === 0 ? 12 : d.getHours() % 12;
+    var tokens = [
+      ['January', monthNames[d.getMonth()]], ['Jan', monthNames[d.getMonth()].slice(0, 3)],
+      ['Monday', dayNames[d.getDay()]], ['Mon', dayNames[d.getDay()].slice(0, 3)],
+      ['2006', pad(d.getFullYear(), 4)], ['Z07:00', offset === 0 ? 'Z' : sign + tz], ['-07:00', sign + tz],
+      ['-0700', sign + tz.replace(':', '')], ['15', pad(d.getHours(), 2)], ['01', pad(d.getMonth() + 1, 2)],
+      ['02', pad(d.getDate(), 2)], ['_2', (d.getDate() < 10 ? ' ' : '') + d.getDate()], ['03', pad(h12, 2)],
+      ['04', pad(d.getMinutes(), 2)], ['05', pad(d.getSeconds(), 2)], ['06', pad(d.getFullYear() % 100, 2)],
+      ['PM', d.getHours() < 12 ? 'AM' : 'PM'], ['pm', d.getHours() < 12 ? 'am' : 'pm'],
+      ['1', String(d.getMonth() + 1)], ['2', String(d.getDate())], ['3', String(h12)],
+      ['4', String(d.getMinutes())], ['5', String(d.getSeconds())]
+    ];
+    var out = '';
+    for (var i = 0; i < layout.length;) {
+      var match = null;
+      for (var t = 0; t < tokens.length; t++) {
+        if (layout.substr(i, tokens[t][0].length) === tokens[t][0]) { match = tokens[t]; break; }
+      }
+      if (match) { out += match[1]; i += match[0].length; } else { out += layout[i]; i++; }
+    }
+    return out;
+  }
+
+  function dateValue(q, raw) {
+    var type = q.getAttribute('data-date-type');
+    var d;
+    if (type === 'time') {
+      var hm = raw.split(':');
+      d = new Date(2000, 0, 1, +hm[0], +hm[1], +(hm[2] || 0));
+    } else {
+      var parts = raw.split('T');
+      var ymd = parts[0].split('-');
+      var t = (parts[1] || '0:0').split(':');
+      d = new Date(+ymd[0], +ymd[1] - 1, +ymd[2], +t[0], +t[1], +(t[2] || 0));
+    }
+    return goFormat(d, q.getAttribute('data-format'));
+  }
+
+  // values returns the answers of a question in the shape expected by Survey.ReviewAnswers.
+  function values(q) {
+    var kind = q.getAttribute('data-kind');
+    var inputs = q.querySelectorAll('input, select, textarea');
+    switch (kind) {
+      case 'information':
+        return [];
+      case 'radio':
+      case 'checkbox':
+        return Array.prototype.filter.call(inputs, function (i) { return i.checked; }).map(function (i) { return i.value; });
+      case 'single_select':
+      case 'multi_select':
+        return Array.prototype.filter.call(inputs[0].options, function (o) { return o.selected && o.value; }).map(function (o) { return o.value; });
+      case 'toggle':
+        return [inputs[0].checked];
+      case 'slider':
+        return [parseInt(inputs[0].value, 10)];
+      case 'telephone':
+        var code = q.querySelector('[data-role=country-code]');
+        var number = q.querySelector('input').value.trim();
+        if (!number) { return []; }
+        return code ? [code.value, number] : [number];
+      case 'date_time':
+        return inputs[0].value ? [dateValue(q, inputs[0].value)] : [];
+      case 'asset':
+        return q._files || [];
+      default:
+        var v = inputs[0].value;
+        return v.trim() ? [v] : [];
+    }
+  }
+
+  // --- visibility ---
+
+  // findQuestion looks for a question in the repeat instances enclosing an element, then in the whole form.
+  function findQuestion(el, nameId) {
+    var selector = '[data-question="' + nameId + '"]';
+    for (var scope = el.closest('.form-instance'); scope; scope = scope.parentElement.closest('.form-instance')) {
+      var q = scope.querySelector(selector);
+      if (q) { return q; }
+    }
+    return form.querySelector(selector);
+  }
+
+  function selected(q) {
+    if (!q || q.closest('[hidden]') || q.disabled) { return []; }
+    return values(q);
+  }
+
+  // satisfied evaluates OR-of-ANDs conditions, as Survey.evaluateDependsOn does.
+  function satisfied(el, conditions) {
+    if (!conditions || conditions.length === 0) { return true; }
+    return conditions.some(function (and) {
+      return and.every(function (dep) {
+        return selected(findQuestion(el, dep.questionNameId)).indexOf(dep.optionNameId) >= 0;
+      });
+    });
+  }
+
+  function refresh() {
+    var conditional = form.querySelectorAll('[data-depends-on], [data-opened-by]');
+    // visibility changes can cascade, repeat until stable
+    for (var pass = 0; pass < 10; pass++) {
+      var changed = false;
+      Array.prototype.forEach.call(conditional, function (el) {
+        var show = satisfied(el, attrJSON(el, 'data-depends-on')) && satisfied(el, attrJSON(el, 'data-opened-by'));
+        if (el.hidden === show) {
+          el.hidden = !show;
+          el.disabled = !show || el.hasAttribute('data-disabled');
+          changed = true;
+        }
+      });
+      if (!changed) { break; }
+    }
+  }
+
+  // --- repeatable groups ---
+
+  function addInstance(repeat) {
+    var template = directChildren(repeat, 'template')[0];
+    var fragment = template.content.cloneNode(true);
+    var id = ++seq;
+    Array.prototype.forEach.call(fragment.querySelectorAll('[id], [for], [name]'), function (el) {
+      ['id', 'for', 'name'].forEach(function (attr) {
+        if (el.hasAttribute(attr)) { el.setAttribute(attr, el.getAttribute(attr).split('__IDX__').join(id)); }
+      });
+    });
+    var instance = fragment.firstElementChild;
+    directChildren(repeat, '.form-instances')[0].appendChild(fragment);
+    init(instance);
+  }
+
+  function init(root) {
+    Array.prototype.forEach.call(root.querySelectorAll('[data-repeat]'), function (repeat) {
+      if (directChildren(repeat, '.form-instances')[0].children.length === 0) { addInstance(repeat); }
+    });
+  }
+
+  // --- answers ---
+
+  // collect returns the answers of the visible questions of an element: a list of values per question,
+  // and a list of instances per repeatable group.
+  function collect(root) {
+    var answers = {};
+    (function walk(el) {
+      Array.prototype.forEach.call(el.children, function (child) {
+        if (child.hidden || child.disabled || child.tagName === 'TEMPLATE') { return; }
+        var nameId = child.getAttribute('data-question');
+        if (nameId) {
+          var v = values(child);
+          if (v.length > 0) { answers[nameId] = v; }
+          return;
+        }
+        if (child.hasAttribute('data-repeat')) {
+          var instances = Array.prototype.map.call(directChildren(child, '.form-instances')[0].children, collect)
+            .filter(function (i) { return Object.keys(i).length > 0; });
+          if (instances.length > 0) { answers[child.getAttribute('data-group')] = instances; }
+          return;
+        }
+        walk(child);
+      });
+    })(root);
+    return answers;
+  }
+
+  function readFile(file) {
+    if (config.uploadUrl) {
+      var body = new FormData();
+      body.append('file', file);
+      return fetch(config.uploadUrl, { method: 'POST', body: body })
+        .then(function (r) {
+          if (!r.ok) { throw new Error('upload failed: ' + r.status); }
+          return r.json();
+        })
+        .then(function (res) { return res.url; });
+    }
+    return new Promise(function (resolve, reject) {
+      var reader = new FileReader();
+      reader.onload = function () { resolve(reader.result); };
+      reader.onerror = function () { reject(reader.error); };
+      reader.readAsDataURL(file);
+    });
+  }
+
+  function onFiles(input) {
+    var q = input.closest('[data-question]');
+    var maxSize = parseInt(input.getAttribute('data-max-size') || '0', 10);
+    var files = Array.prototype.slice.call(input.files);
+    var tooLarge = files.filter(function (f) { return maxSize > 0 && f.size > maxSize; });
+    input.setCustomValidity(tooLarge.length ? tooLarge[0].name + ' exceeds ' + maxSize + ' bytes' : '');
+    q._files = [];
+    q._pending = Promise.all(files.map(readFile)).then(function (list) { q._files = list; });
+  }
+
+  // checkRequired reports required checkbox and multi select questions without answers.
+  function checkRequired() {
+    var ok = true;
+    Array.prototype.forEach.call(form.querySelectorAll('[data-required][data-kind=checkbox]'), function (q) {
+      var first = q.querySelector('input');
+      var missing = !q.closest('[hidden]') && !q.disabled && values(q).length === 0;
+      first.setCustomValidity(missing ? 'Select at least one option.' : '');
+      ok = ok && !missing;
+    });
+    return ok;
+  }
+
+  function status(message, error) {
+    var el = form.querySelector('.form-status');
+    el.textContent = message;
+    el.classList.toggle('form-status--error', !!error);
+    el.hidden = !message;
+  }
+
+  function errorMessage(body, statusCode) {
+    var err = body && body.error;
+    if (!err) { return 'Request failed with status ' + statusCode + '.'; }
+    var lines = [err.message || err.code];
+    var invalid = (err.resume && err.resume.invalidAnswers) || [];
+    invalid.forEach(function (i) { lines.push(i.questionNameId + ': ' + i.error); });
+    return lines.join('\n');
+  }
+
+  form.addEventListener('click', function (ev) {
+    var button = ev.target.closest('[data-action]');
+    if (!button) { return; }
+    if (button.getAttribute('data-action') === 'add') {
+      addInstance(button.closest('[data-repeat]'));
+    } else {
+      button.closest('.form-instance').remove();
+    }
+    refresh();
+  });
+
+  form.addEventListener('input', function (ev) {
+    if (ev.target.type === 'range') { ev.target.parentElement.querySelector('output').textContent = ev.target.value; }
+  });
+
+  form.addEventListener('change', function (ev) {
+    if (ev.target.type === 'file') { onFiles(ev.target); }
+    refresh();
+  });
+
+  form.addEventListener('submit', function (ev) {
+    ev.preventDefault();
+    if (!checkRequired() || !form.reportValidity()) { return; }
+
+    var pending = Array.prototype.map.call(form.querySelectorAll('[data-kind=asset]'), function (q) { return q._pending; });
+    Promise.all(pending).then(function () {
+      var answers = collect(form);
+      var proceed = form.dispatchEvent(new CustomEvent('surveygo:submit', { detail: { answers: answers }, cancelable: true }));
+      if (!proceed || !config.action) { return null; }
+
+      status('', false);
+      return fetch(config.action, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(answers)
+      }).then(function (r) {
+        return r.json().catch(function () { return null; }).then(function (body) {
+          form.dispatchEvent(new CustomEvent('surveygo:response', { detail: { status: r.status, body: body } }));
+          if (r.ok) { status(config.successMessage, false); } else { status(errorMessage(body, r.status), true); }
+        });
+      });
+    }).catch(function (err) { status(String(err && err.message || err), true); });
+  });
+
+  init(form);
+  refresh();
+})();
+`
@@ -0,0 +1,188 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question"
+)
+
+func TestSurveyFormHTML_RendersEveryKind(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := SurveyFormHTML(survey, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(out)
+
+	for _, kind := range []string{
+		"checkbox", "single_select", "slider", "toggle", "multi_select", "radio", "input_text",
+		"information", "email", "telephone", "date_time", "text_area", "identification_number",
+		"external_question", "asset",
+	} {
+		if !strings.Contains(html, `data-kind="`+kind+`"`) {
+			t.Errorf("expected a question of kind %s", kind)
+		}
+	}
+
+	checks := []string{
+		`<style>`,
+		`id="surveygo-form"`,
+		`data-format="02/01/2006" data-date-type="date"`,
+		`type="date"`,
+		`type="range"`,
+		`accept="image/png"`,
+		`data-max-size="1048576"`,
+		`value="&#43;56"`,
+	}
+	for _, c := range checks {
+		if !strings.Contains(html, c) {
+			t.Errorf("expected output to contain %q", c)
+		}
+	}
+
+	// hidden groups are never active
+	if strings.Contains(html, "grp-secret") || strings.Contains(html, "q-secret") {
+		t.Error("hidden group should not be rendered")
+	}
+}
+
+func TestSurveyFormHTML_Conditions(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := SurveyFormHTML(survey, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(out)
+
+	// the group opened by the "dog" option follows its question
+	pets := strings.Index(html, `data-question="q-pets"`)
+	dog := strings.Index(html, `data-group="grp-dog" data-opened-by="[[{&#34;questionNameId&#34;:&#34;q-pets&#34;,&#34;optionNameId&#34;:&#34;dog&#34;}]]"`)
+	dogName := strings.Index(html, `data-question="q-dog-name"`)
+	if pets < 0 || dog < 0 || dogName < 0 || !(pets < dog && dog < dogName) {
+		t.Errorf("expected q-pets, grp-dog, q-dog-name in order, got %d, %d, %d", pets, dog, dogName)
+	}
+
+	if !strings.Contains(html, `data-question="q-dog-name" data-kind="input_text" data-depends-on=`) {
+		t.Error("expected q-dog-name to carry its dependsOn conditions")
+	}
+	if !strings.Contains(html, `data-group="grp-jobs" data-repeat data-depends-on=`) {
+		t.Error("expected grp-jobs to be a repeatable group with dependsOn conditions")
+	}
+}
+
+func TestSurveyFormHTML_RepeatTemplates(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := SurveyFormHTML(survey, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(out)
+
+	if n := strings.Count(html, "<template>"); n != 2 {
+		t.Errorf("expected 2 instance templates (members and nested jobs), got %d", n)
+	}
+	for _, name := range []string{`name="q-name-__IDX__"`, `name="q-works-__IDX__"`, `name="q-job-__IDX__"`} {
+		if !strings.Contains(html, name) {
+			t.Errorf("expected repeat input %s", name)
+		}
+	}
+
+	// questions outside repeatable groups keep their name id
+	if !strings.Contains(html, `name="q-kind"`) {
+		t.Error("expected q-kind input named after its name id")
+	}
+}
+
+func TestSurveyFormHTML_Options(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	out, err := SurveyFormHTML(survey, &FormOptions{
+		Action:        "/surveys/form-survey/versions/1/answers",
+		UploadURL:     "/uploads",
+		StylesheetURL: "/static/form.css",
+		SubmitLabel:   "Send",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(out)
+
+	if !strings.Contains(html, `<link rel="stylesheet" href="/static/form.css">`) {
+		t.Error("expected stylesheet link")
+	}
+	if strings.Contains(html, "<style>") {
+		t.Error("default CSS should not be inlined when a stylesheet is linked")
+	}
+	if !strings.Contains(html, ">Send</button>") {
+		t.Error("expected custom submit label")
+	}
+
+	config := regexp.MustCompile(`data-config="([^"]*)"`).FindStringSubmatch(html)
+	if config == nil {
+		t.Fatal("expected data-config attribute")
+	}
+	for _, c := range []string{`&#34;action&#34;:&#34;/surveys/form-survey/versions/1/answers&#34;`, `&#34;uploadUrl&#34;:&#34;/uploads&#34;`} {
+		if !strings.Contains(config[1], c) {
+			t.Errorf("expected config to contain %s, got %s", c, config[1])
+		}
+	}
+}
+
+// TestSurveyFormHTML_SubmittedShape checks that answers in the shape submitted by the form are accepted by ReviewAnswers.
+func TestSurveyFormHTML_SubmittedShape(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+
+	answers := surveygo.Answers{
+		"q-pets":     {"dog"},
+		"q-breed":    {"lab"},
+		"q-dog-name": {"rex"},
+		"q-kind":     {"flat"},
+		"q-rooms":    {3},
+		"q-owner":    {true},
+		"q-color":    {"blue"},
+		"grp-members": {
+			map[string]any{
+				"q-name":   []any{"Ann"},
+				"q-works":  []any{"works-yes"},
+				"grp-jobs": []any{map[string]any{"q-job": []any{"dev"}}},
+			},
+		},
+		"q-email": {"ann@example.com"},
+		"q-phone": {"+56", "912345678"},
+		"q-birth": {"04/05/2000"},
+		"q-photo": {"data:image/png;base64,iVBORw0KGgo="},
+	}
+
+	resume, err := survey.ReviewAnswers(answers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resume.InvalidAnswers) > 0 {
+		for _, e := range resume.InvalidAnswers {
+			t.Errorf("invalid answer %s: %s", e.QuestionNameId, e.Error)
+		}
+	}
+}
+
+func TestSurveyFormHTML_Cycle(t *testing.T) {
+	survey := &surveygo.Survey{
+		NameId:      "test",
+		Title:       "Test",
+		Version:     "1",
+		GroupsOrder: []string{"grp-a"},
+		Groups: map[string]*question.Group{
+			"grp-a": {NameId: "grp-a", GroupsOrder: []string{"grp-a"}},
+		},
+		Questions: map[string]*question.Question{},
+	}
+
+	if _, err := SurveyFormHTML(survey, nil); err == nil {
+		t.Fatal("expected cycle error, got nil")
+	}
+}
@@ -0,0 +1,331 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// LongHeaders are the columns of the long format, in order.
+var LongHeaders = []string{"response_id", "group_path", "instance_index", "question_nameId", "question_type", "option_nameId", "value", "label"}
+
+// LongRow is a single answer value in long (tidy) format.
+type LongRow struct {
+	// ResponseID is the id of the response the value belongs to.
+	ResponseID string `json:"response_id"`
+
+	// GroupPath is the path of group name ids from the root group to the group of the question, separated by "/".
+	GroupPath string `json:"group_path"`
+
+	// InstanceIndex is the instance of the innermost repeatable group holding the question, numbered from 1.
+	// Nested instances are prefixed by the instance of their parent (e.g. "2.1"). Empty outside repeatable groups.
+	InstanceIndex string `json:"instance_index"`
+
+	// QuestionNameId is the name id of the question.
+	QuestionNameId string `json:"question_nameId"`
+
+	// QuestionType is the type of the question.
+	QuestionType string `json:"question_type"`
+
+	// OptionNameId is the name id of the selected option, for choice questions.
+	OptionNameId string `json:"option_nameId"`
+
+	// Value is the raw answer value.
+	Value string `json:"value"`
+
+	// Label is the display value: the option label, the external label or the AnswerExpr result. Empty if none.
+	Label string `json:"label"`
+}
+
+// record returns the row as CSV values, in LongHeaders order.
+func (r LongRow) record() []string {
+	return []string{r.ResponseID, r.GroupPath, r.InstanceIndex, r.QuestionNameId, r.QuestionType, r.OptionNameId, r.Value, r.Label}
+}
+
+// LongFormat is the encoding of a long format export.
+type LongFormat string
+
+const (
+	// LongCSV encodes the rows as CSV with a LongHeaders header row.
+	LongCSV LongFormat = "csv"
+
+	// LongNDJSON encodes the rows as newline-delimited JSON objects.
+	LongNDJSON LongFormat = "ndjson"
+)
+
+// AnswersToLong flattens the answers of a response to one row per answer value.
+// Multi-select questions produce a row per selected option; unanswered questions produce no rows.
+// Unlike the wide format of AnswersToCSV, columns do not depend on the survey, so exports of
+// different survey versions can be concatenated.
+// Args:
+//   - survey: the survey the answers belong to
+//   - responseID: the id of the response, written to every row
+//   - answers: the answers of the response
+//
+// Returns:
+//   - []LongRow: the rows, in survey order
+//   - error: if the survey groups are invalid (e.g. cycles)
+func AnswersToLong(survey *surveygo.Survey, responseID string, answers surveygo.Answers) ([]LongRow, error) {
+	lf, err := newLongFlattener(survey)
+	if err != nil {
+		return nil, err
+	}
+	return lf.rows(responseID, answers), nil
+}
+
+// AnswersToLongCSV generates the long format CSV of the answers of a response (see AnswersToLong).
+func AnswersToLongCSV(survey *surveygo.Survey, responseID string, answers surveygo.Answers) ([]byte, error) {
+	return answersToLong(survey, responseID, answers, LongCSV)
+}
+
+// AnswersToLongNDJSON generates the long format NDJSON of the answers of a response (see AnswersToLong).
+func AnswersToLongNDJSON(survey *surveygo.Survey, responseID string, answers surveygo.Answers) ([]byte, error) {
+	return answersToLong(survey, responseID, answers, LongNDJSON)
+}
+
+func answersToLong(survey *surveygo.Survey, responseID string, answers surveygo.Answers, format LongFormat) ([]byte, error) {
+	var buf bytes.Buffer
+	lw, err := NewLongWriter(&buf, survey, format)
+	if err != nil {
+		return nil, err
+	}
+	if err = lw.Write(responseID, answers); err != nil {
+		return nil, err
+	}
+	if err = lw.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LongWriter streams the answers of many responses of a survey in long format.
+type LongWriter struct {
+	lf      *longFlattener
+	csv     *csv.Writer
+	json    *bufio.Writer
+	started bool
+}
+
+// NewLongWriter creates a streaming long format writer of the answers of a survey.
+// Args:
+//   - w: the writer the rows are written to
+//   - survey: the survey the answers belong to
+//   - format: the encoding of the rows, LongCSV or LongNDJSON
+//
+// Returns:
+//   - *LongWriter: the writer, ready to write responses
+//   - error: if the format is unknown or the survey groups are invalid (e.g. cycles)
+func NewLongWriter(w io.Writer, survey *surveygo.Survey, format LongFormat) (*LongWriter, error) {
+	lw := &LongWriter{}
+	switch format {
+	case LongCSV:
+		lw.csv = csv.NewWriter(w)
+	case LongNDJSON:
+		lw.json = bufio.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unknown long format %q", format)
+	}
+
+	lf, err := newLongFlattener(survey)
+	if err != nil {
+		return nil, err
+	}
+	lw.lf = lf
+	return lw, nil
+}
+
+// Write writes the rows of the answers of a response.
+// Args:
+//   - responseID: the id of the response, written to every row
+//   - answers: the answers of the response
+//
+// Returns:
+//   - error: if the rows cannot be written
+func (lw *LongWriter) Write(responseID string, answers surveygo.Answers) error {
+	if err := lw.writeHeader(); err != nil {
+		return err
+	}
+
+	for _, row := range lw.lf.rows(responseID, answers) {
+		if lw.csv != nil {
+			if err := lw.csv.Write(row.record()); err != nil {
+				return fmt.Errorf("writing CSV row: %w", err)
+			}
+			continue
+		}
+
+		b, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("encoding row: %w", err)
+		}
+		if _, err = lw.json.Write(append(b, '\n')); err != nil {
+			return fmt.Errorf("writing row: %w", err)
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered rows, and the CSV header row if no response was written, to the underlying writer.
+// Returns:
+//   - error: if the rows cannot be written
+func (lw *LongWriter) Flush() error {
+	if err := lw.writeHeader(); err != nil {
+		return err
+	}
+	if lw.csv != nil {
+		lw.csv.Flush()
+		if err := lw.csv.Error(); err != nil {
+			return fmt.Errorf("flushing CSV: %w", err)
+		}
+		return nil
+	}
+	if err := lw.json.Flush(); err != nil {
+		return fmt.Errorf("flushing NDJSON: %w", err)
+	}
+	return nil
+}
+
+func (lw *LongWriter) writeHeader() error {
+	if lw.started || lw.csv == nil {
+		return nil
+	}
+	lw.started = true
+	if err := lw.csv.Write(LongHeaders); err != nil {
+		return fmt.Errorf("writing CSV header: %w", err)
+	}
+	return nil
+}
+
+// longFlattener flattens answers to long rows.
+type longFlattener struct {
+	roots   []*GroupNode
+	gqIndex map[string]GroupQuestions
+}
+
+func newLongFlattener(survey *surveygo.Survey) (*longFlattener, error) {
+	tree, err := buildGroupTree(survey)
+	if err != nil {
+		return nil, fmt.Errorf("building group tree: %w", err)
+	}
+	questions, err := extractGroupQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("extracting questions: %w", err)
+	}
+	return &longFlattener{roots: tree.Roots, gqIndex: indexGroupQuestions(questions)}, nil
+}
+
+func (lf *longFlattener) rows(responseID string, answers surveygo.Answers) []LongRow {
+	var rows []LongRow
+	for _, root := range lf.roots {
+		rows = lf.appendGroup(rows, root, answers, responseID, "", "")
+	}
+	return rows
+}
+
+// appendGroup appends the rows of the questions of a group and its descendants.
+func (lf *longFlattener) appendGroup(rows []LongRow, node *GroupNode, answers surveygo.Answers, responseID, parentPath, instance string) []LongRow {
+	path := node.NameId
+	if parentPath != "" {
+		path = parentPath + "/" + node.NameId
+	}
+
+	if !node.AllowRepeat {
+		rows = lf.appendQuestions(rows, node, answers, responseID, path, instance)
+		for _, child := range node.Children {
+			rows = lf.appendGroup(rows, child, answers, responseID, path, instance)
+		}
+		return rows
+	}
+
+	for i, inst := range extractGroupInstances(answers[node.NameId]) {
+		key := strconv.Itoa(i + 1)
+		if instance != "" {
+			key = instance + "." + key
+		}
+		rows = lf.appendQuestions(rows, node, inst, responseID, path, key)
+		for _, child := range node.Children {
+			rows = lf.appendGroup(rows, child, inst, responseID, path, key)
+		}
+	}
+	return rows
+}
+
+// appendQuestions appends the rows of the answered direct questions of a group.
+func (lf *longFlattener) appendQuestions(rows []LongRow, node *GroupNode, answers surveygo.Answers, responseID, path, instance string) []LongRow {
+	for _, q := range lf.gqIndex[node.NameId].Questions {
+		ans, ok := answers[q.NameId]
+		if !ok || len(ans) == 0 {
+			continue
+		}
+
+		base := LongRow{
+			ResponseID:     responseID,
+			GroupPath:      path,
+			InstanceIndex:  instance,
+			QuestionNameId: q.NameId,
+			QuestionType:   q.QuestionType,
+		}
+		for _, r := range longValues(q, ans) {
+			row := base
+			row.OptionNameId, row.Value, row.Label = r.OptionNameId, r.Value, r.Label
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// longValues returns the option, value and label of each value of an answer.
+func longValues(q QuestionInfo, ans []any) []LongRow {
+	var exprLabel string
+	if q.AnswerExpr != "" {
+		exprLabel, _ = evalAnswerExprString(q.AnswerExpr, ans, q.Options)
+	}
+
+	var values []LongRow
+	switch {
+	case multiSelectTypes[q.QuestionType]:
+		for _, v := range extractMultiSelectValues(ans) {
+			values = append(values, LongRow{OptionNameId: v, Value: v, Label: longOptionLabel(q, v)})
+		}
+	case q.QuestionType == "single_select" || q.QuestionType == "radio":
+		if v := extractSelectValue(ans); v != "" {
+			values = append(values, LongRow{OptionNameId: v, Value: v, Label: longOptionLabel(q, v)})
+		}
+	case q.QuestionType == "toggle":
+		values = append(values, LongRow{Value: strconv.FormatBool(extractToggleValue(ans))})
+	case q.QuestionType == "slider":
+		if n, ok := extractNumberValue(ans); ok {
+			values = append(values, LongRow{Value: strconv.FormatFloat(n, 'f', -1, 64)})
+		}
+	case q.QuestionType == "external_question":
+		if v, label := extractExternalValue(ans); v != "" || label != "" {
+			values = append(values, LongRow{Value: v, Label: label})
+		}
+	default:
+		if v := extractCSVValue(q.QuestionType, ans, "", ""); v != "" {
+			values = append(values, LongRow{Value: v})
+		}
+	}
+
+	if exprLabel != "" {
+		for i := range values {
+			values[i].Label = exprLabel
+		}
+	}
+	return values
+}
+
+func longOptionLabel(q QuestionInfo, nameID string) string {
+	for _, opt := range q.Options {
+		if opt.NameId == nameID {
+			return opt.Label
+		}
+	}
+	return ""
+}
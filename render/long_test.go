@@ -0,0 +1,129 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAnswersToLong_Nested(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	rows, err := AnswersToLong(survey, "r1", answers)
+	if err != nil {
+		t.Fatalf("AnswersToLong: %v", err)
+	}
+
+	find := func(question, instance, option string) *LongRow {
+		for i := range rows {
+			r := &rows[i]
+			if r.QuestionNameId == question && r.InstanceIndex == instance && r.OptionNameId == option {
+				return r
+			}
+		}
+		t.Fatalf("row %s/%s/%s not found", question, instance, option)
+		return nil
+	}
+
+	company := find("company_name", "", "")
+	want := LongRow{ResponseID: "r1", GroupPath: "grp-company/grp-company_info", QuestionNameId: "company_name", QuestionType: "input_text", Value: "Tether SpA"}
+	if *company != want {
+		t.Errorf("company_name row = %+v, want %+v", *company, want)
+	}
+
+	industry := find("company_industry", "", "tech")
+	if industry.Value != "tech" || industry.Label != "Tecnología" {
+		t.Errorf("company_industry row = %+v", *industry)
+	}
+
+	// multi-select: one row per selected option, nested instance keys
+	remote := find("emp_contract_type", "1.1", "remote")
+	if remote.Label != "Teletrabajo" || remote.GroupPath != "grp-company/grp-departments/grp-dept_employees/grp-emp_role" {
+		t.Errorf("emp_contract_type row = %+v", *remote)
+	}
+	find("emp_contract_type", "1.1", "fulltime")
+	find("emp_name", "2.1", "")
+
+	if phone := find("addr_phone", "", ""); phone.Value != "+56 222334455" {
+		t.Errorf("addr_phone value = %q", phone.Value)
+	}
+	if active := find("dept_active", "2", ""); active.Value != "true" {
+		t.Errorf("dept_active value = %q", active.Value)
+	}
+}
+
+func TestAnswersToLong_Unanswered(t *testing.T) {
+	rows, err := AnswersToLong(newMultiSelectSurvey(), "r1", nil)
+	if err != nil {
+		t.Fatalf("AnswersToLong: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("expected no rows for unanswered questions, got %v", rows)
+	}
+}
+
+func TestLongWriter_Formats(t *testing.T) {
+	survey := loadSurvey(t, "sample.json")
+	answers := loadAnswersFile(t, "sample_answers.json")
+
+	rows, err := AnswersToLong(survey, "r1", answers)
+	if err != nil {
+		t.Fatalf("AnswersToLong: %v", err)
+	}
+
+	b, err := AnswersToLongCSV(survey, "r1", answers)
+	if err != nil {
+		t.Fatalf("AnswersToLongCSV: %v", err)
+	}
+	records := readCSV(t, b)
+	if !reflect.DeepEqual(records[0], LongHeaders) {
+		t.Errorf("header = %v, want %v", records[0], LongHeaders)
+	}
+	if len(records) != len(rows)+1 {
+		t.Fatalf("CSV rows = %d, want %d", len(records), len(rows)+1)
+	}
+	for i, row := range rows {
+		if !reflect.DeepEqual(records[i+1], row.record()) {
+			t.Errorf("CSV row %d = %v, want %v", i+1, records[i+1], row.record())
+		}
+	}
+
+	var buf bytes.Buffer
+	lw, err := NewLongWriter(&buf, survey, LongNDJSON)
+	if err != nil {
+		t.Fatalf("NewLongWriter: %v", err)
+	}
+	for _, id := range []string{"r1", "r2"} {
+		if err = lw.Write(id, answers); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err = lw.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	var got []LongRow
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var row LongRow
+		if err = json.Unmarshal(sc.Bytes(), &row); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, row)
+	}
+	if len(got) != 2*len(rows) {
+		t.Fatalf("NDJSON rows = %d, want %d", len(got), 2*len(rows))
+	}
+	if got[0] != rows[0] || got[len(rows)].ResponseID != "r2" {
+		t.Errorf("unexpected NDJSON rows: %+v", got)
+	}
+}
+
+func TestNewLongWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewLongWriter(&bytes.Buffer{}, newToggleSurvey(), "parquet"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,447 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	surveygo "github.com/rendis/surveygo/v2"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// PDFOptions configures the PDF generated by CardToPDF.
+type PDFOptions struct {
+	// PageSize is the page size: "A4" (default), "A3", "A5", "Letter" or "Legal".
+	PageSize string
+
+	// Landscape sets the landscape orientation, useful for wide repeat tables.
+	Landscape bool
+
+	// Author is the author of the document metadata.
+	Author string
+
+	// Fonts replaces the embedded Go fonts, e.g. with fonts covering the scripts of the answers (optional).
+	Fonts *PDFFonts
+}
+
+// PDFFonts are the TrueType fonts of a PDF. Bold and Italic default to Regular when empty.
+type PDFFonts struct {
+	// Regular is the font of the values and labels (required).
+	Regular []byte
+
+	// Bold is the font of the titles.
+	Bold []byte
+
+	// Italic is the font of the descriptions and the footer.
+	Italic []byte
+}
+
+// pdf layout, in millimeters and points
+const (
+	pdfFont        = "go"
+	pdfMargin      = 15.0
+	pdfLineHeight  = 5.0
+	pdfCellPadding = 1.5
+	pdfMarkSize    = 3.0
+	pdfLabelRatio  = 0.35 // width of the field labels relative to the page content
+	pdfTextSize    = 10.0
+	pdfSmallSize   = 8.0
+)
+
+// pdfSectionSizes are the title font sizes of the sections by depth.
+var pdfSectionSizes = []float64{14, 12, 11}
+
+// pdfLine is a line of a PDF cell, optionally preceded by an option mark.
+type pdfLine struct {
+	Text string
+	Mark int // pdfNoMark, pdfSelected, pdfNotSelected or pdfContinued
+}
+
+const (
+	pdfNoMark = iota
+	pdfSelected
+	pdfNotSelected
+	pdfContinued // wrapped text of a marked option, indented without mark
+)
+
+// CardToPDF renders a survey card as a PDF document.
+//
+// Sections are rendered as titled blocks with a label and a value per field. Multi-select fields list every
+// option with a checked or unchecked mark. Repeat-table sections are rendered as tables whose header row is
+// repeated after each page break, and repeat-list sections as one block per instance. The Go fonts are
+// embedded by default; they cover the Latin, Greek and Cyrillic scripts, and text is not shaped, so other
+// scripts (e.g. Arabic, Devanagari or CJK) need PDFOptions.Fonts with fonts covering them, and right-to-left
+// or complex scripts may still be rendered incorrectly.
+//
+// The title, survey name id and version of the card are set in the document metadata.
+//
+// Args:
+//   - card: the survey card to render
+//   - opts: the PDF options (optional)
+//
+// Returns:
+//   - []byte: the PDF document
+//   - error: if the PDF cannot be generated
+func CardToPDF(card *SurveyCard, opts *PDFOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PDFOptions{}
+	}
+
+	orientation := "P"
+	if opts.Landscape {
+		orientation = "L"
+	}
+	pageSize := opts.PageSize
+	if pageSize == "" {
+		pageSize = "A4"
+	}
+
+	regular, bold, italic := goregular.TTF, gobold.TTF, goitalic.TTF
+	if f := opts.Fonts; f != nil {
+		if len(f.Regular) == 0 {
+			return nil, fmt.Errorf("PDF fonts: regular font is required")
+		}
+		regular, bold, italic = f.Regular, f.Regular, f.Regular
+		if len(f.Bold) > 0 {
+			bold = f.Bold
+		}
+		if len(f.Italic) > 0 {
+			italic = f.Italic
+		}
+	}
+
+	pdf := fpdf.New(orientation, "mm", pageSize, "")
+	pdf.AddUTF8FontFromBytes(pdfFont, "", regular)
+	pdf.AddUTF8FontFromBytes(pdfFont, "B", bold)
+	pdf.AddUTF8FontFromBytes(pdfFont, "I", italic)
+
+	subject := card.SurveyId
+	if card.SurveyVersion != "" {
+		subject += " v" + card.SurveyVersion
+	}
+	pdf.SetTitle(card.Title, true)
+	pdf.SetSubject(subject, true)
+	pdf.SetKeywords(strings.TrimSpace(fmt.Sprintf("surveyId:%s surveyVersion:%s", card.SurveyId, card.SurveyVersion)), true)
+	pdf.SetCreator("surveygo", true)
+	if opts.Author != "" {
+		pdf.SetAuthor(opts.Author, true)
+	}
+
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, pdfMargin)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin + 3)
+		pdf.SetFont(pdfFont, "I", pdfSmallSize)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(0, 4, subject, "", 0, "L", false, 0, "")
+		pdf.SetX(pdfMargin)
+		pdf.CellFormat(0, 4, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	w := &pdfWriter{pdf: pdf}
+	pdf.AddPage()
+	w.title(card.Title)
+	for _, sec := range card.Sections {
+		w.section(sec, 0)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, fmt.Errorf("generating PDF: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("writing PDF: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// pdfWriter lays out the sections of a survey card.
+type pdfWriter struct {
+	pdf *fpdf.Fpdf
+}
+
+func (w *pdfWriter) contentWidth() float64 {
+	pageW, _ := w.pdf.GetPageSize()
+	left, _, right, _ := w.pdf.GetMargins()
+	return pageW - left - right
+}
+
+// ensure adds a page when h millimeters do not fit in the current one. Returns true if a page was added.
+func (w *pdfWriter) ensure(h float64) bool {
+	_, pageH := w.pdf.GetPageSize()
+	if w.pdf.GetY()+h <= pageH-pdfMargin {
+		return false
+	}
+	w.pdf.AddPage()
+	return true
+}
+
+func (w *pdfWriter) title(text string) {
+	w.pdf.SetFont(pdfFont, "B", 18)
+	w.pdf.SetTextColor(0, 0, 0)
+	w.pdf.MultiCell(0, 8, text, "", "L", false)
+	w.pdf.Ln(4)
+}
+
+// sectionTitle writes the title of a section, kept on the same page as the first line of its content.
+func (w *pdfWriter) sectionTitle(text string, depth int) {
+	size := pdfSectionSizes[min(depth, len(pdfSectionSizes)-1)]
+	w.ensure(size/2 + 3*pdfLineHeight)
+	w.pdf.Bookmark(text, depth, -1)
+
+	w.pdf.SetFont(pdfFont, "B", size)
+	w.pdf.SetTextColor(30, 30, 30)
+	w.pdf.MultiCell(0, size/2, text, "", "L", false)
+
+	left, _, _, _ := w.pdf.GetMargins()
+	y := w.pdf.GetY() + 0.5
+	w.pdf.SetDrawColor(200, 200, 200)
+	w.pdf.SetLineWidth(0.2)
+	w.pdf.Line(left, y, left+w.contentWidth(), y)
+	w.pdf.Ln(2)
+}
+
+func (w *pdfWriter) section(sec Section, depth int) {
+	switch sec.Type {
+	case "group":
+		w.group(sec, depth)
+	case "repeat-table":
+		w.repeatTable(sec, depth)
+	case "repeat-list":
+		w.repeatList(sec, depth)
+	}
+}
+
+func (w *pdfWriter) group(sec Section, depth int) {
+	w.sectionTitle(sec.Title, depth)
+
+	left, _, _, _ := w.pdf.GetMargins()
+	labelW := w.contentWidth() * pdfLabelRatio
+	valueW := w.contentWidth() - labelW
+
+	for _, f := range sec.Fields {
+		w.pdf.SetFont(pdfFont, "B", pdfTextSize)
+		labelLines := w.wrap([]pdfLine{{Text: f.Label}}, labelW)
+		w.pdf.SetFont(pdfFont, "", pdfTextSize)
+		valueLines := w.wrap(fieldLines(f.Type, f.Value), valueW)
+
+		h := float64(max(len(labelLines), len(valueLines)))*pdfLineHeight + 2*pdfCellPadding
+		w.ensure(h)
+		y := w.pdf.GetY()
+
+		w.pdf.SetTextColor(90, 90, 90)
+		w.pdf.SetFont(pdfFont, "B", pdfTextSize)
+		w.lines(labelLines, left, y+pdfCellPadding, labelW)
+		w.pdf.SetTextColor(0, 0, 0)
+		w.pdf.SetFont(pdfFont, "", pdfTextSize)
+		w.lines(valueLines, left+labelW, y+pdfCellPadding, valueW)
+
+		w.pdf.SetY(y + h)
+	}
+	w.pdf.Ln(3)
+
+	for _, child := range sec.Sections {
+		w.section(child, depth+1)
+	}
+}
+
+func (w *pdfWriter) repeatTable(sec Section, depth int) {
+	w.sectionTitle(sec.Title, depth)
+	if len(sec.Columns) == 0 {
+		return
+	}
+
+	left, _, _, _ := w.pdf.GetMargins()
+	colW := w.contentWidth() / float64(len(sec.Columns))
+
+	header := func() {
+		w.pdf.SetFont(pdfFont, "B", pdfSmallSize+1)
+		cells := make([][]pdfLine, len(sec.Columns))
+		for i, col := range sec.Columns {
+			cells[i] = w.wrap([]pdfLine{{Text: col.Label}}, colW-2*pdfCellPadding)
+		}
+		w.pdf.SetFillColor(243, 244, 246)
+		w.row(cells, left, colW, true)
+	}
+
+	w.pdf.SetFont(pdfFont, "B", pdfSmallSize+1)
+	w.ensure(2 * (pdfLineHeight + 2*pdfCellPadding))
+	header()
+
+	for _, r := range sec.Rows {
+		w.pdf.SetFont(pdfFont, "", pdfSmallSize+1)
+		cells := make([][]pdfLine, len(sec.Columns))
+		for i, col := range sec.Columns {
+			cells[i] = w.wrap(fieldLines(col.FieldType, r[col.NameId]), colW-2*pdfCellPadding)
+		}
+		if w.ensure(rowHeight(cells)) {
+			header()
+			w.pdf.SetFont(pdfFont, "", pdfSmallSize+1)
+		}
+		w.row(cells, left, colW, false)
+	}
+	w.pdf.Ln(4)
+}
+
+// row writes a table row with borders and returns below it.
+func (w *pdfWriter) row(cells [][]pdfLine, left, colW float64, fill bool) {
+	y := w.pdf.GetY()
+	h := rowHeight(cells)
+
+	style := "D"
+	if fill {
+		style = "FD"
+	}
+	w.pdf.SetDrawColor(200, 200, 200)
+	w.pdf.SetLineWidth(0.2)
+	w.pdf.SetTextColor(0, 0, 0)
+	for i, lines := range cells {
+		x := left + float64(i)*colW
+		w.pdf.Rect(x, y, colW, h, style)
+		w.lines(lines, x+pdfCellPadding, y+pdfCellPadding, colW-2*pdfCellPadding)
+	}
+	w.pdf.SetY(y + h)
+}
+
+func (w *pdfWriter) repeatList(sec Section, depth int) {
+	w.sectionTitle(sec.Title, depth)
+
+	single := len(sec.Instances) == 1
+	for i, inst := range sec.Instances {
+		if !single {
+			w.ensure(3 * pdfLineHeight)
+			w.pdf.SetFont(pdfFont, "I", pdfTextSize)
+			w.pdf.SetTextColor(90, 90, 90)
+			w.pdf.CellFormat(0, pdfLineHeight+1, fmt.Sprintf("%s #%d", sec.Title, i+1), "", 1, "L", false, 0, "")
+		}
+		for _, child := range inst.Sections {
+			w.section(child, depth+1)
+		}
+	}
+}
+
+// wrap splits the lines of a cell to fit its width, keeping the marks on the first line of each option.
+func (w *pdfWriter) wrap(lines []pdfLine, width float64) []pdfLine {
+	var res []pdfLine
+	for _, l := range lines {
+		textW := width
+		if l.Mark != pdfNoMark {
+			textW -= pdfMarkSize + 1.5
+		}
+		parts := w.pdf.SplitText(l.Text, textW)
+		if len(parts) == 0 {
+			parts = []string{""}
+		}
+		for i, p := range parts {
+			line := pdfLine{Text: p}
+			if i == 0 {
+				line.Mark = l.Mark
+			} else if l.Mark != pdfNoMark {
+				line.Mark = pdfContinued
+			}
+			res = append(res, line)
+		}
+	}
+	return res
+}
+
+// lines writes wrapped lines from (x, y).
+func (w *pdfWriter) lines(lines []pdfLine, x, y, width float64) {
+	for i, l := range lines {
+		ly := y + float64(i)*pdfLineHeight
+		tx := x
+		if l.Mark != pdfNoMark {
+			tx += pdfMarkSize + 1.5
+			if l.Mark != pdfContinued {
+				w.mark(x, ly+(pdfLineHeight-pdfMarkSize)/2, l.Mark == pdfSelected)
+			}
+		}
+		w.pdf.SetXY(tx, ly)
+		w.pdf.CellFormat(width-(tx-x), pdfLineHeight, l.Text, "", 0, "L", false, 0, "")
+	}
+}
+
+// mark draws an option box, with a check when selected.
+func (w *pdfWriter) mark(x, y float64, selected bool) {
+	w.pdf.SetLineWidth(0.25)
+	if !selected {
+		w.pdf.SetDrawColor(160, 160, 160)
+		w.pdf.Rect(x, y, pdfMarkSize, pdfMarkSize, "D")
+		return
+	}
+	w.pdf.SetDrawColor(22, 101, 52)
+	w.pdf.SetFillColor(220, 252, 231)
+	w.pdf.Rect(x, y, pdfMarkSize, pdfMarkSize, "FD")
+	w.pdf.SetLineWidth(0.45)
+	w.pdf.Line(x+0.6, y+pdfMarkSize*0.55, x+pdfMarkSize*0.42, y+pdfMarkSize-0.6)
+	w.pdf.Line(x+pdfMarkSize*0.42, y+pdfMarkSize-0.6, x+pdfMarkSize-0.5, y+0.6)
+}
+
+func rowHeight(cells [][]pdfLine) float64 {
+	n := 1
+	for _, c := range cells {
+		n = max(n, len(c))
+	}
+	return float64(n)*pdfLineHeight + 2*pdfCellPadding
+}
+
+// fieldLines returns the lines of a field or cell value: one marked line per option for multi-selects,
+// a single text line otherwise.
+func fieldLines(fieldType string, value any) []pdfLine {
+	if fieldType == "multi-select" {
+		var lines []pdfLine
+		for _, ref := range optionRefsOf(value) {
+			mark := pdfNotSelected
+			if ref.Selected {
+				mark = pdfSelected
+			}
+			lines = append(lines, pdfLine{Text: ref.Label, Mark: mark})
+		}
+		if len(lines) > 0 {
+			return lines
+		}
+	}
+
+	text := fieldValueToText(Field{Type: fieldType, Value: value})
+	if fieldType == "toggle" {
+		if b, ok := value.(bool); ok {
+			text = map[bool]string{true: "Yes", false: "No"}[b]
+		}
+	}
+	return []pdfLine{{Text: text}}
+}
+
+// optionRefsOf returns the options of a multi-select value, either built by AnswersToJSON or decoded from JSON.
+func optionRefsOf(value any) []OptionRef {
+	switch v := value.(type) {
+	case []OptionRef:
+		return v
+	case []any:
+		var refs []OptionRef
+		for _, item := range v {
+			m, ok := item.(map[string]any)
+			if !ok {
+				return nil
+			}
+			label, _ := m["label"].(string)
+			selected, _ := m["selected"].(bool)
+			refs = append(refs, OptionRef{Label: label, Selected: selected})
+		}
+		return refs
+	}
+	return nil
+}
+
+// AnswersToPDF renders survey answers as a PDF document. See CardToPDF.
+func AnswersToPDF(survey *surveygo.Survey, answers surveygo.Answers, opts *PDFOptions) ([]byte, error) {
+	card, err := AnswersToJSON(survey, answers)
+	if err != nil {
+		return nil, err
+	}
+	return CardToPDF(card, opts)
+}
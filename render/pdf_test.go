@@ -0,0 +1,134 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/image/font/gofont/gomono"
+)
+
+// pdfString returns the UTF-16 encoding of the metadata strings of a PDF.
+func pdfString(s string) []byte {
+	b := []byte{0xfe, 0xff}
+	for _, r := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return b
+}
+
+func pdfPages(b []byte) int {
+	return bytes.Count(b, []byte("/Type /Page\n"))
+}
+
+func TestAnswersToPDF(t *testing.T) {
+	survey := loadSurvey(t, "sample_with_choice.json")
+	answers := loadAnswersFile(t, "sample_with_choice_answers_repeat.json")
+
+	b, err := AnswersToPDF(survey, answers, &PDFOptions{Author: "Registro Académico"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !bytes.HasPrefix(b, []byte("%PDF-")) {
+		t.Fatal("expected a PDF document")
+	}
+	for _, meta := range []string{survey.Title, survey.NameId + " v" + survey.Version, "Registro Académico"} {
+		if !bytes.Contains(b, pdfString(meta)) {
+			t.Errorf("expected metadata %q", meta)
+		}
+	}
+	if n := bytes.Count(b, []byte("/FontFile2")); n != 3 {
+		t.Errorf("expected 3 embedded fonts, got %d", n)
+	}
+	if n := pdfPages(b); n != 1 {
+		t.Errorf("expected 1 page, got %d", n)
+	}
+}
+
+func TestCardToPDF_PageBreaks(t *testing.T) {
+	sec := Section{
+		Type:   "repeat-table",
+		NameId: "grp-rows",
+		Title:  "Filas",
+		Columns: []Column{
+			{NameId: "q-name", Label: "Nombre", FieldType: "text"},
+			{NameId: "q-tags", Label: "Etiquetas", FieldType: "multi-select"},
+		},
+	}
+	for i := 0; i < 60; i++ {
+		sec.Rows = append(sec.Rows, Row{
+			"q-name": fmt.Sprintf("Fila %d", i),
+			"q-tags": []OptionRef{{Label: "Uno", Selected: i%2 == 0}, {Label: "Dos", Selected: true}},
+		})
+	}
+	card := &SurveyCard{SurveyId: "rows", Title: "Ñandú", Sections: []Section{sec}}
+
+	b, err := CardToPDF(card, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := pdfPages(b); n < 2 {
+		t.Errorf("expected the table to break across pages, got %d page(s)", n)
+	}
+
+	landscape, err := CardToPDF(card, &PDFOptions{PageSize: "Letter", Landscape: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Contains(landscape, []byte("/MediaBox [0 0 792.00 612.00]")) {
+		t.Error("expected a landscape Letter page")
+	}
+}
+
+func TestAnswersTo_PDF(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	res, err := AnswersTo(survey, answers, OutputOptions{PDF: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.HasPrefix(res.PDF, []byte("%PDF-")) {
+		t.Error("expected a PDF document")
+	}
+	if res.JSON != nil || res.HTML != nil || res.TipTap != nil || res.CSV != nil {
+		t.Error("expected only the PDF output")
+	}
+}
+
+func TestFieldLines(t *testing.T) {
+	lines := fieldLines("multi-select", []any{
+		map[string]any{"label": "Rojo", "selected": true},
+		map[string]any{"label": "Azul", "selected": false},
+	})
+	want := []pdfLine{{Text: "Rojo", Mark: pdfSelected}, {Text: "Azul", Mark: pdfNotSelected}}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("multi-select lines = %v, want %v", lines, want)
+	}
+
+	if lines = fieldLines("toggle", true); lines[0].Text != "Yes" {
+		t.Errorf("toggle line = %q, want Yes", lines[0].Text)
+	}
+	if lines = fieldLines("text", nil); lines[0].Text != "—" {
+		t.Errorf("empty line = %q, want em dash", lines[0].Text)
+	}
+}
+
+func TestCardToPDF_Fonts(t *testing.T) {
+	card := &SurveyCard{Title: "Encuesta", SurveyId: "survey-fonts", SurveyVersion: "1"}
+
+	b, err := CardToPDF(card, &PDFOptions{Fonts: &PDFFonts{Regular: gomono.TTF}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the monospaced font is used for the regular, bold and italic styles
+	if n := bytes.Count(b, []byte("/MissingWidth 600/")); n != 3 {
+		t.Errorf("expected 3 embedded monospaced fonts, got %d", n)
+	}
+
+	if _, err = CardToPDF(card, &PDFOptions{Fonts: &PDFFonts{Bold: gomono.TTF}}); err == nil {
+		t.Error("expected an error without regular font")
+	}
+}
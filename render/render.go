@@ -1,15 +1,18 @@
 package render
 
 import (
+	"bytes"
 	"fmt"
 
 	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/analytics"
 )
 
 // AnswersToCSV generates a CSV from survey answers.
-// An optional CheckMark controls the strings used for selected/not-selected
+// Optional CSVOptions control the delimiter, BOM, header and value styles, the selected columns and
+// the date format. A CheckMark, alone or in CSVOptions, controls the strings used for selected/not-selected
 // marks in multi-select, checkbox, and toggle columns. Defaults to "true"/"false".
-func AnswersToCSV(survey *surveygo.Survey, answers surveygo.Answers, checkMark ...*CheckMark) ([]byte, error) {
+func AnswersToCSV(survey *surveygo.Survey, answers surveygo.Answers, opts ...CSVOption) ([]byte, error) {
 	tree, err := buildGroupTree(survey)
 	if err != nil {
 		return nil, fmt.Errorf("building group tree: %w", err)
@@ -18,18 +21,15 @@ func AnswersToCSV(survey *surveygo.Survey, answers surveygo.Answers, checkMark .
 	if err != nil {
 		return nil, fmt.Errorf("extracting questions: %w", err)
 	}
-	var cm *CheckMark
-	if len(checkMark) > 0 {
-		cm = checkMark[0]
-	}
-	return generateCSV(survey, tree, questions, answers, cm)
+	return generateCSV(survey, tree, questions, answers, newCSVConfig(opts...))
 }
 
 // AnswersToRows generates a [][]string matrix from survey answers.
 // The first row contains column headers; subsequent rows contain data.
 // Repeatable groups expand via cartesian product (same logic as AnswersToCSV).
-// An optional CheckMark controls selected/not-selected strings for boolean columns.
-func AnswersToRows(survey *surveygo.Survey, answers surveygo.Answers, checkMark ...*CheckMark) ([][]string, error) {
+// Optional CSVOptions or a CheckMark control headers, values and columns as in AnswersToCSV;
+// the delimiter and BOM do not apply.
+func AnswersToRows(survey *surveygo.Survey, answers surveygo.Answers, opts ...CSVOption) ([][]string, error) {
 	tree, err := buildGroupTree(survey)
 	if err != nil {
 		return nil, fmt.Errorf("building group tree: %w", err)
@@ -38,11 +38,31 @@ func AnswersToRows(survey *surveygo.Survey, answers surveygo.Answers, checkMark
 	if err != nil {
 		return nil, fmt.Errorf("extracting questions: %w", err)
 	}
-	var cm *CheckMark
+	return generateMatrix(survey, tree, questions, answers, newCSVConfig(opts...)), nil
+}
+
+// AnswersToXLSX generates an XLSX workbook from the answers of a single response.
+// The main answers are written to the XLSXMainSheet sheet and each repeatable group to its own
+// sheet, keyed by responseID (see XLSXWriter). Use NewXLSXWriter to write many responses.
+// An optional CheckMark writes toggle and multi-select option cells as text instead of booleans.
+func AnswersToXLSX(survey *surveygo.Survey, responseID string, answers surveygo.Answers, checkMark ...*CheckMark) ([]byte, error) {
+	opts := &XLSXOptions{}
 	if len(checkMark) > 0 {
-		cm = checkMark[0]
+		opts.CheckMark = checkMark[0]
+	}
+
+	var buf bytes.Buffer
+	xw, err := NewXLSXWriter(&buf, survey, opts)
+	if err != nil {
+		return nil, err
 	}
-	return generateMatrix(survey, tree, questions, answers, cm), nil
+	if err = xw.Write(responseID, answers); err != nil {
+		return nil, err
+	}
+	if err = xw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // AnswersToJSON builds a structured SurveyCard from survey answers.
@@ -96,13 +116,13 @@ func AnswersTo(survey *surveygo.Survey, answers surveygo.Answers, opts OutputOpt
 	result := &AnswersResult{}
 
 	if opts.CSV {
-		result.CSV, err = generateCSV(survey, tree, questions, answers, opts.CheckMark)
+		result.CSV, err = generateCSV(survey, tree, questions, answers, newCSVConfig(opts.CheckMark, opts.CSVOptions))
 		if err != nil {
 			return nil, fmt.Errorf("generating CSV: %w", err)
 		}
 	}
 
-	if opts.JSON || opts.HTML || opts.TipTap {
+	if opts.JSON || opts.HTML || opts.TipTap || opts.PDF {
 		card, cardErr := buildSurveyCard(survey, tree, questions, answers)
 		if cardErr != nil {
 			return nil, fmt.Errorf("building survey card: %w", cardErr)
@@ -122,6 +142,12 @@ func AnswersTo(survey *surveygo.Survey, answers surveygo.Answers, opts OutputOpt
 			doc := buildTipTapDoc(card)
 			result.TipTap = &doc
 		}
+		if opts.PDF {
+			result.PDF, err = CardToPDF(card, opts.PDFOptions)
+			if err != nil {
+				return nil, fmt.Errorf("generating PDF: %w", err)
+			}
+		}
 	}
 
 	return result, nil
@@ -136,6 +162,29 @@ func DefinitionTreeHTML(survey *surveygo.Survey) ([]byte, error) {
 	return renderTreeToBytes(tree)
 }
 
+// ResultsDashboardHTML renders aggregated results as a single HTML page with one chart per question,
+// in GroupsOrder and titled with the question labels: pie or bar charts for choice questions, histograms
+// for sliders, Net Promoter Score gauges for the DashboardOptions.NPSQuestions sliders, answers per month
+// for dates and word counts for free text. Questions without answers to draw are left out.
+// The embedded ECharts library is inlined in the page, so it works offline, unless
+// DashboardOptions.RemoteECharts is set.
+// Args:
+//   - survey: the survey the results belong to
+//   - results: the results of an analytics.Aggregator of the survey
+//   - options: the dashboard options (optional)
+//
+// Returns:
+//   - []byte: the HTML page
+//   - error: if the survey groups are invalid, the page cannot be rendered, or the ECharts library is
+//     not embedded (see render/assets/echarts) and neither EChartsJS nor RemoteECharts is set
+func ResultsDashboardHTML(survey *surveygo.Survey, results *analytics.Results, options ...*DashboardOptions) ([]byte, error) {
+	o := &DashboardOptions{}
+	if len(options) > 0 && options[0] != nil {
+		o = options[0]
+	}
+	return renderDashboard(survey, results, o)
+}
+
 // DefinitionTreeJSON builds the hierarchical group tree with cycle detection.
 func DefinitionTreeJSON(survey *surveygo.Survey) (*GroupTree, error) {
 	return buildGroupTree(survey)
@@ -42,9 +42,10 @@ type GroupQuestions struct {
 
 // SurveyCard is the JSON-renderable card output for a survey response.
 type SurveyCard struct {
-	SurveyId string    `json:"surveyId"`
-	Title    string    `json:"title"`
-	Sections []Section `json:"sections"`
+	SurveyId      string    `json:"surveyId"`
+	SurveyVersion string    `json:"surveyVersion,omitempty"`
+	Title         string    `json:"title"`
+	Sections      []Section `json:"sections"`
 }
 
 // Section represents a group rendered as a card section.
@@ -121,8 +122,11 @@ type OutputOptions struct {
 	JSON   bool
 	HTML   bool
 	TipTap bool
+	PDF    bool
 
-	CheckMark *CheckMark // CSV boolean columns; nil = "true"/"false"
+	CheckMark  *CheckMark  // CSV boolean columns; nil = "true"/"false"
+	CSVOptions *CSVOptions // CSV delimiter, headers, values and columns; nil = defaults (its CheckMark overrides CheckMark)
+	PDFOptions *PDFOptions // PDF page and metadata options; nil = defaults
 }
 
 // HTMLResult contains HTML body and CSS as separate byte slices.
@@ -144,6 +148,7 @@ type AnswersResult struct {
 	JSON   *SurveyCard `json:"json,omitempty"`
 	HTML   *HTMLResult `json:"html,omitempty"`
 	TipTap *TipTapNode `json:"tiptap,omitempty"`
+	PDF    []byte      `json:"pdf,omitempty"`
 }
 
 // TreeResult contains both representations of the group tree.
@@ -0,0 +1,559 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/text"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// XLSXMainSheet is the name of the sheet holding the answers outside repeatable groups.
+	XLSXMainSheet = "Responses"
+
+	// XLSXResponseIDHeader is the header of the response id column of every sheet.
+	XLSXResponseIDHeader = "response_id"
+
+	// XLSXInstanceHeader is the header of the instance column of the repeatable group sheets.
+	// Instances are numbered from 1, nested instances are prefixed by the instance of their
+	// parent (e.g. "2.1" is the first instance inside the second instance of the parent group).
+	XLSXInstanceHeader = "instance"
+
+	// XLSXParentInstanceHeader is the header of the parent instance column of the sheets of
+	// repeatable groups nested inside another repeatable group.
+	XLSXParentInstanceHeader = "parent_instance"
+
+	xlsxOptionsSheet = "Options"
+	xlsxMaxSheetName = 31
+	xlsxMaxDropList  = 255
+	xlsxMinWidth     = 8
+	xlsxMaxWidth     = 60
+)
+
+// xlsxDateFormats maps the date time types to their cell number formats.
+var xlsxDateFormats = map[text.DateTypeFormat]string{
+	text.DateTypeFormatDate:     "yyyy-mm-dd",
+	text.DateTypeFormatTime:     "hh:mm:ss",
+	text.DateTypeFormatDateTime: "yyyy-mm-dd hh:mm:ss",
+}
+
+// XLSXOptions configures the workbook written by an XLSXWriter.
+type XLSXOptions struct {
+	// CheckMark, when set, writes toggle and multi-select option cells as these texts.
+	// When nil, they are written as TRUE/FALSE boolean cells.
+	CheckMark *CheckMark
+}
+
+// XLSXWriter writes the answers of many responses of a survey to an XLSX workbook.
+//
+// Answers outside repeatable groups go to the XLSXMainSheet sheet, one row per response. Each
+// AllowRepeat group gets its own sheet, named after the group, with one row per instance linked
+// to its response by the response_id column and, for nested repeatable groups, to the instance
+// of its parent by the parent_instance column.
+//
+// Cells are typed: sliders are numbers, date_time answers are dates, toggles and multi-select
+// options are booleans (or CheckMark texts), and single selects hold the option labels, restricted
+// by a data-validation list. Header rows are frozen and columns are sized to their content.
+//
+// The workbook is kept in memory and written to the underlying writer on Close.
+type XLSXWriter struct {
+	w         io.Writer
+	file      *excelize.File
+	cm        *CheckMark
+	roots     []*GroupNode
+	gqIndex   map[string]GroupQuestions
+	dateTypes map[string]text.DateTypeFormat // key: question name id
+	sheets    []*xlsxSheet
+	groups    map[string]*xlsxSheet // key: repeatable group name id
+	names     map[string]bool       // lowercased sheet names in use
+	options   int                   // rows used in the options sheet
+	optsSheet string                // name of the options sheet
+	closed    bool
+}
+
+// xlsxSheet is a sheet of the workbook being written.
+type xlsxSheet struct {
+	name   string
+	nested bool
+	cols   []xlsxColumn
+	widths []int
+	next   int // next row to write, 1-based
+}
+
+// xlsxColumn is a column of a sheet. Key columns have no question.
+type xlsxColumn struct {
+	header   string
+	group    string // name id of the group of the question
+	question *QuestionInfo
+	optionID string // multi-select option column
+	style    int    // date cell style, 0 if none
+}
+
+// NewXLSXWriter creates a writer of the answers of a survey to an XLSX workbook.
+// Args:
+//   - w: the writer the workbook is written to on Close
+//   - survey: the survey the answers belong to
+//   - opts: the workbook options (optional)
+//
+// Returns:
+//   - *XLSXWriter: the writer, ready to write responses
+//   - error: if the survey groups are invalid (e.g. cycles) or the workbook cannot be created
+func NewXLSXWriter(w io.Writer, survey *surveygo.Survey, opts *XLSXOptions) (*XLSXWriter, error) {
+	tree, err := buildGroupTree(survey)
+	if err != nil {
+		return nil, fmt.Errorf("building group tree: %w", err)
+	}
+	questions, err := extractGroupQuestions(survey)
+	if err != nil {
+		return nil, fmt.Errorf("extracting questions: %w", err)
+	}
+
+	xw := &XLSXWriter{
+		w:         w,
+		file:      excelize.NewFile(),
+		roots:     tree.Roots,
+		gqIndex:   make(map[string]GroupQuestions, len(questions)),
+		dateTypes: make(map[string]text.DateTypeFormat),
+		groups:    make(map[string]*xlsxSheet),
+		names:     make(map[string]bool),
+	}
+	if opts != nil {
+		xw.cm = opts.CheckMark
+	}
+	for _, gq := range questions {
+		xw.gqIndex[gq.GroupNameId] = gq
+	}
+	for nameID, q := range survey.Questions {
+		if q.QTyp != types.QTypeDateTime {
+			continue
+		}
+		if dt, err := text.CastToDateTime(q.Value); err == nil {
+			xw.dateTypes[nameID] = dt.Type
+		}
+	}
+
+	main := xw.addSheet(XLSXMainSheet, false, false)
+	for _, root := range tree.Roots {
+		xw.addColumns(root, main, false)
+	}
+
+	if err = xw.layout(); err != nil {
+		_ = xw.file.Close()
+		return nil, fmt.Errorf("creating workbook: %w", err)
+	}
+	return xw, nil
+}
+
+// Write writes the answers of a response: one row in the main sheet and one row per instance in
+// the sheets of the repeatable groups.
+// Args:
+//   - responseID: the id of the response, written to the response_id column of every row
+//   - answers: the answers of the response
+//
+// Returns:
+//   - error: if the writer is closed or a cell cannot be written
+func (xw *XLSXWriter) Write(responseID string, answers surveygo.Answers) error {
+	if xw.closed {
+		return fmt.Errorf("xlsx writer is closed")
+	}
+
+	main := xw.sheets[0]
+	row := make([]any, len(main.cols))
+	row[0] = responseID
+	for _, root := range xw.roots {
+		if err := xw.fillGroup(root, answers, main, row, responseID, ""); err != nil {
+			return err
+		}
+	}
+	return xw.writeRow(main, row)
+}
+
+// Close sizes the columns and writes the workbook to the underlying writer.
+// Returns:
+//   - error: if the workbook cannot be written
+func (xw *XLSXWriter) Close() error {
+	if xw.closed {
+		return nil
+	}
+	xw.closed = true
+	defer func() { _ = xw.file.Close() }()
+
+	for _, s := range xw.sheets {
+		for i, width := range s.widths {
+			col, _ := excelize.ColumnNumberToName(i + 1)
+			w := float64(min(max(width, xlsxMinWidth), xlsxMaxWidth) + 2)
+			if err := xw.file.SetColWidth(s.name, col, col, w); err != nil {
+				return fmt.Errorf("sizing sheet %q: %w", s.name, err)
+			}
+		}
+	}
+
+	if err := xw.file.Write(xw.w); err != nil {
+		return fmt.Errorf("writing workbook: %w", err)
+	}
+	return nil
+}
+
+// addSheet registers a sheet with its key columns.
+func (xw *XLSXWriter) addSheet(name string, repeat, nested bool) *xlsxSheet {
+	s := &xlsxSheet{name: xw.sheetName(name), nested: nested, next: 2}
+	s.addColumn(xlsxColumn{header: XLSXResponseIDHeader})
+	if repeat {
+		if nested {
+			s.addColumn(xlsxColumn{header: XLSXParentInstanceHeader})
+		}
+		s.addColumn(xlsxColumn{header: XLSXInstanceHeader})
+	}
+	xw.sheets = append(xw.sheets, s)
+	return s
+}
+
+// addColumns adds the columns of the questions of a group and its descendants to a sheet.
+// Repeatable groups get their own sheet.
+func (xw *XLSXWriter) addColumns(node *GroupNode, s *xlsxSheet, inRepeat bool) {
+	if node.AllowRepeat {
+		s = xw.addSheet(node.NameId, true, inRepeat)
+		xw.groups[node.NameId] = s
+		inRepeat = true
+	}
+
+	gq := xw.gqIndex[node.NameId]
+	for i := range gq.Questions {
+		q := &gq.Questions[i]
+		if q.AnswerExpr == "" && multiSelectTypes[q.QuestionType] {
+			for _, opt := range q.Options {
+				s.addColumn(xlsxColumn{header: optionHeader(*q, opt), group: node.NameId, question: q, optionID: opt.NameId})
+			}
+			continue
+		}
+		s.addColumn(xlsxColumn{header: questionHeader(*q), group: node.NameId, question: q})
+	}
+
+	for _, child := range node.Children {
+		xw.addColumns(child, s, inRepeat)
+	}
+}
+
+func (s *xlsxSheet) addColumn(c xlsxColumn) {
+	s.cols = append(s.cols, c)
+	s.widths = append(s.widths, utf8.RuneCountInString(c.header))
+}
+
+// sheetName returns a valid and unused sheet name based on the given name.
+func (xw *XLSXWriter) sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := truncateRunes(name, xlsxMaxSheetName)
+	for n := 2; xw.names[strings.ToLower(candidate)]; n++ {
+		suffix := "_" + strconv.Itoa(n)
+		candidate = truncateRunes(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	xw.names[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// layout creates the sheets with their frozen header rows, date styles and option lists.
+func (xw *XLSXWriter) layout() error {
+	f := xw.file
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+	dateStyles := make(map[text.DateTypeFormat]int, len(xlsxDateFormats))
+	for dt, format := range xlsxDateFormats {
+		nf := format
+		if dateStyles[dt], err = f.NewStyle(&excelize.Style{CustomNumFmt: &nf}); err != nil {
+			return err
+		}
+	}
+
+	for i, s := range xw.sheets {
+		if i == 0 {
+			err = f.SetSheetName(f.GetSheetName(0), s.name)
+		} else {
+			_, err = f.NewSheet(s.name)
+		}
+		if err != nil {
+			return err
+		}
+
+		headers := make([]any, len(s.cols))
+		for c := range s.cols {
+			headers[c] = s.cols[c].header
+		}
+		if err = f.SetSheetRow(s.name, "A1", &headers); err != nil {
+			return err
+		}
+		last, _ := excelize.ColumnNumberToName(len(s.cols))
+		if err = f.SetCellStyle(s.name, "A1", last+"1", headerStyle); err != nil {
+			return err
+		}
+		if err = f.SetPanes(s.name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+
+		for c := range s.cols {
+			col := &s.cols[c]
+			if col.question == nil || col.question.AnswerExpr != "" {
+				continue
+			}
+			switch col.question.QuestionType {
+			case "date_time":
+				dt := xw.dateTypes[col.question.NameId]
+				if _, ok := xlsxDateFormats[dt]; !ok {
+					dt = text.DateTypeFormatDateTime
+				}
+				col.style = dateStyles[dt]
+				s.widths[c] = max(s.widths[c], len(xlsxDateFormats[dt]))
+			case "single_select", "radio":
+				if err = xw.addOptionList(s, c); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	f.SetActiveSheet(0)
+	return nil
+}
+
+// addOptionList restricts a select column to the labels of its options. Lists too long for an
+// inline list, or with labels containing commas, are written to a hidden options sheet.
+func (xw *XLSXWriter) addOptionList(s *xlsxSheet, c int) error {
+	q := s.cols[c].question
+	if len(q.Options) == 0 {
+		return nil
+	}
+	labels := make([]string, len(q.Options))
+	inline := true
+	for i, opt := range q.Options {
+		labels[i] = optionLabel(opt)
+		inline = inline && !strings.ContainsAny(labels[i], `,"`)
+	}
+
+	col, _ := excelize.ColumnNumberToName(c + 1)
+	dv := excelize.NewDataValidation(true)
+	dv.Sqref = fmt.Sprintf("%s2:%s%d", col, col, excelize.TotalRows)
+
+	if !inline || utf8.RuneCountInString(strings.Join(labels, ",")) > xlsxMaxDropList {
+		ref, err := xw.writeOptions(labels)
+		if err != nil {
+			return err
+		}
+		dv.SetSqrefDropList(ref)
+	} else if err := dv.SetDropList(labels); err != nil {
+		return err
+	}
+	return xw.file.AddDataValidation(s.name, dv)
+}
+
+// writeOptions writes labels to the hidden options sheet, returning the reference to their cells.
+func (xw *XLSXWriter) writeOptions(labels []string) (string, error) {
+	f := xw.file
+	if xw.options == 0 {
+		name := xw.sheetName(xlsxOptionsSheet)
+		if _, err := f.NewSheet(name); err != nil {
+			return "", err
+		}
+		if err := f.SetSheetVisible(name, false); err != nil {
+			return "", err
+		}
+		xw.optsSheet = name
+	}
+
+	first := xw.options + 1
+	for i, label := range labels {
+		cell, _ := excelize.CoordinatesToCellName(1, first+i)
+		if err := f.SetCellStr(xw.optsSheet, cell, label); err != nil {
+			return "", err
+		}
+	}
+	xw.options += len(labels)
+	return fmt.Sprintf("'%s'!$A$%d:$A$%d", xw.optsSheet, first, xw.options), nil
+}
+
+// fillGroup sets the cells of the questions of a group and its non-repeatable descendants in row,
+// writing the instances of repeatable groups to their own sheets.
+func (xw *XLSXWriter) fillGroup(node *GroupNode, answers surveygo.Answers, s *xlsxSheet, row []any, responseID, instance string) error {
+	if node.AllowRepeat {
+		return xw.writeInstances(node, answers, responseID, instance)
+	}
+	xw.fillQuestions(node, answers, s, row)
+	for _, child := range node.Children {
+		if err := xw.fillGroup(child, answers, s, row, responseID, instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeInstances writes a row per instance of a repeatable group to the group sheet.
+func (xw *XLSXWriter) writeInstances(node *GroupNode, answers surveygo.Answers, responseID, parent string) error {
+	s := xw.groups[node.NameId]
+	for i, inst := range extractGroupInstances(answers[node.NameId]) {
+		key := strconv.Itoa(i + 1)
+		if parent != "" {
+			key = parent + "." + key
+		}
+
+		row := make([]any, len(s.cols))
+		row[0] = responseID
+		if s.nested {
+			row[1] = parent
+			row[2] = key
+		} else {
+			row[1] = key
+		}
+
+		xw.fillQuestions(node, inst, s, row)
+		for _, child := range node.Children {
+			if err := xw.fillGroup(child, inst, s, row, responseID, key); err != nil {
+				return err
+			}
+		}
+		if err := xw.writeRow(s, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fillQuestions sets the cells of the direct questions of a group in row.
+func (xw *XLSXWriter) fillQuestions(node *GroupNode, answers surveygo.Answers, s *xlsxSheet, row []any) {
+	for c, col := range s.cols {
+		if col.question == nil || col.group != node.NameId {
+			continue
+		}
+		row[c] = xw.cellValue(col, answers[col.question.NameId])
+	}
+}
+
+// cellValue returns the typed value of a column for an answer, nil for an empty cell.
+func (xw *XLSXWriter) cellValue(col xlsxColumn, ans []any) any {
+	q := col.question
+
+	if q.AnswerExpr != "" {
+		if val, ok := evalAnswerExpr(q.AnswerExpr, ans, q.Options); ok {
+			switch v := val.(type) {
+			case nil:
+				return nil
+			case bool, int, int64, float64, string:
+				return v
+			default:
+				return fmt.Sprintf("%v", v)
+			}
+		}
+	}
+
+	if col.optionID != "" {
+		for _, v := range extractMultiSelectValues(ans) {
+			if v == col.optionID {
+				return xw.mark(true)
+			}
+		}
+		return xw.mark(false)
+	}
+
+	switch q.QuestionType {
+	case "toggle":
+		return xw.mark(extractToggleValue(ans))
+	case "slider":
+		if n, ok := extractNumberValue(ans); ok {
+			return n
+		}
+	case "date_time":
+		if v := extractTextValue(ans); v != "" {
+			if t, err := time.Parse(q.Format, v); err == nil {
+				if xw.dateTypes[q.NameId] == text.DateTypeFormatTime {
+					// time-only values are fractions of a day
+					return float64(t.Hour()*3600+t.Minute()*60+t.Second()) / 86400
+				}
+				return t
+			}
+			return v
+		}
+		return nil
+	case "single_select", "radio":
+		v := extractSelectValue(ans)
+		for _, opt := range q.Options {
+			if opt.NameId == v {
+				return optionLabel(opt)
+			}
+		}
+		if v != "" {
+			return v
+		}
+		return nil
+	}
+
+	if v := extractCSVValue(q.QuestionType, ans, "", ""); v != "" {
+		return v
+	}
+	return nil
+}
+
+// mark returns the cell value of a toggle or multi-select option.
+func (xw *XLSXWriter) mark(selected bool) any {
+	if xw.cm == nil {
+		return selected
+	}
+	if selected {
+		return xw.cm.Selected
+	}
+	return xw.cm.NotSelected
+}
+
+// writeRow writes the non-empty cells of a row to the next row of a sheet.
+func (xw *XLSXWriter) writeRow(s *xlsxSheet, row []any) error {
+	for c, val := range row {
+		if val == nil {
+			continue
+		}
+		cell, _ := excelize.CoordinatesToCellName(c+1, s.next)
+		if err := xw.file.SetCellValue(s.name, cell, val); err != nil {
+			return fmt.Errorf("writing cell %s!%s: %w", s.name, cell, err)
+		}
+		if style := s.cols[c].style; style != 0 {
+			if err := xw.file.SetCellStyle(s.name, cell, cell, style); err != nil {
+				return fmt.Errorf("styling cell %s!%s: %w", s.name, cell, err)
+			}
+		}
+		if str, ok := val.(string); ok {
+			s.widths[c] = max(s.widths[c], utf8.RuneCountInString(str))
+		} else if _, ok := val.(time.Time); !ok {
+			s.widths[c] = max(s.widths[c], len(fmt.Sprint(val)))
+		}
+	}
+	s.next++
+	return nil
+}
+
+func optionLabel(opt OptionInfo) string {
+	if opt.Label != "" {
+		return opt.Label
+	}
+	return opt.NameId
+}
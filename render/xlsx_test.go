@@ -0,0 +1,241 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/question/types"
+	"github.com/rendis/surveygo/v2/question/types/choice"
+	"github.com/xuri/excelize/v2"
+)
+
+// writeXLSX writes the given responses and reopens the resulting workbook.
+func writeXLSX(t *testing.T, survey *surveygo.Survey, opts *XLSXOptions, responses map[string]surveygo.Answers, order ...string) *excelize.File {
+	t.Helper()
+	var buf bytes.Buffer
+	xw, err := NewXLSXWriter(&buf, survey, opts)
+	if err != nil {
+		t.Fatalf("NewXLSXWriter: %v", err)
+	}
+	for _, id := range order {
+		if err := xw.Write(id, responses[id]); err != nil {
+			t.Fatalf("Write(%s): %v", id, err)
+		}
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+// xlsxCell returns the cell of a sheet under the given header, in the given 1-based data row.
+func xlsxCell(t *testing.T, f *excelize.File, sheet, header string, row int) string {
+	t.Helper()
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		t.Fatalf("GetRows(%s): %v", sheet, err)
+	}
+	idx := colIndex(rows[0], header)
+	if idx < 0 {
+		t.Fatalf("sheet %s: header %q not found in %v", sheet, header, rows[0])
+	}
+	cell, _ := excelize.CoordinatesToCellName(idx+1, row+1)
+	return cell
+}
+
+func TestXLSXWriter_RepeatSheets(t *testing.T) {
+	survey := loadSurvey(t, "sample_nested.json")
+	answers := loadAnswersFile(t, "sample_nested_answers.json")
+
+	f := writeXLSX(t, survey, nil, map[string]surveygo.Answers{"r1": answers}, "r1")
+
+	sheets := f.GetSheetList()
+	want := []string{XLSXMainSheet, "grp-departments", "grp-dept_employees"}
+	if strings.Join(sheets, ",") != strings.Join(want, ",") {
+		t.Fatalf("sheets = %v, want %v", sheets, want)
+	}
+
+	main, _ := f.GetRows(XLSXMainSheet)
+	if len(main) != 2 {
+		t.Fatalf("main sheet rows = %d, want 2 (header + response)", len(main))
+	}
+	if main[1][0] != "r1" {
+		t.Errorf("main response_id = %q, want r1", main[1][0])
+	}
+	if colIndex(main[0], "Nombre Departamento") >= 0 {
+		t.Error("repeatable group question should not be on the main sheet")
+	}
+
+	depts, _ := f.GetRows("grp-departments")
+	if len(depts) != 3 {
+		t.Fatalf("departments rows = %d, want 3", len(depts))
+	}
+	if strings.Join(depts[0][:2], ",") != "response_id,instance" {
+		t.Errorf("departments key headers = %v", depts[0][:2])
+	}
+
+	emps, _ := f.GetRows("grp-dept_employees")
+	if len(emps) != 6 {
+		t.Fatalf("employees rows = %d, want 6 (header + 5 employees)", len(emps))
+	}
+	if strings.Join(emps[0][:3], ",") != "response_id,parent_instance,instance" {
+		t.Errorf("employees key headers = %v", emps[0][:3])
+	}
+	if got := strings.Join(emps[4][:3], ","); got != "r1,2,2.1" {
+		t.Errorf("first employee of second department keys = %q, want r1,2,2.1", got)
+	}
+
+	// select answers are written as option labels, restricted by a list
+	cell := xlsxCell(t, f, "grp-dept_employees", "Cargo", 1)
+	if v, _ := f.GetCellValue("grp-dept_employees", cell); v != "Desarrollador" {
+		t.Errorf("Cargo = %q, want Desarrollador", v)
+	}
+	dvs, err := f.GetDataValidations("grp-dept_employees")
+	if err != nil {
+		t.Fatalf("GetDataValidations: %v", err)
+	}
+	if len(dvs) != 1 || !strings.Contains(dvs[0].Formula1, "Desarrollador,Gerente,Analista,Administrativo") {
+		t.Errorf("unexpected data validations: %+v", dvs)
+	}
+
+	for _, sheet := range sheets {
+		panes, err := f.GetPanes(sheet)
+		if err != nil {
+			t.Fatalf("GetPanes(%s): %v", sheet, err)
+		}
+		if !panes.Freeze || panes.YSplit != 1 {
+			t.Errorf("sheet %s header row not frozen: %+v", sheet, panes)
+		}
+	}
+}
+
+func TestXLSXWriter_TypedCells(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	answers := surveygo.Answers{
+		"q-rooms": {float64(4)},
+		"q-owner": {true},
+		"q-color": {"blue"},
+		"q-kind":  {"flat"},
+		"q-birth": {"24/12/1990"},
+		"q-name":  {"ignored outside instances"},
+	}
+
+	f := writeXLSX(t, survey, nil, map[string]surveygo.Answers{"a": answers, "b": {}}, "a", "b")
+
+	typ := func(header string, row int) excelize.CellType {
+		t.Helper()
+		ct, err := f.GetCellType(XLSXMainSheet, xlsxCell(t, f, XLSXMainSheet, header, row))
+		if err != nil {
+			t.Fatalf("GetCellType(%s): %v", header, err)
+		}
+		return ct
+	}
+	value := func(header string, row int) string {
+		t.Helper()
+		v, _ := f.GetCellValue(XLSXMainSheet, xlsxCell(t, f, XLSXMainSheet, header, row))
+		return v
+	}
+
+	if ct := typ("Rooms", 1); ct != excelize.CellTypeNumber && ct != excelize.CellTypeUnset {
+		t.Errorf("Rooms cell type = %v, want number", ct)
+	}
+	if v := value("Rooms", 1); v != "4" {
+		t.Errorf("Rooms = %q, want 4", v)
+	}
+	if ct := typ("Owner", 1); ct != excelize.CellTypeBool {
+		t.Errorf("Owner cell type = %v, want bool", ct)
+	}
+	if v := value("Owner", 1); v != "TRUE" {
+		t.Errorf("Owner = %q, want TRUE", v)
+	}
+	if v := value("Colors - Blue", 1); v != "TRUE" {
+		t.Errorf("Colors - Blue = %q, want TRUE", v)
+	}
+	if v := value("Colors - Red", 1); v != "FALSE" {
+		t.Errorf("Colors - Red = %q, want FALSE", v)
+	}
+	if v := value("Kind", 1); v != "Flat" {
+		t.Errorf("Kind = %q, want Flat", v)
+	}
+	if v := value("Birth date", 1); v != "1990-12-24" {
+		t.Errorf("Birth date = %q, want 1990-12-24", v)
+	}
+
+	// unanswered questions are left blank
+	if v := value("Rooms", 2); v != "" {
+		t.Errorf("unanswered Rooms = %q, want blank", v)
+	}
+	if v := value("response_id", 2); v != "b" {
+		t.Errorf("second response_id = %q, want b", v)
+	}
+
+	if w, _ := f.GetColWidth(XLSXMainSheet, "A"); w < xlsxMinWidth {
+		t.Errorf("column width = %v, want at least %d", w, xlsxMinWidth)
+	}
+}
+
+func TestXLSXWriter_CheckMark(t *testing.T) {
+	survey := loadSurvey(t, "sample_form.json")
+	answers := surveygo.Answers{"q-owner": {false}, "q-color": {"red"}}
+	opts := &XLSXOptions{CheckMark: &CheckMark{Selected: "X", NotSelected: "-"}}
+
+	f := writeXLSX(t, survey, opts, map[string]surveygo.Answers{"a": answers}, "a")
+
+	for header, want := range map[string]string{"Owner": "-", "Colors - Red": "X", "Colors - Blue": "-"} {
+		v, _ := f.GetCellValue(XLSXMainSheet, xlsxCell(t, f, XLSXMainSheet, header, 1))
+		if v != want {
+			t.Errorf("%s = %q, want %q", header, v, want)
+		}
+	}
+}
+
+func TestXLSXWriter_OptionsSheet(t *testing.T) {
+	survey := newMultiSelectSurvey()
+	q := survey.Questions["q-colors"]
+	q.QTyp = types.QTypeSingleSelect
+	q.Value.(*choice.Choice).Options[0].Label = "Red, dark"
+
+	f := writeXLSX(t, survey, nil, map[string]surveygo.Answers{"a": {"q-colors": {"red"}}}, "a")
+
+	// labels with commas cannot be inlined, they are listed in a hidden sheet
+	visible, err := f.GetSheetVisible(xlsxOptionsSheet)
+	if err != nil {
+		t.Fatalf("GetSheetVisible: %v", err)
+	}
+	if visible {
+		t.Error("options sheet should be hidden")
+	}
+	if v, _ := f.GetCellValue(xlsxOptionsSheet, "A1"); v != "Red, dark" {
+		t.Errorf("options A1 = %q, want %q", v, "Red, dark")
+	}
+	dvs, _ := f.GetDataValidations(XLSXMainSheet)
+	if len(dvs) != 1 || dvs[0].Formula1 != "'Options'!$A$1:$A$3" {
+		t.Errorf("unexpected data validations: %+v", dvs)
+	}
+	if v, _ := f.GetCellValue(XLSXMainSheet, "B2"); v != "Red, dark" {
+		t.Errorf("answer = %q, want %q", v, "Red, dark")
+	}
+}
+
+func TestXLSXWriter_Closed(t *testing.T) {
+	survey := newToggleSurvey()
+	var buf bytes.Buffer
+	xw, err := NewXLSXWriter(&buf, survey, nil)
+	if err != nil {
+		t.Fatalf("NewXLSXWriter: %v", err)
+	}
+	if err := xw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := xw.Write("a", surveygo.Answers{}); err == nil {
+		t.Error("expected error writing to a closed writer")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // emailRegex is a regex to validate email.
@@ -47,7 +48,8 @@ func reviewFreeText(questionValue any, answers []any) error {
 		return fmt.Errorf("answer is not a string. got: %v", answer)
 	}
 
-	l := len(a)
+	// lengths count characters (code points), like JSON Schema minLength and maxLength
+	l := utf8.RuneCountInString(a)
 
 	if freeText.Min != nil && l < *freeText.Min {
 		return fmt.Errorf("answer length is less than min length '%d'. got: '%s' (%d)", *freeText.Min, a, l)
@@ -0,0 +1,29 @@
+package reviewer
+
+import (
+	"testing"
+
+	"github.com/rendis/surveygo/v2/question/types/text"
+)
+
+func TestReviewFreeText_Length(t *testing.T) {
+	minLength, maxLength := 5, 5
+	value := &text.FreeText{Min: &minLength, Max: &maxLength}
+
+	tests := []struct {
+		answer string
+		valid  bool
+	}{
+		{"ñandú", true}, // 5 characters, 7 bytes
+		{"abcde", true},
+		{"ñand", false},
+		{"ñandús", false},
+	}
+
+	for _, tt := range tests {
+		err := reviewFreeText(value, []any{tt.answer})
+		if (err == nil) != tt.valid {
+			t.Errorf("%q: error = %v, want valid %v", tt.answer, err, tt.valid)
+		}
+	}
+}
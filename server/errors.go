@@ -0,0 +1,103 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// ErrorCode identifies the kind of error returned by the server.
+type ErrorCode string
+
+const (
+	// CodeNotFound the survey, version or response does not exist.
+	CodeNotFound ErrorCode = "not_found"
+	// CodeInvalidRequest the request body or parameters are malformed.
+	CodeInvalidRequest ErrorCode = "invalid_request"
+	// CodeUnsupportedFormat the requested output format is not supported by the endpoint.
+	CodeUnsupportedFormat ErrorCode = "unsupported_format"
+	// CodeInvalidAnswers at least one submitted answer is invalid.
+	CodeInvalidAnswers ErrorCode = "invalid_answers"
+	// CodeIncompleteAnswers required questions are unanswered and Options.RequireComplete is set.
+	CodeIncompleteAnswers ErrorCode = "incomplete_answers"
+	// CodeConflict the resource already exists.
+	CodeConflict ErrorCode = "conflict"
+	// CodeInternal an unexpected error occurred.
+	CodeInternal ErrorCode = "internal"
+)
+
+// Error is a structured error returned by the server as {"error": {...}}.
+// Handlers and middlewares can return it through WriteError to control the status and code.
+type Error struct {
+	// Status is the HTTP status of the response.
+	Status int `json:"-"`
+
+	// Code identifies the kind of error.
+	Code ErrorCode `json:"code"`
+
+	// Message describes the error.
+	Message string `json:"message"`
+
+	// Resume is the resume of the reviewed answers, with the invalid answers, for answers errors.
+	Resume *surveygo.SurveyResume `json:"resume,omitempty"`
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// internalErrorMessage is the message of the internal errors, whose details are not returned to clients.
+const internalErrorMessage = "internal server error"
+
+// WriteError writes an error response. Errors other than *Error are mapped from the store errors,
+// defaulting to an internal error with a generic message.
+func WriteError(w http.ResponseWriter, err error) {
+	e := asError(err)
+	writeJSON(w, e.Status, map[string]*Error{"error": e})
+}
+
+// writeError writes an error response, passing the unexpected errors to Options.ErrorHook.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e := asError(err)
+	if e.Code == CodeInternal && s.opts.ErrorHook != nil {
+		s.opts.ErrorHook(r, err)
+	}
+	writeJSON(w, e.Status, map[string]*Error{"error": e})
+}
+
+// asError returns err as a structured error.
+func asError(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return toError(err)
+}
+
+// toError maps an error to a structured error.
+func toError(err error) *Error {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: err.Error()}
+	case errors.Is(err, store.ErrAlreadyExists), errors.Is(err, store.ErrRevisionConflict):
+		return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: err.Error()}
+	default:
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return &Error{Status: http.StatusRequestEntityTooLarge, Code: CodeInvalidRequest, Message: err.Error()}
+		}
+		return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: internalErrorMessage}
+	}
+}
+
+func unsupportedFormat(format, supported string) *Error {
+	return &Error{
+		Status:  http.StatusBadRequest,
+		Code:    CodeUnsupportedFormat,
+		Message: fmt.Sprintf("unsupported format '%s', expected one of %s", format, supported),
+	}
+}
@@ -0,0 +1,280 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/render"
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// SubmitResult is the body returned by the submit answers endpoint.
+type SubmitResult struct {
+	// ResponseID is the id of the stored response, empty when the server has no response store.
+	ResponseID string `json:"responseId,omitempty"`
+
+	// Resume is the resume of the reviewed answers.
+	Resume *surveygo.SurveyResume `json:"resume"`
+}
+
+// getSurvey returns a survey definition, localized when a Localizer is configured.
+func (s *Server) getSurvey(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.loadSurvey(r)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	survey := rec.Survey
+	if s.opts.Localizer != nil {
+		lang := r.URL.Query().Get("lang")
+		if lang == "" {
+			lang = r.Header.Get("Accept-Language")
+		}
+		if lang != "" {
+			if survey, err = s.opts.Localizer(r.Context(), survey, lang); err != nil {
+				s.writeError(w, r, err)
+				return
+			}
+			if survey == nil {
+				s.writeError(w, r, fmt.Errorf("localizer returned no survey for language '%s'", lang))
+				return
+			}
+		}
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf(`"%s@%s#%d"`, survey.NameId, survey.Version, rec.Revision))
+	writeJSON(w, http.StatusOK, survey)
+}
+
+// listVersions returns the stored versions of a survey, oldest first.
+func (s *Server) listVersions(w http.ResponseWriter, r *http.Request) {
+	versions, err := s.surveys.ListSurveyVersions(r.Context(), r.PathValue("nameId"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if len(versions) == 0 {
+		s.writeError(w, r, store.ErrNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, versions)
+}
+
+// submitAnswers reviews the answers of the request body and stores them when they are valid.
+func (s *Server) submitAnswers(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.loadSurvey(r)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	answers, err := surveygo.ParseAnswers(http.MaxBytesReader(w, r.Body, s.opts.MaxBodyBytes))
+	var maxBytesErr *http.MaxBytesError
+	if err != nil && !errors.As(err, &maxBytesErr) {
+		err = &Error{Status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: err.Error()}
+	}
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	resume, err := rec.Survey.ReviewAnswers(answers)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	if len(resume.InvalidAnswers) > 0 {
+		s.writeError(w, r, &Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    CodeInvalidAnswers,
+			Message: fmt.Sprintf("%d invalid answers", len(resume.InvalidAnswers)),
+			Resume:  resume,
+		})
+		return
+	}
+
+	if s.opts.RequireComplete && resume.TotalRequiredQuestionsAnswered < resume.TotalRequiredQuestions {
+		s.writeError(w, r, &Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    CodeIncompleteAnswers,
+			Message: fmt.Sprintf("%d of %d required questions answered", resume.TotalRequiredQuestionsAnswered, resume.TotalRequiredQuestions),
+			Resume:  resume,
+		})
+		return
+	}
+
+	if s.opts.Responses == nil {
+		writeJSON(w, http.StatusOK, &SubmitResult{Resume: resume})
+		return
+	}
+
+	resp := &store.Response{
+		SurveyNameId:  rec.Survey.NameId,
+		SurveyVersion: rec.Survey.Version,
+		Answers:       answers,
+	}
+	if err = s.opts.Responses.SaveResponse(r.Context(), resp); err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/surveys/%s/versions/%s/responses/%s/render",
+		url.PathEscape(resp.SurveyNameId), url.PathEscape(resp.SurveyVersion), url.PathEscape(resp.ID)))
+	writeJSON(w, http.StatusCreated, &SubmitResult{ResponseID: resp.ID, Resume: resume})
+}
+
+// renderResponse renders a stored response in the format of the "format" query parameter:
+// json (SurveyCard, default), csv, html (with its stylesheet inlined), tiptap, pdf or xlsx.
+func (s *Server) renderResponse(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Responses == nil {
+		s.writeError(w, r, &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: "responses are not stored by this server"})
+		return
+	}
+
+	rec, err := s.loadSurvey(r)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	resp, err := s.opts.Responses.GetResponse(r.Context(), r.PathValue("responseId"))
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+	if resp.SurveyNameId != rec.Survey.NameId || resp.SurveyVersion != rec.Survey.Version {
+		s.writeError(w, r, store.ErrNotFound)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	var opts render.OutputOptions
+	switch format {
+	case "", "json":
+		opts.JSON = true
+	case "csv":
+		opts.CSV = true
+	case "html":
+		opts.HTML = true
+	case "tiptap":
+		opts.TipTap = true
+	case "pdf":
+		opts.PDF = true
+	case "xlsx":
+		b, err := render.AnswersToXLSX(rec.Survey, resp.ID, resp.Answers)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, resp.ID))
+		writeBytes(w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", b)
+		return
+	default:
+		s.writeError(w, r, unsupportedFormat(format, "json, csv, html, tiptap, pdf, xlsx"))
+		return
+	}
+
+	res, err := render.AnswersTo(rec.Survey, resp.Answers, opts)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	switch {
+	case res.CSV != nil:
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, resp.ID))
+		writeBytes(w, "text/csv; charset=utf-8", res.CSV)
+	case res.HTML != nil:
+		writeBytes(w, "text/html; charset=utf-8", inlineCSS(res.HTML))
+	case res.TipTap != nil:
+		writeJSON(w, http.StatusOK, res.TipTap)
+	case res.PDF != nil:
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, resp.ID))
+		writeBytes(w, "application/pdf", res.PDF)
+	default:
+		writeJSON(w, http.StatusOK, res.JSON)
+	}
+}
+
+// tree returns the definition tree of a survey in the format of the "format" query parameter: json (default) or html.
+func (s *Server) tree(w http.ResponseWriter, r *http.Request) {
+	rec, err := s.loadSurvey(r)
+	if err != nil {
+		s.writeError(w, r, err)
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		tree, err := render.DefinitionTreeJSON(rec.Survey)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, tree)
+	case "html":
+		b, err := render.DefinitionTreeHTML(rec.Survey)
+		if err != nil {
+			s.writeError(w, r, err)
+			return
+		}
+		writeBytes(w, "text/html; charset=utf-8", b)
+	default:
+		s.writeError(w, r, unsupportedFormat(format, "json, html"))
+	}
+}
+
+// loadSurvey returns the survey of the request path, resolving the latest version when needed.
+func (s *Server) loadSurvey(r *http.Request) (*store.SurveyRecord, error) {
+	nameId := r.PathValue("nameId")
+	version := r.PathValue("version")
+
+	if version == "" || version == LatestVersion {
+		latest, err := s.latestVersion(r.Context(), nameId)
+		if err != nil {
+			return nil, err
+		}
+		version = latest
+	}
+
+	return s.surveys.GetSurvey(r.Context(), nameId, version)
+}
+
+// latestVersion returns the most recently stored version of a survey.
+func (s *Server) latestVersion(ctx context.Context, nameId string) (string, error) {
+	versions, err := s.surveys.ListSurveyVersions(ctx, nameId)
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", store.ErrNotFound
+	}
+	return versions[len(versions)-1], nil
+}
+
+// inlineCSS replaces the stylesheet link of a rendered HTML card with its CSS.
+func inlineCSS(res *render.HTMLResult) []byte {
+	link := []byte(`<link rel="stylesheet" href="card.css">`)
+	style := append(append([]byte("<style>\n"), res.CSS...), []byte("</style>")...)
+	return bytes.Replace(res.HTML, link, style, 1)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeBytes(w http.ResponseWriter, contentType string, b []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,133 @@
+// Package server exposes survey definitions and answer submission over HTTP.
+//
+// The Server is an http.Handler backed by a store.SurveyStore (and optionally a store.ResponseStore),
+// so it can be mounted in any net/http application:
+//
+//	GET  /surveys/{nameId}                                         latest version of the survey
+//	GET  /surveys/{nameId}/versions                                stored versions, oldest first
+//	GET  /surveys/{nameId}/versions/{version}                      survey definition
+//	POST /surveys/{nameId}/versions/{version}/answers              review (and store) answers
+//	GET  /surveys/{nameId}/versions/{version}/responses/{id}/render stored response in a render format
+//	GET  /surveys/{nameId}/versions/{version}/tree                  definition tree (json or html)
+//
+// The version "latest" resolves to the most recently stored version of the survey.
+package server
+
+import (
+	"context"
+	"net/http"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// LatestVersion is the version path value resolved to the most recently stored version of a survey.
+const LatestVersion = "latest"
+
+// Operation identifies the endpoint handling a request.
+type Operation string
+
+const (
+	// OpGetSurvey returns a survey definition.
+	OpGetSurvey Operation = "get-survey"
+	// OpListVersions returns the stored versions of a survey.
+	OpListVersions Operation = "list-versions"
+	// OpSubmitAnswers reviews and stores answers.
+	OpSubmitAnswers Operation = "submit-answers"
+	// OpRender renders a stored response.
+	OpRender Operation = "render"
+	// OpTree returns the definition tree of a survey.
+	OpTree Operation = "tree"
+)
+
+// Middleware wraps the handler of an endpoint, e.g. to authenticate or authorize the request.
+// Middlewares run after routing, so r.PathValue("nameId"), r.PathValue("version") and OperationFromContext
+// are available.
+type Middleware func(next http.Handler) http.Handler
+
+// Localizer returns the survey to serve for the requested language (the "lang" query parameter,
+// or the Accept-Language header when absent), e.g. with translated titles and labels.
+type Localizer func(ctx context.Context, s *surveygo.Survey, lang string) (*surveygo.Survey, error)
+
+// ErrorHook receives the unexpected errors (500 internal) of a request, e.g. to log them.
+// The client only receives a generic message.
+type ErrorHook func(r *http.Request, err error)
+
+// Options configures a Server.
+type Options struct {
+	// Responses stores submitted answers. When nil, answers are only reviewed and the render endpoint is disabled.
+	Responses store.ResponseStore
+
+	// Middlewares wrap every endpoint, the first one being the outermost.
+	Middlewares []Middleware
+
+	// Localizer, if set, is applied to the survey served by the get survey endpoints.
+	Localizer Localizer
+
+	// RequireComplete rejects submitted answers that leave required questions unanswered.
+	RequireComplete bool
+
+	// MaxBodyBytes limits the size of submitted answers. Defaults to 1 MiB.
+	MaxBodyBytes int64
+
+	// ErrorHook, if set, is called with the unexpected errors returned to clients as 500 internal.
+	ErrorHook ErrorHook
+}
+
+// Server serves surveys from a store.
+type Server struct {
+	surveys store.SurveyStore
+	opts    Options
+	mux     *http.ServeMux
+}
+
+// New creates a Server serving the surveys of the given store.
+// Args:
+//   - surveys: the store of survey definitions (required)
+//   - opts: the server options (optional)
+//
+// Returns:
+//   - *Server: the server, ready to be used as an http.Handler
+func New(surveys store.SurveyStore, opts *Options) *Server {
+	s := &Server{surveys: surveys, mux: http.NewServeMux()}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.MaxBodyBytes <= 0 {
+		s.opts.MaxBodyBytes = 1 << 20
+	}
+
+	s.handle("GET /surveys/{nameId}", OpGetSurvey, s.getSurvey)
+	s.handle("GET /surveys/{nameId}/versions", OpListVersions, s.listVersions)
+	s.handle("GET /surveys/{nameId}/versions/{version}", OpGetSurvey, s.getSurvey)
+	s.handle("POST /surveys/{nameId}/versions/{version}/answers", OpSubmitAnswers, s.submitAnswers)
+	s.handle("GET /surveys/{nameId}/versions/{version}/responses/{responseId}/render", OpRender, s.renderResponse)
+	s.handle("GET /surveys/{nameId}/versions/{version}/tree", OpTree, s.tree)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handle registers an endpoint wrapped with the configured middlewares.
+func (s *Server) handle(pattern string, op Operation, h http.HandlerFunc) {
+	var handler http.Handler = h
+	for i := len(s.opts.Middlewares) - 1; i >= 0; i-- {
+		handler = s.opts.Middlewares[i](handler)
+	}
+
+	s.mux.Handle(pattern, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), operationKey{}, op)))
+	}))
+}
+
+type operationKey struct{}
+
+// OperationFromContext returns the operation of the request being served, if any.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}
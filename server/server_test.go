@@ -0,0 +1,425 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	sqlstore "github.com/rendis/surveygo/v2/store/sql"
+	_ "modernc.org/sqlite"
+)
+
+func newTestStore(t *testing.T) *sqlstore.Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "surveygo.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	st, err := sqlstore.New(db, sqlstore.DialectSQLite)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err = st.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	b, err := os.ReadFile("testdata/survey.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, version := range []string{"1", "2"} {
+		sv, err := surveygo.ParseFromBytes(b)
+		if err != nil {
+			t.Fatalf("ParseFromBytes: %v", err)
+		}
+		sv.Version = version
+		sv.Title = "Household v" + version
+		if _, err = st.CreateSurvey(context.Background(), sv); err != nil {
+			t.Fatalf("CreateSurvey: %v", err)
+		}
+	}
+	return st
+}
+
+func newTestServer(t *testing.T, opts *Options) (*httptest.Server, *sqlstore.Store) {
+	t.Helper()
+	st := newTestStore(t)
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Responses == nil {
+		opts.Responses = st
+	}
+	srv := httptest.NewServer(New(st, opts))
+	t.Cleanup(srv.Close)
+	return srv, st
+}
+
+func do(t *testing.T, method, url, body string) (*http.Response, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, b
+}
+
+func errorCode(t *testing.T, b []byte) ErrorCode {
+	t.Helper()
+	var body struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil || body.Error == nil {
+		t.Fatalf("expected error body, got %s", b)
+	}
+	return body.Error.Code
+}
+
+func TestGetSurvey(t *testing.T) {
+	srv, _ := newTestServer(t, nil)
+
+	tests := []struct {
+		path    string
+		status  int
+		version string
+	}{
+		{"/surveys/household", http.StatusOK, "2"},
+		{"/surveys/household/versions/latest", http.StatusOK, "2"},
+		{"/surveys/household/versions/1", http.StatusOK, "1"},
+		{"/surveys/household/versions/9", http.StatusNotFound, ""},
+		{"/surveys/unknown", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, b := do(t, http.MethodGet, srv.URL+tt.path, "")
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, b)
+			}
+			if tt.status != http.StatusOK {
+				if code := errorCode(t, b); code != CodeNotFound {
+					t.Errorf("code = %s, want %s", code, CodeNotFound)
+				}
+				return
+			}
+
+			sv, err := surveygo.ParseFromBytes(b)
+			if err != nil {
+				t.Fatalf("served survey does not parse: %v", err)
+			}
+			if sv.Version != tt.version {
+				t.Errorf("version = %s, want %s", sv.Version, tt.version)
+			}
+			if resp.Header.Get("ETag") == "" {
+				t.Error("expected ETag header")
+			}
+		})
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	srv, _ := newTestServer(t, nil)
+
+	resp, b := do(t, http.MethodGet, srv.URL+"/surveys/household/versions", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, b)
+	}
+	var versions []string
+	if err := json.Unmarshal(b, &versions); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(versions, ",") != "1,2" {
+		t.Errorf("versions = %v, want [1 2]", versions)
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	var gotLang string
+	srv, _ := newTestServer(t, &Options{
+		Localizer: func(_ context.Context, s *surveygo.Survey, lang string) (*surveygo.Survey, error) {
+			gotLang = lang
+			s.Title = "Hogar"
+			return s, nil
+		},
+	})
+
+	resp, b := do(t, http.MethodGet, srv.URL+"/surveys/household?lang=es", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, b)
+	}
+	if gotLang != "es" || !strings.Contains(string(b), `"title":"Hogar"`) {
+		t.Errorf("expected localized survey for 'es', got lang '%s': %s", gotLang, b)
+	}
+}
+
+func TestLocalizer_NilSurvey(t *testing.T) {
+	var hookErr error
+	srv, _ := newTestServer(t, &Options{
+		Localizer: func(context.Context, *surveygo.Survey, string) (*surveygo.Survey, error) {
+			return nil, nil
+		},
+		ErrorHook: func(_ *http.Request, err error) { hookErr = err },
+	})
+
+	resp, b := do(t, http.MethodGet, srv.URL+"/surveys/household?lang=es", "")
+	if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(string(b), `"code":"internal"`) {
+		t.Errorf("status = %d: %s", resp.StatusCode, b)
+	}
+
+	// the details go to the hook, not to the client
+	if strings.Contains(string(b), "localizer") {
+		t.Errorf("internal error details leaked to the client: %s", b)
+	}
+	if hookErr == nil || !strings.Contains(hookErr.Error(), "localizer returned no survey") {
+		t.Errorf("ErrorHook error = %v", hookErr)
+	}
+}
+
+func TestSubmitAndRender(t *testing.T) {
+	srv, st := newTestServer(t, nil)
+
+	answers := `{"q-city": "Lisbon", "q-pets": ["dog"], "q-rooms": 4,
+		"grp-members": [{"q-name": ["Ana"], "q-age": [34], "grp-jobs": [{"q-job": ["Teacher"]}]}]}`
+
+	resp, b := do(t, http.MethodPost, srv.URL+"/surveys/household/versions/1/answers", answers)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d: %s", resp.StatusCode, b)
+	}
+
+	var res SubmitResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ResponseID == "" || res.Resume == nil || res.Resume.TotalRequiredQuestionsAnswered != 1 {
+		t.Fatalf("unexpected result: %s", b)
+	}
+
+	stored, err := st.GetResponse(context.Background(), res.ResponseID)
+	if err != nil {
+		t.Fatalf("GetResponse: %v", err)
+	}
+	if stored.SurveyVersion != "1" {
+		t.Errorf("stored version = %s, want 1", stored.SurveyVersion)
+	}
+
+	render := srv.URL + resp.Header.Get("Location")
+	tests := []struct {
+		format      string
+		contentType string
+		contains    string
+	}{
+		{"", "application/json", `"sections"`},
+		{"csv", "text/csv", "Lisbon"},
+		{"html", "text/html", "<style>"},
+		{"tiptap", "application/json", `"type":"doc"`},
+		{"pdf", "application/pdf", "%PDF-"},
+		{"xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "PK"},
+	}
+	for _, tt := range tests {
+		t.Run("render "+tt.format, func(t *testing.T) {
+			resp, b := do(t, http.MethodGet, render+"?format="+tt.format, "")
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("status = %d: %s", resp.StatusCode, b)
+			}
+			if !strings.HasPrefix(resp.Header.Get("Content-Type"), tt.contentType) {
+				t.Errorf("content type = %s, want %s", resp.Header.Get("Content-Type"), tt.contentType)
+			}
+			if !strings.Contains(string(b), tt.contains) {
+				t.Errorf("expected body to contain %s, got: %s", tt.contains, b)
+			}
+		})
+	}
+
+	resp, b = do(t, http.MethodGet, render+"?format=docx", "")
+	if resp.StatusCode != http.StatusBadRequest || errorCode(t, b) != CodeUnsupportedFormat {
+		t.Errorf("expected unsupported format, got %d: %s", resp.StatusCode, b)
+	}
+
+	// the response belongs to version 1
+	resp, _ = do(t, http.MethodGet, srv.URL+"/surveys/household/versions/2/responses/"+res.ResponseID+"/render", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestSubmitAnswers_LocationEscaped(t *testing.T) {
+	srv, st := newTestServer(t, nil)
+
+	rec, err := st.GetSurvey(context.Background(), "household", "1")
+	if err != nil {
+		t.Fatalf("GetSurvey: %v", err)
+	}
+	rec.Survey.Version = "2024/10 beta"
+	if _, err = st.CreateSurvey(context.Background(), rec.Survey); err != nil {
+		t.Fatalf("CreateSurvey: %v", err)
+	}
+
+	resp, b := do(t, http.MethodPost, srv.URL+"/surveys/household/versions/2024%2F10%20beta/answers", `{"q-city": "Lisbon"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status = %d: %s", resp.StatusCode, b)
+	}
+
+	location := resp.Header.Get("Location")
+	if !strings.HasPrefix(location, "/surveys/household/versions/2024%2F10%20beta/responses/") {
+		t.Fatalf("Location = %s", location)
+	}
+	if resp, b = do(t, http.MethodGet, srv.URL+location, ""); resp.StatusCode != http.StatusOK {
+		t.Errorf("render status = %d: %s", resp.StatusCode, b)
+	}
+}
+
+func TestSubmitErrors(t *testing.T) {
+	srv, _ := newTestServer(t, &Options{RequireComplete: true, MaxBodyBytes: 256})
+	url := srv.URL + "/surveys/household/versions/latest/answers"
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   ErrorCode
+	}{
+		{"malformed", `{"q-city": `, http.StatusBadRequest, CodeInvalidRequest},
+		{"not an object", `["q-city"]`, http.StatusBadRequest, CodeInvalidRequest},
+		{"too large", `{"q-city": "` + strings.Repeat("x", 300) + `"}`, http.StatusRequestEntityTooLarge, CodeInvalidRequest},
+		{"invalid answers", `{"q-city": "Lisbon", "q-pets": ["fish"], "q-rooms": 40}`, http.StatusUnprocessableEntity, CodeInvalidAnswers},
+		{"incomplete", `{"q-rooms": 4}`, http.StatusUnprocessableEntity, CodeIncompleteAnswers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, b := do(t, http.MethodPost, url, tt.body)
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d: %s", resp.StatusCode, tt.status, b)
+			}
+			if code := errorCode(t, b); code != tt.code {
+				t.Errorf("code = %s, want %s", code, tt.code)
+			}
+		})
+	}
+
+	_, b := do(t, http.MethodPost, url, `{"q-city": "Lisbon", "q-pets": ["fish"], "q-rooms": 40}`)
+	var body struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Resume == nil || len(body.Error.Resume.InvalidAnswers) != 2 {
+		t.Errorf("expected 2 invalid answers, got: %s", b)
+	}
+}
+
+func TestSubmitWithoutResponseStore(t *testing.T) {
+	srv := httptest.NewServer(New(newTestStore(t), nil))
+	defer srv.Close()
+
+	resp, b := do(t, http.MethodPost, srv.URL+"/surveys/household/versions/1/answers", `{"q-city": "Lisbon"}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.StatusCode, b)
+	}
+	var res SubmitResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.ResponseID != "" || res.Resume == nil {
+		t.Errorf("expected a resume without response id: %s", b)
+	}
+
+	resp, _ = do(t, http.MethodGet, srv.URL+"/surveys/household/versions/1/responses/any/render", "")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestTree(t *testing.T) {
+	srv, _ := newTestServer(t, nil)
+
+	resp, b := do(t, http.MethodGet, srv.URL+"/surveys/household/versions/1/tree", "")
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(b), `"nameId":"grp-jobs"`) {
+		t.Errorf("unexpected JSON tree %d: %s", resp.StatusCode, b)
+	}
+
+	resp, b = do(t, http.MethodGet, srv.URL+"/surveys/household/versions/1/tree?format=html", "")
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("unexpected HTML tree %d: %s", resp.StatusCode, b)
+	}
+
+	resp, _ = do(t, http.MethodGet, srv.URL+"/surveys/household/versions/1/tree?format=svg", "")
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, _ := OperationFromContext(r.Context())
+			if op == OpSubmitAnswers && r.Header.Get("Authorization") != "Bearer token" {
+				WriteError(w, &Error{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "missing token for survey " + r.PathValue("nameId")})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	srv, _ := newTestServer(t, &Options{Middlewares: []Middleware{trace("outer"), trace("inner"), auth}})
+
+	resp, _ := do(t, http.MethodGet, srv.URL+"/surveys/household", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("middlewares order = %v", order)
+	}
+
+	resp, b := do(t, http.MethodPost, srv.URL+"/surveys/household/versions/1/answers", `{"q-city": "Lisbon"}`)
+	if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(string(b), "survey household") {
+		t.Errorf("expected unauthorized, got %d: %s", resp.StatusCode, b)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/surveys/household/versions/1/answers", strings.NewReader(`{"q-city": "Lisbon"}`))
+	req.Header.Set("Authorization", "Bearer token")
+	authorized, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = authorized.Body.Close()
+	if authorized.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want %d", authorized.StatusCode, http.StatusCreated)
+	}
+}
+
+func TestToError(t *testing.T) {
+	if e := toError(errors.New("boom")); e.Status != http.StatusInternalServerError || e.Code != CodeInternal || e.Message == "boom" {
+		t.Errorf("unexpected error mapping: %+v", e)
+	}
+}
@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func loadSurvey(t *testing.T) *surveygo.Survey {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "all_types.json"))
+	if err != nil {
+		t.Fatalf("reading survey: %v", err)
+	}
+	sv, err := surveygo.ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes: %v", err)
+	}
+	return sv
+}
+
+// TestSurveyBSONRoundTrip verifies that a survey covering every question type survives
+// a BSON marshal/unmarshal cycle with the same typed values and JSON representation.
+func TestSurveyBSONRoundTrip(t *testing.T) {
+	original := loadSurvey(t)
+
+	b, err := bson.Marshal(original)
+	if err != nil {
+		t.Fatalf("bson.Marshal: %v", err)
+	}
+
+	var decoded surveygo.Survey
+	if err := decodeDocument(b, &decoded); err != nil {
+		t.Fatalf("bson.Unmarshal: %v", err)
+	}
+
+	for nameId, q := range original.Questions {
+		got, ok := decoded.Questions[nameId]
+		if !ok {
+			t.Errorf("question %q missing after round-trip", nameId)
+			continue
+		}
+		if reflect.TypeOf(got.Value) != reflect.TypeOf(q.Value) {
+			t.Errorf("question %q: value type = %T, want %T", nameId, got.Value, q.Value)
+		}
+	}
+
+	wantJSON, _ := json.Marshal(original)
+	gotJSON, _ := json.Marshal(&decoded)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("JSON mismatch after BSON round-trip\n got: %s\nwant: %s", gotJSON, wantJSON)
+	}
+
+	if err := decoded.ValidateSurvey(); err != nil {
+		t.Errorf("decoded survey is inconsistent: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// collection is the subset of collection operations used by Store.
+// It is satisfied by driverCollection for a real MongoDB deployment and can be replaced by an in-memory stand-in.
+type collection interface {
+	// insertOne inserts a single document.
+	insertOne(ctx context.Context, doc any) error
+
+	// findOne decodes the first document matching filter into out.
+	// Returns driver.ErrNoDocuments when nothing matches.
+	findOne(ctx context.Context, filter bson.D, out any) error
+
+	// find decodes all documents matching filter, ordered by sort, into out (pointer to slice).
+	find(ctx context.Context, filter bson.D, sort bson.D, out any) error
+
+	// replaceOne replaces the first document matching filter and returns the number of matched documents.
+	replaceOne(ctx context.Context, filter bson.D, doc any) (int64, error)
+
+	// deleteOne deletes the first document matching filter and returns the number of deleted documents.
+	deleteOne(ctx context.Context, filter bson.D) (int64, error)
+
+	// createIndexes creates the given indexes if they do not exist.
+	createIndexes(ctx context.Context, models []driver.IndexModel) error
+}
+
+// collectionOptions decodes embedded documents of untyped fields (metadata, answers) as bson.M.
+var collectionOptions = options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})
+
+// driverCollection implements collection on top of a *driver.Collection.
+type driverCollection struct {
+	c *driver.Collection
+}
+
+func (d *driverCollection) insertOne(ctx context.Context, doc any) error {
+	_, err := d.c.InsertOne(ctx, doc)
+	return err
+}
+
+func (d *driverCollection) findOne(ctx context.Context, filter bson.D, out any) error {
+	return d.c.FindOne(ctx, filter).Decode(out)
+}
+
+func (d *driverCollection) find(ctx context.Context, filter bson.D, sort bson.D, out any) error {
+	cursor, err := d.c.Find(ctx, filter, options.Find().SetSort(sort))
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, out)
+}
+
+func (d *driverCollection) replaceOne(ctx context.Context, filter bson.D, doc any) (int64, error) {
+	res, err := d.c.ReplaceOne(ctx, filter, doc)
+	if err != nil {
+		return 0, err
+	}
+	return res.MatchedCount, nil
+}
+
+func (d *driverCollection) deleteOne(ctx context.Context, filter bson.D) (int64, error) {
+	res, err := d.c.DeleteOne(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (d *driverCollection) createIndexes(ctx context.Context, models []driver.IndexModel) error {
+	_, err := d.c.Indexes().CreateMany(ctx, models)
+	return err
+}
@@ -0,0 +1,246 @@
+package mongo
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// memCollection is an in-memory stand-in for a MongoDB collection.
+// Documents are kept as raw BSON, so every operation goes through the same encoding and decoding
+// as a real deployment. Filters support top-level equality only; unique indexes and _id are enforced.
+type memCollection struct {
+	mu         sync.Mutex
+	docs       []bson.Raw
+	uniqueKeys [][]string
+}
+
+func newMemCollection() *memCollection {
+	return &memCollection{uniqueKeys: [][]string{{"_id"}}}
+}
+
+// decodeDocument decodes raw BSON the same way collectionOptions configures the driver.
+func decodeDocument(b []byte, out any) error {
+	dec, err := bson.NewDecoder(bsonrw.NewBSONDocumentReader(b))
+	if err != nil {
+		return err
+	}
+	dec.DefaultDocumentM()
+	return dec.Decode(out)
+}
+
+func (m *memCollection) insertOne(_ context.Context, doc any) error {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkUnique(raw, -1); err != nil {
+		return err
+	}
+	m.docs = append(m.docs, raw)
+	return nil
+}
+
+func (m *memCollection) findOne(_ context.Context, filter bson.D, out any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.docs {
+		if matches(d, filter) {
+			return decodeDocument(d, out)
+		}
+	}
+	return driver.ErrNoDocuments
+}
+
+func (m *memCollection) find(_ context.Context, filter bson.D, sortBy bson.D, out any) error {
+	m.mu.Lock()
+	var found []bson.Raw
+	for _, d := range m.docs {
+		if matches(d, filter) {
+			found = append(found, d)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.SliceStable(found, func(i, j int) bool {
+		for _, e := range sortBy {
+			c := compareRaw(found[i].Lookup(e.Key), found[j].Lookup(e.Key))
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	slice := reflect.ValueOf(out).Elem()
+	slice.Set(reflect.MakeSlice(slice.Type(), 0, len(found)))
+	for _, d := range found {
+		elem := reflect.New(slice.Type().Elem())
+		if err := decodeDocument(d, elem.Interface()); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, elem.Elem()))
+	}
+	return nil
+}
+
+func (m *memCollection) replaceOne(_ context.Context, filter bson.D, doc any) (int64, error) {
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, d := range m.docs {
+		if !matches(d, filter) {
+			continue
+		}
+		// replacements keep the original _id
+		if id, err := d.LookupErr("_id"); err == nil {
+			raw = withID(raw, id)
+		}
+		if err := m.checkUnique(raw, i); err != nil {
+			return 0, err
+		}
+		m.docs[i] = raw
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *memCollection) deleteOne(_ context.Context, filter bson.D) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, d := range m.docs {
+		if matches(d, filter) {
+			m.docs = append(m.docs[:i], m.docs[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *memCollection) createIndexes(_ context.Context, models []driver.IndexModel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, model := range models {
+		if model.Options == nil || model.Options.Unique == nil || !*model.Options.Unique {
+			continue
+		}
+		var keys []string
+		for _, e := range model.Keys.(bson.D) {
+			keys = append(keys, e.Key)
+		}
+		m.uniqueKeys = append(m.uniqueKeys, keys)
+	}
+	return nil
+}
+
+// checkUnique returns a duplicate key error if raw collides with a stored document other than skip.
+func (m *memCollection) checkUnique(raw bson.Raw, skip int) error {
+	for _, keys := range m.uniqueKeys {
+		for i, d := range m.docs {
+			if i == skip {
+				continue
+			}
+			same := true
+			for _, k := range keys {
+				a, errA := raw.LookupErr(k)
+				b, errB := d.LookupErr(k)
+				if errA != nil || errB != nil || !a.Equal(b) {
+					same = false
+					break
+				}
+			}
+			if same {
+				return driver.WriteException{WriteErrors: []driver.WriteError{{
+					Code:    11000,
+					Message: "E11000 duplicate key error: " + strings.Join(keys, ","),
+				}}}
+			}
+		}
+	}
+	return nil
+}
+
+func matches(doc bson.Raw, filter bson.D) bool {
+	for _, e := range filter {
+		got, err := doc.LookupErr(e.Key)
+		if err != nil {
+			return false
+		}
+		t, v, err := bson.MarshalValue(e.Value)
+		if err != nil {
+			return false
+		}
+		if !sameValue(got, t, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameValue(got bson.RawValue, t bsontype.Type, v []byte) bool {
+	if got.Type == t {
+		return bytes.Equal(got.Value, v)
+	}
+	// numeric comparisons across integer widths
+	want := bson.RawValue{Type: t, Value: v}
+	gi, okGot := got.AsInt64OK()
+	wi, okWant := want.AsInt64OK()
+	return okGot && okWant && gi == wi
+}
+
+func compareRaw(a, b bson.RawValue) int {
+	switch a.Type {
+	case bson.TypeDateTime:
+		return cmpInt64(a.DateTime(), b.DateTime())
+	case bson.TypeInt32, bson.TypeInt64:
+		return cmpInt64(a.AsInt64(), b.AsInt64())
+	default:
+		return strings.Compare(a.String(), b.String())
+	}
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// withID rewrites raw so that its _id equals id.
+func withID(raw bson.Raw, id bson.RawValue) bson.Raw {
+	var d bson.D
+	_ = bson.Unmarshal(raw, &d)
+	for i, e := range d {
+		if e.Key == "_id" {
+			d[i].Value = id
+			out, _ := bson.Marshal(d)
+			return out
+		}
+	}
+	d = append(bson.D{{Key: "_id", Value: id}}, d...)
+	out, _ := bson.Marshal(d)
+	return out
+}
@@ -0,0 +1,158 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// responseDocument is the stored representation of a response.
+type responseDocument struct {
+	ID            string           `bson:"_id"`
+	SurveyNameId  string           `bson:"surveyNameId"`
+	SurveyVersion string           `bson:"surveyVersion"`
+	Answers       surveygo.Answers `bson:"answers"`
+	SubmittedAt   time.Time        `bson:"submittedAt"`
+	Metadata      map[string]any   `bson:"metadata,omitempty"`
+}
+
+func (d *responseDocument) toResponse() *store.Response {
+	return &store.Response{
+		ID:            d.ID,
+		SurveyNameId:  d.SurveyNameId,
+		SurveyVersion: d.SurveyVersion,
+		Answers:       normalizeAnswers(d.Answers),
+		SubmittedAt:   d.SubmittedAt,
+		Metadata:      normalizeMap(d.Metadata),
+	}
+}
+
+// SaveResponse stores a new response, assigning ID and SubmittedAt when empty.
+func (s *Store) SaveResponse(ctx context.Context, r *store.Response) error {
+	if r == nil {
+		return errors.New("response is nil")
+	}
+	if r.SurveyNameId == "" || r.SurveyVersion == "" {
+		return errors.New("response survey nameId and version are required")
+	}
+
+	if r.ID == "" {
+		r.ID = primitive.NewObjectID().Hex()
+	}
+	if r.SubmittedAt.IsZero() {
+		r.SubmittedAt = s.timestamp()
+	}
+
+	doc := &responseDocument{
+		ID:            r.ID,
+		SurveyNameId:  r.SurveyNameId,
+		SurveyVersion: r.SurveyVersion,
+		Answers:       r.Answers,
+		SubmittedAt:   r.SubmittedAt,
+		Metadata:      r.Metadata,
+	}
+
+	if err := s.responses.insertOne(ctx, doc); err != nil {
+		if driver.IsDuplicateKeyError(err) {
+			return fmt.Errorf("response '%s': %w", r.ID, store.ErrAlreadyExists)
+		}
+		return fmt.Errorf("inserting response '%s': %w", r.ID, err)
+	}
+
+	return nil
+}
+
+// GetResponse returns the response with the given id.
+func (s *Store) GetResponse(ctx context.Context, id string) (*store.Response, error) {
+	var doc responseDocument
+	if err := s.responses.findOne(ctx, bson.D{{Key: "_id", Value: id}}, &doc); err != nil {
+		if errors.Is(err, driver.ErrNoDocuments) {
+			return nil, fmt.Errorf("response '%s': %w", id, store.ErrNotFound)
+		}
+		return nil, fmt.Errorf("finding response '%s': %w", id, err)
+	}
+	return doc.toResponse(), nil
+}
+
+// ListResponses returns the responses of a survey version, ordered by submission time.
+func (s *Store) ListResponses(ctx context.Context, nameId, version string) ([]*store.Response, error) {
+	var docs []*responseDocument
+	filter := bson.D{{Key: "surveyNameId", Value: nameId}, {Key: "surveyVersion", Value: version}}
+	sort := bson.D{{Key: "submittedAt", Value: 1}, {Key: "_id", Value: 1}}
+	if err := s.responses.find(ctx, filter, sort, &docs); err != nil {
+		return nil, fmt.Errorf("listing responses of survey '%s' version '%s': %w", nameId, version, err)
+	}
+
+	responses := make([]*store.Response, 0, len(docs))
+	for _, d := range docs {
+		responses = append(responses, d.toResponse())
+	}
+	return responses, nil
+}
+
+// normalizeAnswers converts decoded BSON values back to the shapes produced by surveygo.ParseAnswers and
+// expected by Survey.ReviewAnswers: arrays as []any, documents as map[string]any, integers as int and other
+// numbers as float64. Unlike plain encoding/json, integers are not decoded as float64, since slider answers
+// must be int. BSON datetimes become UTC time.Time values.
+func normalizeAnswers(ans surveygo.Answers) surveygo.Answers {
+	if ans == nil {
+		return nil
+	}
+	res := make(surveygo.Answers, len(ans))
+	for k, values := range ans {
+		res[k] = normalizeSlice(values)
+	}
+	return res
+}
+
+func normalizeMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		res[k] = normalizeValue(v)
+	}
+	return res
+}
+
+func normalizeSlice(values []any) []any {
+	if values == nil {
+		return nil
+	}
+	res := make([]any, len(values))
+	for i, v := range values {
+		res[i] = normalizeValue(v)
+	}
+	return res
+}
+
+func normalizeValue(v any) any {
+	switch val := v.(type) {
+	case primitive.A:
+		return normalizeSlice(val)
+	case []any:
+		return normalizeSlice(val)
+	case primitive.M:
+		return normalizeMap(val)
+	case map[string]any:
+		return normalizeMap(val)
+	case primitive.D:
+		return normalizeMap(val.Map())
+	case int32:
+		return int(val)
+	case int64:
+		return int(val)
+	case primitive.DateTime:
+		return val.Time().UTC()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,94 @@
+// Package mongo implements store.SurveyStore and store.ResponseStore on top of MongoDB.
+//
+// Survey definitions are stored one document per nameId+version, with a revision counter used for
+// optimistic concurrency. Responses are stored one document per response.
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rendis/surveygo/v2/store"
+	"go.mongodb.org/mongo-driver/bson"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultSurveysCollection is the collection name used by New for survey definitions.
+	DefaultSurveysCollection = "surveys"
+
+	// DefaultResponsesCollection is the collection name used by New for responses.
+	DefaultResponsesCollection = "responses"
+)
+
+// compile-time interface checks
+var (
+	_ store.SurveyStore   = (*Store)(nil)
+	_ store.ResponseStore = (*Store)(nil)
+)
+
+// Store is a MongoDB backed survey and response store.
+type Store struct {
+	surveys   collection
+	responses collection
+	now       func() time.Time
+}
+
+// New creates a Store using the default collections of the given database.
+func New(db *driver.Database) *Store {
+	return newStore(
+		&driverCollection{c: db.Collection(DefaultSurveysCollection, collectionOptions)},
+		&driverCollection{c: db.Collection(DefaultResponsesCollection, collectionOptions)},
+	)
+}
+
+// NewWithCollections creates a Store using the given collections.
+func NewWithCollections(surveys, responses *driver.Collection) (*Store, error) {
+	sc, err := surveys.Clone(collectionOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cloning surveys collection: %w", err)
+	}
+	rc, err := responses.Clone(collectionOptions)
+	if err != nil {
+		return nil, fmt.Errorf("cloning responses collection: %w", err)
+	}
+	return newStore(&driverCollection{c: sc}, &driverCollection{c: rc}), nil
+}
+
+func newStore(surveys, responses collection) *Store {
+	return &Store{
+		surveys:   surveys,
+		responses: responses,
+		now:       time.Now,
+	}
+}
+
+// EnsureIndexes creates the indexes required by the store:
+//   - surveys: unique (nameId, version)
+//   - responses: (surveyNameId, surveyVersion, submittedAt)
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	surveyIndexes := []driver.IndexModel{{
+		Keys:    bson.D{{Key: "nameId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true).SetName("nameId_version_unique"),
+	}}
+	if err := s.surveys.createIndexes(ctx, surveyIndexes); err != nil {
+		return fmt.Errorf("creating survey indexes: %w", err)
+	}
+
+	responseIndexes := []driver.IndexModel{{
+		Keys:    bson.D{{Key: "surveyNameId", Value: 1}, {Key: "surveyVersion", Value: 1}, {Key: "submittedAt", Value: 1}},
+		Options: options.Index().SetName("survey_submittedAt"),
+	}}
+	if err := s.responses.createIndexes(ctx, responseIndexes); err != nil {
+		return fmt.Errorf("creating response indexes: %w", err)
+	}
+
+	return nil
+}
+
+// timestamp returns the current time truncated to the BSON datetime precision.
+func (s *Store) timestamp() time.Time {
+	return s.now().UTC().Truncate(time.Millisecond)
+}
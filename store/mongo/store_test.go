@@ -0,0 +1,227 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+	driver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TestStore_InMemory runs the store suite against the in-memory collection stand-in.
+func TestStore_InMemory(t *testing.T) {
+	s := newStore(newMemCollection(), newMemCollection())
+	runStoreSuite(t, s)
+}
+
+// TestStore_MongoDB runs the store suite against a real deployment.
+// Set SURVEYGO_MONGO_URI (e.g. mongodb://localhost:27017) to enable it.
+func TestStore_MongoDB(t *testing.T) {
+	uri := os.Getenv("SURVEYGO_MONGO_URI")
+	if uri == "" {
+		t.Skip("SURVEYGO_MONGO_URI not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := driver.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	defer func() { _ = client.Disconnect(context.Background()) }()
+
+	db := client.Database("surveygo_test_" + time.Now().Format("20060102150405"))
+	defer func() { _ = db.Drop(context.Background()) }()
+
+	runStoreSuite(t, New(db))
+}
+
+// TestStore_CreateSurveyWithoutIndexes checks that duplicates are rejected before EnsureIndexes is called.
+func TestStore_CreateSurveyWithoutIndexes(t *testing.T) {
+	ctx := context.Background()
+	s := newStore(newMemCollection(), newMemCollection())
+
+	if _, err := s.CreateSurvey(ctx, loadSurvey(t)); err != nil {
+		t.Fatalf("CreateSurvey: %v", err)
+	}
+	if _, err := s.CreateSurvey(ctx, loadSurvey(t)); !errors.Is(err, store.ErrAlreadyExists) {
+		t.Errorf("duplicate CreateSurvey error = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func runStoreSuite(t *testing.T, s *Store) {
+	ctx := context.Background()
+	if err := s.EnsureIndexes(ctx); err != nil {
+		t.Fatalf("EnsureIndexes: %v", err)
+	}
+
+	t.Run("survey_crud", func(t *testing.T) {
+		sv := loadSurvey(t)
+
+		created, err := s.CreateSurvey(ctx, sv)
+		if err != nil {
+			t.Fatalf("CreateSurvey: %v", err)
+		}
+		if created.Revision != 1 {
+			t.Errorf("revision = %d, want 1", created.Revision)
+		}
+
+		if _, err := s.CreateSurvey(ctx, sv); !errors.Is(err, store.ErrAlreadyExists) {
+			t.Errorf("duplicate CreateSurvey error = %v, want ErrAlreadyExists", err)
+		}
+
+		got, err := s.GetSurvey(ctx, sv.NameId, sv.Version)
+		if err != nil {
+			t.Fatalf("GetSurvey: %v", err)
+		}
+		wantJSON, _ := sv.ToJson()
+		gotJSON, _ := got.Survey.ToJson()
+		if gotJSON != wantJSON {
+			t.Errorf("stored survey differs\n got: %s\nwant: %s", gotJSON, wantJSON)
+		}
+
+		v2 := loadSurvey(t)
+		v2.Version = "2"
+		if _, err := s.CreateSurvey(ctx, v2); err != nil {
+			t.Fatalf("CreateSurvey v2: %v", err)
+		}
+		versions, err := s.ListSurveyVersions(ctx, sv.NameId)
+		if err != nil {
+			t.Fatalf("ListSurveyVersions: %v", err)
+		}
+		if len(versions) != 2 {
+			t.Errorf("versions = %v, want 2 entries", versions)
+		}
+
+		if err := s.DeleteSurvey(ctx, v2.NameId, v2.Version); err != nil {
+			t.Fatalf("DeleteSurvey: %v", err)
+		}
+		if _, err := s.GetSurvey(ctx, v2.NameId, v2.Version); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetSurvey after delete error = %v, want ErrNotFound", err)
+		}
+		if err := s.DeleteSurvey(ctx, v2.NameId, v2.Version); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("second DeleteSurvey error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("optimistic_concurrency", func(t *testing.T) {
+		rec, err := s.GetSurvey(ctx, "all-types", "1")
+		if err != nil {
+			t.Fatalf("GetSurvey: %v", err)
+		}
+
+		rec.Survey.Title = "Updated"
+		updated, err := s.UpdateSurvey(ctx, rec.Survey, rec.Revision)
+		if err != nil {
+			t.Fatalf("UpdateSurvey: %v", err)
+		}
+		if updated.Revision != rec.Revision+1 {
+			t.Errorf("revision = %d, want %d", updated.Revision, rec.Revision+1)
+		}
+		if !updated.CreatedAt.Equal(rec.CreatedAt) {
+			t.Errorf("createdAt changed on update: %v -> %v", rec.CreatedAt, updated.CreatedAt)
+		}
+
+		// stale revision
+		if _, err := s.UpdateSurvey(ctx, rec.Survey, rec.Revision); !errors.Is(err, store.ErrRevisionConflict) {
+			t.Errorf("stale UpdateSurvey error = %v, want ErrRevisionConflict", err)
+		}
+
+		got, _ := s.GetSurvey(ctx, "all-types", "1")
+		if got.Survey.Title != "Updated" {
+			t.Errorf("title = %q, want %q", got.Survey.Title, "Updated")
+		}
+
+		missing := loadSurvey(t)
+		missing.Version = "404"
+		if _, err := s.UpdateSurvey(ctx, missing, 1); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("UpdateSurvey missing error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("responses", func(t *testing.T) {
+		sv := loadSurvey(t)
+		answers := surveygo.Answers{
+			"q-single": {"single-a"},
+			"q-multi":  {"multi-a", "multi-b"},
+			"q-slider": {7},
+			"q-toggle": {true},
+			"q-email":  {"john@example.com"},
+			"grp-asset": {
+				map[string]any{"q-image": []any{"https://example.com/a.png"}},
+				map[string]any{"q-image": []any{"https://example.com/b.png"}},
+			},
+		}
+
+		base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+		second := &store.Response{
+			SurveyNameId:  sv.NameId,
+			SurveyVersion: sv.Version,
+			Answers:       answers,
+			SubmittedAt:   base.Add(time.Hour),
+			Metadata:      map[string]any{"source": "web"},
+		}
+		first := &store.Response{
+			ID:            "resp-1",
+			SurveyNameId:  sv.NameId,
+			SurveyVersion: sv.Version,
+			Answers:       surveygo.Answers{"q-single": {"single-b"}},
+			SubmittedAt:   base,
+		}
+		for _, r := range []*store.Response{second, first} {
+			if err := s.SaveResponse(ctx, r); err != nil {
+				t.Fatalf("SaveResponse: %v", err)
+			}
+		}
+		if second.ID == "" {
+			t.Error("expected generated response id")
+		}
+		if err := s.SaveResponse(ctx, first); !errors.Is(err, store.ErrAlreadyExists) {
+			t.Errorf("duplicate SaveResponse error = %v, want ErrAlreadyExists", err)
+		}
+
+		got, err := s.GetResponse(ctx, second.ID)
+		if err != nil {
+			t.Fatalf("GetResponse: %v", err)
+		}
+		resume, err := sv.ReviewAnswers(got.Answers)
+		if err != nil {
+			t.Fatalf("ReviewAnswers: %v", err)
+		}
+		for _, ia := range resume.InvalidAnswers {
+			t.Fatalf("invalid stored answer %s: %s", ia.QuestionNameId, ia.Error)
+		}
+		if resume.GroupsResume["grp-asset"].AnswerGroups != 2 {
+			t.Errorf("grp-asset instances = %d, want 2", resume.GroupsResume["grp-asset"].AnswerGroups)
+		}
+		if got.Metadata["source"] != "web" {
+			t.Errorf("metadata = %v", got.Metadata)
+		}
+
+		list, err := s.ListResponses(ctx, sv.NameId, sv.Version)
+		if err != nil {
+			t.Fatalf("ListResponses: %v", err)
+		}
+		if len(list) != 2 || list[0].ID != "resp-1" || list[1].ID != second.ID {
+			t.Errorf("ListResponses order = %v", responseIDs(list))
+		}
+
+		if _, err := s.GetResponse(ctx, "missing"); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("GetResponse missing error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func responseIDs(rs []*store.Response) []string {
+	var ids []string
+	for _, r := range rs {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
@@ -0,0 +1,164 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+	"go.mongodb.org/mongo-driver/bson"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// surveyDocument is the stored representation of a survey definition.
+type surveyDocument struct {
+	NameId    string           `bson:"nameId"`
+	Version   string           `bson:"version"`
+	Revision  int64            `bson:"revision"`
+	Survey    *surveygo.Survey `bson:"survey"`
+	CreatedAt time.Time        `bson:"createdAt"`
+	UpdatedAt time.Time        `bson:"updatedAt"`
+}
+
+func (d *surveyDocument) toRecord() *store.SurveyRecord {
+	return &store.SurveyRecord{
+		Survey:    d.Survey,
+		Revision:  d.Revision,
+		CreatedAt: d.CreatedAt,
+		UpdatedAt: d.UpdatedAt,
+	}
+}
+
+// CreateSurvey stores a new survey definition with revision 1.
+// The survey is validated with Survey.ValidateSurvey before being stored.
+// An existing nameId+version is rejected even without the indexes of EnsureIndexes, which also reject
+// concurrent creations of the same nameId+version.
+func (s *Store) CreateSurvey(ctx context.Context, sv *surveygo.Survey) (*store.SurveyRecord, error) {
+	if err := store.CheckSurvey(sv); err != nil {
+		return nil, err
+	}
+
+	_, err := s.findSurvey(ctx, sv.NameId, sv.Version)
+	if err == nil {
+		return nil, fmt.Errorf("survey '%s' version '%s': %w", sv.NameId, sv.Version, store.ErrAlreadyExists)
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	now := s.timestamp()
+	doc := &surveyDocument{
+		NameId:    sv.NameId,
+		Version:   sv.Version,
+		Revision:  1,
+		Survey:    sv,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.surveys.insertOne(ctx, doc); err != nil {
+		if driver.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("survey '%s' version '%s': %w", sv.NameId, sv.Version, store.ErrAlreadyExists)
+		}
+		return nil, fmt.Errorf("inserting survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	return doc.toRecord(), nil
+}
+
+// GetSurvey returns the survey definition for the given nameId and version.
+func (s *Store) GetSurvey(ctx context.Context, nameId, version string) (*store.SurveyRecord, error) {
+	doc, err := s.findSurvey(ctx, nameId, version)
+	if err != nil {
+		return nil, err
+	}
+	return doc.toRecord(), nil
+}
+
+// ListSurveyVersions returns the stored versions of a survey, ordered by creation time.
+func (s *Store) ListSurveyVersions(ctx context.Context, nameId string) ([]string, error) {
+	var docs []*surveyDocument
+	filter := bson.D{{Key: "nameId", Value: nameId}}
+	sort := bson.D{{Key: "createdAt", Value: 1}}
+	if err := s.surveys.find(ctx, filter, sort, &docs); err != nil {
+		return nil, fmt.Errorf("listing versions of survey '%s': %w", nameId, err)
+	}
+
+	versions := make([]string, 0, len(docs))
+	for _, d := range docs {
+		versions = append(versions, d.Version)
+	}
+	return versions, nil
+}
+
+// UpdateSurvey replaces the stored definition if its current revision equals the given revision.
+// The survey is validated with Survey.ValidateSurvey before being stored.
+func (s *Store) UpdateSurvey(ctx context.Context, sv *surveygo.Survey, revision int64) (*store.SurveyRecord, error) {
+	if err := store.CheckSurvey(sv); err != nil {
+		return nil, err
+	}
+
+	current, err := s.findSurvey(ctx, sv.NameId, sv.Version)
+	if err != nil {
+		return nil, err
+	}
+	if current.Revision != revision {
+		return nil, fmt.Errorf("survey '%s' version '%s' is at revision %d, got %d: %w",
+			sv.NameId, sv.Version, current.Revision, revision, store.ErrRevisionConflict)
+	}
+
+	doc := &surveyDocument{
+		NameId:    sv.NameId,
+		Version:   sv.Version,
+		Revision:  revision + 1,
+		Survey:    sv,
+		CreatedAt: current.CreatedAt,
+		UpdatedAt: s.timestamp(),
+	}
+
+	matched, err := s.surveys.replaceOne(ctx, surveyRevisionFilter(sv.NameId, sv.Version, revision), doc)
+	if err != nil {
+		return nil, fmt.Errorf("updating survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	// the document was updated concurrently between the read and the replacement
+	if matched == 0 {
+		return nil, fmt.Errorf("survey '%s' version '%s' was modified concurrently: %w",
+			sv.NameId, sv.Version, store.ErrRevisionConflict)
+	}
+
+	return doc.toRecord(), nil
+}
+
+// DeleteSurvey removes the survey definition for the given nameId and version.
+func (s *Store) DeleteSurvey(ctx context.Context, nameId, version string) error {
+	deleted, err := s.surveys.deleteOne(ctx, surveyFilter(nameId, version))
+	if err != nil {
+		return fmt.Errorf("deleting survey '%s' version '%s': %w", nameId, version, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("survey '%s' version '%s': %w", nameId, version, store.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Store) findSurvey(ctx context.Context, nameId, version string) (*surveyDocument, error) {
+	var doc surveyDocument
+	if err := s.surveys.findOne(ctx, surveyFilter(nameId, version), &doc); err != nil {
+		if errors.Is(err, driver.ErrNoDocuments) {
+			return nil, fmt.Errorf("survey '%s' version '%s': %w", nameId, version, store.ErrNotFound)
+		}
+		return nil, fmt.Errorf("finding survey '%s' version '%s': %w", nameId, version, err)
+	}
+	return &doc, nil
+}
+
+func surveyFilter(nameId, version string) bson.D {
+	return bson.D{{Key: "nameId", Value: nameId}, {Key: "version", Value: version}}
+}
+
+func surveyRevisionFilter(nameId, version string, revision int64) bson.D {
+	return append(surveyFilter(nameId, version), bson.E{Key: "revision", Value: revision})
+}
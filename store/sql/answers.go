@@ -0,0 +1,313 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+// Value types stored in surveygo_answers.value_type.
+const (
+	valueTypeString = "string"
+	valueTypeNumber = "number"
+	valueTypeBool   = "bool"
+	valueTypeNull   = "null"
+	valueTypeJSON   = "json"
+
+	// valueTypeEmpty marks a question answered with an empty slice.
+	valueTypeEmpty = "empty"
+
+	// valueTypeInstance marks a repeatable group instance, so instances without answers are kept.
+	valueTypeInstance = "instance"
+)
+
+// groupPathSeparator separates the segments of answerRow.groupPath.
+// NameIds cannot contain it (see surveygo validNameId).
+const groupPathSeparator = "/"
+
+// answerRow is a single row of the surveygo_answers table.
+//
+// groupPath locates the group instance holding the answer:
+//   - "" for top-level answers (groupInstance is 0)
+//   - "grp" for answers of instance groupInstance of the top-level group "grp"
+//   - "grp/1/sub" for answers of instance groupInstance of group "sub", nested in instance 1 of "grp"
+type answerRow struct {
+	groupPath     string
+	groupInstance int
+	questionId    string
+	valueIndex    int
+	valueType     string
+	value         sql.NullString
+}
+
+// flattenAnswers converts answers into long-format rows.
+// The answers of the repeatable groups (key: group name id) are stored with one instance per object,
+// any other answer, objects included, is stored as question values.
+func flattenAnswers(ans surveygo.Answers, repeatGroups map[string]bool) ([]answerRow, error) {
+	var rows []answerRow
+	if err := flattenInto(ans, repeatGroups, "", 0, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// repeatGroups returns the name ids of the repeatable groups of a survey.
+func repeatGroups(sv *surveygo.Survey) map[string]bool {
+	res := make(map[string]bool)
+	for nameId, g := range sv.Groups {
+		if g.AllowRepeat {
+			res[nameId] = true
+		}
+	}
+	return res
+}
+
+func flattenInto(ans map[string][]any, repeatGroups map[string]bool, groupPath string, instance int, rows *[]answerRow) error {
+	keys := make([]string, 0, len(ans))
+	for k := range ans {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		values := ans[key]
+
+		if instances, ok := groupInstances(values); ok && repeatGroups[key] {
+			path := key
+			if groupPath != "" {
+				path = groupPath + groupPathSeparator + strconv.Itoa(instance) + groupPathSeparator + key
+			}
+			for i, inst := range instances {
+				*rows = append(*rows, answerRow{groupPath: path, groupInstance: i, valueType: valueTypeInstance})
+				if err := flattenInto(inst, repeatGroups, path, i, rows); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if len(values) == 0 {
+			*rows = append(*rows, answerRow{groupPath: groupPath, groupInstance: instance, questionId: key, valueType: valueTypeEmpty})
+			continue
+		}
+
+		for i, v := range values {
+			typ, val, err := encodeValue(v)
+			if err != nil {
+				return fmt.Errorf("question '%s' value %d: %w", key, i, err)
+			}
+			*rows = append(*rows, answerRow{
+				groupPath:     groupPath,
+				groupInstance: instance,
+				questionId:    key,
+				valueIndex:    i,
+				valueType:     typ,
+				value:         val,
+			})
+		}
+	}
+
+	return nil
+}
+
+// groupInstances returns the instances of a repeatable group answer.
+// ok is false when values are not all objects.
+func groupInstances(values []any) ([]map[string][]any, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	instances := make([]map[string][]any, 0, len(values))
+	for _, v := range values {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		inst := make(map[string][]any, len(m))
+		for k, val := range m {
+			if arr, ok := val.([]any); ok {
+				inst[k] = arr
+				continue
+			}
+			inst[k] = []any{val}
+		}
+		instances = append(instances, inst)
+	}
+	return instances, true
+}
+
+func encodeValue(v any) (string, sql.NullString, error) {
+	switch val := v.(type) {
+	case nil:
+		return valueTypeNull, sql.NullString{}, nil
+	case string:
+		return valueTypeString, sql.NullString{String: val, Valid: true}, nil
+	case bool:
+		return valueTypeBool, sql.NullString{String: strconv.FormatBool(val), Valid: true}, nil
+	case json.Number:
+		return valueTypeNumber, sql.NullString{String: val.String(), Valid: true}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return valueTypeNumber, sql.NullString{String: strconv.FormatInt(rv.Int(), 10), Valid: true}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return valueTypeNumber, sql.NullString{String: strconv.FormatUint(rv.Uint(), 10), Valid: true}, nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", sql.NullString{}, fmt.Errorf("unsupported number %v", f)
+		}
+		return valueTypeNumber, sql.NullString{String: strconv.FormatFloat(f, 'f', -1, 64), Valid: true}, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", sql.NullString{}, err
+	}
+	return valueTypeJSON, sql.NullString{String: string(b), Valid: true}, nil
+}
+
+func decodeValue(typ string, val sql.NullString) (any, error) {
+	switch typ {
+	case valueTypeNull:
+		return nil, nil
+	case valueTypeString:
+		return val.String, nil
+	case valueTypeBool:
+		return strconv.ParseBool(val.String)
+	case valueTypeNumber:
+		// integers are restored as int, as expected by Survey.ReviewAnswers (e.g. slider)
+		if i, err := strconv.ParseInt(val.String, 10, 64); err == nil {
+			return int(i), nil
+		}
+		return strconv.ParseFloat(val.String, 64)
+	case valueTypeJSON:
+		var v any
+		err := json.Unmarshal([]byte(val.String), &v)
+		return v, err
+	}
+	return nil, fmt.Errorf("unknown value type '%s'", typ)
+}
+
+// instanceNode rebuilds the answers of a response (root) or of a repeatable group instance.
+type instanceNode struct {
+	answers map[string][]any
+	groups  map[string]map[int]*instanceNode
+}
+
+func newInstanceNode() *instanceNode {
+	return &instanceNode{
+		answers: make(map[string][]any),
+		groups:  make(map[string]map[int]*instanceNode),
+	}
+}
+
+// add places a row in the tree. Rows of a question must be added in value_index order.
+func (n *instanceNode) add(row answerRow) error {
+	node, err := n.resolve(row.groupPath, row.groupInstance)
+	if err != nil {
+		return err
+	}
+
+	switch row.valueType {
+	case valueTypeInstance:
+		return nil
+	case valueTypeEmpty:
+		node.answers[row.questionId] = []any{}
+		return nil
+	}
+
+	v, err := decodeValue(row.valueType, row.value)
+	if err != nil {
+		return fmt.Errorf("question '%s' value %d: %w", row.questionId, row.valueIndex, err)
+	}
+	node.answers[row.questionId] = append(node.answers[row.questionId], v)
+	return nil
+}
+
+// resolve returns the node for a group path and instance, creating intermediate nodes.
+func (n *instanceNode) resolve(groupPath string, instance int) (*instanceNode, error) {
+	if groupPath == "" {
+		return n, nil
+	}
+
+	segments := strings.Split(groupPath, groupPathSeparator)
+	if len(segments)%2 == 0 {
+		return nil, fmt.Errorf("invalid group path '%s'", groupPath)
+	}
+
+	node := n
+	for i := 0; i < len(segments); i += 2 {
+		idx := instance
+		if i+1 < len(segments) {
+			parsed, err := strconv.Atoi(segments[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid group path '%s': %w", groupPath, err)
+			}
+			idx = parsed
+		}
+		node = node.child(segments[i], idx)
+	}
+	return node, nil
+}
+
+func (n *instanceNode) child(group string, idx int) *instanceNode {
+	instances, ok := n.groups[group]
+	if !ok {
+		instances = make(map[int]*instanceNode)
+		n.groups[group] = instances
+	}
+	c, ok := instances[idx]
+	if !ok {
+		c = newInstanceNode()
+		instances[idx] = c
+	}
+	return c
+}
+
+// toAnswers converts the tree back into the shape accepted by Survey.ReviewAnswers.
+func (n *instanceNode) toAnswers() surveygo.Answers {
+	if n == nil {
+		return surveygo.Answers{}
+	}
+
+	res := make(surveygo.Answers, len(n.answers)+len(n.groups))
+	for k, v := range n.answers {
+		res[k] = v
+	}
+	for group, instances := range n.groups {
+		res[group] = instancesToValues(instances)
+	}
+	return res
+}
+
+func instancesToValues(instances map[int]*instanceNode) []any {
+	idxs := make([]int, 0, len(instances))
+	for i := range instances {
+		idxs = append(idxs, i)
+	}
+	sort.Ints(idxs)
+
+	values := make([]any, 0, len(idxs))
+	for _, i := range idxs {
+		inst := instances[i]
+		m := make(map[string]any, len(inst.answers)+len(inst.groups))
+		for k, v := range inst.answers {
+			m[k] = v
+		}
+		for group, nested := range inst.groups {
+			m[group] = instancesToValues(nested)
+		}
+		values = append(values, m)
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// migration is a single schema step. Statements are executed in order inside one transaction.
+type migration struct {
+	version    int
+	statements func(d Dialect) []string
+}
+
+// migrations lists every schema step in order. Never edit an existing entry; append a new one instead.
+var migrations = []migration{
+	{
+		version: 1,
+		statements: func(d Dialect) []string {
+			ts := timestampType(d)
+			return []string{
+				`CREATE TABLE surveygo_surveys (
+					name_id    TEXT   NOT NULL,
+					version    TEXT   NOT NULL,
+					revision   BIGINT NOT NULL,
+					definition TEXT   NOT NULL,
+					created_at ` + ts + ` NOT NULL,
+					updated_at ` + ts + ` NOT NULL,
+					PRIMARY KEY (name_id, version)
+				)`,
+				`CREATE TABLE surveygo_responses (
+					id             TEXT NOT NULL PRIMARY KEY,
+					survey_name_id TEXT NOT NULL,
+					survey_version TEXT NOT NULL,
+					submitted_at   ` + ts + ` NOT NULL,
+					metadata       TEXT
+				)`,
+				`CREATE INDEX surveygo_responses_survey_idx
+					ON surveygo_responses (survey_name_id, survey_version, submitted_at)`,
+				`CREATE TABLE surveygo_answers (
+					response_id    TEXT    NOT NULL,
+					group_path     TEXT    NOT NULL,
+					group_instance INTEGER NOT NULL,
+					question_id    TEXT    NOT NULL,
+					value_index    INTEGER NOT NULL,
+					value_type     TEXT    NOT NULL,
+					value          TEXT,
+					PRIMARY KEY (response_id, group_path, group_instance, question_id, value_index)
+				)`,
+				`CREATE INDEX surveygo_answers_question_idx ON surveygo_answers (question_id)`,
+			}
+		},
+	},
+}
+
+// SchemaVersion returns the latest schema version known by this package.
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrate creates the schema or upgrades it to SchemaVersion.
+// Applied versions are recorded in the surveygo_schema_migrations table; already applied steps are skipped.
+func (s *Store) Migrate(ctx context.Context) error {
+	create := `CREATE TABLE IF NOT EXISTS surveygo_schema_migrations (
+		version    INTEGER NOT NULL PRIMARY KEY,
+		applied_at ` + timestampType(s.dialect) + ` NOT NULL
+	)`
+	if _, err := s.db.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("creating migrations table: %w", err)
+	}
+
+	current, err := s.currentSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := s.applyMigration(ctx, m); err != nil {
+			return fmt.Errorf("applying migration %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) currentSchemaVersion(ctx context.Context) (int, error) {
+	var v sql.NullInt64
+	row := s.db.QueryRowContext(ctx, `SELECT MAX(version) FROM surveygo_schema_migrations`)
+	if err := row.Scan(&v); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return int(v.Int64), nil
+}
+
+func (s *Store) applyMigration(ctx context.Context, m migration) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, stmt := range m.statements(s.dialect) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	insert := s.rebind(`INSERT INTO surveygo_schema_migrations (version, applied_at) VALUES (?, ?)`)
+	if _, err := tx.ExecContext(ctx, insert, m.version, s.timestamp()); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func timestampType(d Dialect) string {
+	if d == DialectPostgres {
+		return "TIMESTAMPTZ"
+	}
+	return "TIMESTAMP"
+}
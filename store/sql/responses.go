@@ -0,0 +1,213 @@
+package sql
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// SaveResponse stores a new response, assigning ID and SubmittedAt when empty.
+// Answers are flattened into surveygo_answers, one row per value. The survey version of the response must
+// be stored, its repeatable groups tell the group instances apart from object answers.
+// Returns ErrNotFound if the survey version is not stored.
+func (s *Store) SaveResponse(ctx context.Context, r *store.Response) error {
+	if r == nil {
+		return errors.New("response is nil")
+	}
+	if r.SurveyNameId == "" || r.SurveyVersion == "" {
+		return errors.New("response survey nameId and version are required")
+	}
+
+	rec, err := s.GetSurvey(ctx, r.SurveyNameId, r.SurveyVersion)
+	if err != nil {
+		return err
+	}
+
+	if r.ID == "" {
+		id, err := newResponseID()
+		if err != nil {
+			return fmt.Errorf("generating response id: %w", err)
+		}
+		r.ID = id
+	}
+	if r.SubmittedAt.IsZero() {
+		r.SubmittedAt = s.timestamp()
+	}
+
+	var metadata sql.NullString
+	if r.Metadata != nil {
+		b, err := json.Marshal(r.Metadata)
+		if err != nil {
+			return fmt.Errorf("marshalling metadata of response '%s': %w", r.ID, err)
+		}
+		metadata = sql.NullString{String: string(b), Valid: true}
+	}
+
+	rows, err := flattenAnswers(r.Answers, repeatGroups(rec.Survey))
+	if err != nil {
+		return fmt.Errorf("flattening answers of response '%s': %w", r.ID, err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	insertResponse := s.rebind(`INSERT INTO surveygo_responses (id, survey_name_id, survey_version, submitted_at, metadata)
+		VALUES (?, ?, ?, ?, ?)`)
+	if _, err := tx.ExecContext(ctx, insertResponse, r.ID, r.SurveyNameId, r.SurveyVersion, r.SubmittedAt.UTC(), metadata); err != nil {
+		if s.isUniqueViolation(err) {
+			return fmt.Errorf("response '%s': %w", r.ID, store.ErrAlreadyExists)
+		}
+		return fmt.Errorf("inserting response '%s': %w", r.ID, err)
+	}
+
+	if len(rows) > 0 {
+		stmt, err := tx.PrepareContext(ctx, s.rebind(`INSERT INTO surveygo_answers
+			(response_id, group_path, group_instance, question_id, value_index, value_type, value)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`))
+		if err != nil {
+			return fmt.Errorf("preparing answers insert: %w", err)
+		}
+		defer func() { _ = stmt.Close() }()
+
+		for _, row := range rows {
+			_, err := stmt.ExecContext(ctx, r.ID, row.groupPath, row.groupInstance, row.questionId, row.valueIndex, row.valueType, row.value)
+			if err != nil {
+				return fmt.Errorf("inserting answer '%s' of response '%s': %w", row.questionId, r.ID, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetResponse returns the response with the given id.
+func (s *Store) GetResponse(ctx context.Context, id string) (*store.Response, error) {
+	query := s.rebind(`SELECT id, survey_name_id, survey_version, submitted_at, metadata
+		FROM surveygo_responses WHERE id = ?`)
+	resp, err := scanResponse(s.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("response '%s': %w", id, store.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding response '%s': %w", id, err)
+	}
+
+	answersQuery := s.rebind(`SELECT response_id, group_path, group_instance, question_id, value_index, value_type, value
+		FROM surveygo_answers WHERE response_id = ?
+		ORDER BY group_path, group_instance, question_id, value_index`)
+	byResponse, err := s.loadAnswers(ctx, answersQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("loading answers of response '%s': %w", id, err)
+	}
+
+	resp.Answers = byResponse[id].toAnswers()
+	return resp, nil
+}
+
+// ListResponses returns the responses of a survey version, ordered by submission time.
+func (s *Store) ListResponses(ctx context.Context, nameId, version string) ([]*store.Response, error) {
+	query := s.rebind(`SELECT id, survey_name_id, survey_version, submitted_at, metadata
+		FROM surveygo_responses WHERE survey_name_id = ? AND survey_version = ?
+		ORDER BY submitted_at, id`)
+	rows, err := s.db.QueryContext(ctx, query, nameId, version)
+	if err != nil {
+		return nil, fmt.Errorf("listing responses of survey '%s' version '%s': %w", nameId, version, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var responses []*store.Response
+	for rows.Next() {
+		resp, err := scanResponse(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning response: %w", err)
+		}
+		responses = append(responses, resp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	answersQuery := s.rebind(`SELECT a.response_id, a.group_path, a.group_instance, a.question_id, a.value_index, a.value_type, a.value
+		FROM surveygo_answers a JOIN surveygo_responses r ON r.id = a.response_id
+		WHERE r.survey_name_id = ? AND r.survey_version = ?
+		ORDER BY a.response_id, a.group_path, a.group_instance, a.question_id, a.value_index`)
+	byResponse, err := s.loadAnswers(ctx, answersQuery, nameId, version)
+	if err != nil {
+		return nil, fmt.Errorf("loading answers of survey '%s' version '%s': %w", nameId, version, err)
+	}
+
+	for _, resp := range responses {
+		resp.Answers = byResponse[resp.ID].toAnswers()
+	}
+	return responses, nil
+}
+
+// loadAnswers runs an answers query and rebuilds the answers tree of each response found.
+func (s *Store) loadAnswers(ctx context.Context, query string, args ...any) (map[string]*instanceNode, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var byResponse = make(map[string]*instanceNode)
+	for rows.Next() {
+		var responseId string
+		var row answerRow
+		if err := rows.Scan(&responseId, &row.groupPath, &row.groupInstance, &row.questionId, &row.valueIndex, &row.valueType, &row.value); err != nil {
+			return nil, err
+		}
+
+		root, ok := byResponse[responseId]
+		if !ok {
+			root = newInstanceNode()
+			byResponse[responseId] = root
+		}
+		if err := root.add(row); err != nil {
+			return nil, err
+		}
+	}
+	return byResponse, rows.Err()
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanResponse(sc rowScanner) (*store.Response, error) {
+	var (
+		resp        store.Response
+		submittedAt time.Time
+		metadata    sql.NullString
+	)
+	if err := sc.Scan(&resp.ID, &resp.SurveyNameId, &resp.SurveyVersion, &submittedAt, &metadata); err != nil {
+		return nil, err
+	}
+	resp.SubmittedAt = submittedAt.UTC()
+
+	if metadata.Valid {
+		if err := json.Unmarshal([]byte(metadata.String), &resp.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshalling metadata of response '%s': %w", resp.ID, err)
+		}
+	}
+	return &resp, nil
+}
+
+func newResponseID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,126 @@
+// Package sql implements store.SurveyStore and store.ResponseStore on top of database/sql.
+//
+// Survey definitions are stored as versioned JSON documents (one row per nameId+version, with a revision
+// counter used for optimistic concurrency). Answers are stored in a normalized long table with one row per
+// answer value, so they can be queried with plain SQL:
+//
+//	SELECT r.id, a.value
+//	FROM surveygo_answers a JOIN surveygo_responses r ON r.id = a.response_id
+//	WHERE r.survey_name_id = 'my-survey' AND a.question_id = 'q-age'
+//
+// Supported dialects are SQLite and PostgreSQL. The caller registers the driver and opens the *sql.DB;
+// Migrate creates or upgrades the schema.
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// Dialect identifies the SQL flavour used by the database.
+type Dialect string
+
+const (
+	// DialectSQLite targets SQLite through modernc.org/sqlite.
+	DialectSQLite Dialect = "sqlite"
+
+	// DialectPostgres targets PostgreSQL (e.g. jackc/pgx/stdlib or lib/pq).
+	DialectPostgres Dialect = "postgres"
+)
+
+// compile-time interface checks
+var (
+	_ store.SurveyStore   = (*Store)(nil)
+	_ store.ResponseStore = (*Store)(nil)
+)
+
+// Store is a database/sql backed survey and response store.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+	now     func() time.Time
+}
+
+// New creates a Store over an open database.
+// Call Migrate before using the store to create or upgrade the schema.
+func New(db *sql.DB, dialect Dialect) (*Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("db is nil")
+	}
+
+	switch dialect {
+	case DialectSQLite, DialectPostgres:
+	default:
+		return nil, fmt.Errorf("unsupported dialect '%s'", dialect)
+	}
+
+	return &Store{db: db, dialect: dialect, now: time.Now}, nil
+}
+
+// rebind rewrites '?' placeholders into the dialect placeholder style.
+func (s *Store) rebind(query string) string {
+	if s.dialect != DialectPostgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Error codes of primary key and unique constraint violations.
+const (
+	sqliteConstraintPrimaryKey = 1555    // SQLITE_CONSTRAINT_PRIMARYKEY
+	sqliteConstraintUnique     = 2067    // SQLITE_CONSTRAINT_UNIQUE
+	postgresUniqueViolation    = "23505" // unique_violation
+)
+
+// sqliteError is implemented by the errors of modernc.org/sqlite (*sqlite.Error), Code is the extended
+// result code.
+type sqliteError interface {
+	error
+	Code() int
+}
+
+// postgresError is implemented by the errors of jackc/pgx (*pgconn.PgError) and lib/pq (*pq.Error).
+type postgresError interface {
+	error
+	SQLState() string
+}
+
+// isUniqueViolation reports whether err is a primary key or unique constraint violation.
+func (s *Store) isUniqueViolation(err error) bool {
+	switch s.dialect {
+	case DialectSQLite:
+		var sqliteErr sqliteError
+		if errors.As(err, &sqliteErr) {
+			code := sqliteErr.Code()
+			return code == sqliteConstraintUnique || code == sqliteConstraintPrimaryKey
+		}
+	case DialectPostgres:
+		var pgErr postgresError
+		if errors.As(err, &pgErr) {
+			return pgErr.SQLState() == postgresUniqueViolation
+		}
+	}
+	return false
+}
+
+// timestamp returns the current time in UTC truncated to microseconds, the precision shared by the dialects.
+func (s *Store) timestamp() time.Time {
+	return s.now().UTC().Truncate(time.Microsecond)
+}
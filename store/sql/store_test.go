@@ -0,0 +1,317 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+	_ "modernc.org/sqlite"
+)
+
+func newSQLiteStore(t *testing.T) *Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "surveygo.db"))
+	if err != nil {
+		t.Fatalf("opening sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	s, err := New(db, DialectSQLite)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := s.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	return s
+}
+
+func loadSurvey(t *testing.T) *surveygo.Survey {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "survey.json"))
+	if err != nil {
+		t.Fatalf("reading survey: %v", err)
+	}
+	sv, err := surveygo.ParseFromBytes(data)
+	if err != nil {
+		t.Fatalf("ParseFromBytes: %v", err)
+	}
+	return sv
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+
+	v, err := s.currentSchemaVersion(ctx)
+	if err != nil {
+		t.Fatalf("currentSchemaVersion: %v", err)
+	}
+	if v != SchemaVersion() {
+		t.Errorf("schema version = %d, want %d", v, SchemaVersion())
+	}
+}
+
+func TestSurveyCRUD(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	sv := loadSurvey(t)
+
+	created, err := s.CreateSurvey(ctx, sv)
+	if err != nil {
+		t.Fatalf("CreateSurvey: %v", err)
+	}
+	if created.Revision != 1 {
+		t.Errorf("revision = %d, want 1", created.Revision)
+	}
+	if _, err := s.CreateSurvey(ctx, sv); !errors.Is(err, store.ErrAlreadyExists) {
+		t.Errorf("duplicate CreateSurvey error = %v, want ErrAlreadyExists", err)
+	}
+
+	got, err := s.GetSurvey(ctx, sv.NameId, sv.Version)
+	if err != nil {
+		t.Fatalf("GetSurvey: %v", err)
+	}
+	wantJSON, _ := sv.ToJson()
+	gotJSON, _ := got.Survey.ToJson()
+	if gotJSON != wantJSON {
+		t.Errorf("stored survey differs\n got: %s\nwant: %s", gotJSON, wantJSON)
+	}
+
+	// optimistic concurrency
+	got.Survey.Title = "Updated"
+	updated, err := s.UpdateSurvey(ctx, got.Survey, got.Revision)
+	if err != nil {
+		t.Fatalf("UpdateSurvey: %v", err)
+	}
+	if updated.Revision != 2 {
+		t.Errorf("revision = %d, want 2", updated.Revision)
+	}
+	if !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("createdAt changed on update: %v -> %v", created.CreatedAt, updated.CreatedAt)
+	}
+	if _, err := s.UpdateSurvey(ctx, got.Survey, got.Revision); !errors.Is(err, store.ErrRevisionConflict) {
+		t.Errorf("stale UpdateSurvey error = %v, want ErrRevisionConflict", err)
+	}
+
+	v2 := loadSurvey(t)
+	v2.Version = "2"
+	if _, err := s.UpdateSurvey(ctx, v2, 1); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("UpdateSurvey missing error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CreateSurvey(ctx, v2); err != nil {
+		t.Fatalf("CreateSurvey v2: %v", err)
+	}
+
+	versions, err := s.ListSurveyVersions(ctx, sv.NameId)
+	if err != nil {
+		t.Fatalf("ListSurveyVersions: %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "1" || versions[1] != "2" {
+		t.Errorf("versions = %v, want [1 2]", versions)
+	}
+
+	if err := s.DeleteSurvey(ctx, sv.NameId, "2"); err != nil {
+		t.Fatalf("DeleteSurvey: %v", err)
+	}
+	if err := s.DeleteSurvey(ctx, sv.NameId, "2"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("second DeleteSurvey error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestResponses_LongTable(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	sv := loadSurvey(t)
+	if _, err := s.CreateSurvey(ctx, sv); err != nil {
+		t.Fatalf("CreateSurvey: %v", err)
+	}
+
+	answers := surveygo.Answers{
+		"q-city":  {"Santiago"},
+		"q-pets":  {"dog", "cat"},
+		"q-rooms": {3},
+		"q-owner": {true},
+		"grp-members": {
+			map[string]any{
+				"q-name": []any{"Ana"},
+				"q-age":  []any{34},
+				"grp-jobs": []any{
+					map[string]any{"q-job": []any{"Nurse"}},
+					map[string]any{"q-job": []any{"Teacher"}},
+				},
+			},
+			map[string]any{},
+		},
+	}
+
+	base := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := &store.Response{
+		SurveyNameId:  sv.NameId,
+		SurveyVersion: sv.Version,
+		Answers:       answers,
+		SubmittedAt:   base.Add(time.Minute),
+		Metadata:      map[string]any{"source": "web"},
+	}
+	if err := s.SaveResponse(ctx, r); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+	if r.ID == "" {
+		t.Fatal("expected generated response id")
+	}
+	earlier := &store.Response{ID: "resp-0", SurveyNameId: sv.NameId, SurveyVersion: sv.Version, SubmittedAt: base,
+		Answers: surveygo.Answers{"q-city": {"Lima"}}}
+	if err := s.SaveResponse(ctx, earlier); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+	if err := s.SaveResponse(ctx, earlier); !errors.Is(err, store.ErrAlreadyExists) {
+		t.Errorf("duplicate SaveResponse error = %v, want ErrAlreadyExists", err)
+	}
+
+	// answers are queryable in long format
+	var job string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM surveygo_answers
+		WHERE response_id = ? AND question_id = 'q-job' AND group_path = 'grp-members/0/grp-jobs' AND group_instance = 1`, r.ID).Scan(&job)
+	if err != nil {
+		t.Fatalf("querying long table: %v", err)
+	}
+	if job != "Teacher" {
+		t.Errorf("job = %q, want Teacher", job)
+	}
+
+	got, err := s.GetResponse(ctx, r.ID)
+	if err != nil {
+		t.Fatalf("GetResponse: %v", err)
+	}
+	if !got.SubmittedAt.Equal(r.SubmittedAt) {
+		t.Errorf("submittedAt = %v, want %v", got.SubmittedAt, r.SubmittedAt)
+	}
+	if got.Metadata["source"] != "web" {
+		t.Errorf("metadata = %v", got.Metadata)
+	}
+
+	resume, err := sv.ReviewAnswers(got.Answers)
+	if err != nil {
+		t.Fatalf("ReviewAnswers: %v", err)
+	}
+	for _, ia := range resume.InvalidAnswers {
+		t.Fatalf("invalid stored answer %s: %s", ia.QuestionNameId, ia.Error)
+	}
+	if n := resume.GroupsResume["grp-members"].AnswerGroups; n != 2 {
+		t.Errorf("grp-members instances = %d, want 2", n)
+	}
+
+	members := got.Answers["grp-members"]
+	first := members[0].(map[string]any)
+	jobs := first["grp-jobs"].([]any)
+	if len(jobs) != 2 || jobs[1].(map[string]any)["q-job"].([]any)[0] != "Teacher" {
+		t.Errorf("nested jobs = %v", jobs)
+	}
+	if pets := got.Answers["q-pets"]; len(pets) != 2 || pets[0] != "dog" || pets[1] != "cat" {
+		t.Errorf("pets = %v, want [dog cat]", pets)
+	}
+
+	list, err := s.ListResponses(ctx, sv.NameId, sv.Version)
+	if err != nil {
+		t.Fatalf("ListResponses: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != "resp-0" || list[1].ID != r.ID {
+		t.Fatalf("ListResponses returned %d responses in unexpected order", len(list))
+	}
+	if list[0].Answers["q-city"][0] != "Lima" {
+		t.Errorf("first response answers = %v", list[0].Answers)
+	}
+
+	if _, err := s.GetResponse(ctx, "missing"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("GetResponse missing error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestResponses_ObjectAnswers(t *testing.T) {
+	s := newSQLiteStore(t)
+	ctx := context.Background()
+	sv := loadSurvey(t)
+
+	// the survey version must be stored
+	r := &store.Response{SurveyNameId: sv.NameId, SurveyVersion: sv.Version, Answers: surveygo.Answers{"q-city": {"Lima"}}}
+	if err := s.SaveResponse(ctx, r); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("SaveResponse without survey error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.CreateSurvey(ctx, sv); err != nil {
+		t.Fatalf("CreateSurvey: %v", err)
+	}
+
+	// object answers of questions (e.g. external questions) are not group instances
+	answers := surveygo.Answers{
+		"q-external":  {map[string]any{"id": "ext-1", "score": float64(4.5)}},
+		"grp-members": {map[string]any{"q-name": []any{"Ana"}}},
+	}
+	r = &store.Response{ID: "resp-objects", SurveyNameId: sv.NameId, SurveyVersion: sv.Version, Answers: answers}
+	if err := s.SaveResponse(ctx, r); err != nil {
+		t.Fatalf("SaveResponse: %v", err)
+	}
+
+	var groupRows int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM surveygo_answers WHERE response_id = ? AND group_path = 'q-external'`,
+		r.ID).Scan(&groupRows); err != nil {
+		t.Fatalf("querying long table: %v", err)
+	}
+	if groupRows != 0 {
+		t.Errorf("object answer stored as %d group rows", groupRows)
+	}
+
+	got, err := s.GetResponse(ctx, r.ID)
+	if err != nil {
+		t.Fatalf("GetResponse: %v", err)
+	}
+	if !reflect.DeepEqual(got.Answers, answers) {
+		t.Errorf("answers = %#v\nwant %#v", got.Answers, answers)
+	}
+}
+
+func TestRebind_Postgres(t *testing.T) {
+	s := &Store{dialect: DialectPostgres}
+	got := s.rebind(`SELECT a FROM t WHERE x = ? AND y = ?`)
+	want := `SELECT a FROM t WHERE x = $1 AND y = $2`
+	if got != want {
+		t.Errorf("rebind = %q, want %q", got, want)
+	}
+}
+
+// pgError mimics *pgconn.PgError and *pq.Error.
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pq: duplicate key value violates unique constraint" }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestIsUniqueViolation_Postgres(t *testing.T) {
+	s := &Store{dialect: DialectPostgres}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"unique violation", &pgError{code: "23505"}, true},
+		{"wrapped unique violation", fmt.Errorf("insert: %w", &pgError{code: "23505"}), true},
+		{"foreign key violation", &pgError{code: "23503"}, false},
+		{"untyped error", errors.New("duplicate key value violates unique constraint"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := s.isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("%s: isUniqueViolation = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
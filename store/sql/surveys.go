@@ -0,0 +1,153 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+	"github.com/rendis/surveygo/v2/store"
+)
+
+// CreateSurvey stores a new survey definition with revision 1.
+// The survey is validated with store.CheckSurvey before being stored.
+func (s *Store) CreateSurvey(ctx context.Context, sv *surveygo.Survey) (*store.SurveyRecord, error) {
+	if err := store.CheckSurvey(sv); err != nil {
+		return nil, err
+	}
+
+	definition, err := json.Marshal(sv)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling survey '%s': %w", sv.NameId, err)
+	}
+
+	now := s.timestamp()
+	query := s.rebind(`INSERT INTO surveygo_surveys (name_id, version, revision, definition, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)`)
+	if _, err := s.db.ExecContext(ctx, query, sv.NameId, sv.Version, 1, string(definition), now, now); err != nil {
+		if s.isUniqueViolation(err) {
+			return nil, fmt.Errorf("survey '%s' version '%s': %w", sv.NameId, sv.Version, store.ErrAlreadyExists)
+		}
+		return nil, fmt.Errorf("inserting survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	return &store.SurveyRecord{Survey: sv, Revision: 1, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+// GetSurvey returns the survey definition for the given nameId and version.
+// The stored definition is parsed with surveygo.ParseFromBytes.
+func (s *Store) GetSurvey(ctx context.Context, nameId, version string) (*store.SurveyRecord, error) {
+	query := s.rebind(`SELECT revision, definition, created_at, updated_at
+		FROM surveygo_surveys WHERE name_id = ? AND version = ?`)
+
+	var (
+		revision             int64
+		definition           string
+		createdAt, updatedAt time.Time
+	)
+	err := s.db.QueryRowContext(ctx, query, nameId, version).Scan(&revision, &definition, &createdAt, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("survey '%s' version '%s': %w", nameId, version, store.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding survey '%s' version '%s': %w", nameId, version, err)
+	}
+
+	sv, err := surveygo.ParseFromBytes([]byte(definition))
+	if err != nil {
+		return nil, fmt.Errorf("parsing stored survey '%s' version '%s': %w", nameId, version, err)
+	}
+
+	return &store.SurveyRecord{
+		Survey:    sv,
+		Revision:  revision,
+		CreatedAt: createdAt.UTC(),
+		UpdatedAt: updatedAt.UTC(),
+	}, nil
+}
+
+// ListSurveyVersions returns the stored versions of a survey, ordered by creation time.
+func (s *Store) ListSurveyVersions(ctx context.Context, nameId string) ([]string, error) {
+	query := s.rebind(`SELECT version FROM surveygo_surveys WHERE name_id = ? ORDER BY created_at, version`)
+	rows, err := s.db.QueryContext(ctx, query, nameId)
+	if err != nil {
+		return nil, fmt.Errorf("listing versions of survey '%s': %w", nameId, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var versions []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scanning version of survey '%s': %w", nameId, err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// UpdateSurvey replaces the stored definition if its current revision equals the given revision.
+// The survey is validated with store.CheckSurvey before being stored.
+func (s *Store) UpdateSurvey(ctx context.Context, sv *surveygo.Survey, revision int64) (*store.SurveyRecord, error) {
+	if err := store.CheckSurvey(sv); err != nil {
+		return nil, err
+	}
+
+	definition, err := json.Marshal(sv)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling survey '%s': %w", sv.NameId, err)
+	}
+
+	now := s.timestamp()
+	query := s.rebind(`UPDATE surveygo_surveys SET revision = ?, definition = ?, updated_at = ?
+		WHERE name_id = ? AND version = ? AND revision = ?`)
+	res, err := s.db.ExecContext(ctx, query, revision+1, string(definition), now, sv.NameId, sv.Version, revision)
+	if err != nil {
+		return nil, fmt.Errorf("updating survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("updating survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	// nothing updated: either the survey does not exist or the revision is stale
+	if affected == 0 {
+		current, err := s.GetSurvey(ctx, sv.NameId, sv.Version)
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("survey '%s' version '%s' is at revision %d, got %d: %w",
+			sv.NameId, sv.Version, current.Revision, revision, store.ErrRevisionConflict)
+	}
+
+	var createdAt time.Time
+	createdQuery := s.rebind(`SELECT created_at FROM surveygo_surveys WHERE name_id = ? AND version = ?`)
+	if err := s.db.QueryRowContext(ctx, createdQuery, sv.NameId, sv.Version).Scan(&createdAt); err != nil {
+		return nil, fmt.Errorf("reading survey '%s' version '%s': %w", sv.NameId, sv.Version, err)
+	}
+
+	return &store.SurveyRecord{Survey: sv, Revision: revision + 1, CreatedAt: createdAt.UTC(), UpdatedAt: now}, nil
+}
+
+// DeleteSurvey removes the survey definition for the given nameId and version.
+// Stored responses are kept.
+func (s *Store) DeleteSurvey(ctx context.Context, nameId, version string) error {
+	query := s.rebind(`DELETE FROM surveygo_surveys WHERE name_id = ? AND version = ?`)
+	res, err := s.db.ExecContext(ctx, query, nameId, version)
+	if err != nil {
+		return fmt.Errorf("deleting survey '%s' version '%s': %w", nameId, version, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("deleting survey '%s' version '%s': %w", nameId, version, err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("survey '%s' version '%s': %w", nameId, version, store.ErrNotFound)
+	}
+	return nil
+}
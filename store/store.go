@@ -0,0 +1,107 @@
+// Package store defines the persistence contracts for survey definitions and responses.
+// Backends live in sub-packages (e.g. store/mongo) and implement SurveyStore and ResponseStore.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	surveygo "github.com/rendis/surveygo/v2"
+)
+
+var (
+	// ErrNotFound is returned when the requested survey or response does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrAlreadyExists is returned when creating a survey whose nameId+version is already stored,
+	// or a response whose id is already stored.
+	ErrAlreadyExists = errors.New("already exists")
+
+	// ErrRevisionConflict is returned when an update is attempted with a stale revision.
+	ErrRevisionConflict = errors.New("revision conflict")
+)
+
+// SurveyRecord is a stored survey definition.
+type SurveyRecord struct {
+	// Survey is the survey definition. The record is keyed by Survey.NameId + Survey.Version.
+	Survey *surveygo.Survey `json:"survey"`
+
+	// Revision is incremented on every update and used for optimistic concurrency.
+	Revision int64 `json:"revision"`
+
+	// CreatedAt is the time the record was created.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// UpdatedAt is the time of the last update.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Response is a stored set of answers for a survey version.
+type Response struct {
+	// ID is the identifier of the response. When empty, the store assigns one.
+	ID string `json:"id"`
+
+	// SurveyNameId is the nameId of the answered survey.
+	SurveyNameId string `json:"surveyNameId"`
+
+	// SurveyVersion is the version of the answered survey.
+	SurveyVersion string `json:"surveyVersion"`
+
+	// Answers are the answers provided by the respondent.
+	Answers surveygo.Answers `json:"answers"`
+
+	// SubmittedAt is the submission time. When zero, the store uses the current time.
+	SubmittedAt time.Time `json:"submittedAt"`
+
+	// Metadata is a map with additional information about the response.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// SurveyStore persists survey definitions keyed by nameId+version.
+type SurveyStore interface {
+	// CreateSurvey stores a new survey definition with revision 1.
+	// Returns ErrAlreadyExists if the nameId+version is already stored.
+	CreateSurvey(ctx context.Context, s *surveygo.Survey) (*SurveyRecord, error)
+
+	// GetSurvey returns the survey definition for the given nameId and version.
+	// Returns ErrNotFound if it does not exist.
+	GetSurvey(ctx context.Context, nameId, version string) (*SurveyRecord, error)
+
+	// ListSurveyVersions returns the stored versions of a survey, oldest first.
+	ListSurveyVersions(ctx context.Context, nameId string) ([]string, error)
+
+	// UpdateSurvey replaces the stored definition if its current revision equals the given revision.
+	// Returns ErrRevisionConflict if the stored revision differs and ErrNotFound if it does not exist.
+	UpdateSurvey(ctx context.Context, s *surveygo.Survey, revision int64) (*SurveyRecord, error)
+
+	// DeleteSurvey removes the survey definition for the given nameId and version.
+	// Returns ErrNotFound if it does not exist.
+	DeleteSurvey(ctx context.Context, nameId, version string) error
+}
+
+// ResponseStore persists survey responses.
+type ResponseStore interface {
+	// SaveResponse stores a new response, assigning ID and SubmittedAt when empty.
+	// Returns ErrAlreadyExists if a response with the same ID is already stored.
+	SaveResponse(ctx context.Context, r *Response) error
+
+	// GetResponse returns the response with the given id.
+	// Returns ErrNotFound if it does not exist.
+	GetResponse(ctx context.Context, id string) (*Response, error)
+
+	// ListResponses returns the responses of a survey version, ordered by submission time.
+	ListResponses(ctx context.Context, nameId, version string) ([]*Response, error)
+}
+
+// CheckSurvey verifies that a survey can be stored: it must be non-nil, carry a nameId and version,
+// and pass Survey.ValidateSurvey.
+func CheckSurvey(s *surveygo.Survey) error {
+	if s == nil {
+		return errors.New("survey is nil")
+	}
+	if s.NameId == "" || s.Version == "" {
+		return errors.New("survey nameId and version are required")
+	}
+	return s.ValidateSurvey()
+}